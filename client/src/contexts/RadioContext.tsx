@@ -30,6 +30,7 @@ export interface Song {
   description: string;
   duration: number;
   position: number;
+  start_offset?: number;
 }
 
 export interface QueueInfo {
@@ -296,7 +297,7 @@ export const RadioProvider: React.FC<{ children?: React.ReactNode }> = ({ childr
 
     const elapsed = calculateElapsedTime(queueInfo.StartTime, currentSong.duration);
     
-    await startPlayback(currentSongFile, elapsed);
+    await startPlayback(currentSongFile, elapsed + (currentSong.start_offset ?? 0));
   }, [currentSongFile, isAudioContextReady, queueInfo.StartTime, getCurrentSong, calculateElapsedTime, startPlayback]);
 
   const fetchQueue = async () => {
@@ -389,7 +390,7 @@ export const RadioProvider: React.FC<{ children?: React.ReactNode }> = ({ childr
 
       // Start playback immediately (song changes start from beginning)
       if (audioToPlay && isAudioContextReady) {
-        await startPlayback(audioToPlay, 0);
+        await startPlayback(audioToPlay, currentSong?.start_offset ?? 0);
       } else {
       }
 
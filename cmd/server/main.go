@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -19,6 +20,7 @@ import (
 	"github.com/feline-dis/go-radio-v2/internal/config"
 	"github.com/feline-dis/go-radio-v2/internal/controllers"
 	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/metrics"
 	"github.com/feline-dis/go-radio-v2/internal/middleware"
 	"github.com/feline-dis/go-radio-v2/internal/repositories"
 	"github.com/feline-dis/go-radio-v2/internal/services"
@@ -44,6 +46,10 @@ func main() {
 
 	fmt.Println("Config:", cfg)
 
+	if _, err := middleware.ParseAuthMode(cfg.Admin.AuthMode); err != nil {
+		log.Fatalf("Invalid AUTH_MODE: %v", err)
+	}
+
 	// Run database migrations
 	if err := runMigrations(); err != nil {
 		log.Fatalf("Failed to run database migrations: %v", err)
@@ -57,7 +63,6 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
-	defer db.Close()
 
 	// Configure connection pool
 	db.SetMaxOpenConns(25)
@@ -83,41 +88,249 @@ func main() {
 	// Initialize YouTube service
 	youtubeService, err := services.NewYouTubeService()
 	if err != nil {
-		log.Fatalf("Failed to initialize YouTube service: %v", err)
+		if !cfg.YouTube.MetadataFallback {
+			log.Fatalf("Failed to initialize YouTube service: %v", err)
+		}
+		log.Printf("Warning: %v. Search is unavailable and imports read metadata with the downloader", err)
+		youtubeService = services.NewYouTubeServiceWithoutKey()
+	}
+	if cfg.YouTube.ProxyURL != "" {
+		proxyURL, err := services.ParseProxyURL(cfg.YouTube.ProxyURL)
+		if err != nil {
+			log.Fatalf("Failed to configure proxy: %v", err)
+		}
+		youtubeService.SetProxy(proxyURL)
+	}
+
+	// Initialize downloader and report the yt-dlp version so stale installs are noticed
+	downloader, err := services.NewDownloader(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize downloader: %v", err)
+	}
+	if reporter, ok := downloader.(services.VersionReporter); ok {
+		if version, err := reporter.Version(context.Background()); err != nil {
+			log.Printf("Warning: Could not determine yt-dlp version: %v", err)
+		} else if services.IsYtDlpVersionOlder(version, cfg.Downloader.MinYtDlpVersion) {
+			log.Printf("Warning: yt-dlp %s is older than %s, downloads may fail", version, cfg.Downloader.MinYtDlpVersion)
+		} else {
+			log.Printf("Using yt-dlp %s", version)
+		}
 	}
 
 	// Initialize event bus
-	eventBus := events.NewEventBus()
+	eventBus := events.NewEventBusWithWorkers(cfg.Server.EventBusWorkers)
 
 	// Initialize services
 	playlistService := services.NewPlaylistService(playlistRepo, songRepo, youtubeService)
+	if cfg.YouTube.MetadataFallback {
+		playlistService.SetVideoInfoFallback(downloader)
+	}
+	playlistService.SetMaxPlaylistSize(cfg.Server.MaxPlaylistSize)
+	playlistService.SetImportFilter(services.ImportFilter{
+		SkipLive:    cfg.YouTube.SkipLiveStreams,
+		MinDuration: time.Duration(cfg.YouTube.MinImportDurationSeconds) * time.Second,
+	})
+	songService := services.NewSongService(songRepo)
+	backfillService := services.NewDurationBackfillService(songRepo, youtubeService)
 	radioService := services.NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
+	playlistService.OnPlaylistChanged(radioService.InvalidatePlaylistCache)
+
+	// Pick where the radio's songs come from
+	switch cfg.Radio.QueueSource {
+	case "", services.QueueSourcePlaylist:
+		if cfg.Radio.QueuePlaylist != "" {
+			playlist, err := playlistRepo.GetByName(cfg.Radio.QueuePlaylist)
+			if err != nil {
+				log.Fatalf("Failed to get queue playlist: %v", err)
+			}
+			if playlist == nil {
+				log.Fatalf("Queue playlist %q not found", cfg.Radio.QueuePlaylist)
+			}
+			radioService.SetQueueSource(services.NewPlaylistQueueSource(playlistRepo, playlist))
+		}
+	case services.QueueSourceRandom:
+		radioService.SetQueueSource(services.NewRandomQueueSource(songRepo, services.DefaultRandomBatchSize))
+	default:
+		log.Fatalf("Unknown queue source %q", cfg.Radio.QueueSource)
+	}
+
+	// Parse the playlist rotation now so a bad schedule fails at startup
+	var playlistScheduler *services.PlaylistScheduler
+	if cfg.Radio.PlaylistSchedule != "" {
+		schedule, err := services.ParsePlaylistSchedule(cfg.Radio.PlaylistSchedule)
+		if err != nil {
+			log.Fatalf("Failed to parse PLAYLIST_SCHEDULE: %v", err)
+		}
+		playlistScheduler = services.NewPlaylistScheduler(radioService, playlistRepo, schedule)
+	}
+
+	// Download songs missing from S3 as the radio reaches them
+	audioFetcher := services.NewAudioFetcher(downloader, s3Service, services.NewFFmpegNormalizer())
+	audioFetcher.SetLocalCopyDir(cfg.Downloader.LocalCopyDir)
+	audioFetcher.SetNormalizedMarker(songRepo)
+	if cfg.Downloader.TrimChapters {
+		audioFetcher.SetChapterRecorder(songRepo, services.ChapterTrimmer{
+			MaxTrim: time.Duration(cfg.Downloader.MaxChapterTrimSeconds) * time.Second,
+		})
+	}
+	if cfg.Downloader.MinFreeDiskMB > 0 {
+		audioFetcher.SetMinFreeSpace(uint64(cfg.Downloader.MinFreeDiskMB) << 20)
+	}
+	repeatMode, err := services.ParseRepeatMode(cfg.Radio.RepeatMode)
+	if err != nil {
+		log.Fatalf("Invalid REPEAT_MODE: %v", err)
+	}
+
+	// Speak each song's title in the gap before it when a speech backend is set
+	synth, err := services.NewSpeechSynthesizer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure announcements: %v", err)
+	}
+	var announcer *services.Announcer
+	if synth != nil {
+		announcer = services.NewAnnouncer(synth, s3Service)
+		if cfg.Radio.InterstitialGapSeconds <= 0 {
+			log.Printf("[WARN] ANNOUNCE_TTS is set but INTERSTITIAL_GAP_SECONDS is 0, so songs won't be announced")
+		}
+	}
+	configureRadio(radioService, cfg, audioFetcher, announcer, repeatMode)
+	radioService.SetVideoInfo(downloader, songRepo)
+
+	// Announce the current track to streaming software when configured
+	nowPlaying := services.NewNowPlayingPublisher(cfg.NowPlaying.File, cfg.NowPlaying.WebhookURL)
+	if nowPlaying.Enabled() {
+		if err := nowPlaying.Subscribe(eventBus.Ordered()); err != nil {
+			log.Fatalf("Failed to subscribe now playing publisher: %v", err)
+		}
+	}
+	if cfg.NowPlaying.DiscordWebhookURL != "" {
+		discord := services.NewDiscordNotifier(cfg.NowPlaying.DiscordWebhookURL, cfg.NowPlaying.DiscordDebounce)
+		if err := discord.Subscribe(eventBus.Ordered()); err != nil {
+			log.Fatalf("Failed to subscribe Discord notifier: %v", err)
+		}
+		defer discord.Stop()
+	}
 
 	// Initialize WebSocket handler with radio service and event bus
 	wsHandler := websocket.NewHandler(radioService, eventBus)
+	wsHandler.SetMissedPongs(cfg.Server.WebSocketMissedPongs)
+	radioService.SetListenerCounter(wsHandler)
 	// Start WebSocket handler in a goroutine
 	go wsHandler.Run()
 
 	// Initialize JWT service
 	jwtService := services.NewJWTService(cfg)
 
+	// Flag websocket clients that connect with a valid token
+	authenticate := func(r *http.Request) bool {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if token == "" {
+			return false
+		}
+		_, err := jwtService.ValidateToken(token)
+		return err == nil
+	}
+	wsHandler.SetAuthenticator(authenticate)
+
+	// The default station is also reachable under /api/v1/stations/default
+	stationManager := services.NewStationManager()
+	if err := stationManager.Add(&services.Station{
+		ID:     services.DefaultStationID,
+		Radio:  radioService,
+		Events: eventBus,
+		Socket: wsHandler,
+	}); err != nil {
+		log.Fatalf("Failed to add default station: %v", err)
+	}
+
+	// Extra stations each get their own event bus and websocket clients
+	stationSpecs, err := services.ParseStations(cfg.Radio.Stations)
+	if err != nil {
+		log.Fatalf("Failed to parse STATIONS: %v", err)
+	}
+	var extraStations []*services.Station
+	sockets := []*websocket.Handler{wsHandler}
+	for _, spec := range stationSpecs {
+		playlist, err := playlistRepo.GetByName(spec.Playlist)
+		if err != nil {
+			log.Fatalf("Failed to get playlist for station %s: %v", spec.ID, err)
+		}
+		if playlist == nil {
+			log.Fatalf("Playlist %q for station %s not found", spec.Playlist, spec.ID)
+		}
+
+		stationBus := events.NewEventBusWithWorkers(cfg.Server.EventBusWorkers)
+		stationRadio := services.NewRadioService(songRepo, playlistRepo, s3Service, stationBus)
+		stationRadio.SetQueueSource(services.NewPlaylistQueueSource(playlistRepo, playlist))
+		configureRadio(stationRadio, cfg, audioFetcher, announcer, repeatMode)
+		stationRadio.SetVideoInfo(downloader, songRepo)
+		playlistService.OnPlaylistChanged(stationRadio.InvalidatePlaylistCache)
+
+		stationSocket := websocket.NewHandler(stationRadio, stationBus)
+		stationSocket.SetAuthenticator(authenticate)
+		stationSocket.SetMissedPongs(cfg.Server.WebSocketMissedPongs)
+		stationRadio.SetListenerCounter(stationSocket)
+		go stationSocket.Run()
+		sockets = append(sockets, stationSocket)
+
+		station := &services.Station{
+			ID:     spec.ID,
+			Radio:  stationRadio,
+			Events: stationBus,
+			Socket: stationSocket,
+		}
+		if err := stationManager.Add(station); err != nil {
+			log.Fatalf("Failed to add station %s: %v", spec.ID, err)
+		}
+		extraStations = append(extraStations, station)
+	}
+
 	// Initialize controllers
 	radioController := controllers.NewRadioController(radioService)
+	radioController.SetListenerCounter(wsHandler)
+	radioController.SetAudioURLs(services.NewAudioURLs(services.NewPresignCache(s3Service), cfg.AWS.PresignExpiry))
 	youtubeController := controllers.NewYouTubeController(youtubeService)
-	playlistController := controllers.NewPlaylistController(playlistService, s3Service)
+	playlistController := controllers.NewPlaylistController(playlistService, s3Service, services.NewFFmpegTranscoder())
+	playlistController.SetAudioCORSOrigin(cfg.Server.AudioCORSOrigin)
+	announcementController := controllers.NewAnnouncementController(s3Service)
+	songController := controllers.NewSongController(songService, s3Service, services.NewFFmpegPeakGenerator())
+	lyricsProvider, err := services.NewLyricsProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure lyrics: %v", err)
+	}
+	if lyricsProvider != nil {
+		songController.SetLyricsService(services.NewLyricsService(songRepo, lyricsProvider, s3Service))
+	}
+	backfillController := controllers.NewBackfillController(backfillService)
+	clientController := controllers.NewClientController(wsHandler)
+	downloaderController := controllers.NewDownloaderController(downloader, cfg.Downloader.MinYtDlpVersion)
+	playlistValidationController := controllers.NewPlaylistValidationController(services.NewPlaylistValidator(playlistRepo, songRepo, s3Service))
+	libraryCleanupController := controllers.NewLibraryCleanupController(services.NewLibraryCleaner(playlistRepo, s3Service))
+	normalizationController := controllers.NewNormalizationController(services.NewNormalizationService(songRepo, playlistRepo, s3Service, services.NewFFmpegNormalizer()))
 	reactionController := controllers.NewReactionController(eventBus)
+	stationController := controllers.NewStationController(stationManager)
 	authController := controllers.NewAuthController(jwtService, cfg)
+	if cfg.Server.LoginRateLimit > 0 {
+		authController.SetLoginRateLimiter(middleware.NewRateLimiter(cfg.Server.LoginRateLimit))
+	}
 
 	// Create router
 	router := mux.NewRouter()
 
+	// Tag every request with an ID for its log lines
+	router.Use(middleware.RequestIDMiddleware)
+
 	// Add CORS middleware for cross-origin requests
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Allow requests from the React development server
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
@@ -131,22 +344,47 @@ func main() {
 
 	// WebSocket endpoint - register directly on the main router
 	router.Handle("/ws", wsHandler)
+	stationController.RegisterWebSocketRoutes(router)
 
 	// Create a subrouter for all other routes that will use the logging middleware
 	apiRouter := router.PathPrefix("").Subrouter()
+	apiRouter.Use(middleware.LoggingMiddlewareWithExclusions(strings.Split(cfg.Server.LogExcludedPaths, ",")))
 
 	// Register all routes on the apiRouter instead of the main router
 	radioController.RegisterRoutes(apiRouter)
 	youtubeController.RegisterRoutes(apiRouter)
 	playlistController.RegisterRoutes(apiRouter)
+	songController.RegisterRoutes(apiRouter)
+	announcementController.RegisterRoutes(apiRouter)
 	authController.RegisterRoutes(apiRouter)
-	
+	stationController.RegisterRoutes(apiRouter)
+
 	// Register reaction routes
-	apiRouter.HandleFunc("/api/v1/reactions", reactionController.SendReaction).Methods("POST")
+	var sendReaction http.Handler = http.HandlerFunc(reactionController.SendReaction)
+	if cfg.Server.ReactionRateLimit > 0 {
+		sendReaction = middleware.NewRateLimiter(cfg.Server.ReactionRateLimit).Middleware(sendReaction)
+	}
+	apiRouter.Handle("/api/v1/reactions", sendReaction).Methods("POST")
 
-	// Admin routes with JWT authentication middleware
+	// Integrations authenticate each request with an HMAC of its body
+	if cfg.Admin.IntegrationSecret != "" {
+		integrationRouter := apiRouter.PathPrefix("/api/v1/integrations").Subrouter()
+		integrationRouter.Use(middleware.SignatureMiddleware(cfg.Admin.IntegrationSecret))
+		controllers.NewIntegrationController(radioService).RegisterRoutes(integrationRouter)
+	}
+
+	// Admin routes with authentication middleware
 	adminRouter := apiRouter.PathPrefix("/api/v1/admin").Subrouter()
-	adminRouter.Use(middleware.AuthMiddleware(jwtService))
+	adminRouter.Use(middleware.AdminAuthMiddleware(cfg.Admin.AuthMode, jwtService, cfg.Admin.Username, cfg.Admin.Password))
+	radioController.RegisterAdminRoutes(adminRouter)
+	playlistController.RegisterAdminRoutes(adminRouter)
+	backfillController.RegisterAdminRoutes(adminRouter)
+	songController.RegisterAdminRoutes(adminRouter)
+	clientController.RegisterAdminRoutes(adminRouter)
+	downloaderController.RegisterAdminRoutes(adminRouter)
+	playlistValidationController.RegisterAdminRoutes(adminRouter)
+	libraryCleanupController.RegisterAdminRoutes(adminRouter)
+	normalizationController.RegisterAdminRoutes(adminRouter)
 
 	// Serve static files for the frontend
 	fs := http.FileServer(http.Dir("/app/static"))
@@ -172,13 +410,13 @@ func main() {
 	// Handle client-side routing - serve index.html for all non-API routes
 	router.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Serving request: %s", r.URL.Path)
-		
+
 		// Don't serve index.html for API routes or WebSocket
 		if strings.HasPrefix(r.URL.Path, "/api") || strings.HasPrefix(r.URL.Path, "/ws") {
 			http.NotFound(w, r)
 			return
 		}
-		
+
 		// For all other routes, serve index.html to support client-side routing
 		http.ServeFile(w, r, "/app/static/index.html")
 	})
@@ -192,6 +430,20 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// Serve Prometheus metrics on their own port, away from the public API
+	metricsServer := &http.Server{
+		Addr:    ":" + cfg.Metrics.Port,
+		Handler: newMetricsHandler(radioService),
+	}
+	if cfg.Metrics.Enabled {
+		go func() {
+			log.Printf("Serving metrics on port %s", cfg.Metrics.Port)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("[ERROR] Metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	// Create a channel to signal when the server is ready
 	serverReady := make(chan struct{})
 
@@ -209,11 +461,27 @@ func main() {
 	<-serverReady
 	log.Println("Server is ready to accept connections")
 
+	// Fill in durations for songs imported without one
+	go func() {
+		if _, err := backfillService.Run(); err != nil {
+			log.Printf("Error backfilling song durations: %v", err)
+		}
+	}()
+
 	// Start the playback loop
 	if err := radioService.StartPlaybackLoop(); err != nil {
 		log.Printf("Error starting playback loop: %v", err)
 	}
 
+	for _, station := range extraStations {
+		if err := station.Radio.StartPlaybackLoop(); err != nil {
+			log.Printf("Error starting playback loop for station %s: %v", station.ID, err)
+		}
+	}
+
+	// Rotate playlists on schedule once playback is running
+	schedulerSteps := startPlaylistScheduler(playlistScheduler)
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -223,10 +491,140 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	// Hang up on websocket clients first so browsers see a clean close rather
+	// than a dropped connection, then stop taking requests, stop the playlist
+	// scheduler so it can't switch playlists behind the later steps, stop
+	// playback so nothing is mid-download, clear the audio cache if asked to
+	// and close the database last. Upgrades after the handlers shut down are refused.
+	radios := []*services.RadioService{radioService}
+	for _, station := range extraStations {
+		radios = append(radios, station.Radio)
 	}
+	steps := []shutdownStep{
+		{name: "websocket clients", run: func(ctx context.Context) error {
+			var errs []error
+			for _, socket := range sockets {
+				errs = append(errs, socket.Shutdown(ctx))
+			}
+			return errors.Join(errs...)
+		}},
+		{name: "http server", run: server.Shutdown},
+		{name: "metrics server", run: metricsServer.Shutdown},
+	}
+	steps = append(steps, schedulerSteps...)
+	steps = append(steps, shutdownStep{name: "playback", run: func(context.Context) error {
+		var errs []error
+		for _, radio := range radios {
+			if err := radio.StopPlayback(); err != nil && !errors.Is(err, services.ErrPlaybackNotRunning) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}})
+	steps = append(steps, audioCacheSteps(cfg.Downloader.CleanAudioOnShutdown, audioFetcher)...)
+	steps = append(steps, shutdownStep{name: "database", run: func(context.Context) error { return db.Close() }})
+	runShutdown(ctx, steps)
 
 	log.Println("Server exiting")
 }
+
+// shutdownStep is one part of the server to stop on exit
+type shutdownStep struct {
+	name string
+	run  func(context.Context) error
+}
+
+// runShutdown runs steps in order. A failed step is logged and the rest
+// still run, so one stuck component doesn't keep the database open.
+func runShutdown(ctx context.Context, steps []shutdownStep) {
+	for _, step := range steps {
+		log.Printf("Shutting down %s", step.name)
+		if err := step.run(ctx); err != nil {
+			log.Printf("[ERROR] Shutdown: Failed to stop %s: %v", step.name, err)
+		}
+	}
+}
+
+// startPlaylistScheduler runs scheduler in the background and returns the
+// step that stops it, waiting for a playlist switch in progress to finish so
+// none happens once playback is stopped. A nil scheduler has no steps.
+func startPlaylistScheduler(scheduler *services.PlaylistScheduler) []shutdownStep {
+	if scheduler == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Run(ctx)
+	}()
+
+	return []shutdownStep{{name: "playlist scheduler", run: func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	}}}
+}
+
+// audioCacheSteps empties the fetcher's local audio copies on shutdown when
+// clean is set, and keeps them otherwise
+func audioCacheSteps(clean bool, fetcher *services.AudioFetcher) []shutdownStep {
+	if !clean {
+		return nil
+	}
+	return []shutdownStep{{name: "audio cache", run: func(context.Context) error {
+		return fetcher.ClearLocalCopies()
+	}}}
+}
+
+// newMetricsHandler serves the default station's queue and download gauges
+// alongside the radio's counters shared by every station
+func newMetricsHandler(radio *services.RadioService) http.Handler {
+	gauges := []struct {
+		name, help string
+		value      func() float64
+	}{
+		{"radio_queue_length", "Songs in the play queue.", func() float64 {
+			return float64(radio.PrefetchStatus().QueueLength)
+		}},
+		{"radio_pending_downloads", "Current and upcoming songs not yet in storage.", func() float64 {
+			return float64(radio.PrefetchStatus().PendingDownloads)
+		}},
+		{"radio_downloads_in_flight", "Song downloads running.", func() float64 {
+			return float64(radio.PrefetchStatus().InFlightDownloads)
+		}},
+	}
+	for _, gauge := range gauges {
+		if err := metrics.Gauge(gauge.name, gauge.help, gauge.value); err != nil {
+			log.Printf("[ERROR] newMetricsHandler: Failed to register %s: %v", gauge.name, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+// configureRadio applies the playback settings shared by every station
+func configureRadio(radio *services.RadioService, cfg *config.Config, fetcher services.SongAudioFetcher, announcer *services.Announcer, repeatMode services.RepeatMode) {
+	radio.SetAudioFetcher(fetcher)
+	radio.SetAnnouncer(announcer)
+	radio.SetInterstitialGap(time.Duration(cfg.Radio.InterstitialGapSeconds) * time.Second)
+	radio.SetSongDurationLimits(
+		time.Duration(cfg.Radio.MinSongDurationSeconds)*time.Second,
+		time.Duration(cfg.Radio.MaxSongDurationSeconds)*time.Second,
+	)
+	radio.SetRepeatMode(repeatMode)
+	radio.SetShuffle(cfg.Radio.ShuffleOnStart)
+	radio.SetPredownloadCount(cfg.Radio.PredownloadCount)
+	radio.SetDownloadFailureLimit(cfg.Radio.DownloadFailureLimit)
+	radio.SetVoteSkipPercent(cfg.Radio.VoteSkipPercent)
+	if cfg.Radio.ExcludedTags != "" {
+		radio.SetExcludedTags(strings.Split(cfg.Radio.ExcludedTags, ","))
+	}
+}
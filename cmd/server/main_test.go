@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+)
+
+func TestRunShutdownRunsEveryStepInOrder(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) shutdownStep {
+		return shutdownStep{name: name, run: func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	runShutdown(context.Background(), []shutdownStep{
+		step("websocket clients", errors.New("deadline exceeded")),
+		step("http server", nil),
+		step("playback", nil),
+		step("database", nil),
+	})
+
+	want := []string{"websocket clients", "http server", "playback", "database"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
+
+func TestAudioCacheStepsClearLocalCopiesWhenSet(t *testing.T) {
+	for _, clean := range []bool{true, false} {
+		dir := t.TempDir()
+		copyPath := filepath.Join(dir, services.SongAudioKey("dQw4w9WgXcQ"))
+		if err := os.MkdirAll(filepath.Dir(copyPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(copyPath, []byte("audio"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fetcher := services.NewAudioFetcher(nil, nil, nil)
+		fetcher.SetLocalCopyDir(dir)
+
+		runShutdown(context.Background(), audioCacheSteps(clean, fetcher))
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("Expected the audio dir kept, got %v", err)
+		}
+		if clean && len(entries) != 0 {
+			t.Errorf("Expected the audio dir emptied, got %d entries", len(entries))
+		}
+		if _, err := os.Stat(copyPath); !clean && err != nil {
+			t.Errorf("Expected the local copy kept, got %v", err)
+		}
+	}
+}
+
+// recordingActivator counts the playlist switches asked of it
+type recordingActivator struct {
+	mu       sync.Mutex
+	switches int
+}
+
+func (r *recordingActivator) SetActivePlaylist(playlistID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.switches++
+	return nil
+}
+
+func (r *recordingActivator) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.switches
+}
+
+type namedPlaylists struct{}
+
+func (namedPlaylists) GetByName(name string) (*models.Playlist, error) {
+	return &models.Playlist{ID: name, Name: name}, nil
+}
+
+func TestPlaylistSchedulerStepStopsTheScheduler(t *testing.T) {
+	if steps := startPlaylistScheduler(nil); steps != nil {
+		t.Errorf("Expected no steps without a scheduler, got %d", len(steps))
+	}
+
+	radio := &recordingActivator{}
+	schedule := []services.ScheduleEntry{{StartHour: 0, EndHour: 12, Playlist: "Morning"}, {StartHour: 12, EndHour: 0, Playlist: "Evening"}}
+	steps := startPlaylistScheduler(services.NewPlaylistScheduler(radio, namedPlaylists{}, schedule))
+
+	// The scheduler switches to the current window as soon as it starts
+	deadline := time.Now().Add(time.Second)
+	for radio.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the scheduler to start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if len(steps) != 1 {
+		t.Fatalf("Expected a single shutdown step, got %d", len(steps))
+	}
+	if err := steps[0].run(ctx); err != nil {
+		t.Errorf("Expected the scheduler to stop, got %v", err)
+	}
+}
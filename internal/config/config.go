@@ -11,14 +11,19 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	AWS      AWSConfig
-	JWT      JWTConfig
-	Database DatabaseConfig
-	Logging  LoggingConfig
-	Metrics  MetricsConfig
-	Admin    AdminConfig
-	YouTube  YouTubeConfig
+	Server     ServerConfig
+	AWS        AWSConfig
+	JWT        JWTConfig
+	Database   DatabaseConfig
+	Logging    LoggingConfig
+	Metrics    MetricsConfig
+	Admin      AdminConfig
+	YouTube    YouTubeConfig
+	Radio      RadioConfig
+	Downloader DownloaderConfig
+	Lyrics     LyricsConfig
+	Announce   AnnounceConfig
+	NowPlaying NowPlayingConfig
 }
 
 type ServerConfig struct {
@@ -26,6 +31,22 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// AudioCORSOrigin is the origin allowed to fetch song audio cross-origin
+	AudioCORSOrigin string
+	// EventBusWorkers is how many event handlers run at once
+	EventBusWorkers int
+	// ReactionRateLimit and LoginRateLimit are the requests per minute each
+	// client may make to those endpoints; zero disables the limit
+	ReactionRateLimit int
+	LoginRateLimit    int
+	// MaxPlaylistSize caps the songs in a playlist; zero is unlimited
+	MaxPlaylistSize int
+	// WebSocketMissedPongs is how many pongs in a row a websocket client may
+	// miss before it is disconnected
+	WebSocketMissedPongs int
+	// LogExcludedPaths is a comma-separated list of path prefixes the request
+	// log skips, such as endpoints clients poll
+	LogExcludedPaths string
 }
 
 type AWSConfig struct {
@@ -33,6 +54,9 @@ type AWSConfig struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	BucketName      string
+	// PresignExpiry is how long presigned audio URLs last; zero hands out
+	// URLs served through this server instead
+	PresignExpiry time.Duration
 }
 
 type JWTConfig struct {
@@ -61,10 +85,114 @@ type MetricsConfig struct {
 type AdminConfig struct {
 	Username string
 	Password string
+	// AuthMode selects how admin routes authenticate: jwt, basic or both
+	AuthMode string
+	// IntegrationSecret signs requests to the integration control endpoint;
+	// empty leaves the endpoint off
+	IntegrationSecret string
 }
 
 type YouTubeConfig struct {
 	APIKey string
+	// ProxyURL routes YouTube API and yt-dlp traffic through an HTTP or SOCKS proxy
+	ProxyURL string
+	// MetadataFallback lets imports read video metadata from the downloader
+	// when there is no API key or its quota is used up
+	MetadataFallback bool
+	// SkipLiveStreams leaves live and upcoming streams out of imports
+	SkipLiveStreams bool
+	// MinImportDurationSeconds leaves shorter videos, e.g. Shorts, out of
+	// imports; 0 keeps them
+	MinImportDurationSeconds int
+}
+
+type DownloaderConfig struct {
+	// Backend selects the audio downloader: ytdlp, youtube-dl or http
+	Backend string
+	// ServiceURL is the base URL of the download service used by the http backend
+	ServiceURL string
+	// CookiesFile and CookiesFromBrowser let yt-dlp fetch age-gated videos
+	CookiesFile        string
+	CookiesFromBrowser string
+	// DownloadTimeout and InfoTimeout bound yt-dlp downloads and metadata lookups
+	DownloadTimeout time.Duration
+	InfoTimeout     time.Duration
+	// MinYtDlpVersion is the oldest yt-dlp release considered up to date, e.g. 2024.08.06
+	MinYtDlpVersion string
+	// LocalCopyDir keeps a local copy of downloaded audio after it is uploaded
+	LocalCopyDir string
+	// CleanAudioOnShutdown empties LocalCopyDir on graceful shutdown, for
+	// deployments whose disk doesn't outlive the process
+	CleanAudioOnShutdown bool
+	// MinFreeDiskMB is the free space required before starting a download
+	MinFreeDiskMB int
+	// TrimChapters reads downloaded songs' video chapters to skip a
+	// non-music intro or outro no longer than MaxChapterTrimSeconds
+	TrimChapters          bool
+	MaxChapterTrimSeconds int
+}
+
+type LyricsConfig struct {
+	// Provider selects where lyrics come from: lrclib, or empty to disable lyrics
+	Provider string
+	// APIURL overrides the provider's base URL, e.g. for a self-hosted LRCLIB
+	APIURL string
+	// APIKey is sent as a bearer token when set
+	APIKey string
+}
+
+type AnnounceConfig struct {
+	// Provider selects the speech backend for announcing songs between
+	// tracks: command, http, or empty to disable announcements
+	Provider string
+	// Command is run with the text on stdin and must write audio to stdout
+	Command string
+	// URL receives a JSON POST of {"text": ...} and must respond with audio
+	URL string
+}
+
+type NowPlayingConfig struct {
+	// File is rewritten with "Artist - Title" on every song change
+	File string
+	// WebhookURL receives a JSON POST on every song change
+	WebhookURL string
+	// DiscordWebhookURL receives a Discord embed for every song that plays
+	DiscordWebhookURL string
+	// DiscordDebounce is how long a song must play before it is announced
+	DiscordDebounce time.Duration
+}
+
+type RadioConfig struct {
+	// InterstitialGapSeconds is the silence inserted between songs
+	InterstitialGapSeconds int
+	// MinSongDurationSeconds and MaxSongDurationSeconds clamp how long a song
+	// is scheduled for; 0 keeps the default minimum and disables the maximum
+	MinSongDurationSeconds int
+	MaxSongDurationSeconds int
+	// QueueSource picks where songs come from: playlist or random
+	QueueSource string
+	// QueuePlaylist pins the playlist source to a playlist by name; empty
+	// starts with the first playlist
+	QueuePlaylist string
+	// PlaylistSchedule rotates playlists by hour, e.g. "06-18=Daytime,18-06=Chill"
+	PlaylistSchedule string
+	// RepeatMode is "all" to restart the queue when it ends, "off" to stop or
+	// "one" to replay each song
+	RepeatMode string
+	// ExcludedTags is a comma-separated list of song tags kept off the air
+	ExcludedTags string
+	// ShuffleOnStart shuffles playlists as they are queued; false plays them in stored order
+	ShuffleOnStart bool
+	// PredownloadCount is how many songs after the current one are downloaded ahead
+	PredownloadCount int
+	// DownloadFailureLimit is how many failed downloads in a row drop a song
+	// from the queue until restart; 0 keeps retrying
+	DownloadFailureLimit int
+	// VoteSkipPercent is the share of listeners whose skip votes must be
+	// exceeded to skip a song
+	VoteSkipPercent int
+	// Stations runs extra stations alongside the default one, e.g. "lofi=Lofi Beats,rock=Rock"
+	Stations string
 }
 
 // Load attempts to load environment variables from .env file
@@ -92,16 +220,26 @@ func Load() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  getDurationEnv("READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
+			Port:            getEnv("PORT", "8080"),
+			ReadTimeout:     getDurationEnv("READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:    getDurationEnv("WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:     getDurationEnv("IDLE_TIMEOUT", 60*time.Second),
+			AudioCORSOrigin: getEnv("AUDIO_CORS_ORIGIN", "*"),
+			EventBusWorkers: getIntEnv("EVENT_BUS_WORKERS", 8),
+
+			ReactionRateLimit: getIntEnv("REACTION_RATE_LIMIT", 60),
+			LoginRateLimit:    getIntEnv("LOGIN_RATE_LIMIT", 10),
+			MaxPlaylistSize:   getIntEnv("MAX_PLAYLIST_SIZE", 0),
+
+			WebSocketMissedPongs: getIntEnv("WS_MISSED_PONGS", 0),
+			LogExcludedPaths:     getEnv("LOG_EXCLUDED_PATHS", "/api/v1/health,/api/v1/now-playing"),
 		},
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-2"),
 			AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
 			SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
 			BucketName:      getEnv("S3_BUCKET_NAME", ""),
+			PresignExpiry:   getDurationEnv("S3_PRESIGN_EXPIRY", 0),
 		},
 		JWT: JWTConfig{
 			Secret:     getEnv("JWT_SECRET", ""),
@@ -123,11 +261,67 @@ func Load() *Config {
 			Port:    getEnv("METRICS_PORT", "9090"),
 		},
 		Admin: AdminConfig{
-			Username: getEnv("ADMIN_USERNAME", "admin"),
-			Password: getEnv("ADMIN_PASSWORD", "admin"),
+			Username:          getEnv("ADMIN_USERNAME", "admin"),
+			Password:          getEnv("ADMIN_PASSWORD", "admin"),
+			AuthMode:          getEnv("AUTH_MODE", "jwt"),
+			IntegrationSecret: getEnv("INTEGRATION_SECRET", ""),
 		},
 		YouTube: YouTubeConfig{
-			APIKey: getEnv("YOUTUBE_API_KEY", ""),
+			APIKey:   getEnv("YOUTUBE_API_KEY", ""),
+			ProxyURL: getEnv("PROXY_URL", ""),
+
+			MetadataFallback:         getBoolEnv("YOUTUBE_METADATA_FALLBACK", true),
+			SkipLiveStreams:          getBoolEnv("SKIP_LIVE_STREAMS", true),
+			MinImportDurationSeconds: getIntEnv("MIN_IMPORT_DURATION_SECONDS", 0),
+		},
+		Radio: RadioConfig{
+			InterstitialGapSeconds: getIntEnv("INTERSTITIAL_GAP_SECONDS", 0),
+			MinSongDurationSeconds: getIntEnv("MIN_SONG_DURATION_SECONDS", 0),
+			MaxSongDurationSeconds: getIntEnv("MAX_SONG_DURATION_SECONDS", 0),
+			QueueSource:            getEnv("QUEUE_SOURCE", "playlist"),
+			QueuePlaylist:          getEnv("QUEUE_PLAYLIST", ""),
+			PlaylistSchedule:       getEnv("PLAYLIST_SCHEDULE", ""),
+			RepeatMode:             getEnv("REPEAT_MODE", "all"),
+			ExcludedTags:           getEnv("EXCLUDED_TAGS", ""),
+			ShuffleOnStart:         getBoolEnv("SHUFFLE_ON_START", true),
+			PredownloadCount:       getIntEnv("PREDOWNLOAD_COUNT", 1),
+			DownloadFailureLimit:   getIntEnv("DOWNLOAD_FAILURE_LIMIT", 3),
+			VoteSkipPercent:        getIntEnv("VOTE_SKIP_PERCENT", 50),
+			Stations:               getEnv("STATIONS", ""),
+		},
+		Lyrics: LyricsConfig{
+			Provider: getEnv("LYRICS_PROVIDER", ""),
+			APIURL:   getEnv("LYRICS_API_URL", ""),
+			APIKey:   getEnv("LYRICS_API_KEY", ""),
+		},
+		Announce: AnnounceConfig{
+			Provider: getEnv("ANNOUNCE_TTS", ""),
+			Command:  getEnv("ANNOUNCE_TTS_COMMAND", ""),
+			URL:      getEnv("ANNOUNCE_TTS_URL", ""),
+		},
+		NowPlaying: NowPlayingConfig{
+			File:       getEnv("NOW_PLAYING_FILE", ""),
+			WebhookURL: getEnv("NOW_PLAYING_WEBHOOK_URL", ""),
+
+			DiscordWebhookURL: getEnv("DISCORD_WEBHOOK_URL", ""),
+			DiscordDebounce:   getDurationEnv("DISCORD_WEBHOOK_DEBOUNCE", 5*time.Second),
+		},
+		Downloader: DownloaderConfig{
+			Backend:    getEnv("DOWNLOADER_BACKEND", "ytdlp"),
+			ServiceURL: getEnv("DOWNLOADER_URL", ""),
+
+			CookiesFile:        getEnv("YTDLP_COOKIES_FILE", ""),
+			CookiesFromBrowser: getEnv("YTDLP_COOKIES_FROM_BROWSER", ""),
+			DownloadTimeout:    getDurationEnv("YTDLP_DOWNLOAD_TIMEOUT", 5*time.Minute),
+			InfoTimeout:        getDurationEnv("YTDLP_INFO_TIMEOUT", 30*time.Second),
+			MinYtDlpVersion:    getEnv("YTDLP_MIN_VERSION", ""),
+			LocalCopyDir:       getEnv("AUDIO_LOCAL_COPY_DIR", ""),
+			MinFreeDiskMB:      getIntEnv("MIN_FREE_DISK_MB", 100),
+
+			CleanAudioOnShutdown: getBoolEnv("CLEAN_AUDIO_ON_SHUTDOWN", false),
+
+			TrimChapters:          getBoolEnv("TRIM_INTRO_CHAPTERS", false),
+			MaxChapterTrimSeconds: getIntEnv("MAX_CHAPTER_TRIM_SECONDS", 90),
 		},
 	}
 }
@@ -148,6 +342,15 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	if value, exists := os.LookupEnv(key); exists {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// AnnouncementController serves the spoken clips announcing songs
+type AnnouncementController struct {
+	storage services.S3ServiceInterface
+}
+
+func NewAnnouncementController(storage services.S3ServiceInterface) *AnnouncementController {
+	return &AnnouncementController{storage: storage}
+}
+
+func (c *AnnouncementController) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/announcements/{id}", c.GetAnnouncement).Methods("GET")
+}
+
+// GetAnnouncement streams an announcement clip by the ID sent in announcement messages
+func (c *AnnouncementController) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if decoded, err := hex.DecodeString(id); err != nil || len(decoded) != 32 {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	key := services.AnnouncementKey(id)
+	exists, err := c.storage.FileExists(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Announcement not found", http.StatusNotFound)
+		return
+	}
+
+	file, err := c.storage.GetFile(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	// Speech backends pick the format, so sniff it from the clip itself
+	body := bufio.NewReader(file)
+	head, _ := body.Peek(512)
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	// Clips are named by the hash of their text, so they never change
+	w.Header().Set("Cache-Control", "public, max-age=31536000")
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("[ERROR] GetAnnouncement: Failed to write clip %s: %v", id, err)
+	}
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+func TestGetAnnouncement(t *testing.T) {
+	storage := newFakeStorage()
+	id := services.AnnouncementID("Now playing: Artist - Title")
+	storage.files[services.AnnouncementKey(id)] = []byte("RIFF\x00\x00\x00\x00WAVEfmt clip")
+
+	router := mux.NewRouter()
+	NewAnnouncementController(storage).RegisterRoutes(router)
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/announcements/"+id)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "audio/wave" {
+		t.Errorf("Expected the clip's format to be sniffed as audio/wave, got %q", got)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "clip") {
+		t.Errorf("Expected the stored clip, got %q", rec.Body.String())
+	}
+
+	missing := services.AnnouncementID("something else")
+	if rec := doRequest(router, http.MethodGet, "/api/v1/announcements/"+missing); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown clip, got %d", rec.Code)
+	}
+	if rec := doRequest(router, http.MethodGet, "/api/v1/announcements/not-a-hash"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed ID, got %d", rec.Code)
+	}
+}
@@ -13,6 +13,9 @@ import (
 type AuthController struct {
 	jwtService *services.JWTService
 	config     *config.Config
+
+	// loginLimiter throttles login attempts when set
+	loginLimiter *middleware.RateLimiter
 }
 
 type LoginRequest struct {
@@ -40,8 +43,17 @@ func NewAuthController(jwtService *services.JWTService, cfg *config.Config) *Aut
 	}
 }
 
+// SetLoginRateLimiter limits how often each client may try to log in
+func (ac *AuthController) SetLoginRateLimiter(limiter *middleware.RateLimiter) {
+	ac.loginLimiter = limiter
+}
+
 func (ac *AuthController) RegisterRoutes(r *mux.Router) {
-	r.HandleFunc("/api/v1/auth/login", ac.Login).Methods("POST")
+	var login http.Handler = http.HandlerFunc(ac.Login)
+	if ac.loginLimiter != nil {
+		login = ac.loginLimiter.Middleware(login)
+	}
+	r.Handle("/api/v1/auth/login", login).Methods("POST")
 	r.HandleFunc("/api/v1/auth/refresh", ac.RefreshToken).Methods("POST")
 	
 	// Protected routes
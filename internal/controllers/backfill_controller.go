@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type BackfillController struct {
+	backfillSvc *services.DurationBackfillService
+}
+
+func NewBackfillController(backfillSvc *services.DurationBackfillService) *BackfillController {
+	return &BackfillController{
+		backfillSvc: backfillSvc,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *BackfillController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/backfill-durations", c.BackfillDurations).Methods("POST")
+}
+
+// BackfillDurations looks up durations for songs stored without one
+func (c *BackfillController) BackfillDurations(w http.ResponseWriter, r *http.Request) {
+	result, err := c.backfillSvc.Run()
+	if errors.Is(err, services.ErrBackfillInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] BackfillDurations: %v", err)
+		http.Error(w, "Failed to backfill durations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/websocket"
+	"github.com/gorilla/mux"
+)
+
+// ClientListerInterface provides a snapshot of connected websocket clients
+type ClientListerInterface interface {
+	Clients() []websocket.ClientInfo
+}
+
+type ClientController struct {
+	clients ClientListerInterface
+}
+
+func NewClientController(clients ClientListerInterface) *ClientController {
+	return &ClientController{
+		clients: clients,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *ClientController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/clients", c.GetClients).Methods("GET")
+}
+
+// GetClients lists the connected websocket clients for debugging
+func (c *ClientController) GetClients(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.clients.Clients())
+}
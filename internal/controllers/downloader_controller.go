@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type DownloaderController struct {
+	downloader services.Downloader
+	minVersion string
+}
+
+type YtDlpVersionResponse struct {
+	Version    string `json:"version"`
+	MinVersion string `json:"min_version,omitempty"`
+	Outdated   bool   `json:"outdated"`
+}
+
+func NewDownloaderController(downloader services.Downloader, minVersion string) *DownloaderController {
+	return &DownloaderController{
+		downloader: downloader,
+		minVersion: minVersion,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *DownloaderController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/ytdlp-version", c.GetYtDlpVersion).Methods("GET")
+}
+
+// GetYtDlpVersion reports the installed yt-dlp version and whether it is outdated
+func (c *DownloaderController) GetYtDlpVersion(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := c.downloader.(services.VersionReporter)
+	if !ok {
+		http.Error(w, "Configured downloader does not report a version", http.StatusNotImplemented)
+		return
+	}
+
+	version, err := reporter.Version(r.Context())
+	if err != nil {
+		log.Printf("[ERROR] GetYtDlpVersion: %v", err)
+		http.Error(w, "Failed to get yt-dlp version", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(YtDlpVersionResponse{
+		Version:    version,
+		MinVersion: c.minVersion,
+		Outdated:   services.IsYtDlpVersionOlder(version, c.minVersion),
+	})
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// fakeDownloader implements services.Downloader without touching the network
+type fakeDownloader struct{}
+
+func (f *fakeDownloader) DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeDownloader) GetVideoInfo(ctx context.Context, videoID string) (*services.VideoInfo, error) {
+	return &services.VideoInfo{ID: videoID}, nil
+}
+
+func (f *fakeDownloader) IsVideoAvailable(ctx context.Context, videoID string) (bool, error) {
+	return true, nil
+}
+
+// fakeVersionedDownloader also reports a tool version
+type fakeVersionedDownloader struct {
+	fakeDownloader
+	version string
+}
+
+func (f *fakeVersionedDownloader) Version(ctx context.Context) (string, error) {
+	return f.version, nil
+}
+
+func newTestAdminRouter(register func(*mux.Router)) *mux.Router {
+	router := mux.NewRouter()
+	register(router.PathPrefix("/api/v1/admin").Subrouter())
+	return router
+}
+
+func TestGetYtDlpVersion(t *testing.T) {
+	controller := NewDownloaderController(&fakeVersionedDownloader{version: "2024.08.06"}, "2024.10.01")
+	router := newTestAdminRouter(controller.RegisterAdminRoutes)
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/admin/ytdlp-version")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var resp YtDlpVersionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Version != "2024.08.06" || !resp.Outdated {
+		t.Errorf("Expected outdated version 2024.08.06, got %+v", resp)
+	}
+}
+
+func TestGetYtDlpVersionUnsupportedBackend(t *testing.T) {
+	controller := NewDownloaderController(&fakeDownloader{}, "")
+	router := newTestAdminRouter(controller.RegisterAdminRoutes)
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/admin/ytdlp-version"); rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", rec.Code)
+	}
+}
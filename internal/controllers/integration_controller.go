@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// RadioControls are the playback controls integrations can use
+type RadioControls interface {
+	Next()
+	Previous()
+	Pause()
+	Resume()
+	SetActivePlaylist(playlistID string) error
+}
+
+// ControlRequest is the body of POST /api/v1/integrations/control
+type ControlRequest struct {
+	Action     string `json:"action"`
+	PlaylistID string `json:"playlist_id,omitempty"`
+}
+
+// IntegrationController lets devices such as a Stream Deck control the radio
+// with signed requests instead of logging in. Routes must be mounted behind
+// middleware.SignatureMiddleware.
+type IntegrationController struct {
+	radio RadioControls
+}
+
+func NewIntegrationController(radio RadioControls) *IntegrationController {
+	return &IntegrationController{radio: radio}
+}
+
+// RegisterRoutes registers the integration endpoints on a router mounted at
+// /api/v1/integrations
+func (c *IntegrationController) RegisterRoutes(integrations *mux.Router) {
+	integrations.HandleFunc("/control", c.Control).Methods("POST")
+}
+
+// Control runs one of skip, previous, pause, resume or set-playlist
+func (c *IntegrationController) Control(w http.ResponseWriter, r *http.Request) {
+	var request ControlRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]string{
+		"status": "success",
+		"action": request.Action,
+	}
+	switch request.Action {
+	case "skip":
+		c.radio.Next()
+	case "previous":
+		c.radio.Previous()
+	case "pause":
+		c.radio.Pause()
+	case "resume":
+		c.radio.Resume()
+	case "set-playlist":
+		if request.PlaylistID == "" {
+			http.Error(w, "playlist_id is required", http.StatusBadRequest)
+			return
+		}
+		err := c.radio.SetActivePlaylist(request.PlaylistID)
+		if errors.Is(err, services.ErrPlaylistNotFound) {
+			http.Error(w, "Playlist not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("[ERROR] Control: Failed to set playlist %s: %v", request.PlaylistID, err)
+			http.Error(w, "Failed to set playlist", http.StatusInternalServerError)
+			return
+		}
+		response["playlist_id"] = request.PlaylistID
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/middleware"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// fakeRadioControls records the controls it is asked to run
+type fakeRadioControls struct {
+	calls       []string
+	playlistErr error
+}
+
+func (f *fakeRadioControls) Next()     { f.calls = append(f.calls, "next") }
+func (f *fakeRadioControls) Previous() { f.calls = append(f.calls, "previous") }
+func (f *fakeRadioControls) Pause()    { f.calls = append(f.calls, "pause") }
+func (f *fakeRadioControls) Resume()   { f.calls = append(f.calls, "resume") }
+
+func (f *fakeRadioControls) SetActivePlaylist(playlistID string) error {
+	f.calls = append(f.calls, "playlist:"+playlistID)
+	return f.playlistErr
+}
+
+// newTestIntegrationRouter mounts the routes behind the signature check the way main does
+func newTestIntegrationRouter(radio RadioControls, secret string) *mux.Router {
+	router := mux.NewRouter()
+	signed := router.PathPrefix("/api/v1/integrations").Subrouter()
+	signed.Use(middleware.SignatureMiddleware(secret))
+	NewIntegrationController(radio).RegisterRoutes(signed)
+	return router
+}
+
+func doSignedRequest(router http.Handler, secret, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/control", strings.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(middleware.TimestampHeader, timestamp)
+	req.Header.Set(middleware.SignatureHeader, middleware.Sign(secret, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIntegrationControlActions(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		playlistErr  error
+		expectedCode int
+		wantCalls    []string
+	}{
+		{name: "skip", body: `{"action":"skip"}`, expectedCode: http.StatusOK, wantCalls: []string{"next"}},
+		{name: "previous", body: `{"action":"previous"}`, expectedCode: http.StatusOK, wantCalls: []string{"previous"}},
+		{name: "pause", body: `{"action":"pause"}`, expectedCode: http.StatusOK, wantCalls: []string{"pause"}},
+		{name: "resume", body: `{"action":"resume"}`, expectedCode: http.StatusOK, wantCalls: []string{"resume"}},
+		{name: "set playlist", body: `{"action":"set-playlist","playlist_id":"p1"}`, expectedCode: http.StatusOK, wantCalls: []string{"playlist:p1"}},
+		{name: "set playlist without id", body: `{"action":"set-playlist"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown playlist", body: `{"action":"set-playlist","playlist_id":"p1"}`, playlistErr: services.ErrPlaylistNotFound, expectedCode: http.StatusNotFound, wantCalls: []string{"playlist:p1"}},
+		{name: "set playlist fails", body: `{"action":"set-playlist","playlist_id":"p1"}`, playlistErr: errors.New("failed to get playlist: connection refused"), expectedCode: http.StatusInternalServerError, wantCalls: []string{"playlist:p1"}},
+		{name: "unknown action", body: `{"action":"rewind"}`, expectedCode: http.StatusBadRequest},
+		{name: "malformed body", body: `{"action":`, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			radio := &fakeRadioControls{playlistErr: tt.playlistErr}
+			router := newTestIntegrationRouter(radio, "shared-secret")
+
+			rec := doSignedRequest(router, "shared-secret", tt.body)
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(radio.calls, tt.wantCalls) {
+				t.Errorf("Expected controls %v, got %v", tt.wantCalls, radio.calls)
+			}
+		})
+	}
+}
+
+func TestIntegrationControlRejectsBadSignature(t *testing.T) {
+	radio := &fakeRadioControls{}
+	router := newTestIntegrationRouter(radio, "shared-secret")
+
+	rec := doSignedRequest(router, "wrong-secret", `{"action":"skip"}`)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+	if len(radio.calls) != 0 {
+		t.Errorf("Expected no controls to run, got %v", radio.calls)
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type LibraryCleanupController struct {
+	cleaner *services.LibraryCleaner
+}
+
+func NewLibraryCleanupController(cleaner *services.LibraryCleaner) *LibraryCleanupController {
+	return &LibraryCleanupController{
+		cleaner: cleaner,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *LibraryCleanupController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/songs/orphaned", c.GetOrphanedSongs).Methods("GET")
+	admin.HandleFunc("/songs/orphaned", c.DeleteOrphanedSongs).Methods("DELETE")
+}
+
+// GetOrphanedSongs lists the library songs that aren't in any playlist
+func (c *LibraryCleanupController) GetOrphanedSongs(w http.ResponseWriter, r *http.Request) {
+	songs, err := c.cleaner.Orphaned()
+	if err != nil {
+		log.Printf("[ERROR] GetOrphanedSongs: %v", err)
+		http.Error(w, "Failed to find orphaned songs", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, songs)
+}
+
+// DeleteOrphanedSongs deletes the songs that aren't in any playlist along
+// with their audio files
+func (c *LibraryCleanupController) DeleteOrphanedSongs(w http.ResponseWriter, r *http.Request) {
+	cleanup, err := c.cleaner.DeleteOrphaned(r.Context())
+	if err != nil {
+		log.Printf("[ERROR] DeleteOrphanedSongs: %v", err)
+		http.Error(w, "Failed to delete orphaned songs", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cleanup)
+}
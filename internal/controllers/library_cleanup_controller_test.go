@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+)
+
+func TestOrphanedSongs(t *testing.T) {
+	songs := repositories.NewInMemorySongRepository()
+	playlists := repositories.NewInMemoryPlaylistRepository(songs)
+	for _, id := range []string{"listed", "orphan"} {
+		if err := songs.Create(&models.Song{YouTubeID: id, Tags: models.Tags{}}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	playlist := &models.Playlist{Name: "Mix"}
+	if err := playlists.Create(playlist); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := playlists.AddSong(playlist.ID, "listed", 0); err != nil {
+		t.Fatalf("AddSong failed: %v", err)
+	}
+
+	storage := newFakeStorage()
+	storage.files[services.SongAudioKey("listed")] = []byte("audio")
+	storage.files[services.SongAudioKey("orphan")] = []byte("audio")
+	storage.files[services.PeaksKey("orphan")] = []byte("peaks")
+	storage.files[services.TranscodedKey("orphan", services.QualityLow)] = []byte("audio")
+
+	controller := NewLibraryCleanupController(services.NewLibraryCleaner(playlists, storage))
+	router := newTestAdminRouter(controller.RegisterAdminRoutes)
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/admin/songs/orphaned")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var orphaned []*models.Song
+	if err := json.NewDecoder(rec.Body).Decode(&orphaned); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(orphaned) != 1 || orphaned[0].YouTubeID != "orphan" {
+		t.Errorf("Expected only the orphaned song to be listed, got %v", orphaned)
+	}
+
+	rec = doRequest(router, http.MethodDelete, "/api/v1/admin/songs/orphaned")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var cleanup services.OrphanCleanup
+	if err := json.NewDecoder(rec.Body).Decode(&cleanup); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !reflect.DeepEqual(cleanup.Deleted, []string{"orphan"}) || len(cleanup.Failed) != 0 {
+		t.Errorf("Expected the orphaned song to be deleted, got %+v", cleanup)
+	}
+
+	if song, _ := songs.GetByYouTubeID("orphan"); song != nil {
+		t.Error("Expected the orphaned song to be gone from the library")
+	}
+	if song, _ := songs.GetByYouTubeID("listed"); song == nil {
+		t.Error("Expected the listed song to stay in the library")
+	}
+	want := map[string]bool{services.SongAudioKey("listed"): true}
+	got := make(map[string]bool)
+	for key := range storage.files {
+		got[key] = true
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only the listed song's audio to remain, have %v", got)
+	}
+}
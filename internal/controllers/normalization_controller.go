@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type NormalizationController struct {
+	normalization *services.NormalizationService
+}
+
+func NewNormalizationController(normalization *services.NormalizationService) *NormalizationController {
+	return &NormalizationController{
+		normalization: normalization,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *NormalizationController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/songs/normalize", c.StartNormalization).Methods("POST")
+	admin.HandleFunc("/songs/normalize", c.GetNormalizationProgress).Methods("GET")
+}
+
+// StartNormalization starts normalizing the stored audio of every song not
+// yet normalized, or only a playlist's songs when playlist_id is given
+func (c *NormalizationController) StartNormalization(w http.ResponseWriter, r *http.Request) {
+	progress, err := c.normalization.Start(r.URL.Query().Get("playlist_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNormalizationInProgress):
+			http.Error(w, "Normalization is already running", http.StatusConflict)
+		case errors.Is(err, services.ErrPlaylistNotFound):
+			http.Error(w, "Playlist not found", http.StatusNotFound)
+		default:
+			log.Printf("[ERROR] StartNormalization: %v", err)
+			http.Error(w, "Failed to start normalization", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, progress)
+}
+
+// GetNormalizationProgress reports on the current or last normalization run
+func (c *NormalizationController) GetNormalizationProgress(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.normalization.Progress())
+}
@@ -1,39 +1,78 @@
 package controllers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/feline-dis/go-radio-v2/internal/models"
 	"github.com/feline-dis/go-radio-v2/internal/services"
 	"github.com/gorilla/mux"
 )
 
 type PlaylistController struct {
 	playlistSvc *services.PlaylistService
-	s3Svc       *services.S3Service
+	s3Svc       services.S3ServiceInterface
+	transcoder  services.Transcoder
+
+	// audioOrigin is sent as Access-Control-Allow-Origin for song audio
+	audioOrigin string
+}
+
+// audioExposedHeaders lets cross-origin audio elements read range responses
+const audioExposedHeaders = "Accept-Ranges, Content-Range, Content-Length"
+
+// maxImportListBytes bounds the size of an uploaded import list
+const maxImportListBytes = 1 << 20
+
+// ImportListResponse reports the playlist an import list created and how each line fared
+type ImportListResponse struct {
+	Playlist *models.Playlist      `json:"playlist"`
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Skipped  int                   `json:"skipped"`
+	Lines    []services.ImportLine `json:"lines"`
 }
 
-func NewPlaylistController(playlistSvc *services.PlaylistService, s3Svc *services.S3Service) *PlaylistController {
+func NewPlaylistController(
+	playlistSvc *services.PlaylistService,
+	s3Svc services.S3ServiceInterface,
+	transcoder services.Transcoder,
+) *PlaylistController {
 	return &PlaylistController{
 		playlistSvc: playlistSvc,
 		s3Svc:       s3Svc,
+		transcoder:  transcoder,
+		audioOrigin: "*",
 	}
 }
 
+// SetAudioCORSOrigin sets the origin allowed to fetch song audio. A specific
+// origin also allows credentialed requests, which browsers refuse with "*".
+func (c *PlaylistController) SetAudioCORSOrigin(origin string) {
+	c.audioOrigin = origin
+}
+
 func (c *PlaylistController) RegisterRoutes(r *mux.Router) {
 	// Public endpoints
 	r.HandleFunc("/api/v1/playlists", c.GetPlaylists).Methods("GET")
 	r.HandleFunc("/api/v1/playlists", c.CreatePlaylist).Methods("POST")
 	r.HandleFunc("/api/v1/playlists/{id}", c.GetPlaylist).Methods("GET")
 	r.HandleFunc("/api/v1/playlists/{id}/songs", c.GetPlaylistSongs).Methods("GET")
+	r.HandleFunc("/api/v1/playlists/{id}/export", c.ExportPlaylist).Methods("GET")
 	r.HandleFunc("/api/v1/playlists/{youtube_id}/file", c.GetSongFile).Methods("GET")
+}
 
-	// Admin endpoints
-	admin := r.PathPrefix("/api/v1/admin/playlists").Subrouter()
-	admin.HandleFunc("/{id}/songs", c.AddSongToPlaylist).Methods("POST")
-	admin.HandleFunc("/{id}/songs/{songId}", c.RemoveSongFromPlaylist).Methods("DELETE")
-	admin.HandleFunc("/{id}/songs/{songId}/position", c.UpdateSongPosition).Methods("PUT")
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *PlaylistController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/playlists/{id}/songs", c.AddSongToPlaylist).Methods("POST")
+	admin.HandleFunc("/playlists/{id}/songs/{songId}", c.RemoveSongFromPlaylist).Methods("DELETE")
+	admin.HandleFunc("/playlists/{id}/songs/{songId}/position", c.UpdateSongPosition).Methods("PUT")
+	admin.HandleFunc("/songs/import-list", c.ImportList).Methods("POST")
 }
 
 func (c *PlaylistController) GetPlaylists(w http.ResponseWriter, r *http.Request) {
@@ -43,8 +82,7 @@ func (c *PlaylistController) GetPlaylists(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(playlists)
+	writeJSONWithETag(w, r, playlists)
 }
 
 func (c *PlaylistController) GetPlaylist(w http.ResponseWriter, r *http.Request) {
@@ -66,8 +104,7 @@ func (c *PlaylistController) GetPlaylist(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(playlist)
+	writeJSONWithETag(w, r, playlist)
 }
 
 func (c *PlaylistController) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
@@ -82,7 +119,25 @@ func (c *PlaylistController) CreatePlaylist(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Songs may be given as bare IDs or pasted YouTube URLs
+	for i, song := range request.Songs {
+		id, err := services.ParseYouTubeID(song)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Songs[i] = id
+	}
+
 	playlist, err := c.playlistSvc.CreatePlaylist(request.Name, request.Description, request.Songs)
+	if errors.Is(err, services.ErrPlaylistNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, services.ErrPlaylistTooLarge) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,6 +148,61 @@ func (c *PlaylistController) CreatePlaylist(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(playlist)
 }
 
+// ImportList creates a playlist named by ?name= from a text body of YouTube
+// URLs or IDs, one per line, or CSV with optional title and artist columns
+func (c *PlaylistController) ImportList(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	lines, err := services.ParseImportList(http.MaxBytesReader(w, r.Body, maxImportListBytes))
+	if err != nil {
+		http.Error(w, "Invalid import list", http.StatusBadRequest)
+		return
+	}
+
+	valid := 0
+	for _, line := range lines {
+		if line.Status != services.ImportStatusFailed {
+			valid++
+		}
+	}
+	if valid == 0 {
+		http.Error(w, "No YouTube URLs or IDs found", http.StatusBadRequest)
+		return
+	}
+
+	playlist, err := c.playlistSvc.ImportList(name, r.URL.Query().Get("description"), lines)
+	if errors.Is(err, services.ErrPlaylistNameTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, services.ErrPlaylistTooLarge) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] ImportList: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := ImportListResponse{Playlist: playlist, Lines: lines}
+	for _, line := range lines {
+		switch line.Status {
+		case services.ImportStatusImported:
+			response.Imported++
+		case services.ImportStatusSkipped:
+			response.Skipped++
+		default:
+			response.Failed++
+		}
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
 func (c *PlaylistController) GetPlaylistSongs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -101,14 +211,45 @@ func (c *PlaylistController) GetPlaylistSongs(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	query := r.URL.Query()
+	if query.Has("limit") || query.Has("offset") {
+		limit, err := optionalIntParam(query.Get("limit"))
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		offset, err := optionalIntParam(query.Get("offset"))
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+
+		page, err := c.playlistSvc.GetPlaylistSongsPage(id, limit, offset)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONWithETag(w, r, page)
+		return
+	}
+
+	// Without paging parameters the whole playlist is returned as a bare list
 	songs, err := c.playlistSvc.GetPlaylistSongs(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(songs)
+	writeJSONWithETag(w, r, songs)
+}
+
+// optionalIntParam parses an integer query parameter, treating empty as zero
+func optionalIntParam(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
 }
 
 func (c *PlaylistController) AddSongToPlaylist(w http.ResponseWriter, r *http.Request) {
@@ -129,7 +270,18 @@ func (c *PlaylistController) AddSongToPlaylist(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := c.playlistSvc.AddSongToPlaylist(id, request.SongID, request.Position); err != nil {
+	songID, err := services.ParseYouTubeID(request.SongID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = c.playlistSvc.AddSongToPlaylist(id, songID, request.Position)
+	if errors.Is(err, services.ErrPlaylistTooLarge) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -198,32 +350,107 @@ func (c *PlaylistController) GetSongFile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	exists, err := c.s3Svc.FileExists(r.Context(), "songs/"+youtubeID+".mp3")
+	// Set explicitly since streamed audio must carry these on every response,
+	// including errors, for cross-origin <audio> elements
+	c.setAudioCORSHeaders(w)
+
+	quality := r.URL.Query().Get("quality")
+	if quality != "" && !services.IsValidQuality(quality) {
+		http.Error(w, "Invalid quality, expected low, medium or high", http.StatusBadRequest)
+		return
+	}
+
+	audio, err := services.FindSongAudio(r.Context(), c.s3Svc, youtubeID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if !exists {
+	if audio == nil {
 		http.Error(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	// Set proper headers for audio streaming
-	w.Header().Set("Content-Type", "audio/mpeg")
-	w.Header().Set("Accept-Ranges", "bytes")
-	w.Header().Set("Cache-Control", "public, max-age=31536000")
+	// Serve a lower bitrate variant when requested and ffmpeg is available
+	if quality != "" && c.transcoder != nil && c.transcoder.Available() {
+		if c.serveTranscoded(w, r, youtubeID, audio.Key, quality) {
+			return
+		}
+	}
 
-	file, err := c.s3Svc.GetFile(r.Context(), "songs/"+youtubeID+".mp3")
+	file, err := c.s3Svc.GetFile(r.Context(), audio.Key)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer file.Close()
 
-	_, err = io.Copy(w, file)
+	c.writeAudio(w, file, audio.ContentType())
+}
+
+// serveTranscoded serves the cached transcoded variant of a song, creating and
+// caching it first if needed. It returns false when nothing was written so the
+// caller can fall back to the original file.
+func (c *PlaylistController) serveTranscoded(w http.ResponseWriter, r *http.Request, youtubeID, key, quality string) bool {
+	transcodedKey := services.TranscodedKey(youtubeID, quality)
+
+	cached, err := c.s3Svc.FileExists(r.Context(), transcodedKey)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		log.Printf("[ERROR] GetSongFile: Failed to check transcoded file %s: %v", transcodedKey, err)
+		return false
+	}
+
+	if cached {
+		file, err := c.s3Svc.GetFile(r.Context(), transcodedKey)
+		if err != nil {
+			log.Printf("[ERROR] GetSongFile: Failed to get transcoded file %s: %v", transcodedKey, err)
+			return false
+		}
+		defer file.Close()
+
+		c.writeAudio(w, file, services.AudioContentType(transcodedKey))
+		return true
+	}
+
+	original, err := c.s3Svc.GetFile(r.Context(), key)
+	if err != nil {
+		log.Printf("[ERROR] GetSongFile: Failed to get original file %s: %v", key, err)
+		return false
+	}
+	defer original.Close()
+
+	var transcoded bytes.Buffer
+	if err := c.transcoder.Transcode(r.Context(), original, &transcoded, quality); err != nil {
+		log.Printf("[ERROR] GetSongFile: Failed to transcode %s to %s quality: %v", youtubeID, quality, err)
+		return false
+	}
+
+	if err := c.s3Svc.UploadFile(r.Context(), transcodedKey, bytes.NewReader(transcoded.Bytes())); err != nil {
+		// Still serve the transcoded audio, it just won't be reused
+		log.Printf("[ERROR] GetSongFile: Failed to cache transcoded file %s: %v", transcodedKey, err)
+	}
+
+	c.writeAudio(w, &transcoded, services.AudioContentType(transcodedKey))
+	return true
+}
+
+func (c *PlaylistController) setAudioCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", c.audioOrigin)
+	w.Header().Set("Access-Control-Expose-Headers", audioExposedHeaders)
+	if c.audioOrigin != "*" {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Add("Vary", "Origin")
+	}
+}
+
+// writeAudio streams audio to the response with headers suitable for playback
+func (c *PlaylistController) writeAudio(w http.ResponseWriter, body io.Reader, contentType string) {
+	// Set proper headers for audio streaming
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Cache-Control", "public, max-age=31536000")
+
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("[ERROR] GetSongFile: Failed to write audio: %v", err)
 	}
 }
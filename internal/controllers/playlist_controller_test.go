@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// fakeStorage is an in-memory stand-in for S3
+type fakeStorage struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	uploads []string
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{files: make(map[string][]byte)}
+}
+
+func (f *fakeStorage) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "https://example.com/" + key, nil
+}
+
+func (f *fakeStorage) UploadFile(ctx context.Context, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[key] = data
+	f.uploads = append(f.uploads, key)
+	return nil
+}
+
+func (f *fakeStorage) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.files[key]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeStorage) FileExists(ctx context.Context, key string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.files[key]
+	return ok, nil
+}
+
+func (f *fakeStorage) FileSize(ctx context.Context, key string) (int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.files[key]
+	return int64(len(data)), ok, nil
+}
+
+func (f *fakeStorage) DeleteFile(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, key)
+	return nil
+}
+
+// fakeTranscoder prefixes the input with the requested quality
+type fakeTranscoder struct {
+	available bool
+	calls     int
+}
+
+func (f *fakeTranscoder) Available() bool {
+	return f.available
+}
+
+func (f *fakeTranscoder) Transcode(ctx context.Context, input io.Reader, output io.Writer, quality string) error {
+	f.calls++
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(output, "%s:%s", quality, data)
+	return err
+}
+
+func newTestPlaylistRouter(c *PlaylistController) *mux.Router {
+	router := mux.NewRouter()
+	c.RegisterRoutes(router)
+	return router
+}
+
+func getSongFile(router http.Handler, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetSongFileQualityRouting(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		available    bool
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "no quality serves original", query: "", available: true, expectedCode: http.StatusOK, expectedBody: "original"},
+		{name: "low quality is transcoded", query: "?quality=low", available: true, expectedCode: http.StatusOK, expectedBody: "low:original"},
+		{name: "high quality is transcoded", query: "?quality=high", available: true, expectedCode: http.StatusOK, expectedBody: "high:original"},
+		{name: "missing ffmpeg falls back to original", query: "?quality=low", available: false, expectedCode: http.StatusOK, expectedBody: "original"},
+		{name: "invalid quality is rejected", query: "?quality=ultra", available: true, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newFakeStorage()
+			storage.files["songs/abc123.mp3"] = []byte("original")
+			transcoder := &fakeTranscoder{available: tt.available}
+			router := newTestPlaylistRouter(NewPlaylistController(nil, storage, transcoder))
+
+			rec := getSongFile(router, "/api/v1/playlists/abc123/file"+tt.query)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if tt.expectedBody != "" && rec.Body.String() != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && rec.Header().Get("Content-Type") != "audio/mpeg" {
+				t.Errorf("Expected audio/mpeg content type, got %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestGetSongFileContentType(t *testing.T) {
+	tests := []struct {
+		key         string
+		contentType string
+	}{
+		{key: "songs/abc123.mp3", contentType: "audio/mpeg"},
+		{key: "songs/abc123.opus", contentType: "audio/ogg"},
+		{key: "songs/abc123.ogg", contentType: "audio/ogg"},
+		{key: "songs/abc123.m4a", contentType: "audio/mp4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			storage := newFakeStorage()
+			storage.files[tt.key] = []byte("original")
+			router := newTestPlaylistRouter(NewPlaylistController(nil, storage, &fakeTranscoder{available: true}))
+
+			rec := getSongFile(router, "/api/v1/playlists/abc123/file")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected content type %q, got %q", tt.contentType, got)
+			}
+			if rec.Body.String() != "original" {
+				t.Errorf("Expected the stored audio, got %q", rec.Body.String())
+			}
+
+			// Transcoded variants are always MP3
+			rec = getSongFile(router, "/api/v1/playlists/abc123/file?quality=low")
+			if got := rec.Header().Get("Content-Type"); got != "audio/mpeg" {
+				t.Errorf("Expected transcoded audio as audio/mpeg, got %q", got)
+			}
+		})
+	}
+}
+
+func TestGetSongFileTranscodeCacheHit(t *testing.T) {
+	storage := newFakeStorage()
+	storage.files["songs/abc123.mp3"] = []byte("original")
+	transcoder := &fakeTranscoder{available: true}
+	router := newTestPlaylistRouter(NewPlaylistController(nil, storage, transcoder))
+
+	first := getSongFile(router, "/api/v1/playlists/abc123/file?quality=medium")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", first.Code)
+	}
+
+	cachedKey := services.TranscodedKey("abc123", "medium")
+	if _, ok := storage.files[cachedKey]; !ok {
+		t.Fatalf("Expected transcoded file to be cached under %s", cachedKey)
+	}
+
+	second := getSongFile(router, "/api/v1/playlists/abc123/file?quality=medium")
+	if second.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", second.Code)
+	}
+
+	if transcoder.calls != 1 {
+		t.Errorf("Expected a single transcode, got %d", transcoder.calls)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("Expected cached body %q, got %q", first.Body.String(), second.Body.String())
+	}
+}
+
+func TestGetSongFileNotFound(t *testing.T) {
+	router := newTestPlaylistRouter(NewPlaylistController(nil, newFakeStorage(), &fakeTranscoder{available: true}))
+
+	rec := getSongFile(router, "/api/v1/playlists/missing/file?quality=low")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "File not found") {
+		t.Errorf("Expected not found message, got %q", rec.Body.String())
+	}
+}
+
+func TestGetPlaylistSongsInvalidPaging(t *testing.T) {
+	router := newTestPlaylistRouter(NewPlaylistController(nil, newFakeStorage(), &fakeTranscoder{}))
+
+	for _, query := range []string{"limit=abc", "offset=abc", "limit=10&offset=-1"} {
+		rec := doRequest(router, http.MethodGet, "/api/v1/playlists/p1/songs?"+query)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGetSongFileCORSHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		origin          string
+		wantCredentials string
+	}{
+		{name: "any origin", origin: "*", wantCredentials: ""},
+		{name: "specific origin", origin: "https://radio.example.com", wantCredentials: "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newFakeStorage()
+			storage.files[services.SongAudioKey("abc123")] = []byte("original")
+			controller := NewPlaylistController(nil, storage, nil)
+			controller.SetAudioCORSOrigin(tt.origin)
+			router := newTestPlaylistRouter(controller)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/playlists/abc123/file", nil)
+			req.Header.Set("Origin", "https://radio.example.com")
+			req.Header.Set("Range", "bytes=0-3")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Expected allowed origin %q, got %q", tt.origin, got)
+			}
+			exposed := rec.Header().Get("Access-Control-Expose-Headers")
+			for _, header := range []string{"Accept-Ranges", "Content-Range", "Content-Length"} {
+				if !strings.Contains(exposed, header) {
+					t.Errorf("Expected %s to be exposed, got %q", header, exposed)
+				}
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Expected credentials %q, got %q", tt.wantCredentials, got)
+			}
+		})
+	}
+}
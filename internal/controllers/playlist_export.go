@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// Formats a playlist can be exported in
+const (
+	exportFormatJSON = "json"
+	exportFormatM3U  = "m3u"
+)
+
+// ExportPlaylist downloads a playlist and its songs as JSON or, with
+// ?format=m3u, as an extended M3U list of YouTube links
+func (c *PlaylistController) ExportPlaylist(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatJSON
+	}
+	if format != exportFormatJSON && format != exportFormatM3U {
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	playlist, err := c.playlistSvc.GetPlaylistByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if playlist == nil {
+		http.Error(w, "Playlist not found", http.StatusNotFound)
+		return
+	}
+
+	songs, err := c.playlistSvc.GetPlaylistSongs(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePlaylistExport(w, r, playlist, songs, format)
+}
+
+// writePlaylistExport streams an export as an attachment, one song at a time,
+// gzip-compressing it when the client accepts that
+func writePlaylistExport(w http.ResponseWriter, r *http.Request, playlist *models.Playlist, songs []*models.Song, format string) {
+	contentType, extension := "application/json", ".json"
+	if format == exportFormatM3U {
+		contentType, extension = "audio/x-mpegurl", ".m3u"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": exportFilename(playlist.Name) + extension,
+	}))
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	var out io.Writer = w
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+	buffered := bufio.NewWriter(out)
+	defer buffered.Flush()
+
+	var err error
+	if format == exportFormatM3U {
+		err = writeM3U(buffered, songs)
+	} else {
+		err = writeExportJSON(buffered, playlist, songs)
+	}
+	if err != nil {
+		// Headers are already sent, so the client sees a truncated body
+		log.Printf("[ERROR] ExportPlaylist: Failed to write export of %s: %v", playlist.ID, err)
+	}
+}
+
+// writeExportJSON writes {"playlist": ..., "songs": [...]} without holding
+// the encoded document in memory
+func writeExportJSON(w io.Writer, playlist *models.Playlist, songs []*models.Song) error {
+	enc := json.NewEncoder(w)
+
+	if _, err := io.WriteString(w, `{"playlist":`); err != nil {
+		return err
+	}
+	if err := enc.Encode(playlist); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"songs":[`); err != nil {
+		return err
+	}
+	for i, song := range songs {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(song); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
+
+// writeM3U writes an extended M3U list pointing at each song on YouTube
+func writeM3U(w io.Writer, songs []*models.Song) error {
+	if _, err := io.WriteString(w, "#EXTM3U\n"); err != nil {
+		return err
+	}
+	for _, song := range songs {
+		title := song.Title
+		if song.Artist != "" {
+			title = song.Artist + " - " + song.Title
+		}
+		if _, err := fmt.Fprintf(w, "#EXTINF:%d,%s\nhttps://www.youtube.com/watch?v=%s\n", song.Duration, title, song.YouTubeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFilename turns a playlist name into a safe file name stem
+func exportFilename(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	stem := strings.TrimSuffix(b.String(), "-")
+	if stem == "" {
+		return "playlist"
+	}
+	return stem
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		// Only an explicit q=0 refuses it
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			return err != nil || q > 0
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func exportFixture() (*models.Playlist, []*models.Song) {
+	playlist := &models.Playlist{ID: "p1", Name: "Late Night / Lo-fi"}
+	songs := []*models.Song{
+		{YouTubeID: "dQw4w9WgXcQ", Title: "First", Artist: "Someone", Duration: 212},
+		{YouTubeID: "9bZkp7q19f0", Title: "Second", Duration: 253},
+	}
+	return playlist, songs
+}
+
+func TestWritePlaylistExportJSON(t *testing.T) {
+	playlist, songs := exportFixture()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playlists/p1/export", nil)
+	rec := httptest.NewRecorder()
+
+	writePlaylistExport(rec, req, playlist, songs, exportFormatJSON)
+
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename=Late-Night-Lo-fi.json` {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected no Content-Encoding without Accept-Encoding")
+	}
+
+	var export struct {
+		Playlist models.Playlist `json:"playlist"`
+		Songs    []models.Song   `json:"songs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v\n%s", err, rec.Body.String())
+	}
+	if export.Playlist.ID != "p1" || len(export.Songs) != 2 || export.Songs[1].YouTubeID != "9bZkp7q19f0" {
+		t.Errorf("Unexpected export %+v", export)
+	}
+}
+
+func TestWritePlaylistExportGzip(t *testing.T) {
+	playlist, songs := exportFixture()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playlists/p1/export", nil)
+	req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	rec := httptest.NewRecorder()
+
+	writePlaylistExport(rec, req, playlist, songs, exportFormatJSON)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Expected gzip Content-Encoding, got %q", got)
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	var export map[string]json.RawMessage
+	if err := json.NewDecoder(gz).Decode(&export); err != nil {
+		t.Fatalf("Decompressed export is not valid JSON: %v", err)
+	}
+	if _, ok := export["songs"]; !ok {
+		t.Errorf("Expected songs in export, got %v", export)
+	}
+}
+
+func TestWritePlaylistExportM3U(t *testing.T) {
+	playlist, songs := exportFixture()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/playlists/p1/export?format=m3u", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	rec := httptest.NewRecorder()
+
+	writePlaylistExport(rec, req, playlist, songs, exportFormatM3U)
+
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasSuffix(got, ".m3u") {
+		t.Errorf("Expected an .m3u attachment, got %q", got)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	expected := "#EXTM3U\n" +
+		"#EXTINF:212,Someone - First\nhttps://www.youtube.com/watch?v=dQw4w9WgXcQ\n" +
+		"#EXTINF:253,Second\nhttps://www.youtube.com/watch?v=9bZkp7q19f0\n"
+	if string(body) != expected {
+		t.Errorf("Unexpected m3u:\n%s", body)
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	tests := map[string]string{
+		"Late Night / Lo-fi": "Late-Night-Lo-fi",
+		"  ../../etc  ":      "etc",
+		"Café Mix":           "Café-Mix",
+		"!!!":                "playlist",
+	}
+	for name, expected := range tests {
+		if got := exportFilename(name); got != expected {
+			t.Errorf("exportFilename(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
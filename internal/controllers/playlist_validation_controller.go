@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type PlaylistValidationController struct {
+	validator *services.PlaylistValidator
+}
+
+func NewPlaylistValidationController(validator *services.PlaylistValidator) *PlaylistValidationController {
+	return &PlaylistValidationController{
+		validator: validator,
+	}
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *PlaylistValidationController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/playlists/{id}/validate", c.ValidatePlaylist).Methods("GET")
+}
+
+// ValidatePlaylist reports which songs in a playlist can't be played
+func (c *PlaylistValidationController) ValidatePlaylist(w http.ResponseWriter, r *http.Request) {
+	playlistID := mux.Vars(r)["id"]
+
+	report, err := c.validator.Validate(r.Context(), playlistID)
+	if errors.Is(err, services.ErrPlaylistNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] ValidatePlaylist: %v", err)
+		http.Error(w, "Failed to validate playlist", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// fakePlaylistSongs serves a single playlist and its song IDs
+type fakePlaylistSongs struct {
+	playlist *models.Playlist
+	songIDs  []string
+}
+
+func (f *fakePlaylistSongs) GetByID(playlistID string) (*models.Playlist, error) {
+	if f.playlist == nil || f.playlist.ID != playlistID {
+		return nil, nil
+	}
+	return f.playlist, nil
+}
+
+func (f *fakePlaylistSongs) GetSongIDs(playlistID string) ([]string, error) {
+	return f.songIDs, nil
+}
+
+// fakeSongBatch serves songs from a map and counts lookups
+type fakeSongBatch struct {
+	songs map[string]*models.Song
+	calls int
+}
+
+func (f *fakeSongBatch) GetByYouTubeIDs(youtubeIDs []string) (map[string]*models.Song, error) {
+	f.calls++
+	found := make(map[string]*models.Song)
+	for _, id := range youtubeIDs {
+		if song, ok := f.songs[id]; ok {
+			found[id] = song
+		}
+	}
+	return found, nil
+}
+
+func TestValidatePlaylist(t *testing.T) {
+	playlists := &fakePlaylistSongs{
+		playlist: &models.Playlist{ID: "p1", Name: "Mixed"},
+		songIDs:  []string{"healthy", "noaudio", "noduration", "gone"},
+	}
+	songs := &fakeSongBatch{songs: map[string]*models.Song{
+		"healthy":    {YouTubeID: "healthy", Title: "Healthy", Duration: 180},
+		"noaudio":    {YouTubeID: "noaudio", Title: "No Audio", Duration: 200},
+		"noduration": {YouTubeID: "noduration", Title: "No Duration"},
+	}}
+	storage := newFakeStorage()
+	storage.files[services.SongAudioKey("healthy")] = []byte("audio")
+	storage.files[services.SongAudioKey("noduration")] = []byte("audio")
+
+	controller := NewPlaylistValidationController(services.NewPlaylistValidator(playlists, songs, storage))
+	router := newTestAdminRouter(func(admin *mux.Router) { controller.RegisterAdminRoutes(admin) })
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/admin/playlists/p1/validate")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var report services.PlaylistValidationReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Healthy != 1 || report.Broken != 3 {
+		t.Errorf("Expected 1 healthy and 3 broken songs, got %d and %d", report.Healthy, report.Broken)
+	}
+	if songs.calls != 1 {
+		t.Errorf("Expected songs to be fetched in one lookup, got %d", songs.calls)
+	}
+
+	want := map[string][]string{
+		"healthy":    {},
+		"noaudio":    {services.SongIssueMissingAudio},
+		"noduration": {services.SongIssueZeroDuration},
+		"gone":       {services.SongIssueMissingSong, services.SongIssueMissingAudio},
+	}
+	if len(report.Songs) != len(want) {
+		t.Fatalf("Expected %d songs in the report, got %d", len(want), len(report.Songs))
+	}
+	for _, song := range report.Songs {
+		if !reflect.DeepEqual(song.Issues, want[song.YouTubeID]) {
+			t.Errorf("Song %s: expected issues %v, got %v", song.YouTubeID, want[song.YouTubeID], song.Issues)
+		}
+	}
+}
+
+func TestValidatePlaylistNotFound(t *testing.T) {
+	controller := NewPlaylistValidationController(services.NewPlaylistValidator(&fakePlaylistSongs{}, &fakeSongBatch{}, newFakeStorage()))
+	router := newTestAdminRouter(func(admin *mux.Router) { controller.RegisterAdminRoutes(admin) })
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/admin/playlists/missing/validate")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
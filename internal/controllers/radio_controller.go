@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/feline-dis/go-radio-v2/internal/models"
@@ -11,28 +14,66 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// ListenerCounter reports how many clients are connected, e.g. the websocket handler
+type ListenerCounter interface {
+	ListenerCount() int
+}
+
 type RadioController struct {
 	radioSvc *services.RadioService
+
+	// listeners is nil when the listener count isn't known
+	listeners ListenerCounter
+
+	audioURLs *services.AudioURLs
 }
 
 func NewRadioController(radioSvc *services.RadioService) *RadioController {
 	return &RadioController{
-		radioSvc: radioSvc,
+		radioSvc:  radioSvc,
+		audioURLs: services.NewAudioURLs(nil, 0),
 	}
 }
 
+// SetAudioURLs sets how queue audio URLs are made; by default they point at
+// the server's file endpoint
+func (c *RadioController) SetAudioURLs(urls *services.AudioURLs) {
+	c.audioURLs = urls
+}
+
+// SetListenerCounter includes the listener count in state snapshots
+func (c *RadioController) SetListenerCounter(listeners ListenerCounter) {
+	c.listeners = listeners
+}
+
 func (c *RadioController) RegisterRoutes(r *mux.Router) {
 	// Public endpoints
 	r.HandleFunc("/api/v1/health", c.HealthCheck).Methods("GET")
 	r.HandleFunc("/api/v1/now-playing", c.GetNowPlaying).Methods("GET")
 	r.HandleFunc("/api/v1/queue", c.GetQueue).Methods("GET")
+	r.HandleFunc("/api/v1/queue/urls", c.GetQueueURLs).Methods("GET")
+	r.HandleFunc("/api/v1/state", c.GetState).Methods("GET")
+	r.HandleFunc("/api/v1/listeners", c.GetListeners).Methods("GET")
+	r.HandleFunc("/api/v1/vote-skip", c.VoteSkip).Methods("POST")
+	r.HandleFunc("/api/v1/timeline", c.GetTimeline).Methods("GET")
+	r.HandleFunc("/api/v1/volume", c.GetVolume).Methods("GET")
 	r.HandleFunc("/api/v1/debug/playback-state", c.GetDebugPlaybackState).Methods("GET")
+}
 
-	// Admin endpoints
-	admin := r.PathPrefix("/api/v1/admin").Subrouter()
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *RadioController) RegisterAdminRoutes(admin *mux.Router) {
 	admin.HandleFunc("/skip", c.Skip).Methods("POST")
 	admin.HandleFunc("/previous", c.Previous).Methods("POST")
+	admin.HandleFunc("/reshuffle", c.Reshuffle).Methods("POST")
+	admin.HandleFunc("/play-next", c.PlayNext).Methods("POST")
+	admin.HandleFunc("/queue", c.EnqueueSong).Methods("POST")
+	admin.HandleFunc("/volume", c.SetVolume).Methods("POST")
+	admin.HandleFunc("/repeat", c.SetRepeatMode).Methods("POST")
+	admin.HandleFunc("/prefetch-status", c.GetPrefetchStatus).Methods("GET")
+	admin.HandleFunc("/playback/stop", c.StopPlayback).Methods("POST")
+	admin.HandleFunc("/playback/start", c.StartPlayback).Methods("POST")
 	admin.HandleFunc("/playlist/set-active", c.SetActivePlaylist).Methods("POST")
+	admin.HandleFunc("/playlists/{id}/preview", c.PreviewPlaylist).Methods("GET")
 }
 
 func (c *RadioController) GetNowPlaying(w http.ResponseWriter, r *http.Request) {
@@ -78,6 +119,149 @@ func (c *RadioController) Previous(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// StopPlayback takes the station off the air until StartPlayback
+func (c *RadioController) StopPlayback(w http.ResponseWriter, r *http.Request) {
+	if err := c.radioSvc.StopPlayback(); err != nil {
+		if errors.Is(err, services.ErrPlaybackNotRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("[ERROR] StopPlayback: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "success",
+		"action": "playback_stopped",
+	})
+}
+
+// StartPlayback puts a stopped station back on the air with a fresh queue
+func (c *RadioController) StartPlayback(w http.ResponseWriter, r *http.Request) {
+	if err := c.radioSvc.StartPlaybackLoop(); err != nil {
+		if errors.Is(err, services.ErrPlaybackRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("[ERROR] StartPlayback: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "success",
+		"action": "playback_started",
+	})
+}
+
+// Reshuffle shuffles the upcoming songs without interrupting the current one
+func (c *RadioController) Reshuffle(w http.ResponseWriter, r *http.Request) {
+	c.radioSvc.ReshuffleQueue()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "success",
+		"action": "reshuffle",
+	})
+}
+
+// PlayNext queues a library song to play right after the current one
+func (c *RadioController) PlayNext(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		YouTubeID string `json:"youtube_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.YouTubeID == "" {
+		http.Error(w, "youtube_id is required", http.StatusBadRequest)
+		return
+	}
+
+	youtubeID, err := services.ParseYouTubeID(request.YouTubeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.radioSvc.PlayNext(youtubeID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrSongNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrNothingPlaying):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[ERROR] PlayNext: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":     "success",
+		"action":     "play_next",
+		"youtube_id": youtubeID,
+	})
+}
+
+// EnqueueSong queues a song to play after the current one, looking it up
+// when it isn't in the library. It answers 202 Accepted while the song
+// downloads, since it only joins the queue once its audio is stored.
+func (c *RadioController) EnqueueSong(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		YouTubeID string `json:"youtube_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.YouTubeID == "" {
+		http.Error(w, "youtube_id is required", http.StatusBadRequest)
+		return
+	}
+
+	youtubeID, err := services.ParseYouTubeID(request.YouTubeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pending, err := c.radioSvc.EnqueueSong(youtubeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSongNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, services.ErrNothingPlaying), errors.Is(err, services.ErrAlreadyPlaying):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[ERROR] EnqueueSong: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status, code := "queued", http.StatusOK
+	if pending {
+		status, code = "downloading", http.StatusAccepted
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":     status,
+		"action":     "enqueue",
+		"youtube_id": youtubeID,
+	})
+}
+
 func (c *RadioController) GetQueue(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[DEBUG] GetQueue: Starting request handling")
 
@@ -96,16 +280,180 @@ func (c *RadioController) GetQueue(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	log.Printf("[DEBUG] GetQueue: Setting content type header")
-	w.Header().Set("Content-Type", "application/json")
-
 	log.Printf("[DEBUG] GetQueue: Encoding response: %+v", queueInfo)
-	if err := json.NewEncoder(w).Encode(queueInfo); err != nil {
-		log.Printf("[ERROR] GetQueue: Failed to encode response: %v", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, queueInfo)
+	log.Printf("[DEBUG] GetQueue: Response sent")
+}
+
+// GetQueueURLs returns audio URLs for the current song and the ?count= songs
+// after it, so clients can prefetch them
+func (c *RadioController) GetQueueURLs(w http.ResponseWriter, r *http.Request) {
+	count, err := optionalIntParam(r.URL.Query().Get("count"))
+	if err != nil {
+		http.Error(w, "Invalid count", http.StatusBadRequest)
 		return
 	}
-	log.Printf("[DEBUG] GetQueue: Response sent successfully")
+
+	songs := c.radioSvc.QueueSongs(count)
+	urls := make([]*services.SongURL, 0, len(songs))
+	for _, song := range songs {
+		url, err := c.audioURLs.SongURL(r.Context(), song.YouTubeID)
+		if err != nil {
+			log.Printf("[ERROR] GetQueueURLs: Failed to get URL for %s: %v", song.YouTubeID, err)
+			http.Error(w, "Failed to get audio URLs", http.StatusInternalServerError)
+			return
+		}
+		urls = append(urls, url)
+	}
+
+	writeJSON(w, http.StatusOK, urls)
+}
+
+// GetState returns the whole playback state in one response for clients that
+// poll instead of holding a websocket. ?queue_window= bounds the upcoming songs.
+func (c *RadioController) GetState(w http.ResponseWriter, r *http.Request) {
+	window, err := optionalIntParam(r.URL.Query().Get("queue_window"))
+	if err != nil {
+		http.Error(w, "Invalid queue_window", http.StatusBadRequest)
+		return
+	}
+
+	snapshot := c.radioSvc.Snapshot(window)
+	if c.listeners != nil {
+		snapshot.ListenerCount = c.listeners.ListenerCount()
+	}
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// GetListeners returns how many clients are connected to the websocket
+func (c *RadioController) GetListeners(w http.ResponseWriter, r *http.Request) {
+	count := 0
+	if c.listeners != nil {
+		count = c.listeners.ListenerCount()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{"count": count})
+}
+
+// GetTimeline returns the songs just played, the current song and the songs
+// coming up with their expected start times. ?before= and ?after= set how
+// many songs are listed on each side.
+func (c *RadioController) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	before, err := timelineWindowParam(r.URL.Query().Get("before"), services.DefaultTimelineBefore)
+	if err != nil {
+		http.Error(w, "Invalid before", http.StatusBadRequest)
+		return
+	}
+	after, err := timelineWindowParam(r.URL.Query().Get("after"), services.DefaultTimelineAfter)
+	if err != nil {
+		http.Error(w, "Invalid after", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c.radioSvc.Timeline(before, after))
+}
+
+// timelineWindowParam parses a song count, using def when it is missing.
+// Zero is allowed, to leave that side of the timeline out.
+func timelineWindowParam(raw string, def int) (int, error) {
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("negative song count %d", n)
+	}
+	return n, nil
+}
+
+// volumeResponse is the level every client plays at, from 0.0 to 1.0
+type volumeResponse struct {
+	Volume float64 `json:"volume"`
+}
+
+// GetVolume returns the level every client plays at
+func (c *RadioController) GetVolume(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, volumeResponse{Volume: c.radioSvc.GetVolume()})
+}
+
+// SetVolume sets the level every client plays at. Values outside 0.0–1.0
+// are clamped and the response gives the level that was applied.
+func (c *RadioController) SetVolume(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Volume *float64 `json:"volume"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Volume == nil {
+		http.Error(w, "volume is required", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, volumeResponse{Volume: c.radioSvc.SetVolume(*request.Volume)})
+}
+
+// VoteSkip casts a listener's vote to skip the current song, which is
+// skipped once enough listeners have voted
+func (c *RadioController) VoteSkip(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	status, err := c.radioSvc.VoteSkip(request.UserID)
+	switch {
+	case errors.Is(err, services.ErrVoterRequired):
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrNothingPlaying):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("[ERROR] VoteSkip: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetPrefetchStatus reports the queue length and how far downloads are behind
+func (c *RadioController) GetPrefetchStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.radioSvc.PrefetchStatus())
+}
+
+// SetRepeatMode sets what happens when a song ends: "one" replays it, "all"
+// starts the queue again once it has played through and "off" stops there
+func (c *RadioController) SetRepeatMode(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Mode string `json:"mode"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	mode, err := services.ParseRepeatMode(request.Mode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.radioSvc.SetRepeatMode(mode)
+
+	writeJSON(w, http.StatusOK, map[string]services.RepeatMode{"repeat_mode": mode})
 }
 
 func (c *RadioController) GetDebugPlaybackState(w http.ResponseWriter, r *http.Request) {
@@ -171,3 +519,20 @@ func (c *RadioController) SetActivePlaylist(w http.ResponseWriter, r *http.Reque
 		"playlist_id": request.PlaylistID,
 	})
 }
+
+// PreviewPlaylist returns the queue switching to a playlist would produce
+// without switching to it
+func (c *RadioController) PreviewPlaylist(w http.ResponseWriter, r *http.Request) {
+	queueInfo, err := c.radioSvc.PreviewPlaylist(mux.Vars(r)["id"])
+	if errors.Is(err, services.ErrPlaylistNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] PreviewPlaylist: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, queueInfo)
+}
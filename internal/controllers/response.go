@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// writeJSON encodes v before writing anything, so an encoding failure becomes
+// a clean 500 instead of a success status with a truncated body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, ok := encodeJSON(w, v)
+	if !ok {
+		return
+	}
+	writeEncodedJSON(w, status, data)
+}
+
+// writeJSONWithETag writes v with an ETag of its encoding, and answers
+// 304 Not Modified when the request's If-None-Match already has it, so
+// polling clients don't download unchanged lists again
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v interface{}) {
+	data, ok := encodeJSON(w, v)
+	if !ok {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	// Clients may cache the response but must check it is still current
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeEncodedJSON(w, http.StatusOK, data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using
+// the weak comparison required for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeJSON marshals v, answering with a 500 and returning false on failure
+func encodeJSON(w http.ResponseWriter, v interface{}) ([]byte, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[ERROR] writeJSON: Failed to encode response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return nil, false
+	}
+	// Match json.Encoder, which ends its output with a newline
+	return append(data, '\n'), true
+}
+
+func writeEncodedJSON(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		log.Printf("[ERROR] writeJSON: Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeJSON(rec, http.StatusCreated, map[string]string{"status": "ok"})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", got)
+	}
+	if got := rec.Body.String(); got != "{\"status\":\"ok\"}\n" {
+		t.Errorf("Unexpected body %q", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "16" {
+		t.Errorf("Expected Content-Length 16, got %q", got)
+	}
+}
+
+func TestWriteJSONUnencodableValue(t *testing.T) {
+	rec := httptest.NewRecorder()
+	// NaN can't be represented in JSON, and fails only after the first field
+	writeJSON(rec, http.StatusOK, struct {
+		Name  string  `json:"name"`
+		Value float64 `json:"value"`
+	}{Name: "queue", Value: math.NaN()})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got == "application/json" {
+		t.Error("Expected the error response not to claim a JSON body")
+	}
+	if strings.Contains(rec.Body.String(), "queue") {
+		t.Errorf("Expected no partial JSON in the body, got %q", rec.Body.String())
+	}
+}
+
+func TestWriteJSONWithETag(t *testing.T) {
+	songs := []string{"song1", "song2"}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONWithETag(w, r, songs)
+	})
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/playlists/1/songs", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d and %q", first.Code, etag)
+	}
+
+	for _, header := range []string{etag, "W/" + etag, `"other", ` + etag} {
+		rec := get(header)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("Expected 304 for If-None-Match %s, got %d", header, rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("Expected no body with 304, got %q", rec.Body.String())
+		}
+	}
+
+	// After the list changes the old ETag no longer matches
+	songs = append(songs, "song3")
+	rec := get(etag)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 after a change, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("ETag"); got == etag || got == "" {
+		t.Errorf("Expected a new ETag after a change, got %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), "song3") {
+		t.Errorf("Expected the updated list, got %q", rec.Body.String())
+	}
+}
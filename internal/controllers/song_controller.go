@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// SongStatusResponse reports whether a song's audio is already in storage
+type SongStatusResponse struct {
+	Downloaded bool  `json:"downloaded"`
+	Size       int64 `json:"size"`
+}
+
+type SongController struct {
+	songSvc       *services.SongService
+	s3Svc         services.S3ServiceInterface
+	peakGenerator services.PeakGenerator
+
+	// lyrics is nil when no lyrics provider is configured
+	lyrics *services.LyricsService
+}
+
+func NewSongController(
+	songSvc *services.SongService,
+	s3Svc services.S3ServiceInterface,
+	peakGenerator services.PeakGenerator,
+) *SongController {
+	return &SongController{
+		songSvc:       songSvc,
+		s3Svc:         s3Svc,
+		peakGenerator: peakGenerator,
+	}
+}
+
+// SetLyricsService enables the lyrics endpoint
+func (c *SongController) SetLyricsService(lyrics *services.LyricsService) {
+	c.lyrics = lyrics
+}
+
+func (c *SongController) RegisterRoutes(r *mux.Router) {
+	// Public endpoints
+	r.HandleFunc("/api/v1/songs", c.GetSongsByTag).Methods("GET")
+	r.HandleFunc("/api/v1/songs/recent", c.GetRecentSongs).Methods("GET")
+	r.HandleFunc("/api/v1/songs/{youtube_id}/peaks", c.GetSongPeaks).Methods("GET")
+	r.HandleFunc("/api/v1/songs/{youtube_id}/status", c.GetSongStatus).Methods("GET")
+	r.HandleFunc("/api/v1/songs/{youtube_id}/lyrics", c.GetSongLyrics).Methods("GET")
+	r.HandleFunc("/api/v1/artists", c.GetArtists).Methods("GET")
+	r.HandleFunc("/api/v1/artists/{name}/songs", c.GetArtistSongs).Methods("GET")
+	r.HandleFunc("/api/v1/albums", c.GetAlbums).Methods("GET")
+}
+
+// RegisterAdminRoutes registers admin endpoints on a router mounted at /api/v1/admin
+func (c *SongController) RegisterAdminRoutes(admin *mux.Router) {
+	admin.HandleFunc("/songs/{youtube_id}", c.UpdateSong).Methods("PUT")
+}
+
+// GetSongsByTag returns the songs carrying the tag given by ?tag=
+func (c *SongController) GetSongsByTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.URL.Query().Get("tag")
+	if tag == "" {
+		http.Error(w, "tag is required", http.StatusBadRequest)
+		return
+	}
+
+	songs, err := c.songSvc.GetByTag(tag)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// UpdateSong updates a song's editable metadata, currently its tags
+func (c *SongController) UpdateSong(w http.ResponseWriter, r *http.Request) {
+	youtubeID := mux.Vars(r)["youtube_id"]
+	if youtubeID == "" {
+		http.Error(w, "Missing YouTube ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	song, err := c.songSvc.SetTags(youtubeID, request.Tags)
+	if errors.Is(err, services.ErrSongNotFound) {
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] UpdateSong: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(song)
+}
+
+// GetRecentSongs returns the most recently added songs, newest first
+func (c *SongController) GetRecentSongs(w http.ResponseWriter, r *http.Request) {
+	limit := services.DefaultRecentSongsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	songs, err := c.songSvc.GetRecentlyAdded(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// GetArtists lists the library's artists with their song counts
+func (c *SongController) GetArtists(w http.ResponseWriter, r *http.Request) {
+	artists, err := c.songSvc.GetArtists()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, artists)
+}
+
+// GetArtistSongs lists one artist's songs; "Unknown" lists songs without an artist
+func (c *SongController) GetArtistSongs(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	songs, err := c.songSvc.GetByArtist(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(songs) == 0 {
+		http.Error(w, "Artist not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, songs)
+}
+
+// GetAlbums lists the library's albums with their artist and song counts
+func (c *SongController) GetAlbums(w http.ResponseWriter, r *http.Request) {
+	albums, err := c.songSvc.GetAlbums()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, albums)
+}
+
+// GetSongStatus reports whether a library song is downloaded and can play without fetching it first
+func (c *SongController) GetSongStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	youtubeID := vars["youtube_id"]
+	if youtubeID == "" {
+		http.Error(w, "Missing YouTube ID", http.StatusBadRequest)
+		return
+	}
+
+	song, err := c.songSvc.GetByYouTubeID(youtubeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if song == nil {
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	}
+
+	audio, err := services.FindSongAudio(r.Context(), c.s3Svc, song.YouTubeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := SongStatusResponse{}
+	if audio != nil {
+		status.Downloaded = true
+		status.Size = audio.Size
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// GetSongPeaks returns the waveform peaks for a song, generating and caching them on first request
+func (c *SongController) GetSongPeaks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	youtubeID := vars["youtube_id"]
+	if youtubeID == "" {
+		http.Error(w, "Missing YouTube ID", http.StatusBadRequest)
+		return
+	}
+
+	peaksKey := services.PeaksKey(youtubeID)
+	cached, err := c.s3Svc.FileExists(r.Context(), peaksKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if cached {
+		file, err := c.s3Svc.GetFile(r.Context(), peaksKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", "application/json")
+		io.Copy(w, file)
+		return
+	}
+
+	audio, err := services.FindSongAudio(r.Context(), c.s3Svc, youtubeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if audio == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	if c.peakGenerator == nil || !c.peakGenerator.Available() {
+		http.Error(w, "Peak generation is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	file, err := c.s3Svc.GetFile(r.Context(), audio.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	peaks, err := c.peakGenerator.GeneratePeaks(r.Context(), file, services.DefaultPeakCount)
+	if err != nil {
+		log.Printf("[ERROR] GetSongPeaks: Failed to generate peaks for %s: %v", youtubeID, err)
+		http.Error(w, "Failed to generate peaks", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(peaks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.s3Svc.UploadFile(r.Context(), peaksKey, bytes.NewReader(data)); err != nil {
+		// Still return the peaks, they will just be regenerated next time
+		log.Printf("[ERROR] GetSongPeaks: Failed to cache peaks for %s: %v", youtubeID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// GetSongLyrics returns a song's lyrics from the configured provider
+func (c *SongController) GetSongLyrics(w http.ResponseWriter, r *http.Request) {
+	youtubeID := mux.Vars(r)["youtube_id"]
+	if youtubeID == "" {
+		http.Error(w, "Missing YouTube ID", http.StatusBadRequest)
+		return
+	}
+
+	if c.lyrics == nil {
+		http.Error(w, "Lyrics are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	lyrics, err := c.lyrics.GetLyrics(r.Context(), youtubeID)
+	switch {
+	case errors.Is(err, services.ErrSongNotFound):
+		http.Error(w, "Song not found", http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrLyricsNotFound):
+		http.Error(w, "Lyrics not found", http.StatusNotFound)
+		return
+	case err != nil:
+		log.Printf("[ERROR] GetSongLyrics: Failed to get lyrics for %s: %v", youtubeID, err)
+		http.Error(w, "Failed to get lyrics", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lyrics)
+}
@@ -0,0 +1,434 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// fakePeakGenerator returns a fixed set of peaks
+type fakePeakGenerator struct {
+	peaks []float64
+	calls int
+}
+
+func (f *fakePeakGenerator) Available() bool {
+	return true
+}
+
+func (f *fakePeakGenerator) GeneratePeaks(ctx context.Context, input io.Reader, count int) ([]float64, error) {
+	f.calls++
+	return f.peaks, nil
+}
+
+func newTestSongRouter(c *SongController) *mux.Router {
+	router := mux.NewRouter()
+	c.RegisterRoutes(router)
+	return router
+}
+
+func doRequest(router http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetSongPeaksGeneratesAndCaches(t *testing.T) {
+	storage := newFakeStorage()
+	storage.files["songs/abc123.mp3"] = []byte("audio")
+	generator := &fakePeakGenerator{peaks: []float64{0.1, 0.5, 1}}
+	router := newTestSongRouter(NewSongController(nil, storage, generator))
+
+	for i := 0; i < 2; i++ {
+		rec := doRequest(router, http.MethodGet, "/api/v1/songs/abc123/peaks")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected status 200, got %d", i, rec.Code)
+		}
+
+		var peaks []float64
+		if err := json.Unmarshal(rec.Body.Bytes(), &peaks); err != nil {
+			t.Fatalf("Request %d: failed to decode peaks: %v", i, err)
+		}
+		if len(peaks) != 3 || peaks[1] != 0.5 {
+			t.Errorf("Request %d: unexpected peaks %v", i, peaks)
+		}
+	}
+
+	if generator.calls != 1 {
+		t.Errorf("Expected peaks to be generated once, got %d", generator.calls)
+	}
+	if _, ok := storage.files[services.PeaksKey("abc123")]; !ok {
+		t.Error("Expected peaks to be cached in storage")
+	}
+}
+
+func TestGetSongPeaksMissingAudio(t *testing.T) {
+	generator := &fakePeakGenerator{peaks: []float64{0.1}}
+	router := newTestSongRouter(NewSongController(nil, newFakeStorage(), generator))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/songs/missing/peaks")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if generator.calls != 0 {
+		t.Errorf("Expected no peak generation for missing audio, got %d calls", generator.calls)
+	}
+}
+
+// fakeSongRepository serves songs from a fixed slice
+type fakeSongRepository struct {
+	songs []*models.Song
+}
+
+func (f *fakeSongRepository) GetRandomSong() (*models.Song, error) {
+	return nil, nil
+}
+
+func (f *fakeSongRepository) GetLeastPlayedSong() (*models.Song, error) {
+	return nil, nil
+}
+
+func (f *fakeSongRepository) UpdatePlayStats(youtubeID string) error {
+	return nil
+}
+
+func (f *fakeSongRepository) GetRecentlyAdded(limit int) ([]*models.Song, error) {
+	if len(f.songs) > limit {
+		return f.songs[:limit], nil
+	}
+	return f.songs, nil
+}
+
+func (f *fakeSongRepository) GetByYouTubeID(youtubeID string) (*models.Song, error) {
+	for _, song := range f.songs {
+		if song.YouTubeID == youtubeID {
+			return song, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeSongRepository) GetWithoutDuration() ([]*models.Song, error) {
+	return nil, nil
+}
+
+func (f *fakeSongRepository) UpdateDuration(youtubeID string, duration int) error {
+	return nil
+}
+
+func (f *fakeSongRepository) GetByTag(tag string) ([]*models.Song, error) {
+	var songs []*models.Song
+	for _, song := range f.songs {
+		if song.Tags.Has(tag) {
+			songs = append(songs, song)
+		}
+	}
+	return songs, nil
+}
+
+func (f *fakeSongRepository) GetArtists() ([]*models.ArtistSummary, error) {
+	var artists []*models.ArtistSummary
+	index := make(map[string]*models.ArtistSummary)
+	for _, song := range f.songs {
+		name := models.LibraryName(song.Artist)
+		if index[name] == nil {
+			index[name] = &models.ArtistSummary{Name: name}
+			artists = append(artists, index[name])
+		}
+		index[name].SongCount++
+	}
+	return artists, nil
+}
+
+func (f *fakeSongRepository) GetAlbums() ([]*models.AlbumSummary, error) {
+	var albums []*models.AlbumSummary
+	index := make(map[[2]string]*models.AlbumSummary)
+	for _, song := range f.songs {
+		key := [2]string{models.LibraryName(song.Album), models.LibraryName(song.Artist)}
+		if index[key] == nil {
+			index[key] = &models.AlbumSummary{Name: key[0], Artist: key[1]}
+			albums = append(albums, index[key])
+		}
+		index[key].SongCount++
+	}
+	return albums, nil
+}
+
+func (f *fakeSongRepository) GetByArtist(artist string) ([]*models.Song, error) {
+	var songs []*models.Song
+	for _, song := range f.songs {
+		if models.LibraryName(song.Artist) == artist {
+			songs = append(songs, song)
+		}
+	}
+	return songs, nil
+}
+
+func (f *fakeSongRepository) UpdateTags(youtubeID string, tags models.Tags) error {
+	for _, song := range f.songs {
+		if song.YouTubeID == youtubeID {
+			song.Tags = tags
+		}
+	}
+	return nil
+}
+
+func TestGetRecentSongs(t *testing.T) {
+	songRepo := &fakeSongRepository{songs: []*models.Song{
+		{YouTubeID: "newest"},
+		{YouTubeID: "older"},
+		{YouTubeID: "oldest"},
+	}}
+	router := newTestSongRouter(NewSongController(services.NewSongService(songRepo), newFakeStorage(), nil))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/songs/recent?limit=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var songs []*models.Song
+	if err := json.Unmarshal(rec.Body.Bytes(), &songs); err != nil {
+		t.Fatalf("Failed to decode songs: %v", err)
+	}
+	if len(songs) != 2 || songs[0].YouTubeID != "newest" || songs[1].YouTubeID != "older" {
+		t.Errorf("Expected the two newest songs in order, got %v", songs)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/songs/recent?limit=abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid limit, got %d", rec.Code)
+	}
+}
+
+func TestGetRecentSongsEmptyLibrary(t *testing.T) {
+	router := newTestSongRouter(NewSongController(services.NewSongService(&fakeSongRepository{}), newFakeStorage(), nil))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/songs/recent")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Errorf("Expected empty JSON array, got %q", body)
+	}
+}
+
+func TestGetSongStatus(t *testing.T) {
+	storage := newFakeStorage()
+	storage.files["songs/cached.mp3"] = []byte("audio")
+	songRepo := &fakeSongRepository{songs: []*models.Song{
+		{YouTubeID: "cached", S3Key: "songs/cached.mp3"},
+		{YouTubeID: "uncached", S3Key: "songs/uncached.mp3"},
+	}}
+	router := newTestSongRouter(NewSongController(services.NewSongService(songRepo), storage, nil))
+
+	tests := []struct {
+		name       string
+		youtubeID  string
+		wantStatus int
+		want       SongStatusResponse
+	}{
+		{"downloaded", "cached", http.StatusOK, SongStatusResponse{Downloaded: true, Size: 5}},
+		{"not downloaded", "uncached", http.StatusOK, SongStatusResponse{Downloaded: false, Size: 0}},
+		{"unknown song", "missing", http.StatusNotFound, SongStatusResponse{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, http.MethodGet, "/api/v1/songs/"+tt.youtubeID+"/status")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var status SongStatusResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatalf("Failed to decode status: %v", err)
+			}
+			if status != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestSongAudioKeyMatchesRetrieval(t *testing.T) {
+	// Audio stored under SongAudioKey must be what the status and file endpoints look up
+	storage := newFakeStorage()
+	storage.files[services.SongAudioKey("abc123")] = []byte("audio")
+	songRepo := &fakeSongRepository{songs: []*models.Song{{YouTubeID: "abc123"}}}
+
+	songRouter := newTestSongRouter(NewSongController(services.NewSongService(songRepo), storage, nil))
+	rec := doRequest(songRouter, http.MethodGet, "/api/v1/songs/abc123/status")
+	var status SongStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if !status.Downloaded {
+		t.Error("Expected song to be reported as downloaded")
+	}
+
+	playlistRouter := newTestPlaylistRouter(NewPlaylistController(nil, storage, nil))
+	rec = doRequest(playlistRouter, http.MethodGet, "/api/v1/playlists/abc123/file")
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected song file to be served, got status %d", rec.Code)
+	}
+}
+
+func TestGetSongsByTag(t *testing.T) {
+	songRepo := &fakeSongRepository{songs: []*models.Song{
+		{YouTubeID: "calm", Tags: models.Tags{"chill"}},
+		{YouTubeID: "loud", Tags: models.Tags{"rock", "explicit"}},
+		{YouTubeID: "both", Tags: models.Tags{"chill", "explicit"}},
+	}}
+	router := newTestSongRouter(NewSongController(services.NewSongService(songRepo), newFakeStorage(), nil))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/songs?tag=Chill")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var songs []*models.Song
+	if err := json.Unmarshal(rec.Body.Bytes(), &songs); err != nil {
+		t.Fatalf("Failed to decode songs: %v", err)
+	}
+	if len(songs) != 2 || songs[0].YouTubeID != "calm" || songs[1].YouTubeID != "both" {
+		t.Errorf("Expected the two chill songs, got %v", songs)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/songs?tag=jazz"); rec.Body.String() != "[]\n" {
+		t.Errorf("Expected an empty list for an unused tag, got %q", rec.Body.String())
+	}
+	if rec := doRequest(router, http.MethodGet, "/api/v1/songs"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a tag, got %d", rec.Code)
+	}
+}
+
+func TestLibraryGroupings(t *testing.T) {
+	songRepo := &fakeSongRepository{songs: []*models.Song{
+		{YouTubeID: "roygbiv", Artist: "Boards of Canada", Album: "Music Has the Right to Children"},
+		{YouTubeID: "aquarius", Artist: "Boards of Canada", Album: "Music Has the Right to Children"},
+		{YouTubeID: "dayvan", Artist: "Boards of Canada", Album: "Geogaddi"},
+		{YouTubeID: "xtal", Artist: "aphex twin", Album: "Selected Ambient Works 85-92"},
+		{YouTubeID: "blank", Artist: "", Album: ""},
+		{YouTubeID: "unknown", Artist: "Unknown", Album: "Unknown"},
+		{YouTubeID: "lower", Artist: " unknown ", Album: "Geogaddi"},
+	}}
+	router := newTestSongRouter(NewSongController(services.NewSongService(songRepo), newFakeStorage(), nil))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/artists")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var artists []models.ArtistSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &artists); err != nil {
+		t.Fatalf("Failed to decode artists: %v", err)
+	}
+	expectedArtists := []models.ArtistSummary{
+		{Name: "aphex twin", SongCount: 1},
+		{Name: "Boards of Canada", SongCount: 3},
+		{Name: "Unknown", SongCount: 3},
+	}
+	if !reflect.DeepEqual(artists, expectedArtists) {
+		t.Errorf("Expected artists %v, got %v", expectedArtists, artists)
+	}
+
+	rec = doRequest(router, http.MethodGet, "/api/v1/albums")
+	var albums []models.AlbumSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &albums); err != nil {
+		t.Fatalf("Failed to decode albums: %v", err)
+	}
+	expectedAlbums := []models.AlbumSummary{
+		{Name: "Geogaddi", Artist: "Boards of Canada", SongCount: 1},
+		{Name: "Geogaddi", Artist: "Unknown", SongCount: 1},
+		{Name: "Music Has the Right to Children", Artist: "Boards of Canada", SongCount: 2},
+		{Name: "Selected Ambient Works 85-92", Artist: "aphex twin", SongCount: 1},
+		{Name: "Unknown", Artist: "Unknown", SongCount: 2},
+	}
+	if !reflect.DeepEqual(albums, expectedAlbums) {
+		t.Errorf("Expected albums %v, got %v", expectedAlbums, albums)
+	}
+
+	rec = doRequest(router, http.MethodGet, "/api/v1/artists/Unknown/songs")
+	var songs []*models.Song
+	if err := json.Unmarshal(rec.Body.Bytes(), &songs); err != nil {
+		t.Fatalf("Failed to decode songs: %v", err)
+	}
+	if len(songs) != 3 {
+		t.Errorf("Expected the 3 songs without an artist, got %d", len(songs))
+	}
+
+	rec = doRequest(router, http.MethodGet, "/api/v1/artists/Boards%20of%20Canada/songs")
+	if err := json.Unmarshal(rec.Body.Bytes(), &songs); err != nil {
+		t.Fatalf("Failed to decode songs: %v", err)
+	}
+	if len(songs) != 3 {
+		t.Errorf("Expected 3 Boards of Canada songs, got %d", len(songs))
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/artists/Nobody/songs"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown artist, got %d", rec.Code)
+	}
+}
+
+func TestUpdateSongTags(t *testing.T) {
+	songRepo := &fakeSongRepository{songs: []*models.Song{{YouTubeID: "abc"}}}
+	controller := NewSongController(services.NewSongService(songRepo), newFakeStorage(), nil)
+	router := newTestAdminRouter(controller.RegisterAdminRoutes)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/songs/abc", strings.NewReader(`{"tags":["Explicit"," chill","explicit"]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if got := songRepo.songs[0].Tags; !reflect.DeepEqual(got, models.Tags{"explicit", "chill"}) {
+		t.Errorf("Expected normalized tags to be stored, got %v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/admin/songs/missing", strings.NewReader(`{"tags":["chill"]}`))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown song, got %d", rec.Code)
+	}
+}
+
+func TestGetSongLyrics(t *testing.T) {
+	c := NewSongController(services.NewSongService(&fakeSongRepository{}), newFakeStorage(), nil)
+	router := newTestSongRouter(c)
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/songs/abc/lyrics"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a lyrics provider, got %d", rec.Code)
+	}
+
+	storage := newFakeStorage()
+	storage.files[services.LyricsKey("abc")] = []byte(`{"plain":"la la la"}`)
+	c.SetLyricsService(services.NewLyricsService(&fakeSongRepository{}, nil, storage))
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/songs/abc/lyrics")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var lyrics services.Lyrics
+	if err := json.NewDecoder(rec.Body).Decode(&lyrics); err != nil {
+		t.Fatalf("Failed to decode lyrics: %v", err)
+	}
+	if lyrics.Plain != "la la la" {
+		t.Errorf("Expected cached lyrics, got %+v", lyrics)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/songs/missing/lyrics"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown song, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// StationController serves read-only endpoints for each station, namespaced
+// under /api/v1/stations/{stationID}
+type StationController struct {
+	stations *services.StationManager
+}
+
+func NewStationController(stations *services.StationManager) *StationController {
+	return &StationController{
+		stations: stations,
+	}
+}
+
+func (c *StationController) RegisterRoutes(r *mux.Router) {
+	// Public endpoints
+	r.HandleFunc("/api/v1/stations", c.GetStations).Methods("GET")
+	r.HandleFunc("/api/v1/stations/{stationID}/now-playing", c.stationHandler((*RadioController).GetNowPlaying)).Methods("GET")
+	r.HandleFunc("/api/v1/stations/{stationID}/queue", c.stationHandler((*RadioController).GetQueue)).Methods("GET")
+	r.HandleFunc("/api/v1/stations/{stationID}/state", c.stationHandler((*RadioController).GetState)).Methods("GET")
+	r.HandleFunc("/api/v1/stations/{stationID}/timeline", c.stationHandler((*RadioController).GetTimeline)).Methods("GET")
+	r.HandleFunc("/api/v1/stations/{stationID}/volume", c.stationHandler((*RadioController).GetVolume)).Methods("GET")
+}
+
+// RegisterWebSocketRoutes registers each station's websocket endpoint at /ws/{stationID}
+func (c *StationController) RegisterWebSocketRoutes(r *mux.Router) {
+	r.HandleFunc("/ws/{stationID}", c.ServeWebSocket)
+}
+
+// StationSummary describes a station in the station list
+type StationSummary struct {
+	ID         string           `json:"id"`
+	Playlist   *models.Playlist `json:"playlist"`
+	NowPlaying *models.Song     `json:"now_playing"`
+}
+
+// GetStations lists every station with what it is playing
+func (c *StationController) GetStations(w http.ResponseWriter, r *http.Request) {
+	stations := c.stations.List()
+	summaries := make([]StationSummary, 0, len(stations))
+	for _, station := range stations {
+		summaries = append(summaries, StationSummary{
+			ID:         station.ID,
+			Playlist:   station.Radio.GetQueueInfo().Playlist,
+			NowPlaying: station.Radio.GetCurrentSong(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// ServeWebSocket hands the connection to the requested station's websocket handler
+func (c *StationController) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	station := c.stations.Get(mux.Vars(r)["stationID"])
+	if station == nil || station.Socket == nil {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+
+	station.Socket.ServeHTTP(w, r)
+}
+
+// stationHandler runs a RadioController handler against the station named in
+// the path, so station routes answer exactly like the single-station ones
+func (c *StationController) stationHandler(handle func(*RadioController, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		station := c.stations.Get(mux.Vars(r)["stationID"])
+		if station == nil {
+			http.Error(w, "Station not found", http.StatusNotFound)
+			return
+		}
+
+		radio := NewRadioController(station.Radio)
+		if listeners, ok := station.Socket.(ListenerCounter); ok {
+			radio.SetListenerCounter(listeners)
+		}
+		handle(radio, w, r)
+	}
+}
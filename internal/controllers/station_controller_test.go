@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/mux"
+)
+
+func newTestStationRouter(t *testing.T, ids ...string) *mux.Router {
+	t.Helper()
+
+	manager := services.NewStationManager()
+	for _, id := range ids {
+		bus := events.NewNoopEventBus()
+		station := &services.Station{
+			ID:     id,
+			Radio:  services.NewRadioService(nil, nil, nil, bus),
+			Events: bus,
+			Socket: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(id))
+			}),
+		}
+		if err := manager.Add(station); err != nil {
+			t.Fatalf("Failed to add station %s: %v", id, err)
+		}
+	}
+
+	c := NewStationController(manager)
+	router := mux.NewRouter()
+	c.RegisterRoutes(router)
+	c.RegisterWebSocketRoutes(router)
+	return router
+}
+
+func TestGetStations(t *testing.T) {
+	router := newTestStationRouter(t, "rock", "lofi")
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/stations")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var stations []StationSummary
+	if err := json.NewDecoder(rec.Body).Decode(&stations); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(stations) != 2 || stations[0].ID != "lofi" || stations[1].ID != "rock" {
+		t.Errorf("Expected stations lofi and rock, got %+v", stations)
+	}
+}
+
+func TestStationRoutes(t *testing.T) {
+	router := newTestStationRouter(t, "lofi")
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/queue")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var queue models.QueueInfo
+	if err := json.NewDecoder(rec.Body).Decode(&queue); err != nil {
+		t.Fatalf("Failed to decode queue: %v", err)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/now-playing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 when nothing is playing, got %d", rec.Code)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/ws/lofi"); rec.Body.String() != "lofi" {
+		t.Errorf("Expected the lofi websocket handler, got %q", rec.Body.String())
+	}
+
+	for _, path := range []string{"/api/v1/stations/jazz/queue", "/api/v1/stations/jazz/now-playing", "/ws/jazz"} {
+		if rec := doRequest(router, http.MethodGet, path); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, rec.Code)
+		}
+	}
+}
+
+// countingSocket is a websocket handler stand-in that reports a fixed listener count
+type countingSocket struct {
+	http.Handler
+	count int
+}
+
+func (s countingSocket) ListenerCount() int { return s.count }
+
+func TestStationState(t *testing.T) {
+	manager := services.NewStationManager()
+	bus := events.NewNoopEventBus()
+	if err := manager.Add(&services.Station{
+		ID:     "lofi",
+		Radio:  services.NewRadioService(nil, nil, nil, bus),
+		Events: bus,
+		Socket: countingSocket{count: 7},
+	}); err != nil {
+		t.Fatalf("Failed to add station: %v", err)
+	}
+	router := mux.NewRouter()
+	NewStationController(manager).RegisterRoutes(router)
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/state")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var snapshot services.RadioSnapshot
+	if err := json.NewDecoder(rec.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if snapshot.ListenerCount != 7 {
+		t.Errorf("Expected 7 listeners, got %d", snapshot.ListenerCount)
+	}
+	if snapshot.CurrentSong != nil || snapshot.Upcoming == nil {
+		t.Errorf("Expected an idle snapshot with an empty queue window, got %+v", snapshot)
+	}
+
+	if rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/state?queue_window=x"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid window, got %d", rec.Code)
+	}
+}
+
+func TestStationTimeline(t *testing.T) {
+	manager := services.NewStationManager()
+	bus := events.NewNoopEventBus()
+	if err := manager.Add(&services.Station{
+		ID:     "lofi",
+		Radio:  services.NewRadioService(nil, nil, nil, bus),
+		Events: bus,
+	}); err != nil {
+		t.Fatalf("Failed to add station: %v", err)
+	}
+	router := mux.NewRouter()
+	NewStationController(manager).RegisterRoutes(router)
+
+	rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/timeline?before=0&after=5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var timeline services.RadioTimeline
+	if err := json.NewDecoder(rec.Body).Decode(&timeline); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if timeline.Current != nil || timeline.History == nil || timeline.Upcoming == nil {
+		t.Errorf("Expected an idle timeline with empty lists, got %+v", timeline)
+	}
+
+	for _, query := range []string{"before=-1", "after=x"} {
+		if rec := doRequest(router, http.MethodGet, "/api/v1/stations/lofi/timeline?"+query); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
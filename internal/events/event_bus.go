@@ -1,8 +1,11 @@
 package events
 
 import (
+	"cmp"
 	"log"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/feline-dis/go-radio-v2/internal/models"
@@ -17,6 +20,10 @@ const (
 	EventSkip           = "skip"
 	EventPrevious       = "previous"
 	EventPlaylistChange = "playlist_change"
+	EventPlaybackStop   = "playback_stopped"
+	EventPlaybackStart  = "playback_started"
+	EventAnnouncement   = "announcement"
+	EventVoteUpdate     = "vote_update"
 )
 
 // Event represents a generic event
@@ -56,6 +63,7 @@ type PlaybackUpdateEvent struct {
 	Remaining float64      `json:"remaining"`
 	Paused    bool         `json:"paused"`
 	TotalTime float64      `json:"total_time"`
+	Volume    float64      `json:"volume"`
 	Timestamp int64        `json:"timestamp"`
 }
 
@@ -90,52 +98,319 @@ type PlaylistChangeEvent struct {
 	Timestamp int64                 `json:"timestamp"`
 }
 
+const (
+	// DefaultSlowHandlerThreshold is how long a handler may run before it is
+	// reported as slow
+	DefaultSlowHandlerThreshold = 500 * time.Millisecond
+	// DefaultWorkerCount is how many handlers the bus runs at once
+	DefaultWorkerCount = 8
+
+	// dispatchQueueSize is how many handler invocations can wait for a worker
+	// before Publish drops them
+	dispatchQueueSize = 1024
+	// orderedQueueSize is how many invocations an ordered subscriber can have
+	// waiting before Publish drops them
+	orderedQueueSize = 256
+
+	// droppedLogInterval is the least time between warnings about dropped
+	// handler invocations
+	droppedLogInterval = 10 * time.Second
+)
+
+// PlaybackStoppedEvent represents playback ending at the end of the queue or
+// being stopped by an operator
+type PlaybackStoppedEvent struct {
+	Playlist  *models.Playlist `json:"playlist"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+// PlaybackStartedEvent represents an operator starting stopped playback again
+type PlaybackStartedEvent struct {
+	Playlist  *models.Playlist `json:"playlist"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+// AnnouncementEvent represents a spoken clip introducing the next song,
+// played in the gap before it starts
+type AnnouncementEvent struct {
+	Song           *models.Song `json:"song"`
+	AnnouncementID string       `json:"announcement_id"`
+	Text           string       `json:"text"`
+	Timestamp      int64        `json:"timestamp"`
+}
+
+// VoteUpdateEvent reports the listeners' votes to skip the current song
+type VoteUpdateEvent struct {
+	Song      *models.Song `json:"song"`
+	Votes     int          `json:"votes"`
+	Required  int          `json:"required"`
+	Timestamp int64        `json:"timestamp"`
+}
+
 // EventHandler is a function that handles events
 type EventHandler func(event Event)
 
+// SubscriptionID identifies a registered handler for Unsubscribe
+type SubscriptionID uint64
+
+// subscription is a registered handler. Handlers with an ordered subscriber
+// run on its queue instead of the shared worker pool.
+type subscription struct {
+	id      SubscriptionID
+	handler EventHandler
+	ordered *OrderedSubscriber
+}
+
 // EventBus manages event subscriptions and publishing
 type EventBus struct {
-	handlers map[string][]EventHandler
+	// handlers holds each event type's subscriptions by ID; lastID is the
+	// most recently issued ID, so IDs also give the order of subscription
+	handlers map[string]map[SubscriptionID]subscription
+	lastID   SubscriptionID
 	mu       sync.RWMutex
+
+	slowThreshold time.Duration
+	// onSlowHandler is called when a handler runs longer than slowThreshold
+	onSlowHandler func(eventType string, elapsed time.Duration)
+
+	// dispatch queues handler invocations for the worker pool
+	dispatch chan handlerCall
+
+	// dropped counts handler invocations dropped because their queue was
+	// full; lastDropLog is when that was last logged, in Unix nanoseconds
+	dropped     atomic.Uint64
+	lastDropLog atomic.Int64
 }
 
-// NewEventBus creates a new event bus
+// handlerCall is one handler invocation waiting for a worker
+type handlerCall struct {
+	handler       EventHandler
+	event         Event
+	slowThreshold time.Duration
+	onSlow        func(eventType string, elapsed time.Duration)
+}
+
+// NewEventBus creates a new event bus with the default number of workers
 func NewEventBus() *EventBus {
-	return &EventBus{
-		handlers: make(map[string][]EventHandler),
+	return NewEventBusWithWorkers(DefaultWorkerCount)
+}
+
+// NewEventBusWithWorkers creates a new event bus whose handlers run on a
+// fixed pool of workers. Non-positive counts fall back to the default.
+func NewEventBusWithWorkers(workers int) *EventBus {
+	if workers <= 0 {
+		workers = DefaultWorkerCount
+	}
+
+	eb := &EventBus{
+		handlers:      make(map[string]map[SubscriptionID]subscription),
+		slowThreshold: DefaultSlowHandlerThreshold,
+		onSlowHandler: logSlowHandler,
+		dispatch:      make(chan handlerCall, dispatchQueueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go eb.worker()
 	}
+	return eb
 }
 
-// Subscribe registers a handler for a specific event type
-func (eb *EventBus) Subscribe(eventType string, handler EventHandler) {
+// SetSlowHandlerThreshold sets how long a handler may run before it is
+// reported as slow. Zero disables the check.
+func (eb *EventBus) SetSlowHandlerThreshold(threshold time.Duration) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.slowThreshold = threshold
+}
+
+func logSlowHandler(eventType string, elapsed time.Duration) {
+	log.Printf("[WARN] EventBus: Slow %s handler took %v", eventType, elapsed)
+}
+
+// Subscribe registers a handler for a specific event type and returns the
+// ID that unsubscribes it
+func (eb *EventBus) Subscribe(eventType string, handler EventHandler) SubscriptionID {
+	return eb.subscribe(eventType, subscription{handler: handler})
+}
+
+func (eb *EventBus) subscribe(eventType string, sub subscription) SubscriptionID {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
 
+	eb.lastID++
+	sub.id = eb.lastID
 	if eb.handlers[eventType] == nil {
-		eb.handlers[eventType] = make([]EventHandler, 0)
+		eb.handlers[eventType] = make(map[SubscriptionID]subscription)
+	}
+	eb.handlers[eventType][sub.id] = sub
+	return sub.id
+}
+
+// Unsubscribe removes the handler registered under id. Events already queued
+// for it may still be delivered; unknown IDs are ignored.
+func (eb *EventBus) Unsubscribe(eventType string, id SubscriptionID) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	delete(eb.handlers[eventType], id)
+	if len(eb.handlers[eventType]) == 0 {
+		delete(eb.handlers, eventType)
+	}
+}
+
+// OrderedSubscriber subscribes handlers that are invoked one at a time in
+// the order events were published, across all the event types it subscribes
+// to. Each ordered subscriber has its own queue, so a slow one doesn't delay
+// other subscribers.
+type OrderedSubscriber struct {
+	bus   *EventBus
+	queue chan handlerCall
+	// done is closed once the goroutine running the queue has returned
+	done chan struct{}
+
+	// mu guards queue against Publish sending on it while Close closes it
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Ordered creates a subscriber whose handlers receive events in publish
+// order. Its goroutine runs until Close is called.
+func (eb *EventBus) Ordered() *OrderedSubscriber {
+	s := &OrderedSubscriber{
+		bus:   eb,
+		queue: make(chan handlerCall, orderedQueueSize),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for call := range s.queue {
+			call.run()
+		}
+	}()
+	return s
+}
+
+// Subscribe registers a handler on the subscriber's ordered queue and
+// returns the ID that unsubscribes it
+func (s *OrderedSubscriber) Subscribe(eventType string, handler EventHandler) SubscriptionID {
+	return s.bus.subscribe(eventType, subscription{handler: handler, ordered: s})
+}
+
+// Unsubscribe removes the handler registered under id
+func (s *OrderedSubscriber) Unsubscribe(eventType string, id SubscriptionID) {
+	s.bus.Unsubscribe(eventType, id)
+}
+
+// Close stops the subscriber's goroutine once the events already queued have
+// been handled. It is meant to be called after unsubscribing its handlers;
+// events published to any that are left are discarded. Closing again is a
+// no-op.
+func (s *OrderedSubscriber) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
 	}
-	eb.handlers[eventType] = append(eb.handlers[eventType], handler)
 }
 
-// Publish sends an event to all registered handlers
+// enqueue queues call without blocking, returning false if the queue is
+// full. Calls for a closed subscriber are discarded.
+func (s *OrderedSubscriber) enqueue(call handlerCall) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return true
+	}
+	select {
+	case s.queue <- call:
+		return true
+	default:
+		return false
+	}
+}
+
+// Publish queues an event for every registered handler. Handlers run on the
+// worker pool, or on their ordered subscriber's queue. Publish never blocks,
+// since callers such as the radio publish while holding their own locks: a
+// handler whose queue is full misses the event, which is counted and logged.
 func (eb *EventBus) Publish(event Event) {
+	// Copy the subscriptions so handlers can unsubscribe while the event is
+	// dispatched, keeping the order they subscribed in
 	eb.mu.RLock()
-	handlers := make([]EventHandler, len(eb.handlers[event.Type]))
-	copy(handlers, eb.handlers[event.Type])
+	subs := make([]subscription, 0, len(eb.handlers[event.Type]))
+	for _, sub := range eb.handlers[event.Type] {
+		subs = append(subs, sub)
+	}
+	threshold := eb.slowThreshold
+	onSlow := eb.onSlowHandler
 	eb.mu.RUnlock()
+	slices.SortFunc(subs, func(a, b subscription) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	for _, sub := range subs {
+		call := handlerCall{
+			handler:       sub.handler,
+			event:         event,
+			slowThreshold: threshold,
+			onSlow:        onSlow,
+		}
+		queued := false
+		if sub.ordered != nil {
+			queued = sub.ordered.enqueue(call)
+		} else {
+			select {
+			case eb.dispatch <- call:
+				queued = true
+			default:
+			}
+		}
+		if !queued {
+			eb.recordDropped(event.Type)
+		}
+	}
+}
+
+// Dropped returns how many handler invocations have been dropped because
+// their queue was full
+func (eb *EventBus) Dropped() uint64 {
+	return eb.dropped.Load()
+}
+
+// recordDropped counts a dropped handler invocation, logging at most once
+// per droppedLogInterval so a flood of events doesn't also flood the log
+func (eb *EventBus) recordDropped(eventType string) {
+	dropped := eb.dropped.Add(1)
+	now := time.Now().UnixNano()
+	last := eb.lastDropLog.Load()
+	if now-last < int64(droppedLogInterval) || !eb.lastDropLog.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("[WARN] EventBus: Handler queue full, dropped a %s event (%d dropped so far)", eventType, dropped)
+}
 
-	for _, handler := range handlers {
-		go func(h EventHandler, e Event) {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("[ERROR] EventBus: Handler panicked: %v", r)
-				}
-			}()
-			h(e)
-		}(handler, event)
+// worker runs queued handler invocations until the process exits
+func (eb *EventBus) worker() {
+	for call := range eb.dispatch {
+		call.run()
 	}
 }
 
+// run invokes the handler, recovering from panics so one bad handler can't
+// take down a worker, and reports it if it ran too long
+func (c handlerCall) run() {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] EventBus: Handler panicked: %v", r)
+		}
+		if elapsed := time.Since(start); c.slowThreshold > 0 && elapsed > c.slowThreshold {
+			c.onSlow(c.event.Type, elapsed)
+		}
+	}()
+	c.handler(c.event)
+}
+
 // PublishSongChange publishes a song change event
 func (eb *EventBus) PublishSongChange(currentSong, nextSong *models.Song, queueInfo *models.QueueInfo) {
 	event := Event{
@@ -188,7 +463,7 @@ func (eb *EventBus) PublishQueueUpdate(queueInfo *models.QueueInfo) {
 }
 
 // PublishPlaybackUpdate publishes a playback update event
-func (eb *EventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool) {
+func (eb *EventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool, volume float64) {
 	event := Event{
 		Type: EventPlaybackUpdate,
 		Payload: PlaybackUpdateEvent{
@@ -197,6 +472,7 @@ func (eb *EventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining
 			Remaining: remaining,
 			Paused:    paused,
 			TotalTime: float64(song.Duration),
+			Volume:    volume,
 			Timestamp: time.Now().UnixMilli(),
 		},
 		Timestamp: time.Now(),
@@ -262,3 +538,59 @@ func (eb *EventBus) PublishPlaylistChange(song *models.Song, nextSong *models.So
 	}
 	eb.Publish(event)
 }
+
+// PublishPlaybackStopped publishes a playback stopped event
+func (eb *EventBus) PublishPlaybackStopped(playlist *models.Playlist) {
+	event := Event{
+		Type: EventPlaybackStop,
+		Payload: PlaybackStoppedEvent{
+			Playlist:  playlist,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		Timestamp: time.Now(),
+	}
+	eb.Publish(event)
+}
+
+// PublishPlaybackStarted publishes a playback started event
+func (eb *EventBus) PublishPlaybackStarted(playlist *models.Playlist) {
+	event := Event{
+		Type: EventPlaybackStart,
+		Payload: PlaybackStartedEvent{
+			Playlist:  playlist,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		Timestamp: time.Now(),
+	}
+	eb.Publish(event)
+}
+
+// PublishAnnouncement publishes an announcement event
+func (eb *EventBus) PublishAnnouncement(song *models.Song, announcementID, text string) {
+	event := Event{
+		Type: EventAnnouncement,
+		Payload: AnnouncementEvent{
+			Song:           song,
+			AnnouncementID: announcementID,
+			Text:           text,
+			Timestamp:      time.Now().UnixMilli(),
+		},
+		Timestamp: time.Now(),
+	}
+	eb.Publish(event)
+}
+
+// PublishVoteUpdate publishes a vote update event
+func (eb *EventBus) PublishVoteUpdate(song *models.Song, votes, required int) {
+	event := Event{
+		Type: EventVoteUpdate,
+		Payload: VoteUpdateEvent{
+			Song:      song,
+			Votes:     votes,
+			Required:  required,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		Timestamp: time.Now(),
+	}
+	eb.Publish(event)
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -247,3 +248,326 @@ func TestHandlerPanicRecovery(t *testing.T) {
 		t.Error("Expected normal handler to be called even after panic")
 	}
 }
+
+func TestSlowHandlerWarning(t *testing.T) {
+	eventBus := NewEventBus()
+	eventBus.SetSlowHandlerThreshold(10 * time.Millisecond)
+
+	slow := make(chan string, 2)
+	eventBus.onSlowHandler = func(eventType string, elapsed time.Duration) {
+		if elapsed < 10*time.Millisecond {
+			t.Errorf("Expected elapsed time over the threshold, got %v", elapsed)
+		}
+		slow <- eventType
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	eventBus.Subscribe("test_event", func(event Event) {
+		defer wg.Done()
+		time.Sleep(50 * time.Millisecond)
+	})
+	eventBus.Subscribe("test_event", func(event Event) {
+		defer wg.Done()
+	})
+
+	eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+	wg.Wait()
+
+	select {
+	case eventType := <-slow:
+		if eventType != "test_event" {
+			t.Errorf("Expected slow handler for test_event, got %s", eventType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the slow handler warning to fire")
+	}
+
+	// Only the sleeping handler should be reported
+	select {
+	case <-slow:
+		t.Error("Expected the fast handler not to be reported")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWorkerPoolBoundsConcurrency(t *testing.T) {
+	const workers = 3
+	const published = 50
+	eventBus := NewEventBusWithWorkers(workers)
+
+	var (
+		mu       sync.Mutex
+		running  int
+		peak     int
+		received int
+		wg       sync.WaitGroup
+	)
+	wg.Add(published)
+	eventBus.Subscribe("test_event", func(event Event) {
+		defer wg.Done()
+
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		received++
+		mu.Unlock()
+	})
+
+	for i := 0; i < published; i++ {
+		eventBus.Publish(Event{Type: "test_event", Payload: i, Timestamp: time.Now()})
+	}
+	wg.Wait()
+
+	if received != published {
+		t.Errorf("Expected %d events delivered, got %d", published, received)
+	}
+	if peak > workers {
+		t.Errorf("Expected at most %d handlers at once, got %d", workers, peak)
+	}
+	if peak < 2 {
+		t.Errorf("Expected handlers to run concurrently, peak was %d", peak)
+	}
+}
+
+func TestWorkerSurvivesPanics(t *testing.T) {
+	eventBus := NewEventBusWithWorkers(1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	eventBus.Subscribe("panic_event", func(event Event) {
+		panic("test panic")
+	})
+	eventBus.Subscribe("test_event", func(event Event) {
+		wg.Done()
+	})
+
+	eventBus.Publish(Event{Type: "panic_event", Timestamp: time.Now()})
+	eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+
+	// The only worker must still be alive after the panic to deliver this
+	wg.Wait()
+}
+
+func TestOrderedSubscriberPreservesPublishOrder(t *testing.T) {
+	eventBus := NewEventBus()
+	subscriber := eventBus.Ordered()
+
+	const published = 100
+	var (
+		mu       sync.Mutex
+		received []int
+		wg       sync.WaitGroup
+	)
+	wg.Add(published)
+	record := func(event Event) {
+		defer wg.Done()
+		// Uneven handler times would reorder events run on the shared pool
+		if event.Payload.(int)%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		received = append(received, event.Payload.(int))
+		mu.Unlock()
+	}
+	subscriber.Subscribe(EventSongChange, record)
+	subscriber.Subscribe(EventQueueUpdate, record)
+
+	for i := 0; i < published; i++ {
+		eventType := EventSongChange
+		if i%2 == 1 {
+			eventType = EventQueueUpdate
+		}
+		eventBus.Publish(Event{Type: eventType, Payload: i, Timestamp: time.Now()})
+	}
+	wg.Wait()
+
+	for i, got := range received {
+		if got != i {
+			t.Fatalf("Expected event %d at position %d, got %d (received %v)", i, i, got, received)
+		}
+	}
+}
+
+func TestOrderedSubscribersAreIsolated(t *testing.T) {
+	eventBus := NewEventBus()
+
+	release := make(chan struct{})
+	defer close(release)
+	eventBus.Ordered().Subscribe("test_event", func(event Event) {
+		<-release
+	})
+
+	delivered := make(chan struct{})
+	eventBus.Ordered().Subscribe("test_event", func(event Event) {
+		close(delivered)
+	})
+
+	eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a blocked subscriber not to delay another subscriber")
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	eventBus := NewEventBus()
+	subscriber := eventBus.Ordered()
+
+	// Both handlers run on one ordered queue, so once the later one has seen
+	// an event the earlier one has too
+	var calls atomic.Int32
+	id := subscriber.Subscribe("test_event", func(event Event) {
+		calls.Add(1)
+	})
+	seen := make(chan struct{}, 2)
+	subscriber.Subscribe("test_event", func(event Event) {
+		seen <- struct{}{}
+	})
+
+	publishAndWait := func() {
+		eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+		select {
+		case <-seen:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for the event")
+		}
+	}
+
+	publishAndWait()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("Expected the handler to be called once, got %d", got)
+	}
+
+	subscriber.Unsubscribe("test_event", id)
+	publishAndWait()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected no calls after unsubscribing, got %d", got)
+	}
+
+	// Unknown IDs are ignored
+	eventBus.Unsubscribe("test_event", id)
+	eventBus.Unsubscribe("other_event", 12345)
+}
+
+func TestUnsubscribeDuringDispatch(t *testing.T) {
+	eventBus := NewEventBus()
+	subscriber := eventBus.Ordered()
+
+	var calls atomic.Int32
+	var id SubscriptionID
+	id = subscriber.Subscribe("test_event", func(event Event) {
+		calls.Add(1)
+		eventBus.Unsubscribe("test_event", id)
+	})
+
+	done := make(chan struct{}, 1)
+	subscriber.Subscribe("done_event", func(event Event) {
+		done <- struct{}{}
+	})
+
+	// Publishing concurrently with the handler removing itself must not race
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+		}()
+	}
+	wg.Wait()
+	eventBus.Publish(Event{Type: "done_event", Timestamp: time.Now()})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for dispatch to finish")
+	}
+	if got := calls.Load(); got < 1 || got > 10 {
+		t.Errorf("Expected between 1 and 10 calls, got %d", got)
+	}
+
+	eventBus.mu.RLock()
+	defer eventBus.mu.RUnlock()
+	if len(eventBus.handlers["test_event"]) != 0 {
+		t.Error("Expected the handler to have unsubscribed itself")
+	}
+}
+
+func TestPublishDropsWhenAQueueIsFull(t *testing.T) {
+	eventBus := NewEventBus()
+
+	release := make(chan struct{})
+	defer close(release)
+	eventBus.Ordered().Subscribe("test_event", func(event Event) {
+		<-release
+	})
+
+	// The stuck handler's queue fills up, but publishing must not block the
+	// caller, who may be holding its own lock
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < orderedQueueSize*2; i++ {
+			eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on a full queue")
+	}
+	if dropped := eventBus.Dropped(); dropped < orderedQueueSize-1 {
+		t.Errorf("Expected at least %d dropped invocations, got %d", orderedQueueSize-1, dropped)
+	}
+}
+
+func TestOrderedSubscriberClose(t *testing.T) {
+	eventBus := NewEventBus()
+	subscriber := eventBus.Ordered()
+
+	var handled atomic.Int32
+	release := make(chan struct{})
+	id := subscriber.Subscribe("test_event", func(event Event) {
+		<-release
+		handled.Add(1)
+	})
+	subscriber.Subscribe("other_event", func(event Event) {
+		handled.Add(1)
+	})
+
+	// Events queued before Close are still handled
+	eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+	eventBus.Publish(Event{Type: "test_event", Timestamp: time.Now()})
+	subscriber.Unsubscribe("test_event", id)
+	subscriber.Close()
+	subscriber.Close()
+	close(release)
+
+	select {
+	case <-subscriber.done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the subscriber's goroutine to stop after Close")
+	}
+	if got := handled.Load(); got != 2 {
+		t.Errorf("Expected the 2 queued events handled, got %d", got)
+	}
+
+	// A handler left subscribed doesn't get events, and they aren't counted
+	// as dropped
+	eventBus.Publish(Event{Type: "other_event", Timestamp: time.Now()})
+	if got := handled.Load(); got != 2 {
+		t.Errorf("Expected no events handled after Close, got %d", got-2)
+	}
+	if dropped := eventBus.Dropped(); dropped != 0 {
+		t.Errorf("Expected no dropped events, got %d", dropped)
+	}
+}
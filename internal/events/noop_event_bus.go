@@ -0,0 +1,61 @@
+package events
+
+import "github.com/feline-dis/go-radio-v2/internal/models"
+
+// NoopEventBus mirrors the EventBus API but discards every event. It is
+// useful for tests and for running the radio without any subscribers.
+type NoopEventBus struct{}
+
+// NewNoopEventBus creates a new no-op event bus
+func NewNoopEventBus() *NoopEventBus {
+	return &NoopEventBus{}
+}
+
+// Subscribe ignores the handler; it will never be called
+func (nb *NoopEventBus) Subscribe(eventType string, handler EventHandler) SubscriptionID {
+	return 0
+}
+
+// Unsubscribe does nothing since no handler was registered
+func (nb *NoopEventBus) Unsubscribe(eventType string, id SubscriptionID) {}
+
+// Publish discards the event
+func (nb *NoopEventBus) Publish(event Event) {}
+
+// PublishSongChange discards the song change event
+func (nb *NoopEventBus) PublishSongChange(currentSong, nextSong *models.Song, queueInfo *models.QueueInfo) {
+}
+
+// PublishQueueUpdate discards the queue update event
+func (nb *NoopEventBus) PublishQueueUpdate(queueInfo *models.QueueInfo) {}
+
+// PublishPlaybackUpdate discards the playback update event
+func (nb *NoopEventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool, volume float64) {
+}
+
+// PublishUserReaction discards the user reaction event
+func (nb *NoopEventBus) PublishUserReaction(emote string) {}
+
+// PublishSkip discards the skip event
+func (nb *NoopEventBus) PublishSkip(song *models.Song, nextSong *models.Song, state *models.PlaybackState) {
+}
+
+// PublishPrevious discards the previous event
+func (nb *NoopEventBus) PublishPrevious(song *models.Song, nextSong *models.Song, state *models.PlaybackState) {
+}
+
+// PublishPlaylistChange discards the playlist change event
+func (nb *NoopEventBus) PublishPlaylistChange(song *models.Song, nextSong *models.Song, playlist *models.Playlist, state *models.PlaybackState) {
+}
+
+// PublishPlaybackStopped discards the playback stopped event
+func (nb *NoopEventBus) PublishPlaybackStopped(playlist *models.Playlist) {}
+
+// PublishPlaybackStarted discards the playback started event
+func (nb *NoopEventBus) PublishPlaybackStarted(playlist *models.Playlist) {}
+
+// PublishAnnouncement discards the announcement event
+func (nb *NoopEventBus) PublishAnnouncement(song *models.Song, announcementID, text string) {}
+
+// PublishVoteUpdate discards the vote update event
+func (nb *NoopEventBus) PublishVoteUpdate(song *models.Song, votes, required int) {}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// Subscriber is the subscription half of the event bus
+type Subscriber interface {
+	Subscribe(eventType string, handler EventHandler) SubscriptionID
+	Unsubscribe(eventType string, id SubscriptionID)
+}
+
+// payloadTypes records the payload type published for each known event type
+var payloadTypes = map[string]reflect.Type{
+	EventSongChange:     reflect.TypeOf(SongChangeEvent{}),
+	EventQueueUpdate:    reflect.TypeOf(QueueUpdateEvent{}),
+	EventPlaybackUpdate: reflect.TypeOf(PlaybackUpdateEvent{}),
+	EventUserReaction:   reflect.TypeOf(UserReactionEvent{}),
+	EventSkip:           reflect.TypeOf(SkipEvent{}),
+	EventPrevious:       reflect.TypeOf(PreviousEvent{}),
+	EventPlaylistChange: reflect.TypeOf(PlaylistChangeEvent{}),
+	EventPlaybackStop:   reflect.TypeOf(PlaybackStoppedEvent{}),
+	EventPlaybackStart:  reflect.TypeOf(PlaybackStartedEvent{}),
+	EventAnnouncement:   reflect.TypeOf(AnnouncementEvent{}),
+	EventVoteUpdate:     reflect.TypeOf(VoteUpdateEvent{}),
+}
+
+// SubscribeTyped registers a handler that receives the concrete payload of an
+// event and returns the ID that unsubscribes it. It returns an error if T is
+// not the payload type published for a known event type, so mismatches
+// surface when subscribing rather than as dropped events at runtime.
+func SubscribeTyped[T any](bus Subscriber, eventType string, handler func(T)) (SubscriptionID, error) {
+	want := reflect.TypeOf((*T)(nil)).Elem()
+	if expected, ok := payloadTypes[eventType]; ok && expected != want {
+		return 0, fmt.Errorf("event %q carries %s payloads, not %s", eventType, expected, want)
+	}
+
+	id := bus.Subscribe(eventType, func(event Event) {
+		payload, ok := event.Payload.(T)
+		if !ok {
+			log.Printf("[ERROR] SubscribeTyped: %s event has payload %T, expected %s", eventType, event.Payload, want)
+			return
+		}
+		handler(payload)
+	})
+	return id, nil
+}
@@ -0,0 +1,67 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func TestSubscribeTypedDeliversPayload(t *testing.T) {
+	eventBus := NewEventBus()
+
+	received := make(chan SkipEvent, 1)
+	_, err := SubscribeTyped(eventBus, EventSkip, func(e SkipEvent) {
+		received <- e
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	song := &models.Song{YouTubeID: "song1"}
+	eventBus.PublishSkip(song, nil, nil)
+
+	select {
+	case e := <-received:
+		if e.Song == nil || e.Song.YouTubeID != "song1" {
+			t.Errorf("Expected song1, got %v", e.Song)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for typed skip event")
+	}
+}
+
+func TestSubscribeTypedRejectsMismatchedPayload(t *testing.T) {
+	eventBus := NewEventBus()
+
+	_, err := SubscribeTyped(eventBus, EventSongChange, func(e QueueUpdateEvent) {})
+	if err == nil {
+		t.Fatal("Expected error subscribing with the wrong payload type")
+	}
+
+	eventBus.mu.RLock()
+	defer eventBus.mu.RUnlock()
+	if len(eventBus.handlers[EventSongChange]) != 0 {
+		t.Error("Expected mismatched handler not to be registered")
+	}
+}
+
+func TestSubscribeTypedUnknownEventType(t *testing.T) {
+	eventBus := NewEventBus()
+
+	received := make(chan string, 1)
+	if _, err := SubscribeTyped(eventBus, "custom_event", func(s string) { received <- s }); err != nil {
+		t.Fatalf("Expected no error for unknown event type, got %v", err)
+	}
+
+	eventBus.Publish(Event{Type: "custom_event", Payload: "hello", Timestamp: time.Now()})
+
+	select {
+	case s := <-received:
+		if s != "hello" {
+			t.Errorf("Expected 'hello', got %q", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for custom event")
+	}
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestHandlerServesRadioMetrics(t *testing.T) {
+	body := scrape(t)
+	for _, name := range []string{
+		"radio_songs_played_total",
+		"radio_skips_total",
+		"radio_downloads_attempted_total",
+		"radio_downloads_failed_total",
+		"radio_websocket_connections_total",
+		"radio_websocket_disconnections_total",
+		"radio_download_duration_seconds_bucket",
+		"radio_download_duration_seconds_count",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, "\n"+name) {
+			t.Errorf("Expected %s in the scrape, got\n%s", name, body)
+		}
+	}
+}
+
+func TestGaugeReadsValueOnEveryScrape(t *testing.T) {
+	queue := 3.0
+	if err := Gauge("test_queue_length", "Songs in the queue.", func() float64 { return queue }); err != nil {
+		t.Fatalf("Gauge failed: %v", err)
+	}
+	if !strings.Contains(scrape(t), "\ntest_queue_length 3\n") {
+		t.Error("Expected the queue length in the scrape")
+	}
+
+	queue = 7
+	if !strings.Contains(scrape(t), "\ntest_queue_length 7\n") {
+		t.Error("Expected the updated queue length in the scrape")
+	}
+
+	// Names can only be registered once
+	if err := Gauge("test_queue_length", "Songs in the queue.", func() float64 { return 0 }); err == nil {
+		t.Error("Expected registering the same gauge twice to fail")
+	}
+}
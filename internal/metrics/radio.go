@@ -0,0 +1,58 @@
+// Package metrics defines the radio's Prometheus metrics. They are
+// registered with the Prometheus default registry, which Handler serves.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// The radio's own metrics, shared by every station
+var (
+	SongsPlayed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_songs_played_total",
+		Help: "Songs that finished or were skipped.",
+	})
+	Skips = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_skips_total",
+		Help: "Songs skipped by an admin or a listener vote.",
+	})
+	DownloadsAttempted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_downloads_attempted_total",
+		Help: "Song downloads started with yt-dlp.",
+	})
+	DownloadsFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_downloads_failed_total",
+		Help: "Song downloads that failed or produced unplayable audio.",
+	})
+	WebSocketConnections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_websocket_connections_total",
+		Help: "WebSocket clients that connected.",
+	})
+	WebSocketDisconnections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "radio_websocket_disconnections_total",
+		Help: "WebSocket clients that disconnected or were dropped.",
+	})
+	DownloadDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "radio_download_duration_seconds",
+		Help:    "How long yt-dlp took to download a song.",
+		Buckets: []float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	})
+)
+
+// Gauge registers a gauge whose value is read from value on every scrape
+func Gauge(name, help string, value func() float64) error {
+	return prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: name,
+		Help: help,
+	}, value))
+}
+
+// Handler serves the registered metrics, along with the Go runtime and
+// process metrics, in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
@@ -14,49 +17,136 @@ const (
 	UserContextKey contextKey = "user"
 )
 
+// Supported admin authentication modes
+const (
+	AuthModeJWT   = "jwt"
+	AuthModeBasic = "basic"
+	AuthModeBoth  = "both"
+)
+
+// ParseAuthMode checks mode is one of the supported admin authentication modes
+func ParseAuthMode(mode string) (string, error) {
+	switch mode {
+	case AuthModeJWT, AuthModeBasic, AuthModeBoth:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown auth mode %q, expected %q, %q or %q", mode, AuthModeJWT, AuthModeBasic, AuthModeBoth)
+}
+
 // AuthMiddleware creates middleware that validates JWT tokens
 func AuthMiddleware(jwtService *services.JWTService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get the Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			username, errMsg := authenticateJWT(jwtService, r)
+			if errMsg != "" {
+				http.Error(w, errMsg, http.StatusUnauthorized)
 				return
 			}
 
-			// Check if it's a Bearer token
-			if !strings.HasPrefix(authHeader, "Bearer ") {
-				http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-				return
-			}
+			next.ServeHTTP(w, withUser(r, username))
+		})
+	}
+}
 
-			// Extract the token
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if tokenString == "" {
-				http.Error(w, "Token is required", http.StatusUnauthorized)
+// BasicAuthMiddleware creates middleware that validates HTTP Basic credentials
+// against the configured admin username and password
+func BasicAuthMiddleware(username, password string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authenticateBasic(r, username, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="go-radio"`)
+				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 				return
 			}
 
-			// Validate the token
-			claims, err := jwtService.ValidateToken(tokenString)
-			if err != nil {
-				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-				return
-			}
+			next.ServeHTTP(w, withUser(r, username))
+		})
+	}
+}
 
-			// Add user info to request context
-			ctx := context.WithValue(r.Context(), UserContextKey, claims.Username)
-			r = r.WithContext(ctx)
+// AdminAuthMiddleware creates middleware for admin routes using the given auth
+// mode. In "both" mode a request may authenticate with either a Bearer token
+// or Basic credentials. The mode should be checked with ParseAuthMode at
+// startup; an unknown mode rejects every request.
+func AdminAuthMiddleware(mode string, jwtService *services.JWTService, username, password string) func(http.Handler) http.Handler {
+	switch mode {
+	case AuthModeBasic:
+		return BasicAuthMiddleware(username, password)
+	case AuthModeBoth:
+		jwtAuth := AuthMiddleware(jwtService)
+		basicAuth := BasicAuthMiddleware(username, password)
+		return func(next http.Handler) http.Handler {
+			jwtHandler := jwtAuth(next)
+			basicHandler := basicAuth(next)
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.Header.Get("Authorization"), "Basic ") {
+					basicHandler.ServeHTTP(w, r)
+					return
+				}
+				jwtHandler.ServeHTTP(w, r)
+			})
+		}
+	case AuthModeJWT:
+		return AuthMiddleware(jwtService)
+	default:
+		log.Printf("[ERROR] AdminAuthMiddleware: Unknown auth mode %q, rejecting admin requests", mode)
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			})
+		}
+	}
+}
 
-			// Call the next handler
-			next.ServeHTTP(w, r)
-		})
+// authenticateJWT validates the Bearer token on the request and returns the
+// username, or an error message if authentication failed
+func authenticateJWT(jwtService *services.JWTService, r *http.Request) (string, string) {
+	// Get the Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", "Authorization header required"
+	}
+
+	// Check if it's a Bearer token
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", "Invalid authorization header format"
+	}
+
+	// Extract the token
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == "" {
+		return "", "Token is required"
+	}
+
+	// Validate the token
+	claims, err := jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return "", "Invalid or expired token"
 	}
+
+	return claims.Username, ""
+}
+
+// authenticateBasic reports whether the request carries the expected Basic credentials
+func authenticateBasic(r *http.Request, username, password string) bool {
+	user, pass, ok := r.BasicAuth()
+	if !ok || username == "" {
+		return false
+	}
+
+	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1
+	passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1
+	return userMatch && passMatch
+}
+
+// withUser returns a copy of the request with the username added to its context
+func withUser(r *http.Request, username string) *http.Request {
+	ctx := context.WithValue(r.Context(), UserContextKey, username)
+	return r.WithContext(ctx)
 }
 
 // GetUserFromContext extracts the username from the request context
 func GetUserFromContext(ctx context.Context) (string, bool) {
 	username, ok := ctx.Value(UserContextKey).(string)
 	return username, ok
-} 
\ No newline at end of file
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/config"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+)
+
+func newTestJWTService() *services.JWTService {
+	return services.NewJWTService(&config.Config{
+		JWT: config.JWTConfig{
+			Secret:     "test-secret-key",
+			Expiration: time.Hour,
+		},
+	})
+}
+
+func TestAdminAuthMiddlewareModes(t *testing.T) {
+	jwtService := newTestJWTService()
+	token, err := jwtService.GenerateToken("admin")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	withBearer := func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	withBasic := func(r *http.Request) { r.SetBasicAuth("admin", "secret") }
+	withBadBasic := func(r *http.Request) { r.SetBasicAuth("admin", "wrong") }
+	withNothing := func(r *http.Request) {}
+
+	tests := []struct {
+		name         string
+		mode         string
+		authorize    func(*http.Request)
+		expectedCode int
+	}{
+		{name: "jwt accepts bearer", mode: AuthModeJWT, authorize: withBearer, expectedCode: http.StatusOK},
+		{name: "jwt rejects basic", mode: AuthModeJWT, authorize: withBasic, expectedCode: http.StatusUnauthorized},
+		{name: "jwt rejects missing", mode: AuthModeJWT, authorize: withNothing, expectedCode: http.StatusUnauthorized},
+		{name: "basic accepts basic", mode: AuthModeBasic, authorize: withBasic, expectedCode: http.StatusOK},
+		{name: "basic rejects bad password", mode: AuthModeBasic, authorize: withBadBasic, expectedCode: http.StatusUnauthorized},
+		{name: "basic rejects bearer", mode: AuthModeBasic, authorize: withBearer, expectedCode: http.StatusUnauthorized},
+		{name: "both accepts bearer", mode: AuthModeBoth, authorize: withBearer, expectedCode: http.StatusOK},
+		{name: "both accepts basic", mode: AuthModeBoth, authorize: withBasic, expectedCode: http.StatusOK},
+		{name: "both rejects bad password", mode: AuthModeBoth, authorize: withBadBasic, expectedCode: http.StatusUnauthorized},
+		{name: "both rejects missing", mode: AuthModeBoth, authorize: withNothing, expectedCode: http.StatusUnauthorized},
+		{name: "unknown mode rejects bearer", mode: "magic", authorize: withBearer, expectedCode: http.StatusUnauthorized},
+		{name: "unknown mode rejects basic", mode: "magic", authorize: withBasic, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = GetUserFromContext(r.Context())
+			})
+			handler := AdminAuthMiddleware(tt.mode, jwtService, "admin", "secret")(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/skip", nil)
+			tt.authorize(req)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if tt.expectedCode == http.StatusOK && gotUser != "admin" {
+				t.Errorf("Expected user 'admin' in context, got %q", gotUser)
+			}
+		})
+	}
+}
+
+func TestBasicAuthMiddlewareChallenge(t *testing.T) {
+	handler := BasicAuthMiddleware("admin", "secret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/skip", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401, got %d", rec.Code)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("Expected WWW-Authenticate challenge header")
+	}
+}
+
+func TestParseAuthMode(t *testing.T) {
+	for _, mode := range []string{AuthModeJWT, AuthModeBasic, AuthModeBoth} {
+		if got, err := ParseAuthMode(mode); err != nil || got != mode {
+			t.Errorf("ParseAuthMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+	for _, mode := range []string{"", "JWT", "bsaic"} {
+		if _, err := ParseAuthMode(mode); err == nil {
+			t.Errorf("Expected an error for auth mode %q", mode)
+		}
+	}
+}
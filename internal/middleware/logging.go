@@ -4,7 +4,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/requestid"
 )
 
 var (
@@ -12,38 +15,77 @@ var (
 	logger = log.New(os.Stdout, "", log.LstdFlags|log.Lmicroseconds)
 )
 
+// DefaultLogExcludedPaths are polled often enough that logging them drowns
+// out everything else. They are the default for LOG_EXCLUDED_PATHS.
+var DefaultLogExcludedPaths = []string{
+	"/api/v1/health",
+	"/api/v1/now-playing",
+}
+
 // LoggingMiddleware creates a middleware that logs HTTP request details
 func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+	return LoggingMiddlewareWithExclusions(nil)(next)
+}
 
-		// Log the incoming request
-		log.Printf("[DEBUG] LoggingMiddleware: Incoming request: %s %s", r.Method, r.URL.Path)
+// LoggingMiddlewareWithExclusions creates a logging middleware that skips
+// requests under any of the excluded path prefixes. A prefix matches whole
+// path segments, so "/api/v1/health" doesn't exclude "/api/v1/healthz".
+// Surrounding spaces and blank prefixes are ignored.
+func LoggingMiddlewareWithExclusions(excluded []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExcludedPath(r.URL.Path, excluded) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			logRequest(next, w, r)
+		})
+	}
+}
 
-		// Create a custom response writer to capture the status code
-		rw := &responseWriter{
-			ResponseWriter: w,
-			statusCode:     http.StatusOK,
+func isExcludedPath(path string, excluded []string) bool {
+	for _, prefix := range excluded {
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		if prefix == "" {
+			continue
+		}
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
 		}
+	}
+	return false
+}
+
+// logRequest serves the request and logs its details
+func logRequest(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	// Log the incoming request
+	requestid.Printf(r.Context(), "[DEBUG] LoggingMiddleware: Incoming request: %s %s", r.Method, r.URL.Path)
+
+	// Create a custom response writer to capture the status code
+	rw := &responseWriter{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+
+	// Process the request
+	requestid.Printf(r.Context(), "[DEBUG] LoggingMiddleware: Calling next handler")
+	next.ServeHTTP(rw, r)
+	requestid.Printf(r.Context(), "[DEBUG] LoggingMiddleware: Next handler completed")
+
+	// Calculate duration
+	duration := time.Since(start)
 
-		// Process the request
-		log.Printf("[DEBUG] LoggingMiddleware: Calling next handler")
-		next.ServeHTTP(rw, r)
-		log.Printf("[DEBUG] LoggingMiddleware: Next handler completed")
-
-		// Calculate duration
-		duration := time.Since(start)
-
-		// Log the request details with a more structured format
-		log.Printf("[DEBUG] LoggingMiddleware: Request completed: %s %s %d %s %s %s",
-			r.Method,
-			r.URL.Path,
-			rw.statusCode,
-			duration.Round(time.Millisecond),
-			r.RemoteAddr,
-			r.UserAgent(),
-		)
-	})
+	// Log the request details with a more structured format
+	requestid.Printf(r.Context(), "[DEBUG] LoggingMiddleware: Request completed: %s %s %d %s %s %s",
+		r.Method,
+		r.URL.Path,
+		rw.statusCode,
+		duration.Round(time.Millisecond),
+		r.RemoteAddr,
+		r.UserAgent(),
+	)
 }
 
 // responseWriter is a custom response writer that captures the status code
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureLog redirects the standard logger for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestLoggingMiddlewareExclusions(t *testing.T) {
+	handler := LoggingMiddlewareWithExclusions(DefaultLogExcludedPaths)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		path   string
+		logged bool
+	}{
+		{path: "/api/v1/health", logged: false},
+		{path: "/api/v1/now-playing", logged: false},
+		{path: "/api/v1/health/deep", logged: false},
+		{path: "/api/v1/healthz", logged: true},
+		{path: "/api/v1/queue", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			buf := captureLog(t)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusTeapot {
+				t.Errorf("Expected the request to be served, got status %d", rec.Code)
+			}
+			if logged := strings.Contains(buf.String(), tt.path); logged != tt.logged {
+				t.Errorf("Expected logged=%v, got log output %q", tt.logged, buf.String())
+			}
+		})
+	}
+}
+
+func TestLoggingMiddlewareLogsEverythingByDefault(t *testing.T) {
+	buf := captureLog(t)
+	handler := LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+	if !strings.Contains(buf.String(), "Request completed: GET /api/v1/health 200") {
+		t.Errorf("Expected the request to be logged, got %q", buf.String())
+	}
+}
+
+func TestLoggingMiddlewareExclusionsFromSetting(t *testing.T) {
+	tests := []struct {
+		setting string
+		logged  bool
+	}{
+		{setting: " /api/v1/state , /api/v1/health", logged: false},
+		{setting: "", logged: true},
+		{setting: " , ", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			buf := captureLog(t)
+			handler := LoggingMiddlewareWithExclusions(strings.Split(tt.setting, ","))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/state", nil))
+			if logged := strings.Contains(buf.String(), "/api/v1/state"); logged != tt.logged {
+				t.Errorf("Expected logged=%v for %q, got log output %q", tt.logged, tt.setting, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSetsRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reactions", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst to pass, got %d", i+1, rec.Code)
+		}
+	}
+
+	// Two a minute refills a token every 30 seconds
+	rec := request("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil {
+		t.Fatalf("Expected a numeric Retry-After, got %q", rec.Header().Get("Retry-After"))
+	}
+	if retryAfter != 30 {
+		t.Errorf("Expected Retry-After 30, got %d", retryAfter)
+	}
+
+	// Other clients have their own bucket
+	if rec := request("10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another client to pass, got %d", rec.Code)
+	}
+
+	// Part way through the wait the header counts down, rounding up
+	now = now.Add(20*time.Second + 500*time.Millisecond)
+	rec = request("10.0.0.1:1234")
+	if got := rec.Header().Get("Retry-After"); rec.Code != http.StatusTooManyRequests || got != "10" {
+		t.Errorf("Expected 429 with Retry-After 10, got %d with %q", rec.Code, got)
+	}
+
+	// Retrying after the advertised wait succeeds
+	now = now.Add(10 * time.Second)
+	if rec := request("10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected the request after Retry-After to pass, got %d", rec.Code)
+	}
+}
+
+func TestRetryAfterSecondsIsAtLeastOne(t *testing.T) {
+	if got := retryAfterSeconds(10 * time.Millisecond); got != 1 {
+		t.Errorf("Expected 1 second, got %d", got)
+	}
+	if got := retryAfterSeconds(1500 * time.Millisecond); got != 2 {
+		t.Errorf("Expected 2 seconds, got %d", got)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/requestid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	handler := RequestIDMiddleware(LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestid.Printf(r.Context(), "[DEBUG] handler: serving")
+	})))
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     string // empty for a generated ID
+	}{
+		{name: "generated"},
+		{name: "honored", incoming: "client-abc.123", want: "client-abc.123"},
+		{name: "spaces replaced", incoming: "forged line"},
+		{name: "newline replaced", incoming: "abc\n[ERROR] forged"},
+		{name: "too long replaced", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLog(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/queue", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestid.Header)
+			if id == "" {
+				t.Fatal("Expected an X-Request-ID response header")
+			}
+			if tt.want != "" && id != tt.want {
+				t.Errorf("Expected the client's ID %q to be echoed, got %q", tt.want, id)
+			}
+			if tt.want == "" && id == tt.incoming {
+				t.Errorf("Expected %q to be replaced with a generated ID", tt.incoming)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) < 2 {
+				t.Fatalf("Expected the middleware and handler to log, got %q", buf.String())
+			}
+			for _, line := range lines {
+				if !strings.HasSuffix(line, " request_id="+id) {
+					t.Errorf("Expected every log line to carry request_id=%s, got %q", id, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	body := `{"action":"skip"}`
+	var received string
+	handler := SignatureMiddleware("shared-secret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+	}))
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name         string
+		timestamp    string
+		signature    string
+		expectedCode int
+	}{
+		{name: "valid", timestamp: now, signature: Sign("shared-secret", now, []byte(body)), expectedCode: http.StatusOK},
+		{name: "uppercase hex", timestamp: now, signature: "sha256=" + strings.ToUpper(strings.TrimPrefix(Sign("shared-secret", now, []byte(body)), "sha256=")), expectedCode: http.StatusOK},
+		{name: "wrong secret", timestamp: now, signature: Sign("other-secret", now, []byte(body)), expectedCode: http.StatusUnauthorized},
+		{name: "other body", timestamp: now, signature: Sign("shared-secret", now, []byte(`{"action":"pause"}`)), expectedCode: http.StatusUnauthorized},
+		{name: "missing prefix", timestamp: now, signature: strings.TrimPrefix(Sign("shared-secret", now, []byte(body)), "sha256="), expectedCode: http.StatusUnauthorized},
+		{name: "not hex", timestamp: now, signature: "sha256=zz", expectedCode: http.StatusUnauthorized},
+		{name: "missing", timestamp: now, signature: "", expectedCode: http.StatusUnauthorized},
+		{name: "replayed", timestamp: stale, signature: Sign("shared-secret", stale, []byte(body)), expectedCode: http.StatusUnauthorized},
+		{name: "future timestamp", timestamp: future, signature: Sign("shared-secret", future, []byte(body)), expectedCode: http.StatusUnauthorized},
+		{name: "timestamp not signed", timestamp: now, signature: Sign("shared-secret", stale, []byte(body)), expectedCode: http.StatusUnauthorized},
+		{name: "missing timestamp", signature: Sign("shared-secret", "", []byte(body)), expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/control", strings.NewReader(body))
+			if tt.timestamp != "" {
+				req.Header.Set(TimestampHeader, tt.timestamp)
+			}
+			if tt.signature != "" {
+				req.Header.Set(SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if tt.expectedCode == http.StatusOK && received != body {
+				t.Errorf("Expected the handler to read the signed body, got %q", received)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Chapter is a titled section of a song's video, in seconds from its start
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Chapters are a song's video chapters. They are stored as a JSON array in a
+// text column.
+type Chapters []Chapter
+
+// Value encodes the chapters as a JSON array for storage
+func (c Chapters) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]Chapter(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan decodes chapters stored as a JSON array
+func (c *Chapters) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into Chapters", src)
+	}
+
+	if len(data) == 0 {
+		*c = nil
+		return nil
+	}
+	var chapters []Chapter
+	if err := json.Unmarshal(data, &chapters); err != nil {
+		return fmt.Errorf("invalid chapters %q: %w", data, err)
+	}
+	*c = chapters
+	return nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChaptersRoundTrip(t *testing.T) {
+	chapters := Chapters{{Title: "Intro", Start: 0, End: 12.5}, {Title: "Song", Start: 12.5, End: 200}}
+
+	value, err := chapters.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	var scanned Chapters
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !reflect.DeepEqual(scanned, chapters) {
+		t.Errorf("Expected %+v after a round trip, got %+v", chapters, scanned)
+	}
+
+	if value, _ := Chapters(nil).Value(); value != "[]" {
+		t.Errorf("Expected nil chapters stored as [], got %v", value)
+	}
+}
+
+func TestSongPlayedDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		want       int
+	}{
+		{name: "untrimmed", want: 200},
+		{name: "start", start: 15, want: 185},
+		{name: "start and end", start: 15, end: 180, want: 165},
+		{name: "end past the song", end: 300, want: 200},
+		{name: "nothing left", start: 190, end: 150, want: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song := &Song{Duration: 200, StartOffset: tt.start, EndOffset: tt.end}
+			if got := song.PlayedDuration(); got != tt.want {
+				t.Errorf("PlayedDuration() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "strings"
+
+// UnknownName groups songs whose artist or album is blank or already "Unknown",
+// which is what imports without metadata store
+const UnknownName = "Unknown"
+
+// ArtistSummary is an artist in the library and how many songs they have
+type ArtistSummary struct {
+	Name      string `json:"name"`
+	SongCount int    `json:"song_count"`
+}
+
+// AlbumSummary is an album in the library and how many of its songs are there
+type AlbumSummary struct {
+	Name      string `json:"name"`
+	Artist    string `json:"artist"`
+	SongCount int    `json:"song_count"`
+}
+
+// LibraryName trims an artist or album name and folds blank and "unknown"
+// spellings into UnknownName, matching how the library groups them
+func LibraryName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, UnknownName) {
+		return UnknownName
+	}
+	return name
+}
@@ -6,16 +6,33 @@ import (
 
 // Song represents a song's metadata in the database
 type Song struct {
-	YouTubeID  string    `json:"youtube_id" db:"youtube_id"`
-	Title      string    `json:"title" db:"title"`
-	Artist     string    `json:"artist" db:"artist"`
-	Album      string    `json:"album" db:"album"`
-	Duration   int       `json:"duration" db:"duration"` // Duration in seconds
-	S3Key      string    `json:"s3_key" db:"s3_key"`
-	LastPlayed time.Time `json:"last_played" db:"last_played"`
-	PlayCount  int       `json:"play_count" db:"play_count"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	YouTubeID   string    `json:"youtube_id" db:"youtube_id"`
+	Title       string    `json:"title" db:"title"`
+	Artist      string    `json:"artist" db:"artist"`
+	Album       string    `json:"album" db:"album"`
+	Duration    int       `json:"duration" db:"duration"` // Duration in seconds
+	S3Key       string    `json:"s3_key" db:"s3_key"`
+	Tags        Tags      `json:"tags" db:"tags"`
+	Chapters    Chapters  `json:"chapters,omitempty" db:"chapters"`
+	StartOffset int       `json:"start_offset" db:"start_offset"` // Seconds skipped at the start
+	EndOffset   int       `json:"end_offset" db:"end_offset"`     // Second playback stops at, 0 plays to the end
+	LastPlayed  time.Time `json:"last_played" db:"last_played"`
+	PlayCount   int       `json:"play_count" db:"play_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PlayedDuration is how many seconds of the song play once its offsets are
+// applied. Offsets that leave nothing to play are ignored.
+func (s *Song) PlayedDuration() int {
+	end := s.Duration
+	if s.EndOffset > 0 && s.EndOffset < end {
+		end = s.EndOffset
+	}
+	if s.StartOffset < 0 || s.StartOffset >= end {
+		return s.Duration
+	}
+	return end - s.StartOffset
 }
 
 // Playlist represents a playlist in the database
@@ -44,6 +61,7 @@ type PlaybackState struct {
 	CurrentPlaylist  *Playlist
 	CurrentSongIndex int
 	Queue            []*Song
+	Volume           float64 // 0.0 (muted) to 1.0 (full), applied by every client
 }
 
 // QueueInfo represents the current queue information
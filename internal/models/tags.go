@@ -0,0 +1,94 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Tags are free-form labels on a song, such as a genre, "explicit" or a
+// language. They are stored as a JSON array in a text column.
+type Tags []string
+
+// NormalizeTags lowercases and trims tags, dropping empty and duplicate ones
+func NormalizeTags(tags []string) Tags {
+	normalized := make(Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// Has reports whether the tags include tag
+func (t Tags) Has(tag string) bool {
+	for _, existing := range t {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny reports whether the tags include any of others
+func (t Tags) HasAny(others []string) bool {
+	for _, tag := range others {
+		if t.Has(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value encodes the tags as a JSON array for storage
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan decodes tags stored as a JSON array
+func (t *Tags) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return fmt.Errorf("invalid tags %q: %w", data, err)
+	}
+	*t = tags
+	return nil
+}
+
+// MarshalJSON encodes missing tags as an empty array rather than null
+func (t Tags) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(t))
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTagsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		tags   Tags
+		stored string
+		want   Tags
+	}{
+		{name: "tags", tags: Tags{"chill", "explicit"}, stored: `["chill","explicit"]`, want: Tags{"chill", "explicit"}},
+		{name: "empty", tags: Tags{}, stored: `[]`, want: Tags{}},
+		{name: "nil", tags: nil, stored: `[]`, want: Tags{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.tags.Value()
+			if err != nil {
+				t.Fatalf("Value failed: %v", err)
+			}
+			if value != tt.stored {
+				t.Errorf("Expected stored value %q, got %v", tt.stored, value)
+			}
+
+			var scanned Tags
+			if err := scanned.Scan([]byte(value.(string))); err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !reflect.DeepEqual(scanned, tt.want) {
+				t.Errorf("Expected %v after round trip, got %v", tt.want, scanned)
+			}
+		})
+	}
+}
+
+func TestTagsScan(t *testing.T) {
+	var tags Tags
+	if err := tags.Scan(nil); err != nil || tags != nil {
+		t.Errorf("Expected NULL to scan as no tags, got %v, %v", tags, err)
+	}
+	if err := tags.Scan(""); err != nil || tags != nil {
+		t.Errorf("Expected an empty string to scan as no tags, got %v, %v", tags, err)
+	}
+	if err := tags.Scan(`["jazz"]`); err != nil || !reflect.DeepEqual(tags, Tags{"jazz"}) {
+		t.Errorf("Expected [jazz], got %v, %v", tags, err)
+	}
+	if err := tags.Scan("not json"); err == nil {
+		t.Error("Expected an error for malformed tags")
+	}
+	if err := tags.Scan(42); err == nil {
+		t.Error("Expected an error for a non-text value")
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Chill ", "explicit", "CHILL", "", "  "})
+	if want := (Tags{"chill", "explicit"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSongWithoutTagsMarshalsEmptyArray(t *testing.T) {
+	data, err := json.Marshal(Song{YouTubeID: "abc"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if tags, ok := decoded["tags"].([]interface{}); !ok || len(tags) != 0 {
+		t.Errorf("Expected tags to be an empty array, got %v", decoded["tags"])
+	}
+}
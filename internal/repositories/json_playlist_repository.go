@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// JSONPlaylistRepository is an InMemoryPlaylistRepository that persists
+// playlists and their songs to playlists.json, written the same way as
+// songs.json. Songs are looked up in, and orphans deleted from, songs.
+type JSONPlaylistRepository struct {
+	*InMemoryPlaylistRepository
+	songs *JSONSongRepository
+	path  string
+
+	// saveMu serializes each change with the write recording it
+	saveMu sync.Mutex
+}
+
+// jsonPlaylist is one entry of playlists.json
+type jsonPlaylist struct {
+	models.Playlist
+	Songs []jsonPlaylistSong `json:"songs"`
+}
+
+// jsonPlaylistSong is one song listed in a jsonPlaylist
+type jsonPlaylistSong struct {
+	YouTubeID string `json:"youtube_id"`
+	Position  int    `json:"position"`
+}
+
+// NewJSONPlaylistRepository loads the playlists.json in dataDir, starting
+// without playlists when there is none yet
+func NewJSONPlaylistRepository(dataDir string, songs *JSONSongRepository) (*JSONPlaylistRepository, error) {
+	r := &JSONPlaylistRepository{
+		InMemoryPlaylistRepository: NewInMemoryPlaylistRepository(songs.InMemorySongRepository),
+		songs:                      songs,
+		path:                       filepath.Join(dataDir, "playlists.json"),
+	}
+
+	var stored []jsonPlaylist
+	if err := readJSONFile(r.path, &stored); err != nil {
+		return nil, err
+	}
+	for _, playlist := range stored {
+		p := playlist.Playlist
+		p.SongCount = 0
+		r.playlists[p.ID] = &p
+		r.nextID = max(r.nextID, playlistSeq(&p))
+
+		entries := make([]playlistEntry, 0, len(playlist.Songs))
+		for _, song := range playlist.Songs {
+			entries = append(entries, playlistEntry{youtubeID: song.YouTubeID, position: song.Position})
+		}
+		r.entries[p.ID] = entries
+	}
+	return r, nil
+}
+
+func (r *JSONPlaylistRepository) Create(playlist *models.Playlist) error {
+	return r.persist(func() error { return r.InMemoryPlaylistRepository.Create(playlist) })
+}
+
+func (r *JSONPlaylistRepository) AddSong(playlistID string, youtubeID string, position int) error {
+	return r.persist(func() error { return r.InMemoryPlaylistRepository.AddSong(playlistID, youtubeID, position) })
+}
+
+func (r *JSONPlaylistRepository) RemoveSong(playlistID string, youtubeID string) error {
+	return r.persist(func() error { return r.InMemoryPlaylistRepository.RemoveSong(playlistID, youtubeID) })
+}
+
+func (r *JSONPlaylistRepository) UpdateSongPosition(playlistID string, youtubeID string, newPosition int) error {
+	return r.persist(func() error {
+		return r.InMemoryPlaylistRepository.UpdateSongPosition(playlistID, youtubeID, newPosition)
+	})
+}
+
+// DeleteOrphanedSong deletes a song from the library if no playlist lists
+// it, reporting whether it was deleted. Only songs.json changes.
+func (r *JSONPlaylistRepository) DeleteOrphanedSong(youtubeID string) (bool, error) {
+	var deleted bool
+	err := r.songs.persist(func() error {
+		var err error
+		deleted, err = r.InMemoryPlaylistRepository.DeleteOrphanedSong(youtubeID)
+		return err
+	})
+	return deleted, err
+}
+
+// persist applies change and writes the playlists if it succeeded
+func (r *JSONPlaylistRepository) persist(change func() error) error {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+// save writes every playlist to playlists.json in creation order, keeping
+// each playlist's songs in the order they were added. The caller must hold
+// r.saveMu.
+func (r *JSONPlaylistRepository) save() error {
+	r.mu.RLock()
+	stored := make([]jsonPlaylist, 0, len(r.playlists))
+	for id, playlist := range r.playlists {
+		songs := make([]jsonPlaylistSong, 0, len(r.entries[id]))
+		for _, entry := range r.entries[id] {
+			songs = append(songs, jsonPlaylistSong{YouTubeID: entry.youtubeID, Position: entry.position})
+		}
+		stored = append(stored, jsonPlaylist{Playlist: *playlist, Songs: songs})
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(stored, func(i, j int) bool {
+		return playlistSeq(&stored[i].Playlist) < playlistSeq(&stored[j].Playlist)
+	})
+	return writeJSONFile(r.path, stored)
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func openJSONRepos(t *testing.T, dir string) (*JSONSongRepository, *JSONPlaylistRepository) {
+	t.Helper()
+	songs, err := NewJSONSongRepository(dir)
+	if err != nil {
+		t.Fatalf("NewJSONSongRepository failed: %v", err)
+	}
+	playlists, err := NewJSONPlaylistRepository(dir, songs)
+	if err != nil {
+		t.Fatalf("NewJSONPlaylistRepository failed: %v", err)
+	}
+	return songs, playlists
+}
+
+func TestJSONRepositoriesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	songs, playlists := openJSONRepos(t, dir)
+
+	for _, song := range []*models.Song{
+		{YouTubeID: "a", Title: "A", Artist: "Artist", Tags: models.Tags{"chill"}},
+		{YouTubeID: "b", Title: "B", Chapters: models.Chapters{{Title: "Intro", Start: 0, End: 30}}},
+		{YouTubeID: "c", Title: "C"},
+	} {
+		if err := songs.Create(song); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := songs.UpdatePlayStats("a"); err != nil {
+		t.Fatalf("UpdatePlayStats failed: %v", err)
+	}
+	if err := songs.MarkNormalized("b"); err != nil {
+		t.Fatalf("MarkNormalized failed: %v", err)
+	}
+
+	playlist := &models.Playlist{Name: "Mix", Description: "desc"}
+	if err := playlists.Create(playlist); err != nil {
+		t.Fatalf("Create playlist failed: %v", err)
+	}
+	for id, position := range map[string]int{"a": 2, "b": 0, "c": 1} {
+		if err := playlists.AddSong(playlist.ID, id, position); err != nil {
+			t.Fatalf("AddSong(%s) failed: %v", id, err)
+		}
+	}
+	if err := playlists.UpdateSongPosition(playlist.ID, "c", 3); err != nil {
+		t.Fatalf("UpdateSongPosition failed: %v", err)
+	}
+
+	wantSong, _ := songs.GetByYouTubeID("a")
+	reopenedSongs, reopenedPlaylists := openJSONRepos(t, dir)
+
+	song, err := reopenedSongs.GetByYouTubeID("a")
+	if err != nil || song == nil {
+		t.Fatalf("GetByYouTubeID after reopening = %v, %v", song, err)
+	}
+	if song.Title != "A" || song.PlayCount != 1 || !reflect.DeepEqual(song.Tags, models.Tags{"chill"}) ||
+		!song.CreatedAt.Equal(wantSong.CreatedAt) || !song.LastPlayed.Equal(wantSong.LastPlayed) {
+		t.Errorf("song after reopening = %+v, want %+v", song, wantSong)
+	}
+	if song, _ := reopenedSongs.GetByYouTubeID("b"); song == nil || !reflect.DeepEqual(song.Chapters, models.Chapters{{Title: "Intro", Start: 0, End: 30}}) {
+		t.Errorf("chapters after reopening = %+v", song)
+	}
+	unnormalized, _ := reopenedSongs.GetUnnormalized()
+	if got := songIDs(unnormalized); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("GetUnnormalized after reopening = %v, want [a c]", got)
+	}
+
+	reopened, err := reopenedPlaylists.GetByName("Mix")
+	if err != nil || reopened == nil || reopened.ID != playlist.ID || reopened.Description != "desc" {
+		t.Fatalf("GetByName after reopening = %+v, %v", reopened, err)
+	}
+	ids, _ := reopenedPlaylists.GetSongIDs(playlist.ID)
+	if !reflect.DeepEqual(ids, []string{"b", "a", "c"}) {
+		t.Errorf("GetSongIDs after reopening = %v, want [b a c]", ids)
+	}
+
+	// Playlists created after reopening don't reuse a stored ID
+	second := &models.Playlist{Name: "Second"}
+	if err := reopenedPlaylists.Create(second); err != nil {
+		t.Fatalf("Create after reopening failed: %v", err)
+	}
+	if second.ID == playlist.ID {
+		t.Errorf("playlist created after reopening reused ID %s", second.ID)
+	}
+}
+
+func TestJSONRepositoriesPersistDeletes(t *testing.T) {
+	dir := t.TempDir()
+	songs, playlists := openJSONRepos(t, dir)
+
+	createSongs(t, songs, &models.Song{YouTubeID: "a"}, &models.Song{YouTubeID: "b"})
+	playlist := &models.Playlist{Name: "Mix"}
+	if err := playlists.Create(playlist); err != nil {
+		t.Fatalf("Create playlist failed: %v", err)
+	}
+	if err := playlists.AddSong(playlist.ID, "a", 0); err != nil {
+		t.Fatalf("AddSong failed: %v", err)
+	}
+	if err := playlists.AddSong(playlist.ID, "b", 1); err != nil {
+		t.Fatalf("AddSong failed: %v", err)
+	}
+
+	if err := playlists.RemoveSong(playlist.ID, "b"); err != nil {
+		t.Fatalf("RemoveSong failed: %v", err)
+	}
+	if deleted, err := playlists.DeleteOrphanedSong("b"); err != nil || !deleted {
+		t.Fatalf("DeleteOrphanedSong = %v, %v, want true", deleted, err)
+	}
+
+	reopenedSongs, reopenedPlaylists := openJSONRepos(t, dir)
+	if song, _ := reopenedSongs.GetByYouTubeID("b"); song != nil {
+		t.Errorf("deleted song found after reopening: %+v", song)
+	}
+	ids, _ := reopenedPlaylists.GetSongIDs(playlist.ID)
+	if !reflect.DeepEqual(ids, []string{"a"}) {
+		t.Errorf("GetSongIDs after reopening = %v, want [a]", ids)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "songs.json" && name != "playlists.json" {
+			t.Errorf("unexpected file %s left in the data directory", name)
+		}
+	}
+}
+
+func TestJSONSongRepositoryRejectsACorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "songs.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := NewJSONSongRepository(dir); err == nil {
+		t.Error("NewJSONSongRepository loaded a corrupt songs.json")
+	}
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// JSONSongRepository is an InMemorySongRepository that persists the library
+// to songs.json, for running without Postgres. Every change rewrites the
+// whole file through a temporary file and a rename, so a crash leaves either
+// the old or the new library on disk.
+type JSONSongRepository struct {
+	*InMemorySongRepository
+	path string
+
+	// saveMu serializes each change with the write recording it, so the file
+	// never goes back to an older snapshot
+	saveMu sync.Mutex
+}
+
+// jsonSong is one entry of songs.json
+type jsonSong struct {
+	models.Song
+	Normalized bool `json:"normalized,omitempty"`
+}
+
+// NewJSONSongRepository loads the songs.json in dataDir, starting an empty
+// library when there is none yet
+func NewJSONSongRepository(dataDir string) (*JSONSongRepository, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	r := &JSONSongRepository{
+		InMemorySongRepository: NewInMemorySongRepository(),
+		path:                   filepath.Join(dataDir, "songs.json"),
+	}
+
+	var stored []jsonSong
+	if err := readJSONFile(r.path, &stored); err != nil {
+		return nil, err
+	}
+	for _, song := range stored {
+		r.songs[song.YouTubeID] = copySong(&song.Song)
+		if song.Normalized {
+			r.normalized[song.YouTubeID] = true
+		}
+	}
+	return r, nil
+}
+
+func (r *JSONSongRepository) Create(song *models.Song) error {
+	return r.persist(func() error { return r.InMemorySongRepository.Create(song) })
+}
+
+func (r *JSONSongRepository) UpdatePlayStats(youtubeID string) error {
+	return r.persist(func() error { return r.InMemorySongRepository.UpdatePlayStats(youtubeID) })
+}
+
+func (r *JSONSongRepository) UpdateDuration(youtubeID string, duration int) error {
+	return r.persist(func() error { return r.InMemorySongRepository.UpdateDuration(youtubeID, duration) })
+}
+
+func (r *JSONSongRepository) MarkNormalized(youtubeID string) error {
+	return r.persist(func() error { return r.InMemorySongRepository.MarkNormalized(youtubeID) })
+}
+
+func (r *JSONSongRepository) UpdateDetails(youtubeID, title, artist string) error {
+	return r.persist(func() error { return r.InMemorySongRepository.UpdateDetails(youtubeID, title, artist) })
+}
+
+func (r *JSONSongRepository) UpdateTags(youtubeID string, tags models.Tags) error {
+	return r.persist(func() error { return r.InMemorySongRepository.UpdateTags(youtubeID, tags) })
+}
+
+func (r *JSONSongRepository) UpdateChapters(youtubeID string, chapters models.Chapters, startOffset, endOffset int) error {
+	return r.persist(func() error {
+		return r.InMemorySongRepository.UpdateChapters(youtubeID, chapters, startOffset, endOffset)
+	})
+}
+
+// persist applies change and writes the library if it succeeded
+func (r *JSONSongRepository) persist(change func() error) error {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+// save writes every song to songs.json by ID. The caller must hold r.saveMu.
+func (r *JSONSongRepository) save() error {
+	r.mu.RLock()
+	stored := make([]jsonSong, 0, len(r.songs))
+	for id, song := range r.songs {
+		stored = append(stored, jsonSong{Song: *copySong(song), Normalized: r.normalized[id]})
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(stored, func(i, j int) bool { return stored[i].YouTubeID < stored[j].YouTubeID })
+	return writeJSONFile(r.path, stored)
+}
+
+// readJSONFile decodes path into v, leaving v untouched when the file doesn't exist
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile replaces path with v encoded as JSON. It writes a temporary
+// file in the same directory and renames it over path, so readers never see
+// a partly written file.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// InMemoryPlaylistRepository keeps playlists in memory with the same query
+// behaviour as PlaylistRepository. Playlist songs are looked up in songs, so
+// entries whose song doesn't exist are skipped the way the SQL join skips them.
+type InMemoryPlaylistRepository struct {
+	songs *InMemorySongRepository
+
+	mu        sync.RWMutex
+	nextID    int
+	playlists map[string]*models.Playlist
+	entries   map[string][]playlistEntry
+}
+
+// playlistEntry is one row of playlist_songs
+type playlistEntry struct {
+	youtubeID string
+	position  int
+}
+
+func NewInMemoryPlaylistRepository(songs *InMemorySongRepository) *InMemoryPlaylistRepository {
+	return &InMemoryPlaylistRepository{
+		songs:     songs,
+		playlists: make(map[string]*models.Playlist),
+		entries:   make(map[string][]playlistEntry),
+	}
+}
+
+func (r *InMemoryPlaylistRepository) Create(playlist *models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.playlists {
+		if existing.Name == playlist.Name {
+			return ErrPlaylistNameTaken
+		}
+	}
+
+	r.nextID++
+	now := time.Now()
+	playlist.ID = strconv.Itoa(r.nextID)
+	playlist.CreatedAt = now
+	playlist.UpdatedAt = now
+
+	stored := *playlist
+	stored.SongCount = 0
+	r.playlists[playlist.ID] = &stored
+	return nil
+}
+
+func (r *InMemoryPlaylistRepository) GetByID(id string) (*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	playlist, ok := r.playlists[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *playlist
+	return &c, nil
+}
+
+func (r *InMemoryPlaylistRepository) GetByName(name string) (*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, playlist := range r.playlists {
+		if playlist.Name == name {
+			c := *playlist
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetAll returns every playlist by name with its song count
+func (r *InMemoryPlaylistRepository) GetAll() ([]*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var playlists []*models.Playlist
+	for id, playlist := range r.playlists {
+		c := *playlist
+		c.SongCount = len(r.entries[id])
+		playlists = append(playlists, &c)
+	}
+	sort.Slice(playlists, func(i, j int) bool { return playlists[i].Name < playlists[j].Name })
+	return playlists, nil
+}
+
+// GetFirstPlaylist returns the earliest created playlist
+func (r *InMemoryPlaylistRepository) GetFirstPlaylist() (*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var first *models.Playlist
+	for _, playlist := range r.playlists {
+		if first == nil || playlistSeq(playlist) < playlistSeq(first) {
+			first = playlist
+		}
+	}
+	if first == nil {
+		return nil, nil
+	}
+	c := *first
+	return &c, nil
+}
+
+// AddSong lists youtubeID in a playlist at position. Like the playlist_songs
+// constraints, the playlist and song must exist and a song is listed once.
+func (r *InMemoryPlaylistRepository) AddSong(playlistID string, youtubeID string, position int) error {
+	song, err := r.songs.GetByYouTubeID(youtubeID)
+	if err != nil {
+		return err
+	}
+	if song == nil {
+		return fmt.Errorf("song %s does not exist", youtubeID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlistID]; !ok {
+		return fmt.Errorf("playlist %s does not exist", playlistID)
+	}
+	for _, entry := range r.entries[playlistID] {
+		if entry.youtubeID == youtubeID {
+			return fmt.Errorf("song %s is already in playlist %s", youtubeID, playlistID)
+		}
+	}
+	r.entries[playlistID] = append(r.entries[playlistID], playlistEntry{youtubeID: youtubeID, position: position})
+	return nil
+}
+
+func (r *InMemoryPlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error) {
+	ids, err := r.GetSongIDs(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	found, err := r.songs.GetByYouTubeIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var songs []*models.Song
+	for _, id := range ids {
+		if song, ok := found[id]; ok {
+			songs = append(songs, song)
+		}
+	}
+	return songs, nil
+}
+
+// GetSongsPage returns one page of a playlist's songs in position order along
+// with the total number of songs in the playlist
+func (r *InMemoryPlaylistRepository) GetSongsPage(playlistID string, limit, offset int) ([]*models.Song, int, error) {
+	songs, err := r.GetSongs(playlistID)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(songs)
+	start := min(max(offset, 0), total)
+	end := total
+	if limit >= 0 {
+		end = min(start+limit, total)
+	}
+	return songs[start:end], total, nil
+}
+
+// GetSongIDs returns the YouTube IDs listed in a playlist in position order,
+// including any whose song no longer exists
+func (r *InMemoryPlaylistRepository) GetSongIDs(playlistID string) ([]string, error) {
+	r.mu.RLock()
+	entries := append([]playlistEntry(nil), r.entries[playlistID]...)
+	r.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].position < entries[j].position })
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.youtubeID)
+	}
+	return ids, nil
+}
+
+func (r *InMemoryPlaylistRepository) RemoveSong(playlistID string, youtubeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.entries[playlistID]
+	for i, entry := range entries {
+		if entry.youtubeID == youtubeID {
+			r.entries[playlistID] = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryPlaylistRepository) UpdateSongPosition(playlistID string, youtubeID string, newPosition int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, entry := range r.entries[playlistID] {
+		if entry.youtubeID == youtubeID {
+			r.entries[playlistID][i].position = newPosition
+			break
+		}
+	}
+	return nil
+}
+
+// GetOrphanedSongs returns the library songs no playlist lists, oldest first
+func (r *InMemoryPlaylistRepository) GetOrphanedSongs() ([]*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	listed := r.listedSongs()
+	return r.songs.sorted(func(song *models.Song) bool {
+		return !listed[song.YouTubeID]
+	}, func(a, b *models.Song) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// DeleteOrphanedSong deletes a song from the library if no playlist lists
+// it, reporting whether it was deleted
+func (r *InMemoryPlaylistRepository) DeleteOrphanedSong(youtubeID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.listedSongs()[youtubeID] {
+		return false, nil
+	}
+	return r.songs.delete(youtubeID), nil
+}
+
+// listedSongs returns the IDs of the songs in any playlist. The caller must
+// hold r.mu.
+func (r *InMemoryPlaylistRepository) listedSongs() map[string]bool {
+	listed := make(map[string]bool)
+	for _, entries := range r.entries {
+		for _, entry := range entries {
+			listed[entry.youtubeID] = true
+		}
+	}
+	return listed
+}
+
+// playlistSeq orders playlists by the sequential IDs Create hands out
+func playlistSeq(playlist *models.Playlist) int {
+	seq, _ := strconv.Atoi(playlist.ID)
+	return seq
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func TestInMemorySongRepositoryReturnsCopies(t *testing.T) {
+	repo := NewInMemorySongRepository()
+	if err := repo.Create(&models.Song{YouTubeID: "a", Title: "A", Tags: models.Tags{"chill"}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	song, _ := repo.GetByYouTubeID("a")
+	song.Title = "changed"
+	song.Tags[0] = "changed"
+
+	stored, _ := repo.GetByYouTubeID("a")
+	if stored.Title != "A" || stored.Tags[0] != "chill" {
+		t.Errorf("stored song was modified through a returned copy: %+v", stored)
+	}
+}
+
+func TestInMemoryPlaylistRepositoryFirstPlaylistIsOldest(t *testing.T) {
+	repo := NewInMemoryPlaylistRepository(NewInMemorySongRepository())
+	for i := 0; i < 12; i++ {
+		if err := repo.Create(&models.Playlist{Name: string(rune('a' + i))}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	first, _ := repo.GetFirstPlaylist()
+	if first == nil || first.Name != "a" {
+		t.Errorf("GetFirstPlaylist = %v, want the first playlist created", first)
+	}
+}
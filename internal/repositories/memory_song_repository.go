@@ -0,0 +1,305 @@
+package repositories
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// InMemorySongRepository keeps songs in a map with the same query behaviour
+// as SongRepository, for tests and running without Postgres. Songs are
+// copied in and out so callers can't change stored rows by accident.
+type InMemorySongRepository struct {
+	mu    sync.RWMutex
+	songs map[string]*models.Song
+
+	// normalized holds the songs marked with MarkNormalized, the songs.normalized column
+	normalized map[string]bool
+}
+
+func NewInMemorySongRepository() *InMemorySongRepository {
+	return &InMemorySongRepository{
+		songs:      make(map[string]*models.Song),
+		normalized: make(map[string]bool),
+	}
+}
+
+func (r *InMemorySongRepository) Create(song *models.Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[song.YouTubeID]; ok {
+		return fmt.Errorf("song %s already exists", song.YouTubeID)
+	}
+	stored := copySong(song)
+	now := time.Now()
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.songs[song.YouTubeID] = stored
+	return nil
+}
+
+func (r *InMemorySongRepository) GetByYouTubeID(youtubeID string) (*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	song, ok := r.songs[youtubeID]
+	if !ok {
+		return nil, nil
+	}
+	return copySong(song), nil
+}
+
+// GetByYouTubeIDs returns the songs with the given IDs keyed by YouTube ID.
+// IDs without a song are absent from the map.
+func (r *InMemorySongRepository) GetByYouTubeIDs(youtubeIDs []string) (map[string]*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs := make(map[string]*models.Song, len(youtubeIDs))
+	for _, id := range youtubeIDs {
+		if song, ok := r.songs[id]; ok {
+			songs[id] = copySong(song)
+		}
+	}
+	return songs, nil
+}
+
+func (r *InMemorySongRepository) UpdatePlayStats(youtubeID string) error {
+	return r.update(youtubeID, func(song *models.Song, now time.Time) {
+		song.LastPlayed = now
+		song.PlayCount++
+	})
+}
+
+func (r *InMemorySongRepository) GetRandomSong() (*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.songs) == 0 {
+		return nil, nil
+	}
+	pick := rand.Intn(len(r.songs))
+	for _, song := range r.songs {
+		if pick == 0 {
+			return copySong(song), nil
+		}
+		pick--
+	}
+	return nil, nil
+}
+
+func (r *InMemorySongRepository) GetLeastPlayedSong() (*models.Song, error) {
+	songs := r.sorted(nil, func(a, b *models.Song) bool {
+		if a.PlayCount != b.PlayCount {
+			return a.PlayCount < b.PlayCount
+		}
+		return a.LastPlayed.Before(b.LastPlayed)
+	})
+	if len(songs) == 0 {
+		return nil, nil
+	}
+	return songs[0], nil
+}
+
+func (r *InMemorySongRepository) GetRecentlyAdded(limit int) ([]*models.Song, error) {
+	songs := r.sorted(nil, newestFirst)
+	if limit >= 0 && limit < len(songs) {
+		songs = songs[:limit]
+	}
+	return songs, nil
+}
+
+func (r *InMemorySongRepository) GetWithoutDuration() ([]*models.Song, error) {
+	return r.sorted(func(song *models.Song) bool {
+		return song.Duration == 0
+	}, func(a, b *models.Song) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+func (r *InMemorySongRepository) UpdateDuration(youtubeID string, duration int) error {
+	return r.update(youtubeID, func(song *models.Song, _ time.Time) {
+		song.Duration = duration
+	})
+}
+
+// GetUnnormalized returns the songs not marked normalized, oldest first
+func (r *InMemorySongRepository) GetUnnormalized() ([]*models.Song, error) {
+	return r.sorted(func(song *models.Song) bool {
+		return !r.normalized[song.YouTubeID]
+	}, func(a, b *models.Song) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// MarkNormalized records that a song's stored audio is loudness normalized.
+// Like an UPDATE matching no rows, an unknown ID is not an error.
+func (r *InMemorySongRepository) MarkNormalized(youtubeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[youtubeID]; ok {
+		r.normalized[youtubeID] = true
+	}
+	return nil
+}
+
+// GetByTag returns the songs carrying tag, newest first
+func (r *InMemorySongRepository) GetByTag(tag string) ([]*models.Song, error) {
+	return r.sorted(func(song *models.Song) bool {
+		return slices.Contains(song.Tags, tag)
+	}, newestFirst), nil
+}
+
+// UpdateDetails overwrites a song's title and artist; empty values are left unchanged
+func (r *InMemorySongRepository) UpdateDetails(youtubeID, title, artist string) error {
+	return r.update(youtubeID, func(song *models.Song, _ time.Time) {
+		if title != "" {
+			song.Title = title
+		}
+		if artist != "" {
+			song.Artist = artist
+		}
+	})
+}
+
+func (r *InMemorySongRepository) UpdateTags(youtubeID string, tags models.Tags) error {
+	return r.update(youtubeID, func(song *models.Song, _ time.Time) {
+		song.Tags = slices.Clone(tags)
+	})
+}
+
+// UpdateChapters stores a song's chapters and the offsets trimming it
+func (r *InMemorySongRepository) UpdateChapters(youtubeID string, chapters models.Chapters, startOffset, endOffset int) error {
+	return r.update(youtubeID, func(song *models.Song, _ time.Time) {
+		song.Chapters = slices.Clone(chapters)
+		song.StartOffset = startOffset
+		song.EndOffset = endOffset
+	})
+}
+
+// GetArtists returns each artist in the library with their song count
+func (r *InMemorySongRepository) GetArtists() ([]*models.ArtistSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, song := range r.songs {
+		counts[models.LibraryName(song.Artist)]++
+	}
+
+	artists := make([]*models.ArtistSummary, 0, len(counts))
+	for name, count := range counts {
+		artists = append(artists, &models.ArtistSummary{Name: name, SongCount: count})
+	}
+	sort.Slice(artists, func(i, j int) bool { return artists[i].Name < artists[j].Name })
+	return artists, nil
+}
+
+// GetAlbums returns each album in the library, per artist, with its song count
+func (r *InMemorySongRepository) GetAlbums() ([]*models.AlbumSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type albumKey struct{ name, artist string }
+	counts := make(map[albumKey]int)
+	for _, song := range r.songs {
+		counts[albumKey{models.LibraryName(song.Album), models.LibraryName(song.Artist)}]++
+	}
+
+	albums := make([]*models.AlbumSummary, 0, len(counts))
+	for key, count := range counts {
+		albums = append(albums, &models.AlbumSummary{Name: key.name, Artist: key.artist, SongCount: count})
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		if albums[i].Name != albums[j].Name {
+			return albums[i].Name < albums[j].Name
+		}
+		return albums[i].Artist < albums[j].Artist
+	})
+	return albums, nil
+}
+
+// GetByArtist returns an artist's songs by album and title. The artist is
+// matched after grouping, so "Unknown" finds songs without an artist.
+func (r *InMemorySongRepository) GetByArtist(artist string) ([]*models.Song, error) {
+	return r.sorted(func(song *models.Song) bool {
+		return models.LibraryName(song.Artist) == artist
+	}, func(a, b *models.Song) bool {
+		if a.Album != b.Album {
+			return a.Album < b.Album
+		}
+		return a.Title < b.Title
+	}), nil
+}
+
+// update applies change to a stored song and bumps its updated_at. Like an
+// UPDATE matching no rows, an unknown ID is not an error.
+func (r *InMemorySongRepository) update(youtubeID string, change func(song *models.Song, now time.Time)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	song, ok := r.songs[youtubeID]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	change(song, now)
+	song.UpdatedAt = now
+	return nil
+}
+
+// delete removes a song, reporting whether it was stored
+func (r *InMemorySongRepository) delete(youtubeID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.songs[youtubeID]; !ok {
+		return false
+	}
+	delete(r.songs, youtubeID)
+	delete(r.normalized, youtubeID)
+	return true
+}
+
+// sorted returns copies of the songs matching keep, or all songs for a nil
+// keep, ordered by less
+func (r *InMemorySongRepository) sorted(keep func(*models.Song) bool, less func(a, b *models.Song) bool) []*models.Song {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs := make([]*models.Song, 0, len(r.songs))
+	for _, song := range r.songs {
+		if keep == nil || keep(song) {
+			songs = append(songs, copySong(song))
+		}
+	}
+	sort.SliceStable(songs, func(i, j int) bool {
+		if less(songs[i], songs[j]) {
+			return true
+		}
+		if less(songs[j], songs[i]) {
+			return false
+		}
+		// Break ties by ID so results don't depend on map order
+		return songs[i].YouTubeID < songs[j].YouTubeID
+	})
+	return songs
+}
+
+func newestFirst(a, b *models.Song) bool {
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+func copySong(song *models.Song) *models.Song {
+	c := *song
+	c.Tags = slices.Clone(song.Tags)
+	c.Chapters = slices.Clone(song.Chapters)
+	return &c
+}
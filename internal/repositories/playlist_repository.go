@@ -2,11 +2,20 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/lib/pq"
 )
 
+// ErrPlaylistNameTaken is returned when creating a playlist whose name is
+// already used, which the unique index on playlists.name rejects
+var ErrPlaylistNameTaken = errors.New("playlist name already taken")
+
+// pqUniqueViolation is the Postgres error code for a unique constraint violation
+const pqUniqueViolation = "23505"
+
 type PlaylistRepository struct {
 	db *sql.DB
 }
@@ -30,6 +39,10 @@ func (r *PlaylistRepository) Create(playlist *models.Playlist) error {
 		now,
 		now,
 	).Scan(&id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		return ErrPlaylistNameTaken
+	}
 	if err != nil {
 		return err
 	}
@@ -114,7 +127,8 @@ func (r *PlaylistRepository) AddSong(playlistID string, youtubeID string, positi
 
 func (r *PlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error) {
 	query := `
-		SELECT s.youtube_id, s.title, s.artist, s.album, s.duration, s.s3_key, s.last_played, s.play_count, s.created_at, s.updated_at
+		SELECT s.youtube_id, s.title, s.artist, s.album, s.duration, s.s3_key, s.last_played, s.play_count, s.created_at, s.updated_at, s.tags,
+			s.chapters, s.start_offset, s.end_offset
 		FROM playlist_songs ps
 		JOIN songs s ON ps.youtube_id = s.youtube_id
 		WHERE ps.playlist_id = $1
@@ -127,6 +141,48 @@ func (r *PlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error)
 	}
 	defer rows.Close()
 
+	return scanPlaylistSongs(rows)
+}
+
+// GetSongsPage returns one page of a playlist's songs in position order along
+// with the total number of songs in the playlist
+func (r *PlaylistRepository) GetSongsPage(playlistID string, limit, offset int) ([]*models.Song, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM playlist_songs ps
+		JOIN songs s ON ps.youtube_id = s.youtube_id
+		WHERE ps.playlist_id = $1
+	`
+
+	var total int
+	if err := r.db.QueryRow(countQuery, playlistID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT s.youtube_id, s.title, s.artist, s.album, s.duration, s.s3_key, s.last_played, s.play_count, s.created_at, s.updated_at, s.tags,
+			s.chapters, s.start_offset, s.end_offset
+		FROM playlist_songs ps
+		JOIN songs s ON ps.youtube_id = s.youtube_id
+		WHERE ps.playlist_id = $1
+		ORDER BY ps.position ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, playlistID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	songs, err := scanPlaylistSongs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+func scanPlaylistSongs(rows *sql.Rows) ([]*models.Song, error) {
 	var songs []*models.Song
 	for rows.Next() {
 		song := &models.Song{}
@@ -141,6 +197,10 @@ func (r *PlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error)
 			&song.PlayCount,
 			&song.CreatedAt,
 			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
 		)
 		if err != nil {
 			return nil, err
@@ -148,7 +208,35 @@ func (r *PlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error)
 		songs = append(songs, song)
 	}
 
-	return songs, nil
+	return songs, rows.Err()
+}
+
+// GetSongIDs returns the YouTube IDs listed in a playlist in position order,
+// including any whose song row no longer exists
+func (r *PlaylistRepository) GetSongIDs(playlistID string) ([]string, error) {
+	query := `
+		SELECT youtube_id
+		FROM playlist_songs
+		WHERE playlist_id = $1
+		ORDER BY position ASC
+	`
+
+	rows, err := r.db.Query(query, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
 }
 
 func (r *PlaylistRepository) RemoveSong(playlistID string, youtubeID string) error {
@@ -224,3 +312,44 @@ func (r *PlaylistRepository) GetFirstPlaylist() (*models.Playlist, error) {
 
 	return playlist, nil
 }
+
+// GetOrphanedSongs returns the library songs no playlist lists, oldest first
+func (r *PlaylistRepository) GetOrphanedSongs() ([]*models.Song, error) {
+	query := `
+		SELECT s.youtube_id, s.title, s.artist, s.album, s.duration, s.s3_key, s.last_played, s.play_count, s.created_at, s.updated_at, s.tags,
+			s.chapters, s.start_offset, s.end_offset
+		FROM songs s
+		LEFT JOIN playlist_songs ps ON ps.youtube_id = s.youtube_id
+		WHERE ps.playlist_id IS NULL
+		ORDER BY s.created_at ASC, s.youtube_id ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPlaylistSongs(rows)
+}
+
+// DeleteOrphanedSong deletes a song from the library if no playlist lists
+// it, reporting whether it was deleted. Checking in the same statement keeps
+// a song that was added to a playlist since it was found to be orphaned.
+func (r *PlaylistRepository) DeleteOrphanedSong(youtubeID string) (bool, error) {
+	query := `
+		DELETE FROM songs s
+		WHERE s.youtube_id = $1
+		AND NOT EXISTS (SELECT 1 FROM playlist_songs ps WHERE ps.youtube_id = s.youtube_id)
+	`
+
+	result, err := r.db.Exec(query, youtubeID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/lib/pq"
+)
+
+// pagedPlaylistDriver answers the count and page queries for a playlist of
+// the given size, applying LIMIT and OFFSET the way the database would
+func pagedPlaylistDriver(size int) *recordingDriver {
+	var all [][]driver.Value
+	for i := 0; i < size; i++ {
+		all = append(all, songRow(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i)))
+	}
+
+	return &recordingDriver{
+		respond: func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+			if strings.Contains(query, "COUNT(*)") {
+				return []string{"count"}, [][]driver.Value{{int64(len(all))}}
+			}
+			limit, offset := int(args[1].(int64)), int(args[2].(int64))
+			start := min(offset, len(all))
+			end := min(start+limit, len(all))
+			return songColumns, all[start:end]
+		},
+	}
+}
+
+func TestGetSongsPage(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		want   []string
+	}{
+		{name: "first page", limit: 2, offset: 0, want: []string{"song0", "song1"}},
+		{name: "middle page", limit: 2, offset: 2, want: []string{"song2", "song3"}},
+		{name: "partial last page", limit: 2, offset: 4, want: []string{"song4"}},
+		{name: "offset at end", limit: 2, offset: 5, want: nil},
+		{name: "offset past end", limit: 2, offset: 10, want: nil},
+		{name: "limit larger than playlist", limit: 50, offset: 0, want: []string{"song0", "song1", "song2", "song3", "song4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := pagedPlaylistDriver(5)
+			repo := NewPlaylistRepository(openRecordingDB(t, d))
+
+			songs, total, err := repo.GetSongsPage("p1", tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if total != 5 {
+				t.Errorf("expected total 5, got %d", total)
+			}
+
+			var got []string
+			for _, song := range songs {
+				got = append(got, song.YouTubeID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected songs %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetSongsPageUsesLimitOffset(t *testing.T) {
+	d := pagedPlaylistDriver(3)
+	repo := NewPlaylistRepository(openRecordingDB(t, d))
+
+	if _, _, err := repo.GetSongsPage("p1", 2, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := d.queryCount(); got != 2 {
+		t.Fatalf("expected a count query and a page query, got %d queries", got)
+	}
+	if !strings.Contains(d.queries[1], "LIMIT $2 OFFSET $3") {
+		t.Errorf("expected the page query to use LIMIT/OFFSET, got %q", d.queries[1])
+	}
+}
+
+func TestCreatePlaylistNameTaken(t *testing.T) {
+	d := &recordingDriver{err: &pq.Error{Code: pqUniqueViolation, Message: "duplicate key value violates unique constraint"}}
+	repo := NewPlaylistRepository(openRecordingDB(t, d))
+
+	err := repo.Create(&models.Playlist{Name: "Chill"})
+	if !errors.Is(err, ErrPlaylistNameTaken) {
+		t.Errorf("Expected ErrPlaylistNameTaken for a unique violation, got %v", err)
+	}
+
+	d.err = &pq.Error{Code: "23503", Message: "foreign key violation"}
+	if err := repo.Create(&models.Playlist{Name: "Chill"}); err == nil || errors.Is(err, ErrPlaylistNameTaken) {
+		t.Errorf("Expected other database errors to pass through, got %v", err)
+	}
+}
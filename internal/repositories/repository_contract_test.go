@@ -0,0 +1,508 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// unknownPlaylistID is well-formed for every backend, so looking it up
+// exercises not-found handling rather than Postgres' UUID parsing
+const unknownPlaylistID = "00000000-0000-0000-0000-000000000000"
+
+// contractSongRepository is what every song backend must provide
+type contractSongRepository interface {
+	Create(song *models.Song) error
+	GetByYouTubeID(youtubeID string) (*models.Song, error)
+	GetByYouTubeIDs(youtubeIDs []string) (map[string]*models.Song, error)
+	UpdatePlayStats(youtubeID string) error
+	GetRandomSong() (*models.Song, error)
+	GetLeastPlayedSong() (*models.Song, error)
+	GetRecentlyAdded(limit int) ([]*models.Song, error)
+	GetWithoutDuration() ([]*models.Song, error)
+	UpdateDuration(youtubeID string, duration int) error
+	GetByTag(tag string) ([]*models.Song, error)
+	UpdateDetails(youtubeID, title, artist string) error
+	UpdateTags(youtubeID string, tags models.Tags) error
+	GetArtists() ([]*models.ArtistSummary, error)
+	GetAlbums() ([]*models.AlbumSummary, error)
+	GetByArtist(artist string) ([]*models.Song, error)
+	GetUnnormalized() ([]*models.Song, error)
+	MarkNormalized(youtubeID string) error
+	UpdateChapters(youtubeID string, chapters models.Chapters, startOffset, endOffset int) error
+}
+
+// contractPlaylistRepository is what every playlist backend must provide
+type contractPlaylistRepository interface {
+	Create(playlist *models.Playlist) error
+	GetByID(id string) (*models.Playlist, error)
+	GetByName(name string) (*models.Playlist, error)
+	GetAll() ([]*models.Playlist, error)
+	GetFirstPlaylist() (*models.Playlist, error)
+	AddSong(playlistID string, youtubeID string, position int) error
+	GetSongs(playlistID string) ([]*models.Song, error)
+	GetSongsPage(playlistID string, limit, offset int) ([]*models.Song, int, error)
+	GetSongIDs(playlistID string) ([]string, error)
+	RemoveSong(playlistID string, youtubeID string) error
+	UpdateSongPosition(playlistID string, youtubeID string, newPosition int) error
+	GetOrphanedSongs() ([]*models.Song, error)
+	DeleteOrphanedSong(youtubeID string) (bool, error)
+}
+
+// repositoryBackend opens an empty song and playlist repository pair
+type repositoryBackend struct {
+	name string
+	open func(t *testing.T) (contractSongRepository, contractPlaylistRepository)
+}
+
+// repositoryBackends lists every backend the contract runs against. Postgres
+// runs only when TEST_DATABASE_URL points at a migrated database, whose
+// tables are emptied before each test.
+func repositoryBackends() []repositoryBackend {
+	return []repositoryBackend{
+		{name: "memory", open: func(*testing.T) (contractSongRepository, contractPlaylistRepository) {
+			songs := NewInMemorySongRepository()
+			return songs, NewInMemoryPlaylistRepository(songs)
+		}},
+		{name: "json", open: openJSONContractRepos},
+		{name: "postgres", open: openPostgresContractRepos},
+	}
+}
+
+func openJSONContractRepos(t *testing.T) (contractSongRepository, contractPlaylistRepository) {
+	dir := t.TempDir()
+	songs, err := NewJSONSongRepository(dir)
+	if err != nil {
+		t.Fatalf("NewJSONSongRepository failed: %v", err)
+	}
+	playlists, err := NewJSONPlaylistRepository(dir, songs)
+	if err != nil {
+		t.Fatalf("NewJSONPlaylistRepository failed: %v", err)
+	}
+	return songs, playlists
+}
+
+func openPostgresContractRepos(t *testing.T) (contractSongRepository, contractPlaylistRepository) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`TRUNCATE playlist_songs, playlists, songs`); err != nil {
+		t.Fatalf("Failed to empty tables: %v", err)
+	}
+	return NewSongRepository(db), NewPlaylistRepository(db)
+}
+
+// runContract runs test against a fresh pair of repositories from each backend
+func runContract(t *testing.T, test func(t *testing.T, songs contractSongRepository, playlists contractPlaylistRepository)) {
+	for _, backend := range repositoryBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			songs, playlists := backend.open(t)
+			test(t, songs, playlists)
+		})
+	}
+}
+
+// createSongs stores songs in order, a moment apart so created_at orders them
+func createSongs(t *testing.T, repo contractSongRepository, songs ...*models.Song) {
+	t.Helper()
+	for _, song := range songs {
+		if err := repo.Create(song); err != nil {
+			t.Fatalf("Create(%s) failed: %v", song.YouTubeID, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func songIDs(songs []*models.Song) []string {
+	ids := make([]string, 0, len(songs))
+	for _, song := range songs {
+		ids = append(ids, song.YouTubeID)
+	}
+	return ids
+}
+
+func TestSongRepositoryContractNotFound(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		if song, err := songs.GetByYouTubeID("missing"); song != nil || err != nil {
+			t.Errorf("GetByYouTubeID(missing) = %v, %v, want nil, nil", song, err)
+		}
+		if song, err := songs.GetRandomSong(); song != nil || err != nil {
+			t.Errorf("GetRandomSong on empty repo = %v, %v, want nil, nil", song, err)
+		}
+		if song, err := songs.GetLeastPlayedSong(); song != nil || err != nil {
+			t.Errorf("GetLeastPlayedSong on empty repo = %v, %v, want nil, nil", song, err)
+		}
+		// Updates that match no song are not errors
+		if err := songs.UpdatePlayStats("missing"); err != nil {
+			t.Errorf("UpdatePlayStats(missing) = %v, want nil", err)
+		}
+		if err := songs.UpdateDuration("missing", 10); err != nil {
+			t.Errorf("UpdateDuration(missing) = %v, want nil", err)
+		}
+	})
+}
+
+func TestSongRepositoryContractCreateAndGet(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs, &models.Song{YouTubeID: "a", Title: "A", Artist: "Band", Tags: models.Tags{"chill"}})
+
+		if err := songs.Create(&models.Song{YouTubeID: "a", Tags: models.Tags{}}); err == nil {
+			t.Error("expected creating a duplicate song to fail")
+		}
+
+		song, err := songs.GetByYouTubeID("a")
+		if err != nil || song == nil {
+			t.Fatalf("GetByYouTubeID(a) = %v, %v", song, err)
+		}
+		if song.Title != "A" || song.Artist != "Band" || !reflect.DeepEqual(song.Tags, models.Tags{"chill"}) {
+			t.Errorf("unexpected song %+v", song)
+		}
+		if song.CreatedAt.IsZero() || song.UpdatedAt.IsZero() {
+			t.Errorf("expected timestamps to be set, got %+v", song)
+		}
+
+		found, err := songs.GetByYouTubeIDs([]string{"a", "missing"})
+		if err != nil || len(found) != 1 || found["a"] == nil {
+			t.Errorf("GetByYouTubeIDs = %v, %v, want only a", found, err)
+		}
+		if found, err := songs.GetByYouTubeIDs(nil); err != nil || len(found) != 0 {
+			t.Errorf("GetByYouTubeIDs(nil) = %v, %v, want empty", found, err)
+		}
+	})
+}
+
+func TestSongRepositoryContractPlayStats(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs, &models.Song{YouTubeID: "a", Tags: models.Tags{}}, &models.Song{YouTubeID: "b", Tags: models.Tags{}})
+
+		if song, _ := songs.GetRandomSong(); song == nil {
+			t.Error("GetRandomSong returned nil with songs stored")
+		}
+
+		if err := songs.UpdatePlayStats("a"); err != nil {
+			t.Fatalf("UpdatePlayStats failed: %v", err)
+		}
+		if least, _ := songs.GetLeastPlayedSong(); least == nil || least.YouTubeID != "b" {
+			t.Errorf("least played = %v, want b", least)
+		}
+
+		time.Sleep(time.Millisecond)
+		if err := songs.UpdatePlayStats("b"); err != nil {
+			t.Fatalf("UpdatePlayStats failed: %v", err)
+		}
+		least, _ := songs.GetLeastPlayedSong()
+		if least == nil || least.YouTubeID != "a" || least.PlayCount != 1 {
+			t.Errorf("least played = %+v, want a with 1 play, played longest ago", least)
+		}
+	})
+}
+
+func TestSongRepositoryContractOrdering(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs,
+			&models.Song{YouTubeID: "a", Artist: "Band", Album: "Second", Title: "x", Tags: models.Tags{"chill"}},
+			&models.Song{YouTubeID: "b", Artist: "Band", Album: "First", Title: "y", Duration: 100, Tags: models.Tags{}},
+			&models.Song{YouTubeID: "c", Artist: " ", Tags: models.Tags{"chill"}},
+		)
+
+		recent, _ := songs.GetRecentlyAdded(2)
+		if got := songIDs(recent); !reflect.DeepEqual(got, []string{"c", "b"}) {
+			t.Errorf("GetRecentlyAdded(2) = %v, want [c b]", got)
+		}
+
+		without, _ := songs.GetWithoutDuration()
+		if got := songIDs(without); !reflect.DeepEqual(got, []string{"a", "c"}) {
+			t.Errorf("GetWithoutDuration = %v, want [a c] oldest first", got)
+		}
+		if err := songs.UpdateDuration("a", 200); err != nil {
+			t.Fatalf("UpdateDuration failed: %v", err)
+		}
+		without, _ = songs.GetWithoutDuration()
+		if got := songIDs(without); !reflect.DeepEqual(got, []string{"c"}) {
+			t.Errorf("GetWithoutDuration after update = %v, want [c]", got)
+		}
+
+		tagged, _ := songs.GetByTag("chill")
+		if got := songIDs(tagged); !reflect.DeepEqual(got, []string{"c", "a"}) {
+			t.Errorf("GetByTag = %v, want [c a] newest first", got)
+		}
+
+		byArtist, _ := songs.GetByArtist("Band")
+		if got := songIDs(byArtist); !reflect.DeepEqual(got, []string{"b", "a"}) {
+			t.Errorf("GetByArtist = %v, want [b a] by album", got)
+		}
+		unknown, _ := songs.GetByArtist(models.UnknownName)
+		if got := songIDs(unknown); !reflect.DeepEqual(got, []string{"c"}) {
+			t.Errorf("GetByArtist(Unknown) = %v, want [c]", got)
+		}
+
+		artists, _ := songs.GetArtists()
+		wantArtists := []*models.ArtistSummary{{Name: "Band", SongCount: 2}, {Name: models.UnknownName, SongCount: 1}}
+		if !reflect.DeepEqual(artists, wantArtists) {
+			t.Errorf("GetArtists = %+v, want %+v", artists, wantArtists)
+		}
+
+		albums, _ := songs.GetAlbums()
+		wantAlbums := []*models.AlbumSummary{
+			{Name: "First", Artist: "Band", SongCount: 1},
+			{Name: "Second", Artist: "Band", SongCount: 1},
+			{Name: models.UnknownName, Artist: models.UnknownName, SongCount: 1},
+		}
+		if !reflect.DeepEqual(albums, wantAlbums) {
+			t.Errorf("GetAlbums = %+v, want %+v", albums, wantAlbums)
+		}
+	})
+}
+
+func TestSongRepositoryContractUpdates(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs, &models.Song{YouTubeID: "a", Title: "Old", Artist: "Someone", Tags: models.Tags{}})
+
+		if err := songs.UpdateDetails("a", "New", ""); err != nil {
+			t.Fatalf("UpdateDetails failed: %v", err)
+		}
+		if err := songs.UpdateTags("a", models.Tags{"loud"}); err != nil {
+			t.Fatalf("UpdateTags failed: %v", err)
+		}
+		song, _ := songs.GetByYouTubeID("a")
+		if song.Title != "New" || song.Artist != "Someone" || !reflect.DeepEqual(song.Tags, models.Tags{"loud"}) {
+			t.Errorf("unexpected song after updates: %+v", song)
+		}
+	})
+}
+
+func TestSongRepositoryContractChapters(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs, &models.Song{YouTubeID: "a", Duration: 240, Tags: models.Tags{}})
+
+		chapters := models.Chapters{
+			{Title: "Intro", Start: 0, End: 20},
+			{Title: "Song", Start: 20, End: 240},
+		}
+		if err := songs.UpdateChapters("a", chapters, 20, 0); err != nil {
+			t.Fatalf("UpdateChapters failed: %v", err)
+		}
+		song, _ := songs.GetByYouTubeID("a")
+		if !reflect.DeepEqual(song.Chapters, chapters) || song.StartOffset != 20 || song.EndOffset != 0 {
+			t.Errorf("unexpected song after UpdateChapters: %+v", song)
+		}
+	})
+}
+
+func TestSongRepositoryContractNormalized(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, _ contractPlaylistRepository) {
+		createSongs(t, songs,
+			&models.Song{YouTubeID: "a", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "b", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "c", Tags: models.Tags{}},
+		)
+
+		got, err := songs.GetUnnormalized()
+		if err != nil {
+			t.Fatalf("GetUnnormalized failed: %v", err)
+		}
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+			t.Errorf("GetUnnormalized = %v, want every song oldest first", ids)
+		}
+
+		if err := songs.MarkNormalized("b"); err != nil {
+			t.Fatalf("MarkNormalized failed: %v", err)
+		}
+		if err := songs.MarkNormalized("missing"); err != nil {
+			t.Errorf("MarkNormalized of an unknown song = %v, want nil", err)
+		}
+		got, _ = songs.GetUnnormalized()
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"a", "c"}) {
+			t.Errorf("GetUnnormalized after marking b = %v, want [a c]", ids)
+		}
+	})
+}
+
+func TestPlaylistRepositoryContractCreateAndGet(t *testing.T) {
+	runContract(t, func(t *testing.T, _ contractSongRepository, playlists contractPlaylistRepository) {
+		if first, err := playlists.GetFirstPlaylist(); first != nil || err != nil {
+			t.Errorf("GetFirstPlaylist on empty repo = %v, %v, want nil, nil", first, err)
+		}
+		if got, err := playlists.GetByID(unknownPlaylistID); got != nil || err != nil {
+			t.Errorf("GetByID(unknown) = %v, %v, want nil, nil", got, err)
+		}
+		if got, err := playlists.GetByName("Pop"); got != nil || err != nil {
+			t.Errorf("GetByName(Pop) = %v, %v, want nil, nil", got, err)
+		}
+
+		rock := &models.Playlist{Name: "Rock"}
+		jazz := &models.Playlist{Name: "Jazz"}
+		for _, playlist := range []*models.Playlist{rock, jazz} {
+			if err := playlists.Create(playlist); err != nil {
+				t.Fatalf("Create(%s) failed: %v", playlist.Name, err)
+			}
+		}
+		if rock.ID == "" || rock.ID == jazz.ID || rock.CreatedAt.IsZero() {
+			t.Errorf("Create didn't assign distinct IDs and timestamps: %+v %+v", rock, jazz)
+		}
+		if err := playlists.Create(&models.Playlist{Name: "Rock"}); !errors.Is(err, ErrPlaylistNameTaken) {
+			t.Errorf("Create with taken name = %v, want ErrPlaylistNameTaken", err)
+		}
+
+		if got, _ := playlists.GetByID(jazz.ID); got == nil || got.Name != "Jazz" {
+			t.Errorf("GetByID = %v, want Jazz", got)
+		}
+		if got, _ := playlists.GetByName("Rock"); got == nil || got.ID != rock.ID {
+			t.Errorf("GetByName = %v, want Rock", got)
+		}
+		// Backends order GetFirstPlaylist differently, so only require one
+		if first, _ := playlists.GetFirstPlaylist(); first == nil {
+			t.Error("GetFirstPlaylist returned nil with playlists stored")
+		}
+
+		all, _ := playlists.GetAll()
+		if len(all) != 2 || all[0].Name != "Jazz" || all[1].Name != "Rock" {
+			t.Errorf("GetAll = %+v, want Jazz then Rock", all)
+		}
+	})
+}
+
+func TestPlaylistRepositoryContractSongs(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, playlists contractPlaylistRepository) {
+		createSongs(t, songs,
+			&models.Song{YouTubeID: "a", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "b", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "c", Tags: models.Tags{}},
+		)
+		playlist := &models.Playlist{Name: "Mix"}
+		if err := playlists.Create(playlist); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		if got, err := playlists.GetSongs(playlist.ID); len(got) != 0 || err != nil {
+			t.Errorf("GetSongs on empty playlist = %v, %v, want none", got, err)
+		}
+
+		for i, id := range []string{"a", "b", "c"} {
+			if err := playlists.AddSong(playlist.ID, id, i); err != nil {
+				t.Fatalf("AddSong(%s) failed: %v", id, err)
+			}
+		}
+		if err := playlists.AddSong(playlist.ID, "a", 3); err == nil {
+			t.Error("expected adding a song twice to fail")
+		}
+		if err := playlists.AddSong(playlist.ID, "missing", 3); err == nil {
+			t.Error("expected adding an unknown song to fail")
+		}
+		if err := playlists.AddSong(unknownPlaylistID, "a", 0); err == nil {
+			t.Error("expected adding to an unknown playlist to fail")
+		}
+
+		got, _ := playlists.GetSongs(playlist.ID)
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+			t.Errorf("GetSongs = %v, want [a b c]", ids)
+		}
+
+		if err := playlists.UpdateSongPosition(playlist.ID, "a", 5); err != nil {
+			t.Fatalf("UpdateSongPosition failed: %v", err)
+		}
+		ids, _ := playlists.GetSongIDs(playlist.ID)
+		if !reflect.DeepEqual(ids, []string{"b", "c", "a"}) {
+			t.Errorf("GetSongIDs after reorder = %v, want [b c a]", ids)
+		}
+
+		page, total, _ := playlists.GetSongsPage(playlist.ID, 2, 1)
+		if total != 3 || !reflect.DeepEqual(songIDs(page), []string{"c", "a"}) {
+			t.Errorf("GetSongsPage(2, 1) = %v of %d, want [c a] of 3", songIDs(page), total)
+		}
+		page, _, _ = playlists.GetSongsPage(playlist.ID, 2, 10)
+		if len(page) != 0 {
+			t.Errorf("GetSongsPage past the end = %v, want none", songIDs(page))
+		}
+
+		if err := playlists.RemoveSong(playlist.ID, "c"); err != nil {
+			t.Fatalf("RemoveSong failed: %v", err)
+		}
+		if err := playlists.RemoveSong(playlist.ID, "c"); err != nil {
+			t.Errorf("RemoveSong of a song no longer listed = %v, want nil", err)
+		}
+		got, _ = playlists.GetSongs(playlist.ID)
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"b", "a"}) {
+			t.Errorf("GetSongs after remove = %v, want [b a]", ids)
+		}
+
+		all, _ := playlists.GetAll()
+		if len(all) != 1 || all[0].SongCount != 2 {
+			t.Errorf("GetAll = %+v, want Mix with 2 songs", all)
+		}
+	})
+}
+
+func TestPlaylistRepositoryContractOrphanedSongs(t *testing.T) {
+	runContract(t, func(t *testing.T, songs contractSongRepository, playlists contractPlaylistRepository) {
+		if got, err := playlists.GetOrphanedSongs(); len(got) != 0 || err != nil {
+			t.Errorf("GetOrphanedSongs on an empty library = %v, %v, want none", got, err)
+		}
+
+		createSongs(t, songs,
+			&models.Song{YouTubeID: "listed", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "orphan1", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "twice", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "removed", Tags: models.Tags{}},
+			&models.Song{YouTubeID: "orphan2", Tags: models.Tags{}},
+		)
+		mix := &models.Playlist{Name: "Mix"}
+		chill := &models.Playlist{Name: "Chill"}
+		for _, playlist := range []*models.Playlist{mix, chill} {
+			if err := playlists.Create(playlist); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+		for i, id := range []string{"listed", "twice", "removed"} {
+			if err := playlists.AddSong(mix.ID, id, i); err != nil {
+				t.Fatalf("AddSong(%s) failed: %v", id, err)
+			}
+		}
+		if err := playlists.AddSong(chill.ID, "twice", 0); err != nil {
+			t.Fatalf("AddSong failed: %v", err)
+		}
+		if err := playlists.RemoveSong(mix.ID, "removed"); err != nil {
+			t.Fatalf("RemoveSong failed: %v", err)
+		}
+
+		got, err := playlists.GetOrphanedSongs()
+		if err != nil {
+			t.Fatalf("GetOrphanedSongs failed: %v", err)
+		}
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"orphan1", "removed", "orphan2"}) {
+			t.Errorf("GetOrphanedSongs = %v, want [orphan1 removed orphan2]", ids)
+		}
+
+		if deleted, err := playlists.DeleteOrphanedSong("listed"); deleted || err != nil {
+			t.Errorf("DeleteOrphanedSong of a listed song = %v, %v, want false", deleted, err)
+		}
+		if deleted, err := playlists.DeleteOrphanedSong("orphan1"); !deleted || err != nil {
+			t.Errorf("DeleteOrphanedSong = %v, %v, want true", deleted, err)
+		}
+		if deleted, err := playlists.DeleteOrphanedSong("orphan1"); deleted || err != nil {
+			t.Errorf("DeleteOrphanedSong of a deleted song = %v, %v, want false", deleted, err)
+		}
+
+		if song, _ := songs.GetByYouTubeID("orphan1"); song != nil {
+			t.Error("expected the orphaned song to be gone from the library")
+		}
+		if song, _ := songs.GetByYouTubeID("listed"); song == nil {
+			t.Error("expected the listed song to stay in the library")
+		}
+		got, _ = playlists.GetOrphanedSongs()
+		if ids := songIDs(got); !reflect.DeepEqual(ids, []string{"removed", "orphan2"}) {
+			t.Errorf("GetOrphanedSongs after delete = %v, want [removed orphan2]", ids)
+		}
+	})
+}
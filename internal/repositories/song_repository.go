@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/lib/pq"
 )
 
 type SongRepository struct {
@@ -19,8 +20,9 @@ func (r *SongRepository) Create(song *models.Song) error {
 	query := `
 		INSERT INTO songs (
 			youtube_id, title, artist, album, duration, s3_key,
-			last_played, play_count, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			last_played, play_count, created_at, updated_at, tags,
+			chapters, start_offset, end_offset
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	now := time.Now()
@@ -35,6 +37,10 @@ func (r *SongRepository) Create(song *models.Song) error {
 		song.PlayCount,
 		now,
 		now,
+		song.Tags,
+		song.Chapters,
+		song.StartOffset,
+		song.EndOffset,
 	)
 
 	return err
@@ -43,7 +49,8 @@ func (r *SongRepository) Create(song *models.Song) error {
 func (r *SongRepository) GetByYouTubeID(youtubeID string) (*models.Song, error) {
 	query := `
 		SELECT youtube_id, title, artist, album, duration, s3_key,
-			   last_played, play_count, created_at, updated_at
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
 		FROM songs
 		WHERE youtube_id = $1
 	`
@@ -60,6 +67,10 @@ func (r *SongRepository) GetByYouTubeID(youtubeID string) (*models.Song, error)
 		&song.PlayCount,
 		&song.CreatedAt,
 		&song.UpdatedAt,
+		&song.Tags,
+		&song.Chapters,
+		&song.StartOffset,
+		&song.EndOffset,
 	)
 
 	if err == sql.ErrNoRows {
@@ -72,6 +83,55 @@ func (r *SongRepository) GetByYouTubeID(youtubeID string) (*models.Song, error)
 	return song, nil
 }
 
+// GetByYouTubeIDs fetches all existing songs with the given IDs in a single
+// query, keyed by YouTube ID. IDs without a song are absent from the map.
+func (r *SongRepository) GetByYouTubeIDs(youtubeIDs []string) (map[string]*models.Song, error) {
+	songs := make(map[string]*models.Song, len(youtubeIDs))
+	if len(youtubeIDs) == 0 {
+		return songs, nil
+	}
+
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		WHERE youtube_id = ANY($1)
+	`
+
+	rows, err := r.db.Query(query, pq.Array(youtubeIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		song := &models.Song{}
+		err := rows.Scan(
+			&song.YouTubeID,
+			&song.Title,
+			&song.Artist,
+			&song.Album,
+			&song.Duration,
+			&song.S3Key,
+			&song.LastPlayed,
+			&song.PlayCount,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		songs[song.YouTubeID] = song
+	}
+
+	return songs, rows.Err()
+}
+
 func (r *SongRepository) UpdatePlayStats(youtubeID string) error {
 	query := `
 		UPDATE songs
@@ -89,7 +149,8 @@ func (r *SongRepository) UpdatePlayStats(youtubeID string) error {
 func (r *SongRepository) GetRandomSong() (*models.Song, error) {
 	query := `
 		SELECT youtube_id, title, artist, album, duration, s3_key,
-			   last_played, play_count, created_at, updated_at
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
 		FROM songs
 		ORDER BY RANDOM()
 		LIMIT 1
@@ -107,6 +168,10 @@ func (r *SongRepository) GetRandomSong() (*models.Song, error) {
 		&song.PlayCount,
 		&song.CreatedAt,
 		&song.UpdatedAt,
+		&song.Tags,
+		&song.Chapters,
+		&song.StartOffset,
+		&song.EndOffset,
 	)
 
 	if err == sql.ErrNoRows {
@@ -122,7 +187,8 @@ func (r *SongRepository) GetRandomSong() (*models.Song, error) {
 func (r *SongRepository) GetLeastPlayedSong() (*models.Song, error) {
 	query := `
 		SELECT youtube_id, title, artist, album, duration, s3_key,
-			   last_played, play_count, created_at, updated_at
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
 		FROM songs
 		ORDER BY play_count ASC, last_played ASC
 		LIMIT 1
@@ -140,6 +206,10 @@ func (r *SongRepository) GetLeastPlayedSong() (*models.Song, error) {
 		&song.PlayCount,
 		&song.CreatedAt,
 		&song.UpdatedAt,
+		&song.Tags,
+		&song.Chapters,
+		&song.StartOffset,
+		&song.EndOffset,
 	)
 
 	if err == sql.ErrNoRows {
@@ -151,3 +221,335 @@ func (r *SongRepository) GetLeastPlayedSong() (*models.Song, error) {
 
 	return song, nil
 }
+
+func (r *SongRepository) GetRecentlyAdded(limit int) ([]*models.Song, error) {
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make([]*models.Song, 0)
+	for rows.Next() {
+		song := &models.Song{}
+		err := rows.Scan(
+			&song.YouTubeID,
+			&song.Title,
+			&song.Artist,
+			&song.Album,
+			&song.Duration,
+			&song.S3Key,
+			&song.LastPlayed,
+			&song.PlayCount,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
+
+func (r *SongRepository) GetWithoutDuration() ([]*models.Song, error) {
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		WHERE duration = 0
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make([]*models.Song, 0)
+	for rows.Next() {
+		song := &models.Song{}
+		err := rows.Scan(
+			&song.YouTubeID,
+			&song.Title,
+			&song.Artist,
+			&song.Album,
+			&song.Duration,
+			&song.S3Key,
+			&song.LastPlayed,
+			&song.PlayCount,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
+
+func (r *SongRepository) UpdateDuration(youtubeID string, duration int) error {
+	query := `
+		UPDATE songs
+		SET duration = $1,
+			updated_at = $2
+		WHERE youtube_id = $3
+	`
+
+	_, err := r.db.Exec(query, duration, time.Now(), youtubeID)
+	return err
+}
+
+// GetUnnormalized returns the songs whose audio hasn't been loudness
+// normalized, oldest first
+func (r *SongRepository) GetUnnormalized() ([]*models.Song, error) {
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		WHERE NOT normalized
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs, err := scanPlaylistSongs(rows)
+	if songs == nil && err == nil {
+		songs = make([]*models.Song, 0)
+	}
+	return songs, err
+}
+
+// MarkNormalized records that a song's stored audio is loudness normalized
+func (r *SongRepository) MarkNormalized(youtubeID string) error {
+	query := `
+		UPDATE songs
+		SET normalized = TRUE,
+			updated_at = $1
+		WHERE youtube_id = $2
+	`
+
+	_, err := r.db.Exec(query, time.Now(), youtubeID)
+	return err
+}
+
+// GetByTag returns the songs carrying tag, newest first
+func (r *SongRepository) GetByTag(tag string) ([]*models.Song, error) {
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		WHERE tags::jsonb @> jsonb_build_array($1::text)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make([]*models.Song, 0)
+	for rows.Next() {
+		song := &models.Song{}
+		err := rows.Scan(
+			&song.YouTubeID,
+			&song.Title,
+			&song.Artist,
+			&song.Album,
+			&song.Duration,
+			&song.S3Key,
+			&song.LastPlayed,
+			&song.PlayCount,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
+
+// UpdateDetails overwrites a song's title and artist; empty values are left unchanged
+func (r *SongRepository) UpdateDetails(youtubeID, title, artist string) error {
+	query := `
+		UPDATE songs
+		SET title = COALESCE(NULLIF($1, ''), title),
+			artist = COALESCE(NULLIF($2, ''), artist),
+			updated_at = $3
+		WHERE youtube_id = $4
+	`
+
+	_, err := r.db.Exec(query, title, artist, time.Now(), youtubeID)
+	return err
+}
+
+func (r *SongRepository) UpdateTags(youtubeID string, tags models.Tags) error {
+	query := `
+		UPDATE songs
+		SET tags = $1,
+			updated_at = $2
+		WHERE youtube_id = $3
+	`
+
+	_, err := r.db.Exec(query, tags, time.Now(), youtubeID)
+	return err
+}
+
+// UpdateChapters stores a song's chapters and the offsets trimming it
+func (r *SongRepository) UpdateChapters(youtubeID string, chapters models.Chapters, startOffset, endOffset int) error {
+	query := `
+		UPDATE songs
+		SET chapters = $1,
+			start_offset = $2,
+			end_offset = $3,
+			updated_at = $4
+		WHERE youtube_id = $5
+	`
+
+	_, err := r.db.Exec(query, chapters, startOffset, endOffset, time.Now(), youtubeID)
+	return err
+}
+
+// libraryNameExpr groups a name column the way models.LibraryName does
+func libraryNameExpr(column string) string {
+	return `CASE WHEN TRIM(COALESCE(` + column + `, '')) = '' OR LOWER(TRIM(` + column + `)) = 'unknown'
+			THEN 'Unknown' ELSE TRIM(` + column + `) END`
+}
+
+// GetArtists returns each artist in the library with their song count
+func (r *SongRepository) GetArtists() ([]*models.ArtistSummary, error) {
+	query := `
+		SELECT ` + libraryNameExpr("artist") + ` AS name, COUNT(*)
+		FROM songs
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artists := make([]*models.ArtistSummary, 0)
+	for rows.Next() {
+		artist := &models.ArtistSummary{}
+		if err := rows.Scan(&artist.Name, &artist.SongCount); err != nil {
+			return nil, err
+		}
+		artists = append(artists, artist)
+	}
+
+	return artists, rows.Err()
+}
+
+// GetAlbums returns each album in the library, per artist, with its song count
+func (r *SongRepository) GetAlbums() ([]*models.AlbumSummary, error) {
+	query := `
+		SELECT ` + libraryNameExpr("album") + ` AS name,
+			   ` + libraryNameExpr("artist") + ` AS artist,
+			   COUNT(*)
+		FROM songs
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	albums := make([]*models.AlbumSummary, 0)
+	for rows.Next() {
+		album := &models.AlbumSummary{}
+		if err := rows.Scan(&album.Name, &album.Artist, &album.SongCount); err != nil {
+			return nil, err
+		}
+		albums = append(albums, album)
+	}
+
+	return albums, rows.Err()
+}
+
+// GetByArtist returns an artist's songs by album and title. The artist is
+// matched after grouping, so "Unknown" finds songs without an artist.
+func (r *SongRepository) GetByArtist(artist string) ([]*models.Song, error) {
+	query := `
+		SELECT youtube_id, title, artist, album, duration, s3_key,
+			   last_played, play_count, created_at, updated_at, tags,
+			   chapters, start_offset, end_offset
+		FROM songs
+		WHERE ` + libraryNameExpr("artist") + ` = $1
+		ORDER BY album, title
+	`
+
+	rows, err := r.db.Query(query, artist)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make([]*models.Song, 0)
+	for rows.Next() {
+		song := &models.Song{}
+		err := rows.Scan(
+			&song.YouTubeID,
+			&song.Title,
+			&song.Artist,
+			&song.Album,
+			&song.Duration,
+			&song.S3Key,
+			&song.LastPlayed,
+			&song.PlayCount,
+			&song.CreatedAt,
+			&song.UpdatedAt,
+			&song.Tags,
+			&song.Chapters,
+			&song.StartOffset,
+			&song.EndOffset,
+		)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
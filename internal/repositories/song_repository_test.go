@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingDriver is a minimal database/sql driver that records every query
+// and answers each one with the same fixed rows, or with respond when set.
+// A non-nil err fails every query instead.
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []string
+	columns []string
+	rows    [][]driver.Value
+	respond func(query string, args []driver.Value) ([]string, [][]driver.Value)
+	err     error
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) queryCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queries)
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error { return nil }
+
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.respond != nil {
+		columns, rows := d.respond(s.query, args)
+		return &recordingRows{columns: columns, rows: rows}, nil
+	}
+	return &recordingRows{columns: d.columns, rows: d.rows}, nil
+}
+
+type recordingRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+var driverSeq struct {
+	sync.Mutex
+	n int
+}
+
+// openRecordingDB registers a fresh recording driver and opens a database on it
+func openRecordingDB(t *testing.T, d *recordingDriver) *sql.DB {
+	t.Helper()
+
+	driverSeq.Lock()
+	driverSeq.n++
+	name := fmt.Sprintf("recording-%d", driverSeq.n)
+	driverSeq.Unlock()
+
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func songRow(youtubeID, title string) []driver.Value {
+	now := time.Now()
+	return []driver.Value{youtubeID, title, "Artist", "Album", int64(180), "songs/" + youtubeID + ".mp3", now, int64(0), now, now, "[]", "[]", int64(0), int64(0)}
+}
+
+var songColumns = []string{"youtube_id", "title", "artist", "album", "duration", "s3_key", "last_played", "play_count", "created_at", "updated_at", "tags", "chapters", "start_offset", "end_offset"}
+
+func TestGetByYouTubeIDsUsesSingleQuery(t *testing.T) {
+	d := &recordingDriver{
+		columns: songColumns,
+		rows: [][]driver.Value{
+			songRow("abc", "First"),
+			songRow("def", "Second"),
+		},
+	}
+	repo := NewSongRepository(openRecordingDB(t, d))
+
+	songs, err := repo.GetByYouTubeIDs([]string{"abc", "def", "missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := d.queryCount(); got != 1 {
+		t.Errorf("expected 1 query, got %d", got)
+	}
+	if len(songs) != 2 {
+		t.Fatalf("expected 2 songs, got %d", len(songs))
+	}
+	if songs["abc"] == nil || songs["abc"].Title != "First" {
+		t.Errorf("expected song abc titled First, got %+v", songs["abc"])
+	}
+	if songs["def"] == nil || songs["def"].Title != "Second" {
+		t.Errorf("expected song def titled Second, got %+v", songs["def"])
+	}
+	if _, ok := songs["missing"]; ok {
+		t.Error("expected missing song to be absent from the result")
+	}
+}
+
+func TestGetByYouTubeIDsEmptyInput(t *testing.T) {
+	d := &recordingDriver{}
+	repo := NewSongRepository(openRecordingDB(t, d))
+
+	songs, err := repo.GetByYouTubeIDs(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if songs == nil || len(songs) != 0 {
+		t.Errorf("expected an empty map, got %v", songs)
+	}
+	if got := d.queryCount(); got != 0 {
+		t.Errorf("expected no queries for empty input, got %d", got)
+	}
+}
+
+func TestGetByTagDecodesTags(t *testing.T) {
+	row := songRow("abc", "First")
+	row[10] = `["chill","explicit"]`
+	d := &recordingDriver{columns: songColumns, rows: [][]driver.Value{row}}
+	repo := NewSongRepository(openRecordingDB(t, d))
+
+	songs, err := repo.GetByTag("chill")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(songs) != 1 {
+		t.Fatalf("expected 1 song, got %d", len(songs))
+	}
+	if !songs[0].Tags.Has("chill") || !songs[0].Tags.Has("explicit") {
+		t.Errorf("expected tags chill and explicit, got %v", songs[0].Tags)
+	}
+	if got := d.queryCount(); got != 1 {
+		t.Errorf("expected 1 query, got %d", got)
+	}
+}
+
+func TestGetArtistsGroupsInQuery(t *testing.T) {
+	d := &recordingDriver{
+		columns: []string{"name", "count"},
+		rows: [][]driver.Value{
+			{"Boards of Canada", int64(3)},
+			{"Unknown", int64(2)},
+		},
+	}
+	repo := NewSongRepository(openRecordingDB(t, d))
+
+	artists, err := repo.GetArtists()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artists) != 2 || artists[0].Name != "Boards of Canada" || artists[0].SongCount != 3 || artists[1].SongCount != 2 {
+		t.Errorf("unexpected artists %+v %+v", artists[0], artists[1])
+	}
+	if got := d.queryCount(); got != 1 {
+		t.Errorf("expected 1 query, got %d", got)
+	}
+	if !strings.Contains(d.queries[0], "GROUP BY") || !strings.Contains(d.queries[0], "'unknown'") {
+		t.Errorf("expected artists to be grouped in the query, got %s", d.queries[0])
+	}
+}
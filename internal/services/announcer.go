@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/config"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// Speech backends selectable through ANNOUNCE_TTS
+const (
+	SpeechProviderCommand = "command"
+	SpeechProviderHTTP    = "http"
+)
+
+// announcementTimeout bounds synthesizing and storing one announcement
+const announcementTimeout = 30 * time.Second
+
+// maxAnnouncementBytes caps the audio accepted from a speech backend
+const maxAnnouncementBytes = 10 << 20
+
+// AnnouncementKey returns the storage key of the announcement clip with id
+func AnnouncementKey(id string) string {
+	return fmt.Sprintf("announcements/%s", id)
+}
+
+// AnnouncementID names the clip for text by its hash, so songs announced
+// with the same words share one clip
+func AnnouncementID(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// AnnouncementText is what is said before song plays
+func AnnouncementText(song *models.Song) string {
+	return "Now playing: " + NowPlayingText(song)
+}
+
+// SpeechSynthesizer turns text into an audio clip
+type SpeechSynthesizer interface {
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
+
+// NewSpeechSynthesizer returns the backend selected by ANNOUNCE_TTS, or nil
+// when announcements are not configured
+func NewSpeechSynthesizer(cfg *config.Config) (SpeechSynthesizer, error) {
+	switch cfg.Announce.Provider {
+	case "":
+		return nil, nil
+	case SpeechProviderCommand:
+		if strings.TrimSpace(cfg.Announce.Command) == "" {
+			return nil, errors.New("ANNOUNCE_TTS_COMMAND is required for the command speech backend")
+		}
+		return NewCommandSynthesizer(cfg.Announce.Command), nil
+	case SpeechProviderHTTP:
+		if cfg.Announce.URL == "" {
+			return nil, errors.New("ANNOUNCE_TTS_URL is required for the http speech backend")
+		}
+		return NewHTTPSynthesizer(cfg.Announce.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown speech backend %q", cfg.Announce.Provider)
+	}
+}
+
+// CommandSynthesizer runs a command with the text on stdin and takes the
+// audio it writes to stdout, e.g. "espeak-ng --stdout"
+type CommandSynthesizer struct {
+	name string
+	args []string
+}
+
+// NewCommandSynthesizer splits command on spaces into a program and its arguments
+func NewCommandSynthesizer(command string) *CommandSynthesizer {
+	fields := strings.Fields(command)
+	return &CommandSynthesizer{name: fields[0], args: fields[1:]}
+}
+
+func (s *CommandSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.name, s.args...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", s.name, err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%s wrote no audio", s.name)
+	}
+	return stdout.Bytes(), nil
+}
+
+// HTTPSynthesizer POSTs {"text": ...} to a TTS service and takes the
+// response body as the audio
+type HTTPSynthesizer struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewHTTPSynthesizer(url string) *HTTPSynthesizer {
+	return &HTTPSynthesizer{
+		url:        url,
+		httpClient: &http.Client{Timeout: announcementTimeout},
+	}
+}
+
+func (s *HTTPSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create speech request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("speech service returned status %d", resp.StatusCode)
+	}
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAnnouncementBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read speech audio: %w", err)
+	}
+	if len(audio) == 0 {
+		return nil, errors.New("speech service returned no audio")
+	}
+	return audio, nil
+}
+
+// Announcement is a spoken clip introducing a song
+type Announcement struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Announcer synthesizes song announcements and caches the clips in storage
+// by the hash of their text, so each is only synthesized once
+type Announcer struct {
+	synth   SpeechSynthesizer
+	storage S3ServiceInterface
+
+	mu       sync.Mutex
+	ready    map[string]bool // announcement IDs known to be in storage
+	inFlight map[string]bool
+}
+
+func NewAnnouncer(synth SpeechSynthesizer, storage S3ServiceInterface) *Announcer {
+	return &Announcer{
+		synth:    synth,
+		storage:  storage,
+		ready:    make(map[string]bool),
+		inFlight: make(map[string]bool),
+	}
+}
+
+// Prepare makes sure song's announcement is in storage, synthesizing it the
+// first time its text is needed
+func (a *Announcer) Prepare(ctx context.Context, song *models.Song) (*Announcement, error) {
+	announcement := announcementFor(song)
+	if a.isReady(announcement.ID) {
+		return announcement, nil
+	}
+
+	key := AnnouncementKey(announcement.ID)
+	cached, err := a.storage.FileExists(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cached announcement: %w", err)
+	}
+	if !cached {
+		audio, err := a.synth.Synthesize(ctx, announcement.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize announcement: %w", err)
+		}
+		if err := a.storage.UploadFile(ctx, key, bytes.NewReader(audio)); err != nil {
+			return nil, fmt.Errorf("failed to store announcement: %w", err)
+		}
+	}
+
+	a.mu.Lock()
+	a.ready[announcement.ID] = true
+	a.mu.Unlock()
+	return announcement, nil
+}
+
+// PrepareAsync prepares song's announcement in the background, doing nothing
+// if it is ready or already being prepared
+func (a *Announcer) PrepareAsync(song *models.Song) {
+	id := AnnouncementID(AnnouncementText(song))
+	a.mu.Lock()
+	if a.ready[id] || a.inFlight[id] {
+		a.mu.Unlock()
+		return
+	}
+	a.inFlight[id] = true
+	a.mu.Unlock()
+
+	go func() {
+		defer func() {
+			a.mu.Lock()
+			delete(a.inFlight, id)
+			a.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), announcementTimeout)
+		defer cancel()
+		if _, err := a.Prepare(ctx, song); err != nil {
+			log.Printf("[ERROR] Announcer: Failed to prepare announcement for %s: %v", song.YouTubeID, err)
+		}
+	}()
+}
+
+// Ready returns song's announcement if its clip is in storage. It never
+// waits on the speech backend, so songs whose clip isn't ready yet simply
+// play unannounced.
+func (a *Announcer) Ready(song *models.Song) (*Announcement, bool) {
+	announcement := announcementFor(song)
+	if !a.isReady(announcement.ID) {
+		return nil, false
+	}
+	return announcement, true
+}
+
+func (a *Announcer) isReady(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ready[id]
+}
+
+func announcementFor(song *models.Song) *Announcement {
+	text := AnnouncementText(song)
+	return &Announcement{ID: AnnouncementID(text), Text: text}
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// fakeSynthesizer returns the text as the audio and counts its calls
+type fakeSynthesizer struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, text)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte("audio:" + text), nil
+}
+
+func (f *fakeSynthesizer) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func TestAnnouncerCachesClipsByText(t *testing.T) {
+	synth := &fakeSynthesizer{}
+	storage := newMemoryStorage()
+	announcer := NewAnnouncer(synth, storage)
+	song := createTestSong("song1", "Title", "Artist", 180)
+
+	if _, ok := announcer.Ready(song); ok {
+		t.Error("Expected no announcement before preparing one")
+	}
+
+	announcement, err := announcer.Prepare(context.Background(), song)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if announcement.Text != "Now playing: Artist - Title" {
+		t.Errorf("Unexpected announcement text %q", announcement.Text)
+	}
+	data, ok := storage.files[AnnouncementKey(announcement.ID)]
+	if !ok || string(data) != "audio:"+announcement.Text {
+		t.Errorf("Expected the clip to be stored under its text hash, got %q", data)
+	}
+
+	// The same text is only synthesized once, even for another song
+	if _, err := announcer.Prepare(context.Background(), song); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	reupload := *song
+	reupload.YouTubeID = "song1-again"
+	if _, err := announcer.Prepare(context.Background(), &reupload); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if synth.callCount() != 1 {
+		t.Errorf("Expected 1 synthesis, got %d", synth.callCount())
+	}
+	if ready, ok := announcer.Ready(song); !ok || ready.ID != announcement.ID {
+		t.Errorf("Expected the announcement to be ready, got %v", ready)
+	}
+
+	// A restarted server finds the clip in storage instead of synthesizing it
+	restarted := NewAnnouncer(synth, storage)
+	if _, err := restarted.Prepare(context.Background(), song); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if synth.callCount() != 1 {
+		t.Errorf("Expected the stored clip to be reused, got %d syntheses", synth.callCount())
+	}
+}
+
+func TestAnnouncerSynthesisFailure(t *testing.T) {
+	synth := &fakeSynthesizer{err: errors.New("no voice")}
+	announcer := NewAnnouncer(synth, newMemoryStorage())
+	song := createTestSong("song1", "Title", "Artist", 180)
+
+	if _, err := announcer.Prepare(context.Background(), song); err == nil {
+		t.Fatal("Expected Prepare to fail")
+	}
+	if _, ok := announcer.Ready(song); ok {
+		t.Error("Expected a failed announcement not to be ready")
+	}
+}
+
+func TestHTTPSynthesizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Method != http.MethodPost {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, "audio:"+body.Text)
+	}))
+	defer server.Close()
+
+	audio, err := NewHTTPSynthesizer(server.URL).Synthesize(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Synthesize failed: %v", err)
+	}
+	if string(audio) != "audio:hello" {
+		t.Errorf("Expected the response body as audio, got %q", audio)
+	}
+}
+
+// waitForAnnouncement blocks until the announcer has song's clip ready
+func waitForAnnouncement(t *testing.T, announcer *Announcer, song *models.Song) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := announcer.Ready(song); ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the announcement of %s", song.YouTubeID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPlaybackLoopAnnouncesNextSongInGap(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+		createTestSong("song3", "Song 3", "Artist 3", 10),
+	}
+
+	clock := newFakeClock()
+	bus := &recordingEventBus{}
+	synth := &fakeSynthesizer{}
+	announcer := NewAnnouncer(synth, newMemoryStorage())
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, clock)
+	service.SetInterstitialGap(2 * time.Second)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetAnnouncer(announcer)
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	state := service.GetPlaybackState()
+	current := state.Queue[state.CurrentSongIndex]
+	next := state.Queue[state.CurrentSongIndex+1]
+
+	// The next song's clip is prepared while the current one plays
+	clock.Advance(t, time.Second)
+	waitForAnnouncement(t, announcer, next)
+	if got := bus.announced(); len(got) != 0 {
+		t.Fatalf("Expected no announcement before the gap, got %d", len(got))
+	}
+
+	// The song's audio has ended and the gap has begun
+	clock.Advance(t, 9500*time.Millisecond)
+	clock.Advance(t, 500*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID != current.YouTubeID {
+		t.Fatalf("Expected %s to still hold the slot during the gap, got %s", current.YouTubeID, song.YouTubeID)
+	}
+	got := bus.announced()
+	if len(got) != 1 || got[0].YouTubeID != next.YouTubeID {
+		t.Fatalf("Expected one announcement of %s, got %v", next.YouTubeID, got)
+	}
+
+	// Once the gap is over the announced song plays
+	clock.Advance(t, 1500*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID != next.YouTubeID {
+		t.Errorf("Expected %s to play after its announcement, got %s", next.YouTubeID, song.YouTubeID)
+	}
+}
+
+func TestPlaybackLoopSkipsAnnouncementsWithoutGap(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+	}
+
+	clock := newFakeClock()
+	bus := &recordingEventBus{}
+	synth := &fakeSynthesizer{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetAnnouncer(NewAnnouncer(synth, newMemoryStorage()))
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	clock.Advance(t, 5*time.Second)
+	clock.Advance(t, 5*time.Second)
+	if got := bus.announced(); len(got) != 0 {
+		t.Errorf("Expected no announcements without a gap, got %d", len(got))
+	}
+	if synth.callCount() != 0 {
+		t.Errorf("Expected nothing synthesized without a gap, got %d", synth.callCount())
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/metrics"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// AudioNormalizer evens out the loudness of a downloaded file before it is stored
+type AudioNormalizer interface {
+	Normalize(ctx context.Context, inputPath, outputPath string) error
+}
+
+// FFmpegNormalizer normalizes audio to -16 LUFS with the ffmpeg loudnorm filter
+type FFmpegNormalizer struct {
+	binary string
+}
+
+func NewFFmpegNormalizer() *FFmpegNormalizer {
+	return &FFmpegNormalizer{binary: "ffmpeg"}
+}
+
+func (n *FFmpegNormalizer) Normalize(ctx context.Context, inputPath, outputPath string) error {
+	cmd := exec.CommandContext(ctx, n.binary,
+		"-i", inputPath,
+		"-af", "loudnorm=I=-16:TP=-1.5:LRA=11", // Normalize to -16 LUFS
+		"-ar", "44100", // Set sample rate to 44.1kHz
+		"-y", // Overwrite output file if it exists
+		outputPath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg normalize failed: %w: %s", err, output)
+	}
+	return nil
+}
+
+// verifyAttempts is how many times a download that fails VerifyAudio is tried
+const verifyAttempts = 2
+
+// AudioFetcher downloads a song's audio and uploads it to storage under
+// SongAudioKey, the same key playback reads it from
+type AudioFetcher struct {
+	downloader Downloader
+	storage    S3ServiceInterface
+	normalizer AudioNormalizer
+	// marker records songs uploaded normalized so they aren't normalized again
+	marker NormalizedMarker
+
+	// chapters records downloaded songs' chapters with the offsets trimmer
+	// picks; nil skips reading chapters
+	chapters ChapterRecorder
+	trimmer  ChapterTrimmer
+
+	// localCopyDir keeps a copy of uploaded audio on disk, laid out by
+	// storage key; empty discards the download once it is uploaded
+	localCopyDir string
+
+	// minFreeSpace is the free space in bytes required before a download
+	// starts; freeSpace reports what is available and is swapped out in tests
+	minFreeSpace uint64
+	freeSpace    func(path string) (uint64, error)
+}
+
+// NewAudioFetcher creates a fetcher. A nil normalizer uploads audio as downloaded.
+func NewAudioFetcher(downloader Downloader, storage S3ServiceInterface, normalizer AudioNormalizer) *AudioFetcher {
+	return &AudioFetcher{
+		downloader: downloader,
+		storage:    storage,
+		normalizer: normalizer,
+		freeSpace:  availableDiskSpace,
+	}
+}
+
+// NormalizedMarker records that a song's stored audio is normalized
+type NormalizedMarker interface {
+	MarkNormalized(youtubeID string) error
+}
+
+// SetNormalizedMarker marks songs normalized as their audio is uploaded
+func (f *AudioFetcher) SetNormalizedMarker(marker NormalizedMarker) {
+	f.marker = marker
+}
+
+// SetLocalCopyDir keeps a local copy of every upload under dir
+func (f *AudioFetcher) SetLocalCopyDir(dir string) {
+	f.localCopyDir = dir
+}
+
+// ClearLocalCopies deletes every local copy of uploaded audio, leaving the
+// local copy directory itself in place
+func (f *AudioFetcher) ClearLocalCopies() error {
+	if f.localCopyDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(f.localCopyDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		errs = append(errs, os.RemoveAll(filepath.Join(f.localCopyDir, entry.Name())))
+	}
+	return errors.Join(errs...)
+}
+
+// SetMinFreeSpace refuses downloads while less than bytes are free in the
+// temp directory. Zero disables the check.
+func (f *AudioFetcher) SetMinFreeSpace(bytes uint64) {
+	f.minFreeSpace = bytes
+}
+
+// checkFreeSpace fails with ErrDiskFull when dir has less than the minimum
+// free space. Platforms where free space can't be read are not blocked.
+func (f *AudioFetcher) checkFreeSpace(dir string) error {
+	if f.minFreeSpace == 0 {
+		return nil
+	}
+
+	free, err := f.freeSpace(dir)
+	if err != nil {
+		log.Printf("[WARN] AudioFetcher: Could not check free space in %s: %v", dir, err)
+		return nil
+	}
+	if free < f.minFreeSpace {
+		return fmt.Errorf("%w: %d MB free in %s, need %d MB", ErrDiskFull, free>>20, dir, f.minFreeSpace>>20)
+	}
+	return nil
+}
+
+// Fetch makes sure the song's audio is in storage, downloading and uploading
+// it if needed. It reports whether a download happened.
+func (f *AudioFetcher) Fetch(ctx context.Context, youtubeID string) (bool, error) {
+	stored, err := FindSongAudio(ctx, f.storage, youtubeID)
+	if err != nil {
+		return false, err
+	}
+	if stored != nil && stored.Size > 0 {
+		return false, nil
+	}
+	if stored != nil {
+		log.Printf("[WARN] AudioFetcher: %s is empty, downloading it again", stored.Key)
+	}
+
+	if err := f.checkFreeSpace(os.TempDir()); err != nil {
+		return false, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "go-radio-download-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	audioPath, err := f.downloadVerified(ctx, youtubeID, tempDir)
+	if err != nil {
+		return false, err
+	}
+
+	if f.normalizer != nil {
+		normalizedPath := filepath.Join(tempDir, youtubeID+"_normalized.mp3")
+		if err := f.normalizer.Normalize(ctx, audioPath, normalizedPath); err != nil {
+			return false, err
+		}
+		audioPath = normalizedPath
+	}
+
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open downloaded audio: %w", err)
+	}
+	defer file.Close()
+
+	key := SongAudioKey(youtubeID)
+	if err := f.storage.UploadFile(ctx, key, file); err != nil {
+		return false, fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+
+	if f.normalizer != nil && f.marker != nil {
+		if err := f.marker.MarkNormalized(youtubeID); err != nil {
+			// Left unmarked, it is only normalized again by the next run
+			log.Printf("[WARN] AudioFetcher: Failed to mark %s normalized: %v", youtubeID, err)
+		}
+	}
+
+	if f.chapters != nil {
+		f.recordChapters(ctx, youtubeID)
+	}
+
+	if f.localCopyDir != "" {
+		if err := copyFile(audioPath, filepath.Join(f.localCopyDir, key)); err != nil {
+			// The upload succeeded, so the song is still playable
+			log.Printf("[WARN] AudioFetcher: Failed to keep local copy of %s: %v", key, err)
+		}
+	}
+
+	return true, nil
+}
+
+// ChapterRecorder stores a song's chapters and the offsets trimming it
+type ChapterRecorder interface {
+	UpdateChapters(youtubeID string, chapters models.Chapters, startOffset, endOffset int) error
+}
+
+// SetChapterRecorder reads each downloaded song's video chapters and records
+// them with the offsets trimmer picks to skip a non-music intro and outro
+func (f *AudioFetcher) SetChapterRecorder(recorder ChapterRecorder, trimmer ChapterTrimmer) {
+	f.chapters = recorder
+	f.trimmer = trimmer
+}
+
+// recordChapters stores the song's chapters and offsets. Failures are only
+// logged, the song plays untrimmed.
+func (f *AudioFetcher) recordChapters(ctx context.Context, youtubeID string) {
+	info, err := f.downloader.GetVideoInfo(ctx, youtubeID)
+	if err != nil {
+		log.Printf("[WARN] AudioFetcher: Failed to read chapters for %s: %v", youtubeID, err)
+		return
+	}
+	if len(info.Chapters) == 0 {
+		return
+	}
+
+	start, end := f.trimmer.Offsets(info.Chapters, info.Duration)
+	if err := f.chapters.UpdateChapters(youtubeID, info.Chapters, start, end); err != nil {
+		log.Printf("[WARN] AudioFetcher: Failed to record chapters for %s: %v", youtubeID, err)
+		return
+	}
+	if start > 0 || end > 0 {
+		log.Printf("[DEBUG] AudioFetcher: Trimming %s to %ds-%ds from its chapters", youtubeID, start, end)
+	}
+}
+
+// downloadVerified downloads the song and checks it with VerifyAudio,
+// downloading it again once if the first file is corrupt
+func (f *AudioFetcher) downloadVerified(ctx context.Context, youtubeID, dir string) (string, error) {
+	var verifyErr error
+	for attempt := 1; attempt <= verifyAttempts; attempt++ {
+		metrics.DownloadsAttempted.Inc()
+		started := time.Now()
+		audioPath, err := f.downloader.DownloadAudio(ctx, youtubeID, dir)
+		metrics.DownloadDuration.Observe(time.Since(started).Seconds())
+		if err != nil {
+			metrics.DownloadsFailed.Inc()
+			return "", err
+		}
+
+		verifyErr = VerifyAudio(audioPath)
+		if verifyErr == nil {
+			return audioPath, nil
+		}
+		metrics.DownloadsFailed.Inc()
+		log.Printf("[WARN] AudioFetcher: Download %d of %s failed verification: %v", attempt, youtubeID, verifyErr)
+		os.Remove(audioPath)
+	}
+	return "", verifyErr
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+)
+
+// memoryStorage keeps uploaded files in memory, keyed like S3
+type memoryStorage struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	uploads []string
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "https://example.com/" + key, nil
+}
+
+func (m *memoryStorage) UploadFile(ctx context.Context, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[key] = data
+	m.uploads = append(m.uploads, key)
+	return nil
+}
+
+func (m *memoryStorage) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) FileExists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[key]
+	return ok, nil
+}
+
+func (m *memoryStorage) FileSize(ctx context.Context, key string) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[key]
+	return int64(len(data)), ok, nil
+}
+
+func (m *memoryStorage) DeleteFile(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, key)
+	return nil
+}
+
+// testMP3 is an empty ID3 tag followed by a single MPEG frame header, just
+// enough to pass VerifyAudio
+const testMP3 = "ID3\x03\x00\x00\x00\x00\x00\x00\xff\xfb\x90\x64audio"
+
+// fileDownloader writes fixed content as the downloaded audio
+type fileDownloader struct {
+	content string
+	err     error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (d *fileDownloader) DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error) {
+	d.mu.Lock()
+	d.calls++
+	d.mu.Unlock()
+	if d.err != nil {
+		return "", d.err
+	}
+	path := filepath.Join(outputDir, videoID+".mp3")
+	return path, os.WriteFile(path, []byte(d.content), 0o644)
+}
+
+func (d *fileDownloader) GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	return &VideoInfo{ID: videoID}, nil
+}
+
+func (d *fileDownloader) IsVideoAvailable(ctx context.Context, videoID string) (bool, error) {
+	return true, nil
+}
+
+// prefixNormalizer marks the file it writes so tests can tell it ran
+type prefixNormalizer struct{}
+
+func (prefixNormalizer) Normalize(ctx context.Context, inputPath, outputPath string) error {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, append([]byte("normalized:"), data...), 0o644)
+}
+
+func TestAudioFetcherUploadsUnderRetrievalKey(t *testing.T) {
+	storage := newMemoryStorage()
+	downloader := &fileDownloader{content: testMP3}
+	fetcher := NewAudioFetcher(downloader, storage, prefixNormalizer{})
+
+	downloaded, err := fetcher.Fetch(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !downloaded {
+		t.Error("Expected the song to be downloaded")
+	}
+
+	if len(storage.uploads) != 1 || storage.uploads[0] != SongAudioKey("abc123") {
+		t.Fatalf("Expected one upload to %s, got %v", SongAudioKey("abc123"), storage.uploads)
+	}
+
+	file, err := storage.GetFile(context.Background(), SongAudioKey("abc123"))
+	if err != nil {
+		t.Fatalf("Uploaded audio is not retrievable: %v", err)
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+	if string(data) != "normalized:"+testMP3 {
+		t.Errorf("Expected normalized audio, got %q", data)
+	}
+}
+
+func TestAudioFetcherSkipsStoredSongs(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.files[SongAudioKey("abc123")] = []byte("audio")
+	downloader := &fileDownloader{content: testMP3}
+	fetcher := NewAudioFetcher(downloader, storage, nil)
+
+	downloaded, err := fetcher.Fetch(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if downloaded {
+		t.Error("Expected stored song not to be downloaded again")
+	}
+	if downloader.calls != 0 {
+		t.Errorf("Expected no downloads, got %d", downloader.calls)
+	}
+}
+
+func TestAudioFetcherReturnsDownloadErrors(t *testing.T) {
+	storage := newMemoryStorage()
+	fetcher := NewAudioFetcher(&fileDownloader{err: ErrVideoUnavailable}, storage, nil)
+
+	_, err := fetcher.Fetch(context.Background(), "gone")
+	if !errors.Is(err, ErrVideoUnavailable) {
+		t.Errorf("Expected ErrVideoUnavailable, got %v", err)
+	}
+	if len(storage.uploads) != 0 {
+		t.Errorf("Expected no uploads, got %v", storage.uploads)
+	}
+}
+
+func TestAudioFetcherLocalCopy(t *testing.T) {
+	tests := []struct {
+		name    string
+		keepDir bool
+	}{
+		{"discarded by default", false},
+		{"kept when configured", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			fetcher := NewAudioFetcher(&fileDownloader{content: testMP3}, storage, nil)
+			dir := t.TempDir()
+			if tt.keepDir {
+				fetcher.SetLocalCopyDir(dir)
+			}
+
+			if _, err := fetcher.Fetch(context.Background(), "abc123"); err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+
+			if _, ok := storage.files[SongAudioKey("abc123")]; !ok {
+				t.Error("Expected the audio to be uploaded")
+			}
+			data, err := os.ReadFile(filepath.Join(dir, SongAudioKey("abc123")))
+			if tt.keepDir {
+				if err != nil || string(data) != testMP3 {
+					t.Errorf("Expected local copy with the audio, got %q (%v)", data, err)
+				}
+			} else if err == nil {
+				t.Error("Expected no local copy")
+			}
+		})
+	}
+}
+
+func TestAudioFetcherRequiresFreeSpace(t *testing.T) {
+	storage := newMemoryStorage()
+	downloader := &fileDownloader{content: testMP3}
+	fetcher := NewAudioFetcher(downloader, storage, nil)
+	fetcher.SetMinFreeSpace(100 << 20)
+	fetcher.freeSpace = func(path string) (uint64, error) {
+		return 10 << 20, nil
+	}
+
+	_, err := fetcher.Fetch(context.Background(), "abc123")
+	if !errors.Is(err, ErrDiskFull) {
+		t.Fatalf("Expected ErrDiskFull, got %v", err)
+	}
+	if downloader.calls != 0 {
+		t.Errorf("Expected the download to be skipped, got %d calls", downloader.calls)
+	}
+
+	// Enough space lets the download through
+	fetcher.freeSpace = func(path string) (uint64, error) {
+		return 200 << 20, nil
+	}
+	if _, err := fetcher.Fetch(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if downloader.calls != 1 {
+		t.Errorf("Expected 1 download, got %d", downloader.calls)
+	}
+}
+
+func TestAudioFetcherRejectsInvalidAudio(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"html error page", "<html>Too many requests</html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			downloader := &fileDownloader{content: tt.content}
+			fetcher := NewAudioFetcher(downloader, storage, nil)
+
+			_, err := fetcher.Fetch(context.Background(), "abc123")
+			if !errors.Is(err, ErrInvalidAudio) {
+				t.Errorf("Expected ErrInvalidAudio, got %v", err)
+			}
+			if len(storage.uploads) != 0 {
+				t.Errorf("Expected no uploads, got %v", storage.uploads)
+			}
+			if downloader.calls != verifyAttempts {
+				t.Errorf("Expected %d download attempts, got %d", verifyAttempts, downloader.calls)
+			}
+		})
+	}
+}
+
+func TestAudioFetcherReplacesEmptyStoredAudio(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.files[SongAudioKey("abc123")] = []byte{}
+	downloader := &fileDownloader{content: testMP3}
+	fetcher := NewAudioFetcher(downloader, storage, nil)
+
+	downloaded, err := fetcher.Fetch(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !downloaded || string(storage.files[SongAudioKey("abc123")]) != testMP3 {
+		t.Error("Expected the empty stored file to be downloaded again")
+	}
+}
+
+// sequenceDownloader writes the next of its contents on each download
+type sequenceDownloader struct {
+	fileDownloader
+	contents []string
+}
+
+func (d *sequenceDownloader) DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error) {
+	d.content = d.contents[d.calls]
+	return d.fileDownloader.DownloadAudio(ctx, videoID, outputDir)
+}
+
+func TestAudioFetcherRedownloadsCorruptAudio(t *testing.T) {
+	storage := newMemoryStorage()
+	downloader := &sequenceDownloader{contents: []string{"truncated", testMP3}}
+	fetcher := NewAudioFetcher(downloader, storage, nil)
+
+	if _, err := fetcher.Fetch(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if downloader.calls != 2 {
+		t.Errorf("Expected 2 downloads, got %d", downloader.calls)
+	}
+	if string(storage.files[SongAudioKey("abc123")]) != testMP3 {
+		t.Error("Expected the verified download to be uploaded")
+	}
+}
+
+func TestAudioFetcherMarksNormalizedUploads(t *testing.T) {
+	songs := repositories.NewInMemorySongRepository()
+	if err := songs.Create(&models.Song{YouTubeID: "abc123", Tags: models.Tags{}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	fetcher := NewAudioFetcher(&fileDownloader{content: testMP3}, newMemoryStorage(), prefixNormalizer{})
+	fetcher.SetNormalizedMarker(songs)
+
+	if _, err := fetcher.Fetch(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	pending, err := songs.GetUnnormalized()
+	if err != nil {
+		t.Fatalf("GetUnnormalized failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected the normalized download to be marked, still pending: %v", pending)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Audio formats song audio may be stored in
+const (
+	AudioFormatMP3  = "mp3"
+	AudioFormatOpus = "opus"
+	AudioFormatOgg  = "ogg"
+	AudioFormatM4A  = "m4a"
+)
+
+// audioContentTypes maps each supported format to the MIME type it is served as
+var audioContentTypes = map[string]string{
+	AudioFormatMP3:  "audio/mpeg",
+	AudioFormatOpus: "audio/ogg",
+	AudioFormatOgg:  "audio/ogg",
+	AudioFormatM4A:  "audio/mp4",
+}
+
+// songAudioFormats are looked for in order when finding a song's audio. MP3
+// comes first since it's what downloads produce.
+var songAudioFormats = []string{AudioFormatMP3, AudioFormatOpus, AudioFormatOgg, AudioFormatM4A}
+
+// SongAudioKeyFor returns the storage key of a song's audio in format
+func SongAudioKeyFor(youtubeID, format string) string {
+	return SongAudioPrefix + youtubeID + "." + format
+}
+
+// AudioContentType returns the MIME type of the audio stored under key,
+// judged by its extension. Unknown extensions are served as MP3, the only
+// format stored before others were supported.
+func AudioContentType(key string) string {
+	format := strings.ToLower(strings.TrimPrefix(path.Ext(key), "."))
+	if contentType, ok := audioContentTypes[format]; ok {
+		return contentType
+	}
+	return audioContentTypes[AudioFormatMP3]
+}
+
+// StoredAudio is a song's audio file in storage
+type StoredAudio struct {
+	Key  string
+	Size int64
+}
+
+// ContentType returns the MIME type to serve the audio as
+func (a *StoredAudio) ContentType() string {
+	return AudioContentType(a.Key)
+}
+
+// FindSongAudio returns a song's audio in whichever supported format it is
+// stored in, or nil when there is none
+func FindSongAudio(ctx context.Context, storage S3ServiceInterface, youtubeID string) (*StoredAudio, error) {
+	for _, format := range songAudioFormats {
+		key := SongAudioKeyFor(youtubeID, format)
+		size, exists, err := storage.FileSize(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", key, err)
+		}
+		if exists {
+			return &StoredAudio{Key: key, Size: size}, nil
+		}
+	}
+	return nil, nil
+}
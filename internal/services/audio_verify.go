@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrInvalidAudio means a downloaded file is empty, truncated or not MP3 audio
+var ErrInvalidAudio = errors.New("invalid audio file")
+
+const (
+	id3HeaderSize    = 10
+	id3FooterFlag    = 0x10
+	mpegHeaderLength = 4
+)
+
+// VerifyAudio checks that the file at path looks like playable MP3 audio:
+// any leading ID3v2 tag must be complete and be followed by a valid MPEG
+// frame header. Errors for bad files wrap ErrInvalidAudio.
+func VerifyAudio(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header := make([]byte, id3HeaderSize)
+	n, err := io.ReadFull(file, header)
+	if n == 0 {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidAudio, path)
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	// Skip the ID3v2 tag to reach the first frame
+	offset := int64(0)
+	if n == id3HeaderSize && string(header[:3]) == "ID3" {
+		offset = id3HeaderSize + int64(syncsafeInt(header[6:10]))
+		if header[5]&id3FooterFlag != 0 {
+			offset += id3HeaderSize
+		}
+	}
+
+	frame := make([]byte, mpegHeaderLength)
+	if _, err := file.ReadAt(frame, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %s is truncated", ErrInvalidAudio, path)
+		}
+		return err
+	}
+	if !isMPEGFrameHeader(frame) {
+		return fmt.Errorf("%w: %s has no MPEG frame header", ErrInvalidAudio, path)
+	}
+	return nil
+}
+
+// syncsafeInt decodes the 28-bit size stored in an ID3v2 header
+func syncsafeInt(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// isMPEGFrameHeader reports whether b starts with a frame sync followed by a
+// version, layer, bitrate and sample rate that aren't reserved values
+func isMPEGFrameHeader(b []byte) bool {
+	if b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (b[1] >> 3) & 0x03
+	layer := (b[1] >> 1) & 0x03
+	bitrate := b[2] >> 4
+	sampleRate := (b[2] >> 2) & 0x03
+	return version != 0x01 && layer != 0x00 && bitrate != 0x00 && bitrate != 0x0F && sampleRate != 0x03
+}
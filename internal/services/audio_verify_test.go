@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyAudio(t *testing.T) {
+	frame := "\xff\xfb\x90\x64"
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid with ID3 tag", testMP3, false},
+		{"valid without tag", frame + "audio", false},
+		{"empty", "", true},
+		{"truncated ID3 tag", "ID3\x03\x00\x00\x00\x00\x01\x00" + frame, true},
+		{"truncated frame header", "\xff\xfb", true},
+		{"non-audio", "<html>Too many requests</html>", true},
+		{"reserved sample rate", "\xff\xfb\x9c\x64audio", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "song.mp3")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			err := VerifyAudio(path)
+			if tt.wantErr && !errors.Is(err, ErrInvalidAudio) {
+				t.Errorf("Expected ErrInvalidAudio, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
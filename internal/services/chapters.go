@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// fillerChapterWords mark chapter titles that aren't music, e.g. "Intro" or
+// "Outro / credits"
+var fillerChapterWords = map[string]bool{
+	"intro":        true,
+	"introduction": true,
+	"outro":        true,
+	"skit":         true,
+	"interview":    true,
+	"credits":      true,
+	"talking":      true,
+	"sponsor":      true,
+}
+
+// ChapterTrimmer picks offsets that skip a song's non-music intro and outro
+// from its video chapters. It errs towards playing too much: only a first or
+// last chapter titled as filler is trimmed, and only when it is short and
+// most of the song is left.
+type ChapterTrimmer struct {
+	// MaxTrim is the longest intro or outro chapter that is trimmed
+	MaxTrim time.Duration
+}
+
+// Offsets returns the start and end offsets in seconds for a song of
+// duration seconds, zero for an end that isn't trimmed
+func (t ChapterTrimmer) Offsets(chapters models.Chapters, duration int) (start, end int) {
+	if len(chapters) < 2 || duration <= 0 {
+		return 0, 0
+	}
+
+	first, last := chapters[0], chapters[len(chapters)-1]
+	if t.trims(first) {
+		start = int(math.Round(first.End))
+	}
+	if t.trims(last) && last.Start < float64(duration) {
+		end = int(math.Round(last.Start))
+	}
+
+	played := duration - start
+	if end > 0 {
+		played = end - start
+	}
+	if played < duration/2 {
+		return 0, 0
+	}
+	return start, end
+}
+
+// trims reports whether chapter is short filler to skip
+func (t ChapterTrimmer) trims(chapter models.Chapter) bool {
+	length := time.Duration((chapter.End - chapter.Start) * float64(time.Second))
+	if length <= 0 || length > t.MaxTrim {
+		return false
+	}
+	return isFillerChapter(chapter.Title)
+}
+
+// isFillerChapter reports whether a chapter title names non-music filler
+func isFillerChapter(title string) bool {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if fillerChapterWords[word] {
+			return true
+		}
+	}
+	return false
+}
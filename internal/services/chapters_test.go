@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+)
+
+// sampleChapterInfo is trimmed yt-dlp --dump-json output for a music video
+// with a spoken intro and a credits outro
+const sampleChapterInfo = `{
+	"id": "abc123",
+	"title": "Band - Song (Official Video)",
+	"uploader": "Band",
+	"duration": 245.0,
+	"chapters": [
+		{"start_time": 0.0, "end_time": 21.5, "title": "Intro (talking)"},
+		{"start_time": 21.5, "end_time": 120.0, "title": "Verse"},
+		{"start_time": 120.0, "end_time": 228.0, "title": "Chorus"},
+		{"start_time": 228.0, "end_time": 245.0, "title": "Outro / Credits"}
+	]
+}`
+
+func TestYtDlpServiceGetVideoInfoChapters(t *testing.T) {
+	binary := writeFakeBinary(t, "cat <<'EOF'\n"+sampleChapterInfo+"\nEOF\n")
+	service := NewYtDlpServiceWithBinary(binary)
+
+	info, err := service.GetVideoInfo(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(info.Chapters) != 4 {
+		t.Fatalf("Expected 4 chapters, got %+v", info.Chapters)
+	}
+	if want := (models.Chapter{Title: "Intro (talking)", Start: 0, End: 21.5}); info.Chapters[0] != want {
+		t.Errorf("Expected first chapter %+v, got %+v", want, info.Chapters[0])
+	}
+
+	trimmer := ChapterTrimmer{MaxTrim: 90 * time.Second}
+	if start, end := trimmer.Offsets(info.Chapters, info.Duration); start != 22 || end != 228 {
+		t.Errorf("Expected offsets 22-228, got %d-%d", start, end)
+	}
+}
+
+func TestChapterTrimmerOffsets(t *testing.T) {
+	trimmer := ChapterTrimmer{MaxTrim: 60 * time.Second}
+	tests := []struct {
+		name      string
+		chapters  models.Chapters
+		duration  int
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name:     "no chapters",
+			duration: 200,
+		},
+		{
+			name:     "single chapter",
+			chapters: models.Chapters{{Title: "Intro", Start: 0, End: 200}},
+			duration: 200,
+		},
+		{
+			name:      "intro only",
+			chapters:  models.Chapters{{Title: "Intro", Start: 0, End: 15}, {Title: "Song", Start: 15, End: 200}},
+			duration:  200,
+			wantStart: 15,
+		},
+		{
+			name:     "outro only",
+			chapters: models.Chapters{{Title: "Song", Start: 0, End: 180}, {Title: "outro", Start: 180, End: 200}},
+			duration: 200,
+			wantEnd:  180,
+		},
+		{
+			name:     "musical chapter titles are kept",
+			chapters: models.Chapters{{Title: "Introspection", Start: 0, End: 30}, {Title: "Verse", Start: 30, End: 200}},
+			duration: 200,
+		},
+		{
+			name:     "intro longer than the limit is kept",
+			chapters: models.Chapters{{Title: "Intro", Start: 0, End: 75}, {Title: "Song", Start: 75, End: 300}},
+			duration: 300,
+		},
+		{
+			name: "trimming most of the song is refused",
+			chapters: models.Chapters{
+				{Title: "Skit", Start: 0, End: 50},
+				{Title: "Song", Start: 50, End: 80},
+				{Title: "Interview", Start: 80, End: 120},
+			},
+			duration: 120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := trimmer.Offsets(tt.chapters, tt.duration)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("Offsets() = %d-%d, want %d-%d", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// chapterDownloader is a fileDownloader whose videos have chapters
+type chapterDownloader struct {
+	fileDownloader
+	info *VideoInfo
+}
+
+func (d *chapterDownloader) GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	return d.info, nil
+}
+
+func TestAudioFetcherRecordsChapters(t *testing.T) {
+	songs := repositories.NewInMemorySongRepository()
+	if err := songs.Create(&models.Song{YouTubeID: "abc123", Duration: 200, Tags: models.Tags{}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	chapters := models.Chapters{{Title: "Intro", Start: 0, End: 12}, {Title: "Song", Start: 12, End: 200}}
+	downloader := &chapterDownloader{
+		fileDownloader: fileDownloader{content: testMP3},
+		info:           &VideoInfo{ID: "abc123", Duration: 200, Chapters: chapters},
+	}
+	fetcher := NewAudioFetcher(downloader, newMemoryStorage(), nil)
+	fetcher.SetChapterRecorder(songs, ChapterTrimmer{MaxTrim: time.Minute})
+
+	if _, err := fetcher.Fetch(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	song, _ := songs.GetByYouTubeID("abc123")
+	if len(song.Chapters) != 2 || song.StartOffset != 12 || song.EndOffset != 0 {
+		t.Errorf("Expected the chapters and a 12s start offset, got %+v", song)
+	}
+	if got := song.PlayedDuration(); got != 188 {
+		t.Errorf("Expected 188s to play, got %d", got)
+	}
+}
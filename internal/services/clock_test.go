@@ -0,0 +1,91 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when the test calls Advance
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{c: make(chan time.Time), stop: make(chan struct{})}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance moves time forward and ticks every ticker twice. Ticks are
+// unbuffered, so when Advance returns the tick at the new time has been
+// fully handled by the receiving loop.
+func (c *fakeClock) Advance(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	c.waitForTicker(t)
+
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	tickers := append([]*fakeTicker(nil), c.tickers...)
+	c.mu.Unlock()
+
+	for i := 0; i < 2; i++ {
+		for _, ticker := range tickers {
+			select {
+			case ticker.c <- now:
+			case <-ticker.stop:
+			case <-time.After(time.Second):
+				t.Fatal("Timed out delivering a tick")
+			}
+		}
+	}
+}
+
+// waitForTicker blocks until the code under test has created a ticker
+func (c *fakeClock) waitForTicker(t *testing.T) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.mu.Lock()
+		count := len(c.tickers)
+		c.mu.Unlock()
+		if count > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for a ticker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type fakeTicker struct {
+	c        chan time.Time
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// DefaultDiscordDebounce is how long the song must stay unchanged before it
+// is announced, so a run of skips posts only the song it lands on
+const DefaultDiscordDebounce = 5 * time.Second
+
+// discordEmbedColor is the accent bar shown beside the embed
+const discordEmbedColor = 0x5865F2
+
+// discordWebhookPayload is the body of a Discord "execute webhook" request
+type discordWebhookPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Thumbnail   *discordImage  `json:"thumbnail,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordImage struct {
+	URL string `json:"url"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// DiscordNotifier posts a Discord embed for each song that plays. Song
+// changes within the debounce window replace the pending post.
+type DiscordNotifier struct {
+	webhookURL string
+	debounce   time.Duration
+	client     *http.Client
+
+	mu      sync.Mutex
+	pending *time.Timer
+}
+
+// NewDiscordNotifier creates a notifier; a non-positive debounce posts immediately
+func NewDiscordNotifier(webhookURL string, debounce time.Duration) *DiscordNotifier {
+	return &DiscordNotifier{
+		webhookURL: webhookURL,
+		debounce:   max(debounce, 0),
+		client:     &http.Client{Timeout: nowPlayingWebhookTimeout},
+	}
+}
+
+// Subscribe announces songs from the song_change events published on bus
+func (n *DiscordNotifier) Subscribe(bus events.Subscriber) error {
+	_, err := events.SubscribeTyped(bus, events.EventSongChange, func(e events.SongChangeEvent) {
+		n.SongChanged(e.CurrentSong, e.Playlist)
+	})
+	return err
+}
+
+// SongChanged schedules an announcement of song once the debounce window
+// passes without another change
+func (n *DiscordNotifier) SongChanged(song *models.Song, playlist *models.Playlist) {
+	if song == nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pending != nil {
+		n.pending.Stop()
+	}
+	n.pending = time.AfterFunc(n.debounce, func() {
+		if err := n.post(song, playlist); err != nil {
+			log.Printf("[ERROR] DiscordNotifier: Failed to announce %s: %v", song.YouTubeID, err)
+		}
+	})
+}
+
+// Stop cancels any announcement that hasn't been sent yet
+func (n *DiscordNotifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pending != nil {
+		n.pending.Stop()
+	}
+}
+
+func (n *DiscordNotifier) post(song *models.Song, playlist *models.Playlist) error {
+	body, err := json.Marshal(discordWebhookPayload{Embeds: []discordEmbed{songEmbed(song, playlist, time.Now())}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nowPlayingWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// songEmbed describes a song as a Discord embed linking to its video
+func songEmbed(song *models.Song, playlist *models.Playlist, now time.Time) discordEmbed {
+	embed := discordEmbed{
+		Title:     song.Title,
+		URL:       "https://www.youtube.com/watch?v=" + song.YouTubeID,
+		Color:     discordEmbedColor,
+		Thumbnail: &discordImage{URL: "https://i.ytimg.com/vi/" + song.YouTubeID + "/hqdefault.jpg"},
+		Timestamp: now.UTC().Format(time.RFC3339),
+	}
+	if artist := models.LibraryName(song.Artist); artist != models.UnknownName {
+		embed.Description = artist
+	}
+	if song.Duration > 0 {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Length",
+			Value:  fmt.Sprintf("%d:%02d", song.Duration/60, song.Duration%60),
+			Inline: true,
+		})
+	}
+	if playlist != nil && playlist.Name != "" {
+		embed.Fields = append(embed.Fields, discordField{Name: "Playlist", Value: playlist.Name, Inline: true})
+	}
+	return embed
+}
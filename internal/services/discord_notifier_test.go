@@ -0,0 +1,84 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func TestDiscordNotifierPostsDebouncedEmbed(t *testing.T) {
+	received := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+		received <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	eventBus := events.NewEventBus()
+	notifier := NewDiscordNotifier(server.URL, 100*time.Millisecond)
+	defer notifier.Stop()
+	if err := notifier.Subscribe(eventBus.Ordered()); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// Rapid skips land on the last song, which is the only one announced
+	playlist := createTestPlaylist("1", "Late Night")
+	for _, song := range []*models.Song{
+		createTestSong("song1", "First", "Artist", 100),
+		createTestSong("song2", "Second", "Artist", 100),
+		createTestSong("dQw4w9WgXcQ", "Roygbiv", "Boards of Canada", 150),
+	} {
+		eventBus.PublishSongChange(song, nil, &models.QueueInfo{Queue: []*models.Song{song}, Playlist: playlist})
+	}
+
+	var payload map[string]interface{}
+	select {
+	case payload = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for webhook call")
+	}
+
+	embeds, ok := payload["embeds"].([]interface{})
+	if !ok || len(embeds) != 1 {
+		t.Fatalf("Expected one embed, got %v", payload["embeds"])
+	}
+	embed := embeds[0].(map[string]interface{})
+	if embed["title"] != "Roygbiv" || embed["description"] != "Boards of Canada" {
+		t.Errorf("Expected title and artist of the last song, got %v and %v", embed["title"], embed["description"])
+	}
+	if embed["url"] != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("Unexpected url %v", embed["url"])
+	}
+	thumbnail, _ := embed["thumbnail"].(map[string]interface{})
+	if thumbnail["url"] != "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg" {
+		t.Errorf("Unexpected thumbnail %v", embed["thumbnail"])
+	}
+	fields, _ := embed["fields"].([]interface{})
+	if len(fields) != 2 {
+		t.Fatalf("Expected length and playlist fields, got %v", embed["fields"])
+	}
+	if length := fields[0].(map[string]interface{}); length["value"] != "2:30" {
+		t.Errorf("Expected length 2:30, got %v", length["value"])
+	}
+	if name := fields[1].(map[string]interface{}); name["value"] != "Late Night" {
+		t.Errorf("Expected playlist Late Night, got %v", name["value"])
+	}
+	if _, err := time.Parse(time.RFC3339, embed["timestamp"].(string)); err != nil {
+		t.Errorf("Expected an RFC 3339 timestamp, got %v", embed["timestamp"])
+	}
+
+	select {
+	case extra := <-received:
+		t.Errorf("Expected a single webhook call, got another: %v", extra)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
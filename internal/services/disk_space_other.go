@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package services
+
+import "errors"
+
+// availableDiskSpace is not implemented on this platform, so the free space
+// preflight is skipped
+func availableDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("checking free disk space is not supported on this platform")
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+// Download failure causes. Errors returned by downloaders wrap one of these
+// when the cause could be identified, so callers can use errors.Is to tell a
+// permanently unavailable video from a failure worth retrying.
+var (
+	// ErrVideoUnavailable means the video was removed, made private or never existed
+	ErrVideoUnavailable = errors.New("video unavailable")
+	// ErrGeoBlocked means the video isn't available from the server's region
+	ErrGeoBlocked = errors.New("video is geo-blocked")
+	// ErrAgeRestricted means the video can only be fetched with cookies from a signed-in account
+	ErrAgeRestricted = errors.New("age-restricted video, cookies required")
+	// ErrDownloadNetwork means the download failed because of a network problem
+	ErrDownloadNetwork = errors.New("network error while downloading")
+	// ErrDiskFull means there was no space left to write the download
+	ErrDiskFull = errors.New("no space left on device")
+)
+
+// downloadErrorPatterns maps lowercase stderr fragments to failure causes.
+// Order matters: the first matching cause wins.
+var downloadErrorPatterns = []struct {
+	cause    error
+	patterns []string
+}{
+	{ErrAgeRestricted, []string{
+		"sign in to confirm your age",
+		"age-restricted",
+		"members-only",
+		"join this channel",
+	}},
+	{ErrGeoBlocked, []string{
+		"available in your country",
+		"blocked it in your country",
+		"geo restriction",
+		"geo-restricted",
+	}},
+	{ErrVideoUnavailable, []string{
+		"video unavailable",
+		"private video",
+		"this video has been removed",
+		"this video is no longer available",
+		"account associated with this video has been terminated",
+		"does not exist",
+	}},
+	{ErrDiskFull, []string{
+		"no space left on device",
+	}},
+	{ErrDownloadNetwork, []string{
+		"unable to download webpage",
+		"urlopen error",
+		"connection reset",
+		"connection refused",
+		"timed out",
+		"temporary failure in name resolution",
+		"name or service not known",
+		"network is unreachable",
+		"http error 5",
+	}},
+}
+
+// classifyDownloadError maps yt-dlp's stderr to a failure cause, or nil if
+// no known pattern matched
+func classifyDownloadError(stderr string) error {
+	lower := strings.ToLower(stderr)
+	for _, entry := range downloadErrorPatterns {
+		for _, pattern := range entry.patterns {
+			if strings.Contains(lower, pattern) {
+				return entry.cause
+			}
+		}
+	}
+	return nil
+}
+
+// IsPermanentDownloadError reports whether retrying the download is pointless
+// without changing the video or the server's configuration
+func IsPermanentDownloadError(err error) bool {
+	return errors.Is(err, ErrVideoUnavailable) ||
+		errors.Is(err, ErrGeoBlocked) ||
+		errors.Is(err, ErrAgeRestricted)
+}
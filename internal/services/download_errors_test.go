@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClassifyDownloadError(t *testing.T) {
+	tests := []struct {
+		name     string
+		stderr   string
+		expected error
+	}{
+		{
+			name:     "unavailable",
+			stderr:   "ERROR: [youtube] dQw4w9WgXcQ: Video unavailable",
+			expected: ErrVideoUnavailable,
+		},
+		{
+			name:     "private",
+			stderr:   "ERROR: [youtube] abc123: Private video. Sign in if you've been granted access to this video",
+			expected: ErrVideoUnavailable,
+		},
+		{
+			name:     "removed",
+			stderr:   "ERROR: [youtube] abc123: This video has been removed for violating YouTube's Terms of Service",
+			expected: ErrVideoUnavailable,
+		},
+		{
+			name:     "geo blocked",
+			stderr:   "ERROR: [youtube] abc123: The uploader has not made this video available in your country",
+			expected: ErrGeoBlocked,
+		},
+		{
+			name:     "geo blocked on copyright grounds",
+			stderr:   "ERROR: [youtube] abc123: Video unavailable. This video contains content from SME, who has blocked it in your country on copyright grounds",
+			expected: ErrGeoBlocked,
+		},
+		{
+			name:     "age restricted",
+			stderr:   "ERROR: [youtube] abc123: Sign in to confirm your age. This video may be inappropriate for some users.",
+			expected: ErrAgeRestricted,
+		},
+		{
+			name:     "network",
+			stderr:   "ERROR: [youtube] abc123: Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>",
+			expected: ErrDownloadNetwork,
+		},
+		{
+			name:     "server error",
+			stderr:   "ERROR: unable to download video data: HTTP Error 503: Service Unavailable",
+			expected: ErrDownloadNetwork,
+		},
+		{
+			name:     "disk full",
+			stderr:   "ERROR: unable to write data: [Errno 28] No space left on device",
+			expected: ErrDiskFull,
+		},
+		{
+			name:     "unknown",
+			stderr:   "ERROR: something unexpected happened",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyDownloadError(tt.stderr); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDownloadAudioReturnsTypedErrors(t *testing.T) {
+	binary := writeFakeBinary(t, "echo 'ERROR: [youtube] abc123: Video unavailable' >&2; exit 1")
+	service := NewYtDlpServiceWithBinary(binary)
+
+	_, err := service.DownloadAudio(context.Background(), "abc123", t.TempDir())
+	if !errors.Is(err, ErrVideoUnavailable) {
+		t.Fatalf("Expected ErrVideoUnavailable, got %v", err)
+	}
+	if !IsPermanentDownloadError(err) {
+		t.Error("Expected unavailable video to be a permanent failure")
+	}
+
+	binary = writeFakeBinary(t, "echo 'ERROR: Unable to download webpage: connection reset by peer' >&2; exit 1")
+	service = NewYtDlpServiceWithBinary(binary)
+
+	_, err = service.DownloadAudio(context.Background(), "abc123", t.TempDir())
+	if !errors.Is(err, ErrDownloadNetwork) {
+		t.Fatalf("Expected ErrDownloadNetwork, got %v", err)
+	}
+	if IsPermanentDownloadError(err) {
+		t.Error("Expected network failure to be retryable")
+	}
+
+	// A network failure says nothing about whether the video exists
+	if _, err := service.IsVideoAvailable(context.Background(), "abc123"); !errors.Is(err, ErrDownloadNetwork) {
+		t.Errorf("Expected IsVideoAvailable to return the network error, got %v", err)
+	}
+}
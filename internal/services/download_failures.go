@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// DefaultDownloadFailureLimit is how many downloads of a song may fail in a
+// row before the radio drops it for the rest of the session
+const DefaultDownloadFailureLimit = 3
+
+// SetDownloadFailureLimit sets how many consecutive failed downloads take a
+// song out of the queue until restart. A non-positive limit keeps retrying
+// forever. It must be called before StartPlaybackLoop.
+func (s *RadioService) SetDownloadFailureLimit(limit int) {
+	s.downloadFailureLimit = max(limit, 0)
+}
+
+// startOnDownloadedSong downloads the songs in queue in turn until one is in
+// storage and returns the queue starting from it, so one bad download doesn't
+// keep the station off the air. Songs that failed move to the back. It fails
+// only when none of the songs can be downloaded.
+func (s *RadioService) startOnDownloadedSong(queue []*models.Song) ([]*models.Song, error) {
+	if s.audioFetcher == nil {
+		return queue, nil
+	}
+
+	var lastErr error
+	for i, song := range queue {
+		if _, err := s.audioFetcher.Fetch(context.Background(), song.YouTubeID); err != nil {
+			log.Printf("[ERROR] StartPlaybackLoop: Failed to download %s, trying the next song: %v", song.YouTubeID, err)
+			lastErr = err
+			continue
+		}
+		s.mu.Lock()
+		s.downloaded[song.YouTubeID] = true
+		s.mu.Unlock()
+
+		rotated := make([]*models.Song, 0, len(queue))
+		rotated = append(rotated, queue[i:]...)
+		return append(rotated, queue[:i]...), nil
+	}
+	return nil, fmt.Errorf("none of the %d songs could be downloaded: %w", len(queue), lastErr)
+}
+
+// recordFetchResult counts a song's failed downloads, dropping it from the
+// queue once they reach the limit, and clears the count once one succeeds.
+// Running out of disk or network says nothing about the song, so those
+// failures don't count.
+func (s *RadioService) recordFetchResult(youtubeID string, err error) {
+	if errors.Is(err, ErrDiskFull) || errors.Is(err, ErrDownloadNetwork) {
+		return
+	}
+
+	s.mu.Lock()
+	if err == nil {
+		delete(s.fetchFailures, youtubeID)
+		s.downloaded[youtubeID] = true
+		s.mu.Unlock()
+		return
+	}
+	delete(s.downloaded, youtubeID)
+
+	s.fetchFailures[youtubeID]++
+	failures := s.fetchFailures[youtubeID]
+	if s.downloadFailureLimit <= 0 || failures < s.downloadFailureLimit {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.fetchFailures, youtubeID)
+	s.disabledSongs[youtubeID] = true
+	log.Printf("[WARN] recordFetchResult: Download of %s failed %d times in a row, dropping it until restart", youtubeID, failures)
+	s.dropFromQueue(youtubeID)
+}
+
+// dropFromQueue removes every copy of a song from the queue and tells
+// listeners. If it was playing, the song after it starts. The caller must
+// hold s.mu, which dropFromQueue releases before publishing.
+func (s *RadioService) dropFromQueue(youtubeID string) {
+	if s.state == nil {
+		s.mu.Unlock()
+		return
+	}
+
+	index := s.state.CurrentSongIndex
+	queue := make([]*models.Song, 0, len(s.state.Queue))
+	newIndex := 0
+	removedCurrent := false
+	for i, song := range s.state.Queue {
+		if song.YouTubeID == youtubeID {
+			removedCurrent = removedCurrent || i == index
+			continue
+		}
+		if i < index {
+			newIndex++
+		}
+		queue = append(queue, song)
+	}
+	if len(queue) == len(s.state.Queue) {
+		s.mu.Unlock()
+		return
+	}
+
+	// Build a new slice since published queue info may still reference the old one
+	s.state.Queue = queue
+	if len(queue) == 0 {
+		playlist := s.state.CurrentPlaylist
+		s.state.CurrentSongIndex = 0
+		s.mu.Unlock()
+
+		log.Printf("[DEBUG] dropFromQueue: No playable songs left, stopping playback")
+		if s.eventBus != nil {
+			s.eventBus.PublishPlaybackStopped(playlist)
+		}
+		return
+	}
+
+	if newIndex >= len(queue) {
+		newIndex = 0
+	}
+	s.state.CurrentSongIndex = newIndex
+	if !removedCurrent {
+		s.mu.Unlock()
+		if s.eventBus != nil {
+			s.eventBus.PublishQueueUpdate(s.GetQueueInfo())
+		}
+		return
+	}
+
+	s.state.StartTime = s.clock.Now()
+	s.state.Paused = false
+	currentSong := queue[newIndex]
+	nextSong := nextInQueue(queue, newIndex)
+	s.mu.Unlock()
+
+	s.notifySongChange(currentSong, nextSong)
+}
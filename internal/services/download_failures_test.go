@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// failingFetcher fails every download of one video, the way yt-dlp does for
+// an unavailable one, and succeeds for the rest
+type failingFetcher struct {
+	failID string
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *failingFetcher) Fetch(ctx context.Context, youtubeID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[youtubeID]++
+	if youtubeID == f.failID {
+		return false, fmt.Errorf("yt-dlp failed: %w", ErrVideoUnavailable)
+	}
+	return true, nil
+}
+
+// fetchAndWait downloads song through the radio and waits for the result to
+// be recorded
+func fetchAndWait(t *testing.T, service *RadioService, song *models.Song) {
+	t.Helper()
+
+	service.ensureSongsDownloaded(song)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, inFlight := service.fetching.Load(song.YouTubeID); !inFlight {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s to be fetched", song.YouTubeID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func queueIDs(service *RadioService) []string {
+	var ids []string
+	for _, song := range service.GetQueueInfo().Queue {
+		ids = append(ids, song.YouTubeID)
+	}
+	return ids
+}
+
+func TestDropsSongAfterRepeatedDownloadFailures(t *testing.T) {
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, bus, newFakeClock())
+	service.SetAudioFetcher(&failingFetcher{failID: "bad"})
+	service.SetDownloadFailureLimit(3)
+
+	bad := createTestSong("bad", "Bad", "Artist", 180)
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		bad,
+		createTestSong("song3", "Song 3", "Artist", 180),
+		bad,
+	}
+
+	for i := 0; i < 2; i++ {
+		fetchAndWait(t, service, bad)
+	}
+	if got := queueIDs(service); len(got) != 4 || bus.queueUpdateCount() != 0 {
+		t.Fatalf("Expected the song to stay queued below the limit, got %v", got)
+	}
+
+	fetchAndWait(t, service, bad)
+	if got := queueIDs(service); fmt.Sprint(got) != "[song1 song3]" {
+		t.Errorf("Expected every copy of the song to be dropped, got %v", got)
+	}
+	if current := service.GetCurrentSong(); current == nil || current.YouTubeID != "song1" {
+		t.Errorf("Expected song1 to keep playing, got %v", current)
+	}
+	if bus.queueUpdateCount() != 1 {
+		t.Errorf("Expected 1 queue update, got %d", bus.queueUpdateCount())
+	}
+}
+
+func TestDownloadSuccessResetsFailures(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, &recordingEventBus{})
+	service.SetDownloadFailureLimit(2)
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("flaky", "Flaky", "Artist", 180),
+	}
+
+	failure := fmt.Errorf("yt-dlp failed: %w", ErrVideoUnavailable)
+	for _, err := range []error{failure, nil, failure, fmt.Errorf("out of space: %w", ErrDiskFull)} {
+		service.recordFetchResult("flaky", err)
+	}
+	if got := queueIDs(service); len(got) != 2 {
+		t.Errorf("Expected non-consecutive failures to keep the song queued, got %v", got)
+	}
+
+	service.recordFetchResult("flaky", failure)
+	if got := queueIDs(service); fmt.Sprint(got) != "[song1]" {
+		t.Errorf("Expected the song dropped after 2 failures in a row, got %v", got)
+	}
+}
+
+func TestDroppingCurrentSongAdvances(t *testing.T) {
+	clock := newFakeClock()
+	playlistRepo := NewMockPlaylistRepository()
+	playlist := createTestPlaylist("1", "Test Playlist")
+	songs := []*models.Song{
+		createTestSong("bad", "Bad", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+		createTestSong("song3", "Song 3", "Artist", 180),
+	}
+	playlistRepo.playlists[playlist.ID] = playlist
+	playlistRepo.songs[playlist.ID] = songs
+
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, &recordingEventBus{}, clock)
+	service.SetDownloadFailureLimit(1)
+	service.state.CurrentPlaylist = playlist
+	service.state.Queue = append([]*models.Song(nil), songs...)
+	service.state.StartTime = clock.Now().Add(-time.Minute)
+
+	service.recordFetchResult("bad", fmt.Errorf("yt-dlp failed: %w", ErrGeoBlocked))
+
+	if current := service.GetCurrentSong(); current == nil || current.YouTubeID != "song2" {
+		t.Errorf("Expected song2 to start in place of the dropped song, got %v", current)
+	}
+	if elapsed := service.GetElapsedTime(); elapsed != 0 {
+		t.Errorf("Expected song2 to start from the beginning, got %v elapsed", elapsed)
+	}
+
+	// The song stays out when its playlist is queued again
+	service.SetShuffle(false)
+	if err := service.SetActivePlaylist(playlist.ID); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+	if got := queueIDs(service); fmt.Sprint(got) != "[song2 song3]" {
+		t.Errorf("Expected the dropped song to stay out of the queue, got %v", got)
+	}
+}
+
+func TestStartPlaybackSkipsUndownloadableSongs(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("bad", "Bad", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+		createTestSong("song3", "Song 3", "Artist", 180),
+	}
+
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, newFakeClock())
+	service.SetShuffle(false)
+	service.SetAudioFetcher(&failingFetcher{failID: "bad"})
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Expected playback to start despite the failed download: %v", err)
+	}
+	defer service.StopPlayback()
+
+	if current := service.GetCurrentSong(); current == nil || current.YouTubeID != "song2" {
+		t.Fatalf("Expected playback to start on song2, got %v", current)
+	}
+	if got := queueIDs(service); fmt.Sprint(got) != "[song2 song3 bad]" {
+		t.Errorf("Expected the failed song to move to the back, got %v", got)
+	}
+	if changes := bus.changedSongs(); len(changes) == 0 || changes[0].current.YouTubeID != "song2" {
+		t.Errorf("Expected listeners to be told song2 is playing, got %v", changes)
+	}
+}
+
+func TestStartPlaybackFailsWhenNothingDownloads(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{createTestSong("bad", "Bad", "Artist", 180)}
+
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, &recordingEventBus{}, newFakeClock())
+	service.SetAudioFetcher(&failingFetcher{failID: "bad"})
+
+	if err := service.StartPlaybackLoop(); !errors.Is(err, ErrVideoUnavailable) {
+		t.Errorf("Expected startup to fail with the download error, got %v", err)
+	}
+	if current := service.GetCurrentSong(); current != nil {
+		t.Errorf("Expected nothing to be playing, got %s", current.YouTubeID)
+	}
+}
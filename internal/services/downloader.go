@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/feline-dis/go-radio-v2/internal/config"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// Downloader backends selectable through DOWNLOADER_BACKEND
+const (
+	DownloaderBackendYtDlp     = "ytdlp"
+	DownloaderBackendYoutubeDL = "youtube-dl"
+	DownloaderBackendHTTP      = "http"
+)
+
+// VideoInfo is the metadata a downloader reports for a video
+type VideoInfo struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Uploader string `json:"uploader"`
+	Duration int    `json:"duration"` // seconds
+	IsLive   bool   `json:"is_live"`  // live or upcoming stream
+
+	Chapters models.Chapters `json:"chapters,omitempty"`
+}
+
+// Downloader fetches audio and metadata for YouTube videos
+type Downloader interface {
+	// DownloadAudio downloads the video's audio as mp3 into outputDir and
+	// returns the path of the downloaded file
+	DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error)
+	GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error)
+	IsVideoAvailable(ctx context.Context, videoID string) (bool, error)
+}
+
+// VersionReporter is implemented by downloaders that can report their tool version
+type VersionReporter interface {
+	Version(ctx context.Context) (string, error)
+}
+
+// NewDownloader builds the downloader backend selected in the config
+func NewDownloader(cfg *config.Config) (Downloader, error) {
+	if cfg.Downloader.DownloadTimeout <= 0 {
+		return nil, fmt.Errorf("YTDLP_DOWNLOAD_TIMEOUT must be positive, got %v", cfg.Downloader.DownloadTimeout)
+	}
+	if cfg.Downloader.InfoTimeout <= 0 {
+		return nil, fmt.Errorf("YTDLP_INFO_TIMEOUT must be positive, got %v", cfg.Downloader.InfoTimeout)
+	}
+
+	options := YtDlpOptions{
+		CookiesFile:        cfg.Downloader.CookiesFile,
+		CookiesFromBrowser: cfg.Downloader.CookiesFromBrowser,
+		Proxy:              cfg.YouTube.ProxyURL,
+		DownloadTimeout:    cfg.Downloader.DownloadTimeout,
+		InfoTimeout:        cfg.Downloader.InfoTimeout,
+	}
+
+	switch cfg.Downloader.Backend {
+	case "", DownloaderBackendYtDlp:
+		return NewYtDlpServiceWithOptions("yt-dlp", options), nil
+	case DownloaderBackendYoutubeDL:
+		return NewYtDlpServiceWithOptions("youtube-dl", options), nil
+	case DownloaderBackendHTTP:
+		if cfg.Downloader.ServiceURL == "" {
+			return nil, fmt.Errorf("DOWNLOADER_URL is required for the %s downloader backend", DownloaderBackendHTTP)
+		}
+		return NewHTTPDownloader(cfg.Downloader.ServiceURL), nil
+	default:
+		return nil, fmt.Errorf("unknown downloader backend %q", cfg.Downloader.Backend)
+	}
+}
+
+func videoURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/config"
+)
+
+// writeFakeBinary writes an executable shell script standing in for yt-dlp
+func writeFakeBinary(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fake-ytdlp")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("Failed to write fake binary: %v", err)
+	}
+	return path
+}
+
+func TestNewDownloaderSelectsBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		url     string
+		check   func(t *testing.T, d Downloader)
+		wantErr bool
+	}{
+		{
+			name:    "default is yt-dlp",
+			backend: "",
+			check: func(t *testing.T, d Downloader) {
+				if s, ok := d.(*YtDlpService); !ok || s.binary != "yt-dlp" {
+					t.Errorf("Expected yt-dlp service, got %#v", d)
+				}
+			},
+		},
+		{
+			name:    "ytdlp",
+			backend: DownloaderBackendYtDlp,
+			check: func(t *testing.T, d Downloader) {
+				if s, ok := d.(*YtDlpService); !ok || s.binary != "yt-dlp" {
+					t.Errorf("Expected yt-dlp service, got %#v", d)
+				}
+			},
+		},
+		{
+			name:    "youtube-dl",
+			backend: DownloaderBackendYoutubeDL,
+			check: func(t *testing.T, d Downloader) {
+				if s, ok := d.(*YtDlpService); !ok || s.binary != "youtube-dl" {
+					t.Errorf("Expected youtube-dl service, got %#v", d)
+				}
+			},
+		},
+		{
+			name:    "http",
+			backend: DownloaderBackendHTTP,
+			url:     "http://downloader:8000/",
+			check: func(t *testing.T, d Downloader) {
+				if h, ok := d.(*HTTPDownloader); !ok || h.baseURL != "http://downloader:8000" {
+					t.Errorf("Expected HTTP downloader, got %#v", d)
+				}
+			},
+		},
+		{name: "http without url", backend: DownloaderBackendHTTP, wantErr: true},
+		{name: "unknown", backend: "carrier-pigeon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Downloader: config.DownloaderConfig{
+				Backend:         tt.backend,
+				ServiceURL:      tt.url,
+				DownloadTimeout: 5 * time.Minute,
+				InfoTimeout:     30 * time.Second,
+			}}
+			d, err := NewDownloader(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			tt.check(t, d)
+		})
+	}
+}
+
+func TestYtDlpServiceGetVideoInfo(t *testing.T) {
+	binary := writeFakeBinary(t, `echo '{"id":"abc123","title":"Test Song","uploader":"Test Artist","duration":183.4}'`)
+	service := NewYtDlpServiceWithBinary(binary)
+
+	info, err := service.GetVideoInfo(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if info.ID != "abc123" || info.Title != "Test Song" || info.Uploader != "Test Artist" || info.Duration != 183 {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+}
+
+func TestYtDlpServiceIsVideoAvailable(t *testing.T) {
+	available := NewYtDlpServiceWithBinary(writeFakeBinary(t, "exit 0"))
+	if ok, err := available.IsVideoAvailable(context.Background(), "abc123"); err != nil || !ok {
+		t.Errorf("Expected available video, got %v, %v", ok, err)
+	}
+
+	unavailable := NewYtDlpServiceWithBinary(writeFakeBinary(t, "echo 'ERROR: Video unavailable' >&2; exit 1"))
+	if ok, err := unavailable.IsVideoAvailable(context.Background(), "abc123"); err != nil || ok {
+		t.Errorf("Expected unavailable video without error, got %v, %v", ok, err)
+	}
+
+	missing := NewYtDlpServiceWithBinary(filepath.Join(t.TempDir(), "does-not-exist"))
+	if _, err := missing.IsVideoAvailable(context.Background(), "abc123"); err == nil {
+		t.Error("Expected error for a missing binary")
+	}
+}
+
+func TestYtDlpServiceDownloadAudio(t *testing.T) {
+	// The fake writes to the path given with -o, like yt-dlp does
+	binary := writeFakeBinary(t, `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then out="$2"; fi
+	shift
+done
+echo audio > "$out"
+`)
+	service := NewYtDlpServiceWithBinary(binary)
+
+	dir := t.TempDir()
+	path, err := service.DownloadAudio(context.Background(), "abc123", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != filepath.Join(dir, "abc123.mp3") {
+		t.Errorf("Unexpected download path %s", path)
+	}
+}
+
+func TestYtDlpServiceDownloadAudioRemovesPartialFiles(t *testing.T) {
+	// The fake leaves fragments behind and then fails, like an interrupted download
+	binary := writeFakeBinary(t, `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then out="$2"; fi
+	shift
+done
+echo partial > "$out.part"
+echo partial > "$out"
+echo 'ERROR: Connection reset by peer' >&2
+exit 1
+`)
+	service := NewYtDlpServiceWithBinary(binary)
+
+	dir := t.TempDir()
+	if _, err := service.DownloadAudio(context.Background(), "abc123", dir); !errors.Is(err, ErrDownloadNetwork) {
+		t.Fatalf("Expected ErrDownloadNetwork, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read output dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected partial files to be removed, found %d entries", len(entries))
+	}
+}
+
+// writeArgsRecorder writes a fake binary that records its arguments, one per
+// line, and succeeds. It returns the binary path and the recorded args path.
+func writeArgsRecorder(t *testing.T) (string, string) {
+	t.Helper()
+
+	argsPath := filepath.Join(t.TempDir(), "args")
+	binary := writeFakeBinary(t, `for arg in "$@"; do echo "$arg"; done > "`+argsPath+`"
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-o" ]; then echo audio > "$2"; fi
+	shift
+done
+`)
+	return binary, argsPath
+}
+
+func readArgs(t *testing.T, argsPath string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(argsPath)
+	if err != nil {
+		t.Fatalf("Failed to read recorded args: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func containsArgPair(args []string, flag, value string) bool {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag && args[i+1] == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestYtDlpServiceCookies(t *testing.T) {
+	binary, argsPath := writeArgsRecorder(t)
+	service := NewYtDlpServiceWithOptions(binary, YtDlpOptions{
+		CookiesFile:        "/secrets/cookies.txt",
+		CookiesFromBrowser: "firefox",
+	})
+
+	if _, err := service.DownloadAudio(context.Background(), "abc123", t.TempDir()); err != nil {
+		t.Fatalf("DownloadAudio failed: %v", err)
+	}
+	args := readArgs(t, argsPath)
+	if !containsArgPair(args, "--cookies", "/secrets/cookies.txt") || !containsArgPair(args, "--cookies-from-browser", "firefox") {
+		t.Errorf("Expected cookie flags in download args, got %v", args)
+	}
+
+	if _, err := service.IsVideoAvailable(context.Background(), "abc123"); err != nil {
+		t.Fatalf("IsVideoAvailable failed: %v", err)
+	}
+	if args := readArgs(t, argsPath); !containsArgPair(args, "--cookies", "/secrets/cookies.txt") {
+		t.Errorf("Expected cookies flag in availability args, got %v", args)
+	}
+
+	// Without configuration no cookie flags are passed
+	plain := NewYtDlpServiceWithBinary(binary)
+	if _, err := plain.IsVideoAvailable(context.Background(), "abc123"); err != nil {
+		t.Fatalf("IsVideoAvailable failed: %v", err)
+	}
+	for _, arg := range readArgs(t, argsPath) {
+		if strings.HasPrefix(arg, "--cookies") {
+			t.Errorf("Expected no cookie flags, got %s", arg)
+		}
+	}
+}
+
+func TestYtDlpServiceAgeRestricted(t *testing.T) {
+	binary := writeFakeBinary(t, "echo 'ERROR: [youtube] abc123: Sign in to confirm your age. This video may be inappropriate for some users.' >&2; exit 1")
+	service := NewYtDlpServiceWithBinary(binary)
+
+	if _, err := service.DownloadAudio(context.Background(), "abc123", t.TempDir()); !errors.Is(err, ErrAgeRestricted) {
+		t.Errorf("Expected ErrAgeRestricted from DownloadAudio, got %v", err)
+	}
+	ok, err := service.IsVideoAvailable(context.Background(), "abc123")
+	if ok || !errors.Is(err, ErrAgeRestricted) {
+		t.Errorf("Expected unavailable with ErrAgeRestricted, got %v, %v", ok, err)
+	}
+}
+
+func TestYtDlpServiceProxy(t *testing.T) {
+	binary, argsPath := writeArgsRecorder(t)
+
+	proxied := NewYtDlpServiceWithOptions(binary, YtDlpOptions{Proxy: "socks5://127.0.0.1:1080"})
+	calls := []func() error{
+		func() error { _, err := proxied.DownloadAudio(context.Background(), "abc123", t.TempDir()); return err },
+		func() error { _, err := proxied.IsVideoAvailable(context.Background(), "abc123"); return err },
+	}
+	for _, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if args := readArgs(t, argsPath); !containsArgPair(args, "--proxy", "socks5://127.0.0.1:1080") {
+			t.Errorf("Expected proxy flag, got %v", args)
+		}
+	}
+
+	plain := NewYtDlpServiceWithBinary(binary)
+	if _, err := plain.IsVideoAvailable(context.Background(), "abc123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, arg := range readArgs(t, argsPath) {
+		if arg == "--proxy" {
+			t.Error("Expected no proxy flag when unconfigured")
+		}
+	}
+}
+
+func TestNewDownloaderRejectsNonPositiveTimeouts(t *testing.T) {
+	configs := []config.DownloaderConfig{
+		{Backend: DownloaderBackendYtDlp, DownloadTimeout: 0, InfoTimeout: time.Second},
+		{Backend: DownloaderBackendYtDlp, DownloadTimeout: time.Minute, InfoTimeout: -time.Second},
+	}
+	for _, dc := range configs {
+		if _, err := NewDownloader(&config.Config{Downloader: dc}); err == nil {
+			t.Errorf("Expected error for timeouts %v / %v", dc.DownloadTimeout, dc.InfoTimeout)
+		}
+	}
+
+	d, err := NewDownloader(&config.Config{Downloader: config.DownloaderConfig{
+		DownloadTimeout: 10 * time.Minute,
+		InfoTimeout:     5 * time.Second,
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := d.(*YtDlpService)
+	if service.downloadTimeout != 10*time.Minute || service.infoTimeout != 5*time.Second {
+		t.Errorf("Expected configured timeouts, got %v / %v", service.downloadTimeout, service.infoTimeout)
+	}
+}
+
+func TestYtDlpServiceHonorsTimeouts(t *testing.T) {
+	binary := writeFakeBinary(t, "exec sleep 5")
+	service := NewYtDlpServiceWithOptions(binary, YtDlpOptions{
+		DownloadTimeout: 200 * time.Millisecond,
+		InfoTimeout:     100 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := service.GetVideoInfo(context.Background(), "abc123"); err == nil {
+		t.Error("Expected info lookup to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected info timeout to be honored, took %v", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := service.DownloadAudio(context.Background(), "abc123", t.TempDir()); err == nil {
+		t.Error("Expected download to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected download timeout to be honored, took %v", elapsed)
+	}
+}
+
+func TestYtDlpServiceVersion(t *testing.T) {
+	service := NewYtDlpServiceWithBinary(writeFakeBinary(t, `[ "$1" = "--version" ] && echo "2024.08.06"`))
+
+	version, err := service.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if version != "2024.08.06" {
+		t.Errorf("Expected version 2024.08.06, got %q", version)
+	}
+
+	failing := NewYtDlpServiceWithBinary(writeFakeBinary(t, "exit 1"))
+	if _, err := failing.Version(context.Background()); err == nil {
+		t.Error("Expected error from failing binary")
+	}
+}
+
+func TestIsYtDlpVersionOlder(t *testing.T) {
+	tests := []struct {
+		version, minVersion string
+		expected            bool
+	}{
+		{"2024.08.06", "", false},
+		{"2024.08.06", "2024.08.06", false},
+		{"2024.08.06", "2024.10.01", true},
+		{"2023.12.30", "2024.01.01", true},
+		{"2025.01.15", "2024.10.01", false},
+		{"2024.08.06.232415", "2024.08.06", false},
+		{"2024.8.6", "2024.08.07", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsYtDlpVersionOlder(tt.version, tt.minVersion); got != tt.expected {
+			t.Errorf("IsYtDlpVersionOlder(%q, %q) = %v, expected %v", tt.version, tt.minVersion, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// durationLookupBatchSize is the most video IDs the YouTube API accepts per request
+const durationLookupBatchSize = 50
+
+// ErrBackfillInProgress is returned when a backfill is requested while one is running
+var ErrBackfillInProgress = errors.New("duration backfill already in progress")
+
+// VideoDurationLookup resolves video durations by YouTube ID
+type VideoDurationLookup interface {
+	GetVideoDurations(videoIDs []string) (map[string]time.Duration, error)
+}
+
+// BackfillResult summarises a duration backfill run
+type BackfillResult struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// DurationBackfillService fills in durations for songs stored with a zero duration
+type DurationBackfillService struct {
+	songRepo SongRepositoryInterface
+	lookup   VideoDurationLookup
+	running  sync.Mutex
+}
+
+func NewDurationBackfillService(songRepo SongRepositoryInterface, lookup VideoDurationLookup) *DurationBackfillService {
+	return &DurationBackfillService{
+		songRepo: songRepo,
+		lookup:   lookup,
+	}
+}
+
+// Run looks up and stores the duration of every song without one. Only one
+// run may be active at a time; concurrent calls get ErrBackfillInProgress.
+func (s *DurationBackfillService) Run() (*BackfillResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBackfillInProgress
+	}
+	defer s.running.Unlock()
+
+	songs, err := s.songRepo.GetWithoutDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackfillResult{Checked: len(songs)}
+	for start := 0; start < len(songs); start += durationLookupBatchSize {
+		end := start + durationLookupBatchSize
+		if end > len(songs) {
+			end = len(songs)
+		}
+		batch := songs[start:end]
+
+		ids := make([]string, len(batch))
+		for i, song := range batch {
+			ids[i] = song.YouTubeID
+		}
+
+		durations, err := s.lookup.GetVideoDurations(ids)
+		if err != nil {
+			log.Printf("[ERROR] DurationBackfillService.Run: Failed to look up durations: %v", err)
+			result.Failed += len(batch)
+			continue
+		}
+
+		for _, song := range batch {
+			duration, ok := durations[song.YouTubeID]
+			if !ok {
+				log.Printf("[ERROR] DurationBackfillService.Run: No duration found for %s", song.YouTubeID)
+				result.Failed++
+				continue
+			}
+
+			seconds := int(duration.Seconds())
+			if err := s.songRepo.UpdateDuration(song.YouTubeID, seconds); err != nil {
+				log.Printf("[ERROR] DurationBackfillService.Run: Failed to update %s: %v", song.YouTubeID, err)
+				result.Failed++
+				continue
+			}
+			song.Duration = seconds
+			result.Updated++
+		}
+	}
+
+	log.Printf("Duration backfill finished: %d checked, %d updated, %d failed", result.Checked, result.Updated, result.Failed)
+	return result, nil
+}
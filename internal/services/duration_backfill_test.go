@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockDurationLookup struct {
+	durations map[string]time.Duration
+	err       error
+	calls     [][]string
+}
+
+func (m *mockDurationLookup) GetVideoDurations(videoIDs []string) (map[string]time.Duration, error) {
+	m.calls = append(m.calls, videoIDs)
+	if m.err != nil {
+		return nil, m.err
+	}
+	result := make(map[string]time.Duration)
+	for _, id := range videoIDs {
+		if d, ok := m.durations[id]; ok {
+			result[id] = d
+		}
+	}
+	return result, nil
+}
+
+func TestDurationBackfillRun(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	songRepo.Create(createTestSong("known", "Known", "Artist", 0))
+	songRepo.Create(createTestSong("missing", "Missing", "Artist", 0))
+	songRepo.Create(createTestSong("fine", "Fine", "Artist", 200))
+
+	lookup := &mockDurationLookup{durations: map[string]time.Duration{
+		"known": 3*time.Minute + 5*time.Second,
+	}}
+	service := NewDurationBackfillService(songRepo, lookup)
+
+	result, err := service.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Checked != 2 || result.Updated != 1 || result.Failed != 1 {
+		t.Errorf("Expected 2 checked, 1 updated, 1 failed, got %+v", result)
+	}
+	if songRepo.songs["known"].Duration != 185 {
+		t.Errorf("Expected duration 185, got %d", songRepo.songs["known"].Duration)
+	}
+	if songRepo.songs["missing"].Duration != 0 {
+		t.Errorf("Expected missing song to keep zero duration, got %d", songRepo.songs["missing"].Duration)
+	}
+	if len(lookup.calls) != 1 || len(lookup.calls[0]) != 2 {
+		t.Errorf("Expected one lookup for the two zero-duration songs, got %v", lookup.calls)
+	}
+}
+
+func TestDurationBackfillBatches(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	lookup := &mockDurationLookup{durations: map[string]time.Duration{}}
+	for i := 0; i < durationLookupBatchSize+5; i++ {
+		id := fmt.Sprintf("song%02d", i)
+		songRepo.Create(createTestSong(id, id, "Artist", 0))
+		lookup.durations[id] = time.Minute
+	}
+
+	result, err := NewDurationBackfillService(songRepo, lookup).Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Updated != durationLookupBatchSize+5 {
+		t.Errorf("Expected %d updated, got %d", durationLookupBatchSize+5, result.Updated)
+	}
+	if len(lookup.calls) != 2 {
+		t.Errorf("Expected 2 lookup batches, got %d", len(lookup.calls))
+	}
+}
+
+func TestDurationBackfillLookupError(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	songRepo.Create(createTestSong("song1", "Song 1", "Artist", 0))
+
+	lookup := &mockDurationLookup{err: errors.New("quota exceeded")}
+	result, err := NewDurationBackfillService(songRepo, lookup).Run()
+	if err != nil {
+		t.Fatalf("Expected lookup errors to be counted, not returned: %v", err)
+	}
+	if result.Failed != 1 || result.Updated != 0 {
+		t.Errorf("Expected 1 failed and 0 updated, got %+v", result)
+	}
+}
+
+func TestDurationBackfillInProgress(t *testing.T) {
+	service := NewDurationBackfillService(NewMockSongRepository(), &mockDurationLookup{})
+
+	service.running.Lock()
+	defer service.running.Unlock()
+
+	if _, err := service.Run(); !errors.Is(err, ErrBackfillInProgress) {
+		t.Errorf("Expected ErrBackfillInProgress, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// ErrAlreadyPlaying is returned when queueing the song that is playing
+var ErrAlreadyPlaying = errors.New("song is already playing")
+
+// SongCreator adds songs to the library
+type SongCreator interface {
+	Create(song *models.Song) error
+}
+
+// SetVideoInfo lets EnqueueSong queue songs that aren't in the library yet,
+// looking them up with info and adding them to library
+func (s *RadioService) SetVideoInfo(info VideoInfoFetcher, library SongCreator) {
+	s.videoInfo = info
+	s.library = library
+}
+
+// EnqueueSong queues a song to play after the current one, moving it up if
+// it is already queued. Songs missing from the library are looked up and
+// added to it. A song whose audio isn't in storage yet is downloaded in the
+// background and only joins the queue once the download succeeds, so it
+// never starts playing without audio; pending reports that it is waiting.
+func (s *RadioService) EnqueueSong(youtubeID string) (pending bool, err error) {
+	s.mu.RLock()
+	playing := s.state != nil && len(s.state.Queue) > 0
+	downloaded := s.downloaded[youtubeID]
+	s.mu.RUnlock()
+	if !playing {
+		return false, ErrNothingPlaying
+	}
+
+	song, err := s.findOrAddSong(youtubeID)
+	if err != nil {
+		return false, err
+	}
+
+	if downloaded || s.audioFetcher == nil {
+		return false, s.queueAfterCurrent(song)
+	}
+
+	// A predownload of the same song may already be running, in which case
+	// this waits for it instead of starting another
+	fetch := s.fetchSong(youtubeID)
+	go func() {
+		<-fetch.done
+		if fetch.err != nil {
+			log.Printf("[ERROR] EnqueueSong: Failed to download %s, not queueing it: %v", youtubeID, fetch.err)
+			return
+		}
+		if err := s.queueAfterCurrent(song); err != nil {
+			log.Printf("[WARN] EnqueueSong: Downloaded %s but couldn't queue it: %v", youtubeID, err)
+		}
+	}()
+	return true, nil
+}
+
+// findOrAddSong returns the library song, looking it up with the video info
+// fetcher and adding it to the library when it isn't there
+func (s *RadioService) findOrAddSong(youtubeID string) (*models.Song, error) {
+	song, err := s.songRepo.GetByYouTubeID(youtubeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song: %w", err)
+	}
+	if song != nil {
+		return song, nil
+	}
+	if s.videoInfo == nil || s.library == nil {
+		return nil, ErrSongNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), videoInfoTimeout)
+	defer cancel()
+	info, err := s.videoInfo.GetVideoInfo(ctx, youtubeID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSongNotFound, err)
+	}
+	song = videoDetails{
+		ID:       youtubeID,
+		Title:    info.Title,
+		Artist:   strings.TrimSuffix(info.Uploader, " - Topic"),
+		Duration: time.Duration(info.Duration) * time.Second,
+	}.song()
+	if err := s.library.Create(song); err != nil {
+		return nil, fmt.Errorf("failed to add song to the library: %w", err)
+	}
+	log.Printf("[DEBUG] EnqueueSong: Added %s (%s) to the library", song.YouTubeID, song.Title)
+	return song, nil
+}
+
+// queueAfterCurrent puts song right after the current one, dropping any
+// other copy of it from the queue, and tells listeners
+func (s *RadioService) queueAfterCurrent(song *models.Song) error {
+	s.mu.Lock()
+	if s.state == nil || len(s.state.Queue) == 0 {
+		s.mu.Unlock()
+		return ErrNothingPlaying
+	}
+	index := min(max(s.state.CurrentSongIndex, 0), len(s.state.Queue)-1)
+	if s.state.Queue[index].YouTubeID == song.YouTubeID {
+		s.mu.Unlock()
+		return ErrAlreadyPlaying
+	}
+
+	// Build a new slice since published queue info may still reference the old one
+	queue := make([]*models.Song, 0, len(s.state.Queue)+1)
+	newIndex := index
+	for i, queued := range s.state.Queue {
+		if queued.YouTubeID == song.YouTubeID {
+			if i < index {
+				newIndex--
+			}
+			continue
+		}
+		queue = append(queue, queued)
+		if i == index {
+			queue = append(queue, song)
+		}
+	}
+	s.state.Queue = queue
+	s.state.CurrentSongIndex = newIndex
+	s.mu.Unlock()
+
+	log.Printf("[DEBUG] EnqueueSong: Queued %s (%s) to play next", song.YouTubeID, song.Title)
+	s.ensureSongsDownloaded(song)
+	if s.eventBus != nil {
+		s.eventBus.PublishQueueUpdate(s.GetQueueInfo())
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func newEnqueueTestService(bus *recordingEventBus) (*RadioService, *MockSongRepository) {
+	songRepo := NewMockSongRepository()
+	service := NewRadioService(songRepo, NewMockPlaylistRepository(), &MockS3Service{}, bus)
+	for _, id := range []string{"song1", "song2", "song3", "song4"} {
+		song := createTestSong(id, "Song", "Artist", 180)
+		songRepo.Create(song)
+		service.state.Queue = append(service.state.Queue, song)
+	}
+	service.state.CurrentSongIndex = 1
+	return service, songRepo
+}
+
+func TestEnqueueSongMovesQueuedSongsUp(t *testing.T) {
+	bus := &recordingEventBus{}
+	service, _ := newEnqueueTestService(bus)
+
+	// A later copy moves up to play next rather than being duplicated
+	if pending, err := service.EnqueueSong("song4"); err != nil || pending {
+		t.Fatalf("Expected song4 queued straight away, got pending %v, %v", pending, err)
+	}
+	if got := fmt.Sprint(queueIDs(service)); got != "[song1 song2 song4 song3]" {
+		t.Errorf("Expected song4 after the current song, got %s", got)
+	}
+
+	// An earlier copy moves too, keeping the current song playing
+	if _, err := service.EnqueueSong("song1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(queueIDs(service)); got != "[song2 song1 song4 song3]" {
+		t.Errorf("Expected song1 after the current song, got %s", got)
+	}
+	if current := service.GetCurrentSong(); current == nil || current.YouTubeID != "song2" {
+		t.Errorf("Expected song2 to keep playing, got %+v", current)
+	}
+
+	if _, err := service.EnqueueSong("song2"); !errors.Is(err, ErrAlreadyPlaying) {
+		t.Errorf("Expected ErrAlreadyPlaying for the current song, got %v", err)
+	}
+	if got := bus.queueUpdateCount(); got != 2 {
+		t.Errorf("Expected 2 queue updates, got %d", got)
+	}
+}
+
+func TestEnqueueSongLooksUpSongsMissingFromTheLibrary(t *testing.T) {
+	service, songRepo := newEnqueueTestService(&recordingEventBus{})
+
+	if _, err := service.EnqueueSong("dQw4w9WgXcQ"); !errors.Is(err, ErrSongNotFound) {
+		t.Errorf("Expected ErrSongNotFound without a video info fetcher, got %v", err)
+	}
+
+	service.SetVideoInfo(newFakeVideoInfo(), songRepo)
+	if _, err := service.EnqueueSong("dQw4w9WgXcQ"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	song, _ := songRepo.GetByYouTubeID("dQw4w9WgXcQ")
+	if song == nil || song.Artist != "Rick Astley" || song.Duration != 213 {
+		t.Errorf("Expected the song added to the library, got %+v", song)
+	}
+	if got := fmt.Sprint(queueIDs(service)); got != "[song1 song2 dQw4w9WgXcQ song3 song4]" {
+		t.Errorf("Expected the song after the current one, got %s", got)
+	}
+
+	if _, err := service.EnqueueSong("missing0000"); !errors.Is(err, ErrSongNotFound) {
+		t.Errorf("Expected ErrSongNotFound for an unavailable video, got %v", err)
+	}
+
+	service.state.Queue = []*models.Song{}
+	if _, err := service.EnqueueSong("song1"); !errors.Is(err, ErrNothingPlaying) {
+		t.Errorf("Expected ErrNothingPlaying with an empty queue, got %v", err)
+	}
+}
+
+func TestEnqueueSongWaitsForTheDownload(t *testing.T) {
+	service, songRepo := newEnqueueTestService(&recordingEventBus{})
+	songRepo.Create(createTestSong("extra", "Extra", "Artist", 180))
+	fetcher := &blockingFetcher{started: make(chan string, 10), release: make(chan struct{})}
+	service.SetAudioFetcher(fetcher)
+
+	pending, err := service.EnqueueSong("extra")
+	if err != nil || !pending {
+		t.Fatalf("Expected the song to wait for its download, got pending %v, %v", pending, err)
+	}
+	if id := <-fetcher.started; id != "extra" {
+		t.Fatalf("Expected extra to be downloading, got %s", id)
+	}
+	if got := fmt.Sprint(queueIDs(service)); got != "[song1 song2 song3 song4]" {
+		t.Errorf("Expected the queue unchanged while the song downloads, got %s", got)
+	}
+
+	close(fetcher.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for fmt.Sprint(queueIDs(service)) != "[song1 song2 extra song3 song4]" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the song to be queued, got %v", queueIDs(service))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Songs already in storage are queued straight away
+	songRepo.Create(createTestSong("stored", "Stored", "Artist", 180))
+	service.mu.Lock()
+	service.downloaded["stored"] = true
+	service.mu.Unlock()
+	if pending, err := service.EnqueueSong("stored"); err != nil || pending {
+		t.Errorf("Expected the stored song queued straight away, got pending %v, %v", pending, err)
+	}
+}
+
+func TestEnqueueSongJoinsARunningPredownload(t *testing.T) {
+	service, songRepo := newEnqueueTestService(&recordingEventBus{})
+	extra := createTestSong("extra", "Extra", "Artist", 180)
+	songRepo.Create(extra)
+	fetcher := &blockingFetcher{started: make(chan string, 10), release: make(chan struct{})}
+	service.SetAudioFetcher(fetcher)
+
+	service.ensureSongsDownloaded(extra)
+	<-fetcher.started
+	if pending, err := service.EnqueueSong("extra"); err != nil || !pending {
+		t.Fatalf("Expected the song to wait for its download, got pending %v, %v", pending, err)
+	}
+	select {
+	case id := <-fetcher.started:
+		t.Fatalf("Expected the running download to be shared, %s was fetched again", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(fetcher.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for fmt.Sprint(queueIDs(service)) != "[song1 song2 extra song3 song4]" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the song to be queued, got %v", queueIDs(service))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEnqueueSongDropsFailedDownloads(t *testing.T) {
+	service, songRepo := newEnqueueTestService(&recordingEventBus{})
+	songRepo.Create(createTestSong("broken", "Broken", "Artist", 180))
+	fetcher := &failingFetcher{failID: "broken"}
+	service.SetAudioFetcher(fetcher)
+
+	if _, err := service.EnqueueSong("broken"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		fetcher.mu.Lock()
+		calls := fetcher.calls["broken"]
+		fetcher.mu.Unlock()
+		if calls > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the download")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := fmt.Sprint(queueIDs(service)); got != "[song1 song2 song3 song4]" {
+		t.Errorf("Expected a song that failed to download to stay out of the queue, got %s", got)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HTTPDownloader delegates downloads to an external service exposing
+// GET {base}/audio/{id} (mp3 body) and GET {base}/info/{id} (VideoInfo JSON)
+type HTTPDownloader struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewHTTPDownloader(baseURL string) *HTTPDownloader {
+	return &HTTPDownloader{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// DownloadAudio streams the service's audio response into outputDir
+func (d *HTTPDownloader) DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error) {
+	resp, err := d.get(ctx, "audio", videoID)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download service returned status %d for %s", resp.StatusCode, videoID)
+	}
+
+	outputPath := filepath.Join(outputDir, videoID+".mp3")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", outputPath, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("failed to save audio for %s: %w", videoID, err)
+	}
+	return outputPath, nil
+}
+
+// GetVideoInfo fetches the video's metadata from the service
+func (d *HTTPDownloader) GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	resp, err := d.get(ctx, "info", videoID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download service returned status %d for %s", resp.StatusCode, videoID)
+	}
+
+	var info VideoInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode info for %s: %w", videoID, err)
+	}
+	return &info, nil
+}
+
+// IsVideoAvailable treats a 404 from the info endpoint as unavailable
+func (d *HTTPDownloader) IsVideoAvailable(ctx context.Context, videoID string) (bool, error) {
+	resp, err := d.get(ctx, "info", videoID)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("download service returned status %d for %s", resp.StatusCode, videoID)
+	}
+}
+
+func (d *HTTPDownloader) get(ctx context.Context, endpoint, videoID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/"+endpoint+"/"+url.PathEscape(videoID), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach download service: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImportFilter keeps videos that don't suit the radio out of imports
+type ImportFilter struct {
+	// SkipLive leaves out live and upcoming streams, which have no end
+	SkipLive bool
+	// MinDuration leaves out shorter videos, e.g. 60s for YouTube Shorts.
+	// Zero keeps them.
+	MinDuration time.Duration
+}
+
+// SetImportFilter makes imports leave out the videos filter rejects
+func (s *PlaylistService) SetImportFilter(filter ImportFilter) {
+	s.importFilter = filter
+}
+
+// reject returns why the filter leaves video out of an import, or "" to keep it
+func (f ImportFilter) reject(video videoDetails) string {
+	if f.SkipLive && video.Live {
+		return "live stream"
+	}
+	// Videos with no known duration, such as kept live streams, fail later
+	if f.MinDuration > 0 && video.Duration > 0 && video.Duration < f.MinDuration {
+		return fmt.Sprintf("shorter than %s", f.MinDuration)
+	}
+	return ""
+}
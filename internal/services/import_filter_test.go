@@ -0,0 +1,68 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportFilterRejectsLiveStreamsAndShorts(t *testing.T) {
+	info := newFakeVideoInfo()
+	info.videos["jfKfPfyJRdk"] = &VideoInfo{ID: "jfKfPfyJRdk", Title: "lofi hip hop radio", Uploader: "Lofi Girl", IsLive: true}
+	info.videos["aqz-KE-bpKQ"] = &VideoInfo{ID: "aqz-KE-bpKQ", Title: "A short", Uploader: "someone", Duration: 45}
+
+	service := &PlaylistService{youtubeSvc: NewYouTubeServiceWithoutKey()}
+	service.SetVideoInfoFallback(info)
+	videos, err := service.lookupVideos([]string{"jfKfPfyJRdk", "aqz-KE-bpKQ", "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(videos) != 3 {
+		t.Fatalf("Expected 3 videos, got %+v", videos)
+	}
+
+	tests := []struct {
+		name   string
+		filter ImportFilter
+		want   []string
+	}{
+		{"no filter", ImportFilter{}, []string{"", "", ""}},
+		{"live", ImportFilter{SkipLive: true}, []string{"live stream", "", ""}},
+		{"shorts", ImportFilter{SkipLive: true, MinDuration: time.Minute}, []string{"live stream", "shorter than 1m0s", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, video := range videos {
+				if got := tt.filter.reject(video); got != tt.want[i] {
+					t.Errorf("reject(%s) = %q, want %q", video.ID, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetVideoDetailsFlagsLiveBroadcasts(t *testing.T) {
+	body := `{"items":[
+		{"id":"jfKfPfyJRdk","snippet":{"title":"lofi","liveBroadcastContent":"live"},"contentDetails":{"duration":"P0D"}},
+		{"id":"upcoming000","snippet":{"title":"premiere","liveBroadcastContent":"upcoming"},"contentDetails":{"duration":"P0D"}},
+		{"id":"dQw4w9WgXcQ","snippet":{"title":"song","liveBroadcastContent":"none"},"contentDetails":{"duration":"PT3M33S"}}
+	]}`
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	})
+	youtubeSvc := &YouTubeService{apiKey: "key", httpClient: &http.Client{Transport: transport}}
+
+	videos, err := youtubeSvc.getVideoDetails([]string{"jfKfPfyJRdk", "upcoming000", "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(videos) != 3 || !videos[0].Live || !videos[1].Live || videos[2].Live {
+		t.Errorf("Expected the live and upcoming streams flagged, got %+v", videos)
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// Import line statuses reported by PlaylistService.ImportList
+const (
+	ImportStatusImported = "imported"
+	ImportStatusFailed   = "failed"
+	ImportStatusSkipped  = "skipped"
+)
+
+// ImportLine is one song requested by an import list, with its outcome
+type ImportLine struct {
+	Line      int    `json:"line"`
+	Input     string `json:"input"`
+	YouTubeID string `json:"youtube_id,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Artist    string `json:"artist,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ParseImportList reads one YouTube URL or ID per line, optionally followed
+// by title and artist CSV columns. Blank lines, # comments and a leading
+// header row are skipped. Lines that don't hold a usable ID are returned
+// already marked failed so the caller can report them.
+func ParseImportList(r io.Reader) ([]ImportLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	reader.Comment = '#'
+
+	var lines []ImportLine
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			lines = append(lines, ImportLine{
+				Line:   parseErr.Line,
+				Status: ImportStatusFailed,
+				Error:  "malformed line",
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import list: %w", err)
+		}
+
+		input := strings.TrimSpace(record[0])
+		if input == "" {
+			continue
+		}
+		if first && isImportHeader(input) {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		entry := ImportLine{Line: line, Input: input}
+		if len(record) > 1 {
+			entry.Title = strings.TrimSpace(record[1])
+		}
+		if len(record) > 2 {
+			entry.Artist = strings.TrimSpace(record[2])
+		}
+
+		id, err := ParseYouTubeID(input)
+		if err != nil {
+			entry.Status = ImportStatusFailed
+			entry.Error = "not a YouTube URL or video ID"
+		} else {
+			entry.YouTubeID = id
+		}
+		lines = append(lines, entry)
+	}
+	return lines, nil
+}
+
+func isImportHeader(field string) bool {
+	switch strings.ToLower(field) {
+	case "url", "id", "youtube_id", "video":
+		return true
+	}
+	return false
+}
+
+// ImportList creates a playlist from a parsed import list, feeding every
+// usable line through the concurrent import pipeline, and records each
+// line's outcome in lines
+func (s *PlaylistService) ImportList(name, description string, lines []ImportLine) (*models.Playlist, error) {
+	// Each video is imported once, later repeats are reported as duplicates
+	var songIDs []string
+	firstLine := make(map[string]int)
+	for i := range lines {
+		line := &lines[i]
+		if line.Status == ImportStatusFailed {
+			continue
+		}
+		if first, ok := firstLine[line.YouTubeID]; ok {
+			line.Status = ImportStatusFailed
+			line.Error = fmt.Sprintf("duplicate of line %d", first)
+			continue
+		}
+		firstLine[line.YouTubeID] = line.Line
+		songIDs = append(songIDs, line.YouTubeID)
+	}
+	if err := s.checkPlaylistSize(len(songIDs)); err != nil {
+		return nil, err
+	}
+
+	playlist, err := s.createPlaylist(name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	added, skipped := s.processSongsConcurrently(playlist.ID, songIDs)
+
+	for i := range lines {
+		line := &lines[i]
+		if line.Status == ImportStatusFailed {
+			continue
+		}
+		if reason, ok := skipped[line.YouTubeID]; ok {
+			line.Status = ImportStatusSkipped
+			line.Error = reason
+			continue
+		}
+		if !added[line.YouTubeID] {
+			line.Status = ImportStatusFailed
+			line.Error = "video not found or could not be imported"
+			continue
+		}
+
+		line.Status = ImportStatusImported
+		if line.Title != "" || line.Artist != "" {
+			if err := s.songRepo.UpdateDetails(line.YouTubeID, line.Title, line.Artist); err != nil {
+				// The song is in the playlist, it just keeps YouTube's details
+				log.Printf("[ERROR] ImportList: Failed to update details for %s: %v", line.YouTubeID, err)
+			}
+		}
+	}
+
+	s.notifyChanged(playlist.ID)
+	return playlist, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImportList(t *testing.T) {
+	input := strings.Join([]string{
+		"url,title,artist",
+		"dQw4w9WgXcQ",
+		"",
+		"# a comment",
+		"https://www.youtube.com/watch?v=9bZkp7q19f0&t=42",
+		"https://example.com/not-a-video",
+		"kJQP7kiw5Fk,Despacito,Luis Fonsi",
+		"not an id",
+		`"https://www.youtube.com/watch?v=OPf0YbXqDm0", Uptown Funk,Mark Ronson`,
+	}, "\n")
+
+	lines, err := ParseImportList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []ImportLine{
+		{Line: 2, Input: "dQw4w9WgXcQ", YouTubeID: "dQw4w9WgXcQ"},
+		{Line: 5, Input: "https://www.youtube.com/watch?v=9bZkp7q19f0&t=42", YouTubeID: "9bZkp7q19f0"},
+		{Line: 6, Input: "https://example.com/not-a-video", Status: ImportStatusFailed, Error: "not a YouTube URL or video ID"},
+		{Line: 7, Input: "kJQP7kiw5Fk", YouTubeID: "kJQP7kiw5Fk", Title: "Despacito", Artist: "Luis Fonsi"},
+		{Line: 8, Input: "not an id", Status: ImportStatusFailed, Error: "not a YouTube URL or video ID"},
+		{Line: 9, Input: "https://www.youtube.com/watch?v=OPf0YbXqDm0", YouTubeID: "OPf0YbXqDm0", Title: "Uptown Funk", Artist: "Mark Ronson"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Unexpected lines:\n got %+v\nwant %+v", lines, want)
+	}
+}
+
+func TestParseImportListEmpty(t *testing.T) {
+	lines, err := ParseImportList(strings.NewReader("\n\n# nothing here\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("Expected no lines, got %+v", lines)
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// OrphanStore finds and deletes library songs that no playlist lists
+type OrphanStore interface {
+	GetOrphanedSongs() ([]*models.Song, error)
+	DeleteOrphanedSong(youtubeID string) (bool, error)
+}
+
+// OrphanCleanup reports what DeleteOrphaned removed
+type OrphanCleanup struct {
+	Deleted []string `json:"deleted"`
+	// Failed maps the songs that couldn't be removed to why
+	Failed map[string]string `json:"failed"`
+}
+
+// LibraryCleaner removes songs left in the library after being taken out of
+// every playlist, along with their audio
+type LibraryCleaner struct {
+	store   OrphanStore
+	storage S3ServiceInterface
+}
+
+func NewLibraryCleaner(store OrphanStore, storage S3ServiceInterface) *LibraryCleaner {
+	return &LibraryCleaner{store: store, storage: storage}
+}
+
+// Orphaned returns the songs no playlist lists, oldest first
+func (c *LibraryCleaner) Orphaned() ([]*models.Song, error) {
+	songs, err := c.store.GetOrphanedSongs()
+	if err != nil {
+		return nil, err
+	}
+	if songs == nil {
+		songs = []*models.Song{}
+	}
+	return songs, nil
+}
+
+// DeleteOrphaned deletes every orphaned song and the files stored for it.
+// A song added to a playlist since it was listed is kept. Files are deleted
+// after the song, so a failure leaves stray files rather than a song
+// without its audio.
+func (c *LibraryCleaner) DeleteOrphaned(ctx context.Context) (*OrphanCleanup, error) {
+	songs, err := c.store.GetOrphanedSongs()
+	if err != nil {
+		return nil, err
+	}
+
+	cleanup := &OrphanCleanup{Deleted: []string{}, Failed: map[string]string{}}
+	for _, song := range songs {
+		deleted, err := c.store.DeleteOrphanedSong(song.YouTubeID)
+		if err != nil {
+			log.Printf("[ERROR] DeleteOrphaned: Failed to delete %s: %v", song.YouTubeID, err)
+			cleanup.Failed[song.YouTubeID] = err.Error()
+			continue
+		}
+		if !deleted {
+			continue
+		}
+
+		cleanup.Deleted = append(cleanup.Deleted, song.YouTubeID)
+		if err := c.deleteFiles(ctx, song.YouTubeID); err != nil {
+			log.Printf("[ERROR] DeleteOrphaned: Deleted %s but not all of its files: %v", song.YouTubeID, err)
+			cleanup.Failed[song.YouTubeID] = err.Error()
+		}
+	}
+	return cleanup, nil
+}
+
+// deleteFiles removes a song's audio and everything derived from it
+func (c *LibraryCleaner) deleteFiles(ctx context.Context, youtubeID string) error {
+	keys := []string{PeaksKey(youtubeID), LyricsKey(youtubeID)}
+	for _, format := range songAudioFormats {
+		keys = append(keys, SongAudioKeyFor(youtubeID, format))
+	}
+	for quality := range qualityBitrates {
+		keys = append(keys, TranscodedKey(youtubeID, quality))
+	}
+
+	for _, key := range keys {
+		if err := c.storage.DeleteFile(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
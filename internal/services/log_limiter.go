@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// logLimiter collapses identical log messages logged within a window into a
+// single line, reporting how many repeats were suppressed once the message
+// is logged again after the window.
+type logLimiter struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	lastLogged map[string]time.Time
+	suppressed map[string]int
+}
+
+func newLogLimiter(window time.Duration) *logLimiter {
+	return &logLimiter{
+		window:     window,
+		now:        time.Now,
+		lastLogged: make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// Printf logs the formatted message unless the same message was logged
+// within the window
+func (l *logLimiter) Printf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+
+	l.mu.Lock()
+	now := l.now()
+	if last, ok := l.lastLogged[message]; ok && now.Sub(last) < l.window {
+		l.suppressed[message]++
+		l.mu.Unlock()
+		return
+	}
+
+	suppressed := l.suppressed[message]
+	delete(l.suppressed, message)
+	l.lastLogged[message] = now
+
+	// Forget messages that have gone quiet so the maps don't grow unbounded
+	for m, last := range l.lastLogged {
+		if now.Sub(last) >= l.window && l.suppressed[m] == 0 && m != message {
+			delete(l.lastLogged, m)
+		}
+	}
+	l.mu.Unlock()
+
+	if suppressed > 0 {
+		log.Printf("%s (repeated %d times)", message, suppressed)
+		return
+	}
+	log.Print(message)
+}
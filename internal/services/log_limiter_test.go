@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureLog redirects the standard logger into a buffer for the duration of a test
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+
+	buf := &syncBuffer{}
+	previous := log.Writer()
+	log.SetOutput(buf)
+	t.Cleanup(func() {
+		log.SetOutput(previous)
+	})
+	return buf
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogLimiterCollapsesDuplicates(t *testing.T) {
+	buf := captureLog(t)
+
+	now := time.Now()
+	limiter := newLogLimiter(time.Second)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		limiter.Printf("skipping unplayable song %s", "abc")
+	}
+	limiter.Printf("different message")
+
+	output := buf.String()
+	if count := strings.Count(output, "skipping unplayable song abc"); count != 1 {
+		t.Errorf("Expected duplicate message to be logged once, got %d times:\n%s", count, output)
+	}
+	if !strings.Contains(output, "different message") {
+		t.Error("Expected distinct message to be logged")
+	}
+
+	// Once the window has passed the message is logged again with the suppressed count
+	now = now.Add(2 * time.Second)
+	limiter.Printf("skipping unplayable song %s", "abc")
+
+	output = buf.String()
+	if !strings.Contains(output, "skipping unplayable song abc (repeated 9 times)") {
+		t.Errorf("Expected suppressed count after the window, got:\n%s", output)
+	}
+}
+
+func TestPlaybackLoopDedupesLogs(t *testing.T) {
+	buf := captureLog(t)
+
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, nil)
+	stop := make(chan struct{})
+	defer close(stop)
+	go service.playbackLoop(nil, stop)
+
+	// The loop ticks every 100ms against an empty queue
+	time.Sleep(550 * time.Millisecond)
+
+	if count := strings.Count(buf.String(), "Queue is empty"); count != 1 {
+		t.Errorf("Expected the empty queue message once, got %d times", count)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/config"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/requestid"
+)
+
+// Lyrics providers selectable through LYRICS_PROVIDER
+const (
+	LyricsProviderLRCLib = "lrclib"
+)
+
+// DefaultLRCLibURL is used when LYRICS_API_URL is not set
+const DefaultLRCLibURL = "https://lrclib.net"
+
+// ErrLyricsNotFound is returned when a provider has no lyrics for a song
+var ErrLyricsNotFound = errors.New("lyrics not found")
+
+// LyricsKey returns the storage key used to cache a song's lyrics
+func LyricsKey(youtubeID string) string {
+	return fmt.Sprintf("lyrics/%s.json", youtubeID)
+}
+
+// Lyrics holds a song's plain lyrics and, when the provider has them,
+// LRC-formatted lyrics synced to playback
+type Lyrics struct {
+	Plain  string `json:"plain"`
+	Synced string `json:"synced,omitempty"`
+}
+
+// LyricsProvider looks up lyrics by artist and title. Duration, in seconds,
+// helps pick the right recording and may be zero when unknown.
+type LyricsProvider interface {
+	FindLyrics(ctx context.Context, artist, title string, duration int) (*Lyrics, error)
+}
+
+// NewLyricsProvider returns the provider selected by LYRICS_PROVIDER, or nil
+// when lyrics are not configured
+func NewLyricsProvider(cfg *config.Config) (LyricsProvider, error) {
+	switch cfg.Lyrics.Provider {
+	case "":
+		return nil, nil
+	case LyricsProviderLRCLib:
+		baseURL := cfg.Lyrics.APIURL
+		if baseURL == "" {
+			baseURL = DefaultLRCLibURL
+		}
+		return NewLRCLibProvider(baseURL, cfg.Lyrics.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown lyrics provider %q", cfg.Lyrics.Provider)
+	}
+}
+
+// LRCLibProvider queries an LRCLIB-compatible API via GET {base}/api/get
+type LRCLibProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewLRCLibProvider(baseURL, apiKey string) *LRCLibProvider {
+	return &LRCLibProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// FindLyrics returns ErrLyricsNotFound for unknown and instrumental tracks
+func (p *LRCLibProvider) FindLyrics(ctx context.Context, artist, title string, duration int) (*Lyrics, error) {
+	query := url.Values{}
+	query.Set("artist_name", artist)
+	query.Set("track_name", title)
+	if duration > 0 {
+		query.Set("duration", strconv.Itoa(duration))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/get?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lyrics request: %w", err)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("lyrics request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrLyricsNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lyrics provider returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		PlainLyrics  string `json:"plainLyrics"`
+		SyncedLyrics string `json:"syncedLyrics"`
+		Instrumental bool   `json:"instrumental"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode lyrics: %w", err)
+	}
+	if body.Instrumental || (body.PlainLyrics == "" && body.SyncedLyrics == "") {
+		return nil, ErrLyricsNotFound
+	}
+
+	return &Lyrics{Plain: body.PlainLyrics, Synced: body.SyncedLyrics}, nil
+}
+
+// SongLookup fetches a single song by YouTube ID
+type SongLookup interface {
+	GetByYouTubeID(youtubeID string) (*models.Song, error)
+}
+
+// LyricsService fetches song lyrics from a provider and caches them in storage
+type LyricsService struct {
+	songs    SongLookup
+	provider LyricsProvider
+	storage  S3ServiceInterface
+}
+
+func NewLyricsService(songs SongLookup, provider LyricsProvider, storage S3ServiceInterface) *LyricsService {
+	return &LyricsService{
+		songs:    songs,
+		provider: provider,
+		storage:  storage,
+	}
+}
+
+// GetLyrics returns the cached lyrics for a song, asking the provider and
+// caching the result on first request. Only found lyrics are cached, so songs
+// the provider learns about later are picked up.
+func (s *LyricsService) GetLyrics(ctx context.Context, youtubeID string) (*Lyrics, error) {
+	key := LyricsKey(youtubeID)
+	cached, err := s.storage.FileExists(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cached lyrics: %w", err)
+	}
+	if cached {
+		lyrics, err := s.readCached(ctx, key)
+		if err == nil {
+			return lyrics, nil
+		}
+		// Fall through and refetch, the cache entry will be replaced
+		requestid.Printf(ctx, "[ERROR] GetLyrics: Failed to read cached lyrics for %s: %v", youtubeID, err)
+	}
+
+	song, err := s.songs.GetByYouTubeID(youtubeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song: %w", err)
+	}
+	if song == nil {
+		return nil, ErrSongNotFound
+	}
+
+	lyrics, err := s.provider.FindLyrics(ctx, song.Artist, song.Title, song.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(lyrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lyrics: %w", err)
+	}
+	if err := s.storage.UploadFile(ctx, key, bytes.NewReader(data)); err != nil {
+		// Still return the lyrics, they will just be fetched again next time
+		requestid.Printf(ctx, "[ERROR] GetLyrics: Failed to cache lyrics for %s: %v", youtubeID, err)
+	}
+
+	return lyrics, nil
+}
+
+func (s *LyricsService) readCached(ctx context.Context, key string) (*Lyrics, error) {
+	file, err := s.storage.GetFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lyrics Lyrics
+	if err := json.NewDecoder(file).Decode(&lyrics); err != nil {
+		return nil, err
+	}
+	return &lyrics, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeLyricsProvider serves lyrics keyed by title and counts lookups
+type fakeLyricsProvider struct {
+	lyrics map[string]*Lyrics
+	calls  int
+}
+
+func (f *fakeLyricsProvider) FindLyrics(ctx context.Context, artist, title string, duration int) (*Lyrics, error) {
+	f.calls++
+	lyrics, ok := f.lyrics[title]
+	if !ok {
+		return nil, ErrLyricsNotFound
+	}
+	return lyrics, nil
+}
+
+func newTestLyricsService() (*LyricsService, *fakeLyricsProvider, *memoryStorage) {
+	songRepo := NewMockSongRepository()
+	songRepo.songs["hit"] = createTestSong("hit", "Known Song", "Artist", 180)
+	songRepo.songs["miss"] = createTestSong("miss", "Unknown Song", "Artist", 180)
+
+	provider := &fakeLyricsProvider{lyrics: map[string]*Lyrics{
+		"Known Song": {Plain: "la la la", Synced: "[00:01.00] la la la"},
+	}}
+	storage := newMemoryStorage()
+	return NewLyricsService(songRepo, provider, storage), provider, storage
+}
+
+func TestGetLyricsCachesHits(t *testing.T) {
+	service, provider, storage := newTestLyricsService()
+
+	for i := 0; i < 2; i++ {
+		lyrics, err := service.GetLyrics(context.Background(), "hit")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if lyrics.Plain != "la la la" || lyrics.Synced != "[00:01.00] la la la" {
+			t.Errorf("Unexpected lyrics %+v", lyrics)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("Expected the provider to be asked once, got %d calls", provider.calls)
+	}
+	if _, ok := storage.files[LyricsKey("hit")]; !ok {
+		t.Errorf("Expected lyrics cached under %s", LyricsKey("hit"))
+	}
+}
+
+func TestGetLyricsMiss(t *testing.T) {
+	service, provider, storage := newTestLyricsService()
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.GetLyrics(context.Background(), "miss"); !errors.Is(err, ErrLyricsNotFound) {
+			t.Fatalf("Expected ErrLyricsNotFound, got %v", err)
+		}
+	}
+
+	// Misses aren't cached so lyrics added to the provider later are found
+	if provider.calls != 2 {
+		t.Errorf("Expected the provider to be asked every time, got %d calls", provider.calls)
+	}
+	if len(storage.files) != 0 {
+		t.Errorf("Expected nothing cached, got %v", storage.files)
+	}
+}
+
+func TestGetLyricsUnknownSong(t *testing.T) {
+	service, provider, _ := newTestLyricsService()
+
+	if _, err := service.GetLyrics(context.Background(), "nope"); !errors.Is(err, ErrSongNotFound) {
+		t.Fatalf("Expected ErrSongNotFound, got %v", err)
+	}
+	if provider.calls != 0 {
+		t.Errorf("Expected no provider lookups for an unknown song, got %d", provider.calls)
+	}
+}
+
+func TestLRCLibProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/get" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Expected the API key as a bearer token, got %q", got)
+		}
+
+		switch r.URL.Query().Get("track_name") {
+		case "Known Song":
+			if r.URL.Query().Get("artist_name") != "Artist" || r.URL.Query().Get("duration") != "180" {
+				t.Errorf("Unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"plainLyrics":"la la la","syncedLyrics":"[00:01.00] la la la"}`))
+		case "Instrumental":
+			w.Write([]byte(`{"instrumental":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewLRCLibProvider(server.URL+"/", "secret")
+
+	lyrics, err := provider.FindLyrics(context.Background(), "Artist", "Known Song", 180)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lyrics.Plain != "la la la" || lyrics.Synced != "[00:01.00] la la la" {
+		t.Errorf("Unexpected lyrics %+v", lyrics)
+	}
+
+	for _, title := range []string{"Instrumental", "Unknown Song"} {
+		if _, err := provider.FindLyrics(context.Background(), "Artist", title, 0); !errors.Is(err, ErrLyricsNotFound) {
+			t.Errorf("Expected ErrLyricsNotFound for %q, got %v", title, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// normalizeSongTimeout bounds fetching, normalizing and storing one song
+const normalizeSongTimeout = 10 * time.Minute
+
+// ErrNormalizationInProgress is returned when a normalization run is
+// requested while one is running
+var ErrNormalizationInProgress = errors.New("normalization already in progress")
+
+// NormalizationStore tracks which songs' stored audio is loudness normalized
+type NormalizationStore interface {
+	NormalizedMarker
+	GetUnnormalized() ([]*models.Song, error)
+}
+
+// NormalizationProgress reports on the current or last normalization run
+type NormalizationProgress struct {
+	Running bool `json:"running"`
+	// PlaylistID is the playlist the run was limited to, empty for the library
+	PlaylistID string `json:"playlist_id,omitempty"`
+	Total      int    `json:"total"`
+	Normalized int    `json:"normalized"`
+	// Skipped counts songs with no audio in storage to normalize
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// NormalizationService runs the loudness normalization applied to new
+// downloads over audio stored before normalization existed
+type NormalizationService struct {
+	songs      NormalizationStore
+	playlists  PlaylistSongLister
+	storage    S3ServiceInterface
+	normalizer AudioNormalizer
+
+	mu       sync.Mutex
+	progress NormalizationProgress
+	done     chan struct{}
+}
+
+func NewNormalizationService(songs NormalizationStore, playlists PlaylistSongLister, storage S3ServiceInterface, normalizer AudioNormalizer) *NormalizationService {
+	return &NormalizationService{
+		songs:      songs,
+		playlists:  playlists,
+		storage:    storage,
+		normalizer: normalizer,
+	}
+}
+
+// Start normalizes every song not yet normalized in the background, or only
+// those in playlistID when it isn't empty. Only one run may be active at a
+// time; concurrent calls get ErrNormalizationInProgress.
+func (s *NormalizationService) Start(playlistID string) (NormalizationProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.progress.Running {
+		return s.progress, ErrNormalizationInProgress
+	}
+
+	songs, err := s.pending(playlistID)
+	if err != nil {
+		return NormalizationProgress{}, err
+	}
+
+	now := time.Now()
+	s.progress = NormalizationProgress{
+		Running:    true,
+		PlaylistID: playlistID,
+		Total:      len(songs),
+		StartedAt:  &now,
+	}
+	s.done = make(chan struct{})
+	go s.run(songs, s.done)
+	return s.progress, nil
+}
+
+// Progress returns the state of the current or last run
+func (s *NormalizationService) Progress() NormalizationProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+// pending returns the songs left to normalize, limited to a playlist's
+func (s *NormalizationService) pending(playlistID string) ([]*models.Song, error) {
+	songs, err := s.songs.GetUnnormalized()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get songs to normalize: %w", err)
+	}
+	if playlistID == "" {
+		return songs, nil
+	}
+
+	playlist, err := s.playlists.GetByID(playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+	if playlist == nil {
+		return nil, ErrPlaylistNotFound
+	}
+	ids, err := s.playlists.GetSongIDs(playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist songs: %w", err)
+	}
+
+	listed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+	inPlaylist := make([]*models.Song, 0, len(songs))
+	for _, song := range songs {
+		if listed[song.YouTubeID] {
+			inPlaylist = append(inPlaylist, song)
+		}
+	}
+	return inPlaylist, nil
+}
+
+func (s *NormalizationService) run(songs []*models.Song, done chan struct{}) {
+	defer close(done)
+
+	for _, song := range songs {
+		normalized, err := s.normalizeSong(song.YouTubeID)
+
+		s.mu.Lock()
+		switch {
+		case err != nil:
+			log.Printf("[ERROR] NormalizationService: Failed to normalize %s: %v", song.YouTubeID, err)
+			s.progress.Failed++
+		case !normalized:
+			s.progress.Skipped++
+		default:
+			s.progress.Normalized++
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	s.progress.Running = false
+	s.progress.FinishedAt = &now
+	progress := s.progress
+	s.mu.Unlock()
+
+	log.Printf("Normalization finished: %d normalized, %d skipped, %d failed of %d",
+		progress.Normalized, progress.Skipped, progress.Failed, progress.Total)
+}
+
+// normalizeSong replaces a song's stored audio with a normalized copy in the
+// same format. It reports false when there is no audio to normalize.
+func (s *NormalizationService) normalizeSong(youtubeID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), normalizeSongTimeout)
+	defer cancel()
+
+	audio, err := FindSongAudio(ctx, s.storage, youtubeID)
+	if err != nil {
+		return false, err
+	}
+	if audio == nil {
+		return false, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "go-radio-normalize-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// ffmpeg picks the output format from the extension, so keep the original's
+	ext := path.Ext(audio.Key)
+	inputPath := filepath.Join(tempDir, "original"+ext)
+	outputPath := filepath.Join(tempDir, "normalized"+ext)
+	if err := s.download(ctx, audio.Key, inputPath); err != nil {
+		return false, err
+	}
+	if err := s.normalizer.Normalize(ctx, inputPath, outputPath); err != nil {
+		return false, err
+	}
+
+	normalized, err := os.ReadFile(outputPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read normalized audio: %w", err)
+	}
+	if err := s.storage.UploadFile(ctx, audio.Key, bytes.NewReader(normalized)); err != nil {
+		return false, fmt.Errorf("failed to upload %s: %w", audio.Key, err)
+	}
+	if err := s.songs.MarkNormalized(youtubeID); err != nil {
+		return false, fmt.Errorf("failed to mark %s normalized: %w", youtubeID, err)
+	}
+
+	// Files derived from the old audio would no longer match it
+	derived := []string{PeaksKey(youtubeID)}
+	for quality := range qualityBitrates {
+		derived = append(derived, TranscodedKey(youtubeID, quality))
+	}
+	for _, key := range derived {
+		if err := s.storage.DeleteFile(ctx, key); err != nil {
+			log.Printf("[WARN] NormalizationService: Failed to delete stale %s: %v", key, err)
+		}
+	}
+	return true, nil
+}
+
+// download copies the file stored under key to dst
+func (s *NormalizationService) download(ctx context.Context, key, dst string) error {
+	file, err := s.storage.GetFile(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	defer file.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+)
+
+// recordingNormalizer stands in for ffmpeg, recording the files it was given
+// and waiting on release, when set, before each one
+type recordingNormalizer struct {
+	prefixNormalizer
+	release chan struct{}
+
+	mu     sync.Mutex
+	inputs []string
+}
+
+func (n *recordingNormalizer) Normalize(ctx context.Context, inputPath, outputPath string) error {
+	if n.release != nil {
+		<-n.release
+	}
+	n.mu.Lock()
+	n.inputs = append(n.inputs, filepath.Ext(inputPath))
+	n.mu.Unlock()
+	return n.prefixNormalizer.Normalize(ctx, inputPath, outputPath)
+}
+
+// waitForNormalization blocks until the run Start began has finished
+func waitForNormalization(t *testing.T, service *NormalizationService) NormalizationProgress {
+	t.Helper()
+	service.mu.Lock()
+	done := service.done
+	service.mu.Unlock()
+	<-done
+	return service.Progress()
+}
+
+func newNormalizationFixture(t *testing.T) (*repositories.InMemorySongRepository, *repositories.InMemoryPlaylistRepository, *memoryStorage) {
+	t.Helper()
+	songs := repositories.NewInMemorySongRepository()
+	playlists := repositories.NewInMemoryPlaylistRepository(songs)
+	for _, id := range []string{"done", "mp3", "opus", "missing"} {
+		if err := songs.Create(&models.Song{YouTubeID: id, Tags: models.Tags{}}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := songs.MarkNormalized("done"); err != nil {
+		t.Fatalf("MarkNormalized failed: %v", err)
+	}
+
+	storage := newMemoryStorage()
+	storage.files[SongAudioKey("done")] = []byte("normalized:audio")
+	storage.files[SongAudioKey("mp3")] = []byte("audio")
+	storage.files[PeaksKey("mp3")] = []byte("peaks")
+	storage.files[TranscodedKey("mp3", QualityLow)] = []byte("audio")
+	storage.files[SongAudioKeyFor("opus", AudioFormatOpus)] = []byte("audio")
+	return songs, playlists, storage
+}
+
+func TestNormalizationServiceNormalizesLibrary(t *testing.T) {
+	songs, playlists, storage := newNormalizationFixture(t)
+	normalizer := &recordingNormalizer{}
+	service := NewNormalizationService(songs, playlists, storage, normalizer)
+
+	if _, err := service.Start(""); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	progress := waitForNormalization(t, service)
+
+	if progress.Running || progress.Total != 3 || progress.Normalized != 2 || progress.Skipped != 1 || progress.Failed != 0 {
+		t.Errorf("Expected 2 of 3 songs normalized and the one without audio skipped, got %+v", progress)
+	}
+	sort.Strings(normalizer.inputs)
+	if !reflect.DeepEqual(normalizer.inputs, []string{".mp3", ".opus"}) {
+		t.Errorf("Expected each song normalized in its own format, got %v", normalizer.inputs)
+	}
+
+	for key, want := range map[string]string{
+		SongAudioKey("done"):                     "normalized:audio",
+		SongAudioKey("mp3"):                      "normalized:audio",
+		SongAudioKeyFor("opus", AudioFormatOpus): "normalized:audio",
+	} {
+		if got := string(storage.files[key]); got != want {
+			t.Errorf("Expected %s to hold %q, got %q", key, want, got)
+		}
+	}
+	for _, key := range []string{PeaksKey("mp3"), TranscodedKey("mp3", QualityLow)} {
+		if _, ok := storage.files[key]; ok {
+			t.Errorf("Expected stale %s to be deleted", key)
+		}
+	}
+
+	pending, err := songs.GetUnnormalized()
+	if err != nil {
+		t.Fatalf("GetUnnormalized failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].YouTubeID != "missing" {
+		t.Errorf("Expected only the song without audio left to normalize, got %v", pending)
+	}
+
+	// A second run has nothing left to do
+	if _, err := service.Start(""); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	progress = waitForNormalization(t, service)
+	if progress.Normalized != 0 || len(normalizer.inputs) != 2 {
+		t.Errorf("Expected normalized songs to be skipped, got %+v", progress)
+	}
+}
+
+func TestNormalizationServiceScopedToPlaylist(t *testing.T) {
+	songs, playlists, storage := newNormalizationFixture(t)
+	playlist := &models.Playlist{Name: "Mix"}
+	if err := playlists.Create(playlist); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i, id := range []string{"done", "opus"} {
+		if err := playlists.AddSong(playlist.ID, id, i); err != nil {
+			t.Fatalf("AddSong failed: %v", err)
+		}
+	}
+	normalizer := &recordingNormalizer{}
+	service := NewNormalizationService(songs, playlists, storage, normalizer)
+
+	if _, err := service.Start("no-such-playlist"); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Fatalf("Expected ErrPlaylistNotFound, got %v", err)
+	}
+
+	if _, err := service.Start(playlist.ID); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	progress := waitForNormalization(t, service)
+
+	if progress.PlaylistID != playlist.ID || progress.Total != 1 || progress.Normalized != 1 {
+		t.Errorf("Expected only the playlist's unnormalized song to be normalized, got %+v", progress)
+	}
+	if string(storage.files[SongAudioKey("mp3")]) != "audio" {
+		t.Error("Expected songs outside the playlist to be left alone")
+	}
+}
+
+func TestNormalizationServiceRejectsConcurrentRuns(t *testing.T) {
+	songs, playlists, storage := newNormalizationFixture(t)
+	normalizer := &recordingNormalizer{release: make(chan struct{})}
+	service := NewNormalizationService(songs, playlists, storage, normalizer)
+
+	if _, err := service.Start(""); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if progress := service.Progress(); !progress.Running {
+		t.Errorf("Expected the run to be reported running, got %+v", progress)
+	}
+	if _, err := service.Start(""); !errors.Is(err, ErrNormalizationInProgress) {
+		t.Errorf("Expected ErrNormalizationInProgress, got %v", err)
+	}
+
+	close(normalizer.release)
+	if progress := waitForNormalization(t, service); progress.Running {
+		t.Errorf("Expected the run to finish, got %+v", progress)
+	}
+}
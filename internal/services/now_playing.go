@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// nowPlayingWebhookTimeout bounds each webhook call so a slow receiver
+// doesn't hold up later song changes
+const nowPlayingWebhookTimeout = 10 * time.Second
+
+// NowPlaying is the webhook payload sent on each song change. Text is empty
+// and Song nil once playback stops.
+type NowPlaying struct {
+	Text      string       `json:"text"`
+	Song      *models.Song `json:"song"`
+	StartedAt time.Time    `json:"started_at"`
+}
+
+// NowPlayingPublisher writes the current track to a text file, e.g. for an
+// OBS text source, and posts it to a webhook. Either output may be unset.
+type NowPlayingPublisher struct {
+	filePath   string
+	webhookURL string
+	client     *http.Client
+}
+
+func NewNowPlayingPublisher(filePath, webhookURL string) *NowPlayingPublisher {
+	return &NowPlayingPublisher{
+		filePath:   filePath,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: nowPlayingWebhookTimeout},
+	}
+}
+
+// Enabled reports whether a file or webhook is configured
+func (p *NowPlayingPublisher) Enabled() bool {
+	return p.filePath != "" || p.webhookURL != ""
+}
+
+// Subscribe publishes on every song change and clears the output when
+// playback stops. Pass an ordered subscriber so the last song wins.
+func (p *NowPlayingPublisher) Subscribe(bus events.Subscriber) error {
+	if _, err := events.SubscribeTyped(bus, events.EventSongChange, func(e events.SongChangeEvent) {
+		p.Publish(e.CurrentSong, e.StartTime)
+	}); err != nil {
+		return err
+	}
+	_, err := events.SubscribeTyped(bus, events.EventPlaybackStop, func(events.PlaybackStoppedEvent) {
+		p.Publish(nil, time.Time{})
+	})
+	return err
+}
+
+// Publish writes song, or nothing for a nil song, to the configured outputs
+func (p *NowPlayingPublisher) Publish(song *models.Song, startedAt time.Time) {
+	nowPlaying := NowPlaying{
+		Text:      NowPlayingText(song),
+		Song:      song,
+		StartedAt: startedAt,
+	}
+
+	if p.filePath != "" {
+		if err := writeFileAtomic(p.filePath, []byte(nowPlaying.Text+"\n")); err != nil {
+			log.Printf("[ERROR] NowPlayingPublisher: Failed to write %s: %v", p.filePath, err)
+		}
+	}
+	if p.webhookURL != "" {
+		if err := p.postWebhook(nowPlaying); err != nil {
+			log.Printf("[ERROR] NowPlayingPublisher: Failed to call webhook: %v", err)
+		}
+	}
+}
+
+func (p *NowPlayingPublisher) postWebhook(nowPlaying NowPlaying) error {
+	body, err := json.Marshal(nowPlaying)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nowPlayingWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NowPlayingText formats a song as "Artist - Title", leaving out an unknown artist
+func NowPlayingText(song *models.Song) string {
+	if song == nil {
+		return ""
+	}
+	if artist := models.LibraryName(song.Artist); artist != models.UnknownName {
+		return artist + " - " + song.Title
+	}
+	return song.Title
+}
+
+// writeFileAtomic replaces path with data so readers never see a partial
+// write: the data goes to a temp file in the same directory that is then
+// renamed over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// waitForFile polls path until it holds expected
+func waitForFile(t *testing.T, path, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if string(data) == expected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to contain %q, got %q", path, expected, data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNowPlayingPublisherWritesFileOnSongChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "now_playing.txt")
+	eventBus := events.NewEventBus()
+	publisher := NewNowPlayingPublisher(path, "")
+	if err := publisher.Subscribe(eventBus.Ordered()); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	song := createTestSong("song1", "Roygbiv", "Boards of Canada", 150)
+	eventBus.PublishSongChange(song, nil, &models.QueueInfo{Queue: []*models.Song{song}})
+	waitForFile(t, path, "Boards of Canada - Roygbiv\n")
+
+	untitled := createTestSong("song2", "Mystery Track", "Unknown", 150)
+	eventBus.PublishSongChange(untitled, nil, &models.QueueInfo{Queue: []*models.Song{untitled}})
+	waitForFile(t, path, "Mystery Track\n")
+
+	eventBus.PublishPlaybackStopped(nil)
+	waitForFile(t, path, "\n")
+
+	// The temp files used for atomic writes are cleaned up
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the now playing file, got %d entries", len(entries))
+	}
+}
+
+func TestNowPlayingPublisherCallsWebhook(t *testing.T) {
+	received := make(chan NowPlaying, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected %s request with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		var payload NowPlaying
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	eventBus := events.NewEventBus()
+	publisher := NewNowPlayingPublisher("", server.URL)
+	if err := publisher.Subscribe(eventBus.Ordered()); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	song := createTestSong("song1", "Roygbiv", "Boards of Canada", 150)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	eventBus.PublishSongChange(song, nil, &models.QueueInfo{Queue: []*models.Song{song}, StartTime: start})
+
+	select {
+	case payload := <-received:
+		if payload.Text != "Boards of Canada - Roygbiv" {
+			t.Errorf("Expected text %q, got %q", "Boards of Canada - Roygbiv", payload.Text)
+		}
+		if payload.Song == nil || payload.Song.YouTubeID != "song1" {
+			t.Errorf("Expected song1 in payload, got %+v", payload.Song)
+		}
+		if !payload.StartedAt.Equal(start) {
+			t.Errorf("Expected start time %v, got %v", start, payload.StartedAt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for webhook call")
+	}
+}
+
+func TestNowPlayingPublisherDisabled(t *testing.T) {
+	publisher := NewNowPlayingPublisher("", "")
+	if publisher.Enabled() {
+		t.Error("Expected publisher without outputs to be disabled")
+	}
+	// Publishing without outputs does nothing
+	publisher.Publish(createTestSong("song1", "Song", "Artist", 150), time.Now())
+}
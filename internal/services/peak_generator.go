@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+)
+
+// DefaultPeakCount is the number of peaks generated for a song's waveform
+const DefaultPeakCount = 800
+
+// peakSampleRate is the sample rate audio is decoded at before computing peaks.
+// Waveforms only need a coarse envelope so a low rate keeps decoding cheap.
+const peakSampleRate = "8000"
+
+// PeaksKey returns the storage key used to cache a song's waveform peaks
+func PeaksKey(youtubeID string) string {
+	return fmt.Sprintf("peaks/%s.json", youtubeID)
+}
+
+// PeakGenerator computes a downsampled waveform from audio
+type PeakGenerator interface {
+	Available() bool
+	GeneratePeaks(ctx context.Context, input io.Reader, count int) ([]float64, error)
+}
+
+// FFmpegPeakGenerator decodes audio with ffmpeg and computes normalized peaks
+type FFmpegPeakGenerator struct {
+	binary string
+}
+
+func NewFFmpegPeakGenerator() *FFmpegPeakGenerator {
+	return &FFmpegPeakGenerator{binary: "ffmpeg"}
+}
+
+// Available reports whether the ffmpeg binary can be found in PATH
+func (g *FFmpegPeakGenerator) Available() bool {
+	_, err := exec.LookPath(g.binary)
+	return err == nil
+}
+
+// GeneratePeaks decodes input to mono 16-bit PCM and reduces it to count peaks in [0, 1]
+func (g *FFmpegPeakGenerator) GeneratePeaks(ctx context.Context, input io.Reader, count int) ([]float64, error) {
+	var pcm bytes.Buffer
+	cmd := exec.CommandContext(ctx, g.binary,
+		"-i", "pipe:0",
+		"-ac", "1",
+		"-ar", peakSampleRate,
+		"-f", "s16le",
+		"pipe:1",
+	)
+	cmd.Stdin = input
+	cmd.Stdout = &pcm
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg decode failed: %w", err)
+	}
+
+	samples := make([]int16, pcm.Len()/2)
+	if err := binary.Read(&pcm, binary.LittleEndian, samples); err != nil {
+		return nil, fmt.Errorf("failed to read decoded samples: %w", err)
+	}
+
+	return computePeaks(samples, count), nil
+}
+
+// computePeaks splits samples into count buckets and returns the normalized
+// maximum amplitude of each bucket
+func computePeaks(samples []int16, count int) []float64 {
+	if count <= 0 || len(samples) == 0 {
+		return []float64{}
+	}
+	if count > len(samples) {
+		count = len(samples)
+	}
+
+	peaks := make([]float64, count)
+	bucketSize := float64(len(samples)) / float64(count)
+	for i := 0; i < count; i++ {
+		start := int(float64(i) * bucketSize)
+		end := int(float64(i+1) * bucketSize)
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		var peak float64
+		for _, sample := range samples[start:end] {
+			if amplitude := math.Abs(float64(sample)) / 32768; amplitude > peak {
+				peak = amplitude
+			}
+		}
+		peaks[i] = peak
+	}
+
+	return peaks
+}
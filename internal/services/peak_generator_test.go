@@ -0,0 +1,26 @@
+package services
+
+import "testing"
+
+func TestComputePeaks(t *testing.T) {
+	samples := []int16{0, 16384, -32768, 100, 8192, -8192}
+
+	peaks := computePeaks(samples, 3)
+	expected := []float64{0.5, 1, 0.25}
+	if len(peaks) != len(expected) {
+		t.Fatalf("Expected %d peaks, got %d", len(expected), len(peaks))
+	}
+	for i := range expected {
+		if peaks[i] != expected[i] {
+			t.Errorf("Peak %d: expected %f, got %f", i, expected[i], peaks[i])
+		}
+	}
+
+	if peaks := computePeaks(nil, 10); len(peaks) != 0 {
+		t.Errorf("Expected no peaks for empty input, got %v", peaks)
+	}
+
+	if peaks := computePeaks(samples, 100); len(peaks) != len(samples) {
+		t.Errorf("Expected peak count to be capped at sample count, got %d", len(peaks))
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import "github.com/feline-dis/go-radio-v2/internal/models"
+
+// playHistorySize is how many finished songs the radio remembers
+const playHistorySize = 50
+
+// playHistory is a fixed-size ring buffer of the songs played most recently.
+// It isn't safe for concurrent use; RadioService guards it with its mutex.
+type playHistory struct {
+	songs []*models.Song
+	start int // index of the oldest song
+	size  int
+}
+
+func newPlayHistory(capacity int) *playHistory {
+	return &playHistory{songs: make([]*models.Song, capacity)}
+}
+
+// push records a song as played, dropping the oldest one when full
+func (h *playHistory) push(song *models.Song) {
+	if song == nil || len(h.songs) == 0 {
+		return
+	}
+	if h.size < len(h.songs) {
+		h.songs[(h.start+h.size)%len(h.songs)] = song
+		h.size++
+		return
+	}
+	h.songs[h.start] = song
+	h.start = (h.start + 1) % len(h.songs)
+}
+
+// pop removes and returns the most recently played song, or nil if empty
+func (h *playHistory) pop() *models.Song {
+	if h.size == 0 {
+		return nil
+	}
+	h.size--
+	i := (h.start + h.size) % len(h.songs)
+	song := h.songs[i]
+	h.songs[i] = nil
+	return song
+}
+
+// recent returns up to n of the most recently played songs, oldest first
+func (h *playHistory) recent(n int) []*models.Song {
+	n = max(0, min(n, h.size))
+	songs := make([]*models.Song, 0, n)
+	for i := h.size - n; i < h.size; i++ {
+		songs = append(songs, h.songs[(h.start+i)%len(h.songs)])
+	}
+	return songs
+}
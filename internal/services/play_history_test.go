@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPlayHistoryDropsOldest(t *testing.T) {
+	history := newPlayHistory(3)
+	for i := 1; i <= 5; i++ {
+		history.push(createTestSong(fmt.Sprintf("song%d", i), "Song", "Artist", 180))
+	}
+
+	for _, expected := range []string{"song5", "song4", "song3"} {
+		song := history.pop()
+		if song == nil || song.YouTubeID != expected {
+			t.Fatalf("Expected %s, got %v", expected, song)
+		}
+	}
+	if song := history.pop(); song != nil {
+		t.Errorf("Expected an empty history, got %s", song.YouTubeID)
+	}
+
+	// The buffer keeps working after wrapping around and emptying
+	history.push(createTestSong("song6", "Song", "Artist", 180))
+	if song := history.pop(); song == nil || song.YouTubeID != "song6" {
+		t.Errorf("Expected song6, got %v", song)
+	}
+}
+
+func TestPlayHistoryRecent(t *testing.T) {
+	history := newPlayHistory(3)
+	if songs := history.recent(2); songs == nil || len(songs) != 0 {
+		t.Errorf("Expected no recent songs from an empty history, got %v", songs)
+	}
+
+	for i := 1; i <= 5; i++ {
+		history.push(createTestSong(fmt.Sprintf("song%d", i), "Song", "Artist", 180))
+	}
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{}},
+		{2, []string{"song4", "song5"}},
+		{10, []string{"song3", "song4", "song5"}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for _, song := range history.recent(tt.n) {
+			got = append(got, song.YouTubeID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("recent(%d): expected %v, got %v", tt.n, tt.want, got)
+		}
+	}
+
+	// Reading recent songs leaves them for Previous
+	if song := history.pop(); song == nil || song.YouTubeID != "song5" {
+		t.Errorf("Expected song5 to still be poppable, got %v", song)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// playlistCacheTTL bounds how stale cached playlist songs can get if an
+// invalidation is missed
+const playlistCacheTTL = time.Minute
+
+type playlistCacheEntry struct {
+	songs   []*models.Song
+	expires time.Time
+}
+
+// playlistSongsCache is a short-lived cache of playlist ID to songs
+type playlistSongsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]playlistCacheEntry
+}
+
+func newPlaylistSongsCache(ttl time.Duration) *playlistSongsCache {
+	return &playlistSongsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]playlistCacheEntry),
+	}
+}
+
+// get returns a copy of the cached songs so callers can't modify the cache
+func (c *playlistSongsCache) get(playlistID string) ([]*models.Song, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[playlistID]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(entry.expires) {
+		delete(c.entries, playlistID)
+		return nil, false
+	}
+
+	songs := make([]*models.Song, len(entry.songs))
+	copy(songs, entry.songs)
+	return songs, true
+}
+
+func (c *playlistSongsCache) set(playlistID string, songs []*models.Song) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := make([]*models.Song, len(songs))
+	copy(cached, songs)
+	c.entries[playlistID] = playlistCacheEntry{
+		songs:   cached,
+		expires: c.now().Add(c.ttl),
+	}
+}
+
+func (c *playlistSongsCache) invalidate(playlistID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, playlistID)
+}
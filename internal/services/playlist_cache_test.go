@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func newPlaylistCacheTestService() (*RadioService, *MockPlaylistRepository) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlist := createTestPlaylist("1", "Test Playlist")
+	playlistRepo.playlists["1"] = playlist
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+	}
+
+	service := NewRadioService(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus())
+	return service, playlistRepo
+}
+
+func TestSetActivePlaylistUsesCache(t *testing.T) {
+	service, playlistRepo := newPlaylistCacheTestService()
+
+	for i := 0; i < 3; i++ {
+		if err := service.SetActivePlaylist("1"); err != nil {
+			t.Fatalf("SetActivePlaylist failed: %v", err)
+		}
+	}
+
+	if playlistRepo.getSongsCalls != 1 {
+		t.Errorf("Expected 1 repository call, got %d", playlistRepo.getSongsCalls)
+	}
+}
+
+func TestInvalidatePlaylistCache(t *testing.T) {
+	service, playlistRepo := newPlaylistCacheTestService()
+
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+
+	// A mutation adds a song and invalidates the cache
+	playlistRepo.songs["1"] = append(playlistRepo.songs["1"], createTestSong("song3", "Song 3", "Artist 3", 160))
+	service.InvalidatePlaylistCache("1")
+
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+
+	if playlistRepo.getSongsCalls != 2 {
+		t.Errorf("Expected 2 repository calls after invalidation, got %d", playlistRepo.getSongsCalls)
+	}
+	if len(service.state.Queue) != 3 {
+		t.Errorf("Expected queue to include the new song, got %d songs", len(service.state.Queue))
+	}
+}
+
+func TestPlaylistCacheExpires(t *testing.T) {
+	now := time.Now()
+	cache := newPlaylistSongsCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.set("1", []*models.Song{createTestSong("song1", "Song 1", "Artist 1", 180)})
+	if _, ok := cache.get("1"); !ok {
+		t.Fatal("Expected cache hit before expiry")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.get("1"); ok {
+		t.Error("Expected cache miss after expiry")
+	}
+}
+
+func TestPlaylistCacheReturnsCopy(t *testing.T) {
+	cache := newPlaylistSongsCache(time.Minute)
+	cache.set("1", []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+	})
+
+	songs, _ := cache.get("1")
+	songs[0], songs[1] = songs[1], songs[0]
+
+	cached, _ := cache.get("1")
+	if cached[0].YouTubeID != "song1" {
+		t.Error("Expected reordering a returned slice not to affect the cache")
+	}
+}
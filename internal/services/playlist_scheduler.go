@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// playlistScheduleInterval is how often the scheduler checks for a boundary
+const playlistScheduleInterval = 30 * time.Second
+
+// ScheduleEntry plays a playlist from StartHour up to, but not including,
+// EndHour. Windows may wrap past midnight, e.g. 22-06.
+type ScheduleEntry struct {
+	StartHour int
+	EndHour   int
+	Playlist  string
+}
+
+// contains reports whether hour falls inside the entry's window
+func (e ScheduleEntry) contains(hour int) bool {
+	if e.StartHour < e.EndHour {
+		return hour >= e.StartHour && hour < e.EndHour
+	}
+	return hour >= e.StartHour || hour < e.EndHour
+}
+
+// ParsePlaylistSchedule parses a schedule such as "06-18=Daytime,18-06=Chill"
+func ParsePlaylistSchedule(spec string) ([]ScheduleEntry, error) {
+	var entries []ScheduleEntry
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		hours, name, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid schedule entry %q: expected START-END=playlist", part)
+		}
+		startRaw, endRaw, ok := strings.Cut(strings.TrimSpace(hours), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule entry %q: expected START-END=playlist", part)
+		}
+
+		start, err := parseScheduleHour(startRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule entry %q: %w", part, err)
+		}
+		end, err := parseScheduleHour(endRaw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule entry %q: %w", part, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("invalid schedule entry %q: start and end hour are the same", part)
+		}
+
+		entries = append(entries, ScheduleEntry{StartHour: start, EndHour: end, Playlist: name})
+	}
+	return entries, nil
+}
+
+func parseScheduleHour(raw string) (int, error) {
+	hour, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("hour %q must be between 0 and 23", raw)
+	}
+	return hour, nil
+}
+
+// PlaylistActivator switches the playlist on air
+type PlaylistActivator interface {
+	SetActivePlaylist(playlistID string) error
+}
+
+// PlaylistLookup finds playlists by name
+type PlaylistLookup interface {
+	GetByName(name string) (*models.Playlist, error)
+}
+
+// PlaylistScheduler switches the active playlist when the clock crosses into
+// a new schedule window. It only acts at boundaries, so a playlist picked by
+// hand stays on air until the next window starts.
+type PlaylistScheduler struct {
+	radio    PlaylistActivator
+	lookup   PlaylistLookup
+	entries  []ScheduleEntry
+	now      func() time.Time
+	interval time.Duration
+
+	// current is the index of the window last switched to, -1 for none
+	current int
+}
+
+func NewPlaylistScheduler(radio PlaylistActivator, lookup PlaylistLookup, entries []ScheduleEntry) *PlaylistScheduler {
+	return &PlaylistScheduler{
+		radio:    radio,
+		lookup:   lookup,
+		entries:  entries,
+		now:      time.Now,
+		interval: playlistScheduleInterval,
+		current:  -1,
+	}
+}
+
+// Run checks the schedule immediately and then periodically until ctx is done
+func (s *PlaylistScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.Tick()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Tick()
+		}
+	}
+}
+
+// Tick switches to the scheduled playlist if a new window has started
+func (s *PlaylistScheduler) Tick() {
+	index := s.windowAt(s.now())
+	if index == s.current {
+		return
+	}
+	if index < 0 {
+		// Outside every window, leave the current playlist alone
+		s.current = index
+		return
+	}
+
+	entry := s.entries[index]
+	playlist, err := s.lookup.GetByName(entry.Playlist)
+	if err != nil {
+		log.Printf("[ERROR] PlaylistScheduler: Failed to look up playlist %q: %v", entry.Playlist, err)
+		return
+	}
+	if playlist == nil {
+		log.Printf("[ERROR] PlaylistScheduler: Scheduled playlist %q not found", entry.Playlist)
+		s.current = index
+		return
+	}
+
+	if err := s.radio.SetActivePlaylist(playlist.ID); err != nil {
+		log.Printf("[ERROR] PlaylistScheduler: Failed to switch to playlist %q: %v", entry.Playlist, err)
+		return
+	}
+	log.Printf("[DEBUG] PlaylistScheduler: Switched to scheduled playlist %q", entry.Playlist)
+	s.current = index
+}
+
+// windowAt returns the index of the first entry covering t, or -1
+func (s *PlaylistScheduler) windowAt(t time.Time) int {
+	for i, entry := range s.entries {
+		if entry.contains(t.Hour()) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// fakeActivator records the playlists it was asked to switch to
+type fakeActivator struct {
+	switched []string
+}
+
+func (f *fakeActivator) SetActivePlaylist(playlistID string) error {
+	f.switched = append(f.switched, playlistID)
+	return nil
+}
+
+// fakePlaylistLookup resolves names to playlists whose ID is the name
+type fakePlaylistLookup struct{}
+
+func (fakePlaylistLookup) GetByName(name string) (*models.Playlist, error) {
+	if name == "Missing" {
+		return nil, nil
+	}
+	return &models.Playlist{ID: name, Name: name}, nil
+}
+
+func TestParsePlaylistSchedule(t *testing.T) {
+	entries, err := ParsePlaylistSchedule("06-18=Daytime, 18-06 = Chill Evening")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []ScheduleEntry{
+		{StartHour: 6, EndHour: 18, Playlist: "Daytime"},
+		{StartHour: 18, EndHour: 6, Playlist: "Chill Evening"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+
+	for _, spec := range []string{"06-18", "6=Daytime", "06-24=Late", "xx-06=Bad", "06-06=Same", "06-18="} {
+		if _, err := ParsePlaylistSchedule(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestPlaylistSchedulerSwitchesAtBoundaries(t *testing.T) {
+	radio := &fakeActivator{}
+	scheduler := NewPlaylistScheduler(radio, fakePlaylistLookup{}, []ScheduleEntry{
+		{StartHour: 6, EndHour: 18, Playlist: "Daytime"},
+		{StartHour: 18, EndHour: 6, Playlist: "Chill"},
+	})
+
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local)
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.Tick()
+	if len(radio.switched) != 1 || radio.switched[0] != "Daytime" {
+		t.Fatalf("Expected switch to Daytime, got %v", radio.switched)
+	}
+
+	// Staying inside the window doesn't switch again, so a manual pick sticks
+	now = now.Add(4 * time.Hour)
+	scheduler.Tick()
+	if len(radio.switched) != 1 {
+		t.Errorf("Expected no switch inside the window, got %v", radio.switched)
+	}
+
+	// Crossing into the evening window wraps past midnight
+	now = time.Date(2026, 1, 1, 18, 0, 0, 0, time.Local)
+	scheduler.Tick()
+	now = time.Date(2026, 1, 2, 2, 0, 0, 0, time.Local)
+	scheduler.Tick()
+	if len(radio.switched) != 2 || radio.switched[1] != "Chill" {
+		t.Errorf("Expected a single switch to Chill, got %v", radio.switched)
+	}
+}
+
+func TestPlaylistSchedulerOutsideWindowsAndMissingPlaylist(t *testing.T) {
+	radio := &fakeActivator{}
+	scheduler := NewPlaylistScheduler(radio, fakePlaylistLookup{}, []ScheduleEntry{
+		{StartHour: 8, EndHour: 10, Playlist: "Morning"},
+		{StartHour: 20, EndHour: 22, Playlist: "Missing"},
+	})
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.Tick()
+	now = time.Date(2026, 1, 1, 21, 0, 0, 0, time.Local)
+	scheduler.Tick()
+	if len(radio.switched) != 0 {
+		t.Errorf("Expected no switches, got %v", radio.switched)
+	}
+
+	now = time.Date(2026, 1, 2, 8, 30, 0, 0, time.Local)
+	scheduler.Tick()
+	if len(radio.switched) != 1 || radio.switched[0] != "Morning" {
+		t.Errorf("Expected switch to Morning, got %v", radio.switched)
+	}
+}
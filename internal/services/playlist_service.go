@@ -1,7 +1,7 @@
 package services
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -13,10 +13,58 @@ import (
 	"github.com/feline-dis/go-radio-v2/internal/repositories"
 )
 
+const (
+	// DefaultPlaylistSongsPageLimit is used when a page is requested without a limit
+	DefaultPlaylistSongsPageLimit = 50
+	// MaxPlaylistSongsPageLimit caps how many playlist songs can be requested at once
+	MaxPlaylistSongsPageLimit = 500
+)
+
+// PlaylistSongsPage is one page of a playlist's songs
+type PlaylistSongsPage struct {
+	Songs  []*models.Song `json:"songs"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// ErrPlaylistNameTaken is returned when creating a playlist with a name
+// another playlist already has
+var ErrPlaylistNameTaken = repositories.ErrPlaylistNameTaken
+
+// ErrPlaylistTooLarge is returned when a playlist would hold more songs
+// than the configured maximum
+var ErrPlaylistTooLarge = errors.New("playlist is too large")
+
+// PlaylistStore is the playlist storage PlaylistService reads and edits
+type PlaylistStore interface {
+	Create(playlist *models.Playlist) error
+	GetByID(id string) (*models.Playlist, error)
+	GetByName(name string) (*models.Playlist, error)
+	GetAll() ([]*models.Playlist, error)
+	GetSongs(playlistID string) ([]*models.Song, error)
+	GetSongsPage(playlistID string, limit, offset int) ([]*models.Song, int, error)
+	AddSong(playlistID string, youtubeID string, position int) error
+	RemoveSong(playlistID string, youtubeID string) error
+	UpdateSongPosition(playlistID string, youtubeID string, newPosition int) error
+}
+
 type PlaylistService struct {
-	playlistRepo *repositories.PlaylistRepository
+	playlistRepo PlaylistStore
 	songRepo     *repositories.SongRepository
 	youtubeSvc   *YouTubeService
+
+	// changeHooks are notified with the playlist ID after every mutation
+	changeHooks []func(playlistID string)
+
+	// videoInfo looks up video metadata when the YouTube API can't
+	videoInfo VideoInfoFetcher
+
+	// maxPlaylistSize caps the songs in a playlist; 0 is unlimited
+	maxPlaylistSize int
+
+	// importFilter leaves unsuitable videos, e.g. live streams, out of imports
+	importFilter ImportFilter
 }
 
 // songProcessingResult holds the result of processing a song
@@ -24,6 +72,10 @@ type songProcessingResult struct {
 	song     *models.Song
 	position int
 	err      error
+
+	// videoID and skipped are set when the import filter left the video out
+	videoID string
+	skipped string
 }
 
 // batchJob represents a batch of songs to be processed
@@ -33,7 +85,7 @@ type batchJob struct {
 }
 
 func NewPlaylistService(
-	playlistRepo *repositories.PlaylistRepository,
+	playlistRepo PlaylistStore,
 	songRepo *repositories.SongRepository,
 	youtubeSvc *YouTubeService,
 ) *PlaylistService {
@@ -44,31 +96,76 @@ func NewPlaylistService(
 	}
 }
 
+// SetVideoInfoFallback makes imports read video metadata from info, e.g.
+// yt-dlp, when there is no YouTube API key or its quota is used up
+func (s *PlaylistService) SetVideoInfoFallback(info VideoInfoFetcher) {
+	s.videoInfo = info
+}
+
+// SetMaxPlaylistSize caps how many songs a playlist may hold, so a huge
+// import can't set off thousands of lookups and downloads. Zero is unlimited.
+func (s *PlaylistService) SetMaxPlaylistSize(size int) {
+	s.maxPlaylistSize = max(size, 0)
+}
+
+// checkPlaylistSize fails with ErrPlaylistTooLarge if size exceeds the maximum
+func (s *PlaylistService) checkPlaylistSize(size int) error {
+	if s.maxPlaylistSize > 0 && size > s.maxPlaylistSize {
+		return fmt.Errorf("%w: %d songs, the limit is %d", ErrPlaylistTooLarge, size, s.maxPlaylistSize)
+	}
+	return nil
+}
+
 // CreatePlaylist creates a new playlist with the given songs using concurrent processing
 func (s *PlaylistService) CreatePlaylist(name, description string, songIDs []string) (*models.Playlist, error) {
-	// Create the playlist
-	playlist := &models.Playlist{
-		Name:        name,
-		Description: description,
+	if err := s.checkPlaylistSize(len(songIDs)); err != nil {
+		return nil, err
 	}
 
-	if err := s.playlistRepo.Create(playlist); err != nil {
+	playlist, err := s.createPlaylist(name, description)
+	if err != nil {
 		return nil, err
 	}
 
-	// Process songs concurrently if there are any
+	// Process songs concurrently if there are any. Songs that fail are
+	// logged and left out, the playlist itself was created successfully.
 	if len(songIDs) > 0 {
-		if err := s.processSongsConcurrently(playlist.ID, songIDs); err != nil {
-			log.Printf("Error processing songs concurrently: %v", err)
-			// Don't return error here as playlist was created successfully
-		}
+		s.processSongsConcurrently(playlist.ID, songIDs)
+		s.notifyChanged(playlist.ID)
 	}
 
 	return playlist, nil
 }
 
-// processSongsConcurrently processes songs using concurrent workers
-func (s *PlaylistService) processSongsConcurrently(playlistID string, songIDs []string) error {
+// createPlaylist creates an empty playlist, failing with ErrPlaylistNameTaken
+// if the name is in use. The lookup gives a clear error up front and the
+// unique index catches a concurrent create of the same name.
+func (s *PlaylistService) createPlaylist(name, description string) (*models.Playlist, error) {
+	existing, err := s.playlistRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %q", ErrPlaylistNameTaken, name)
+	}
+
+	playlist := &models.Playlist{
+		Name:        name,
+		Description: description,
+	}
+	if err := s.playlistRepo.Create(playlist); err != nil {
+		if errors.Is(err, ErrPlaylistNameTaken) {
+			return nil, fmt.Errorf("%w: %q", ErrPlaylistNameTaken, name)
+		}
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// processSongsConcurrently processes songs using concurrent workers and
+// returns the IDs of the songs added to the playlist, and of the songs the
+// import filter left out with the reason
+func (s *PlaylistService) processSongsConcurrently(playlistID string, songIDs []string) (map[string]bool, map[string]string) {
 	const (
 		batchSize  = 10
 		maxWorkers = 3 // Limit concurrent API calls to avoid rate limits
@@ -137,26 +234,37 @@ func (s *PlaylistService) processSongsConcurrently(playlistID string, songIDs []
 	}
 
 	// Add songs to playlist in order
+	added := make(map[string]bool)
+	skipped := make(map[string]string)
 	var addErrors []error
 	for _, result := range sortedResults {
 		if result.err != nil {
 			addErrors = append(addErrors, result.err)
 			continue
 		}
+		if result.skipped != "" {
+			skipped[result.videoID] = result.skipped
+			continue
+		}
 
 		if result.song != nil {
 			if err := s.playlistRepo.AddSong(playlistID, result.song.YouTubeID, result.position); err != nil {
 				log.Printf("Error adding song to playlist: %v", err)
 				addErrors = append(addErrors, err)
+				continue
 			}
+			added[result.song.YouTubeID] = true
 		}
 	}
 
 	if len(addErrors) > 0 {
 		log.Printf("Encountered %d errors while adding songs to playlist", len(addErrors))
 	}
+	if len(skipped) > 0 {
+		log.Printf("Import filter left %d songs out of playlist %s", len(skipped), playlistID)
+	}
 
-	return nil
+	return added, skipped
 }
 
 // processBatchWorker processes batches of songs concurrently
@@ -187,14 +295,7 @@ func (s *PlaylistService) processBatchWorker(
 // processBatch processes a batch of songs and returns results
 func (s *PlaylistService) processBatch(songIDs []string, startIndex int) []songProcessingResult {
 	// Get song details from YouTube
-	ids := strings.Join(songIDs, ",")
-	detailsURL := fmt.Sprintf(
-		"https://www.googleapis.com/youtube/v3/videos?part=snippet,contentDetails&id=%s&key=%s",
-		ids,
-		s.youtubeSvc.apiKey,
-	)
-
-	resp, err := s.youtubeSvc.httpClient.Get(detailsURL)
+	videos, err := s.lookupVideos(songIDs)
 	if err != nil {
 		log.Printf("Error getting video details: %v", err)
 		// Return errors for all songs in this batch
@@ -207,26 +308,17 @@ func (s *PlaylistService) processBatch(songIDs []string, startIndex int) []songP
 		}
 		return results
 	}
-	defer resp.Body.Close()
 
-	var videoResp struct {
-		Items []struct {
-			ID      string `json:"id"`
-			Snippet struct {
-				Title       string `json:"title"`
-				Description string `json:"description"`
-			} `json:"snippet"`
-			ContentDetails struct {
-				Duration string `json:"duration"`
-			} `json:"contentDetails"`
-		} `json:"items"`
+	// Look up which songs already exist in one query instead of one per video
+	videoIDs := make([]string, len(videos))
+	for i, video := range videos {
+		videoIDs[i] = video.ID
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&videoResp); err != nil {
-		log.Printf("Error decoding video details: %v", err)
-		// Return errors for all songs in this batch
-		results := make([]songProcessingResult, len(songIDs))
-		for i := range songIDs {
+	existingSongs, err := s.songRepo.GetByYouTubeIDs(videoIDs)
+	if err != nil {
+		log.Printf("Error checking existing songs: %v", err)
+		results := make([]songProcessingResult, len(videos))
+		for i := range videos {
 			results[i] = songProcessingResult{
 				position: startIndex + i,
 				err:      err,
@@ -236,56 +328,37 @@ func (s *PlaylistService) processBatch(songIDs []string, startIndex int) []songP
 	}
 
 	// Process each video item concurrently
-	results := make([]songProcessingResult, len(videoResp.Items))
+	results := make([]songProcessingResult, len(videos))
 	var wg sync.WaitGroup
 
-	for i, item := range videoResp.Items {
+	for i, video := range videos {
 		wg.Add(1)
-		go func(i int, item struct {
-			ID      string `json:"id"`
-			Snippet struct {
-				Title       string `json:"title"`
-				Description string `json:"description"`
-			} `json:"snippet"`
-			ContentDetails struct {
-				Duration string `json:"duration"`
-			} `json:"contentDetails"`
-		}) {
+		go func(i int, video videoDetails) {
 			defer wg.Done()
 
-			// Parse duration (format: PT1H2M10S)
-			duration := parseDuration(item.ContentDetails.Duration)
-			if duration == 0 {
-				log.Printf("Warning: Could not parse duration for video %s", item.ID)
+			if reason := s.importFilter.reject(video); reason != "" {
 				results[i] = songProcessingResult{
 					position: startIndex + i,
-					err:      fmt.Errorf("could not parse duration for video %s", item.ID),
+					videoID:  video.ID,
+					skipped:  reason,
 				}
 				return
 			}
 
-			// Create song entry
-			song := &models.Song{
-				YouTubeID: item.ID,
-				Title:     item.Snippet.Title,
-				Artist:    "Unknown", // We could try to extract this from title/description
-				Album:     "Unknown",
-				Duration:  int(duration.Seconds()),
-				S3Key:     fmt.Sprintf("songs/%s.mp3", item.ID), // Assuming this is the format
-			}
-
-			// Check if song already exists
-			existingSong, err := s.songRepo.GetByYouTubeID(song.YouTubeID)
-			if err != nil {
-				log.Printf("Error checking existing song: %v", err)
+			if video.Duration == 0 {
+				log.Printf("Warning: Could not parse duration for video %s", video.ID)
 				results[i] = songProcessingResult{
 					position: startIndex + i,
-					err:      err,
+					err:      fmt.Errorf("could not parse duration for video %s", video.ID),
 				}
 				return
 			}
 
-			if existingSong == nil {
+			// Create song entry
+			song := video.song()
+
+			existingSong, exists := existingSongs[song.YouTubeID]
+			if !exists {
 				// Create new song
 				if err := s.songRepo.Create(song); err != nil {
 					log.Printf("Error creating song: %v", err)
@@ -305,7 +378,7 @@ func (s *PlaylistService) processBatch(songIDs []string, startIndex int) []songP
 				position: startIndex + i,
 				err:      nil,
 			}
-		}(i, item)
+		}(i, video)
 	}
 
 	wg.Wait()
@@ -367,17 +440,81 @@ func (s *PlaylistService) GetPlaylistSongs(playlistID string) ([]*models.Song, e
 	return s.playlistRepo.GetSongs(playlistID)
 }
 
+// GetPlaylistSongsPage returns one page of a playlist's songs. Non-positive
+// limits fall back to the default, large limits are clamped and negative
+// offsets start from the beginning.
+func (s *PlaylistService) GetPlaylistSongsPage(playlistID string, limit, offset int) (*PlaylistSongsPage, error) {
+	if limit <= 0 {
+		limit = DefaultPlaylistSongsPageLimit
+	}
+	if limit > MaxPlaylistSongsPageLimit {
+		limit = MaxPlaylistSongsPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	songs, total, err := s.playlistRepo.GetSongsPage(playlistID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if songs == nil {
+		songs = []*models.Song{}
+	}
+
+	return &PlaylistSongsPage{
+		Songs:  songs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
 // AddSongToPlaylist adds a song to a playlist at the specified position
 func (s *PlaylistService) AddSongToPlaylist(playlistID string, songID string, position int) error {
-	return s.playlistRepo.AddSong(playlistID, songID, position)
+	if s.maxPlaylistSize > 0 {
+		_, total, err := s.playlistRepo.GetSongsPage(playlistID, 0, 0)
+		if err != nil {
+			return err
+		}
+		if err := s.checkPlaylistSize(total + 1); err != nil {
+			return err
+		}
+	}
+
+	if err := s.playlistRepo.AddSong(playlistID, songID, position); err != nil {
+		return err
+	}
+	s.notifyChanged(playlistID)
+	return nil
 }
 
 // RemoveSongFromPlaylist removes a song from a playlist
 func (s *PlaylistService) RemoveSongFromPlaylist(playlistID string, songID string) error {
-	return s.playlistRepo.RemoveSong(playlistID, songID)
+	if err := s.playlistRepo.RemoveSong(playlistID, songID); err != nil {
+		return err
+	}
+	s.notifyChanged(playlistID)
+	return nil
 }
 
 // UpdateSongPosition updates the position of a song in a playlist
 func (s *PlaylistService) UpdateSongPosition(playlistID string, songID string, newPosition int) error {
-	return s.playlistRepo.UpdateSongPosition(playlistID, songID, newPosition)
+	if err := s.playlistRepo.UpdateSongPosition(playlistID, songID, newPosition); err != nil {
+		return err
+	}
+	s.notifyChanged(playlistID)
+	return nil
+}
+
+// OnPlaylistChanged registers a hook called with the playlist ID after the
+// playlist's songs change, e.g. to invalidate caches
+func (s *PlaylistService) OnPlaylistChanged(hook func(playlistID string)) {
+	s.changeHooks = append(s.changeHooks, hook)
+}
+
+func (s *PlaylistService) notifyChanged(playlistID string) {
+	for _, hook := range s.changeHooks {
+		hook(playlistID)
+	}
 }
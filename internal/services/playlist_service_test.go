@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+)
+
+// The repository the server uses must satisfy the service's store
+var _ PlaylistStore = (*repositories.PlaylistRepository)(nil)
+
+func TestCreatePlaylistRejectsTakenName(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.playlists["1"] = createTestPlaylist("1", "Chill")
+	service := NewPlaylistService(playlistRepo, nil, nil)
+
+	if _, err := service.CreatePlaylist("Chill", "again", nil); !errors.Is(err, ErrPlaylistNameTaken) {
+		t.Errorf("Expected ErrPlaylistNameTaken, got %v", err)
+	}
+	if len(playlistRepo.playlists) != 1 {
+		t.Errorf("Expected no playlist to be created, have %d", len(playlistRepo.playlists))
+	}
+
+	if _, err := service.CreatePlaylist("Focus", "", nil); err != nil {
+		t.Errorf("Expected a new name to be created, got %v", err)
+	}
+}
+
+// racingPlaylistStore lets every lookup miss until all racers have looked,
+// then enforces unique names on create the way the database index does
+type racingPlaylistStore struct {
+	*MockPlaylistRepository
+	looked sync.WaitGroup
+
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func (s *racingPlaylistStore) GetByName(name string) (*models.Playlist, error) {
+	s.looked.Done()
+	s.looked.Wait()
+	return nil, nil
+}
+
+func (s *racingPlaylistStore) Create(playlist *models.Playlist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.names[playlist.Name] {
+		return repositories.ErrPlaylistNameTaken
+	}
+	s.names[playlist.Name] = true
+	return nil
+}
+
+func TestCreatePlaylistNameRace(t *testing.T) {
+	const racers = 2
+	store := &racingPlaylistStore{MockPlaylistRepository: NewMockPlaylistRepository(), names: make(map[string]bool)}
+	store.looked.Add(racers)
+	service := NewPlaylistService(store, nil, nil)
+
+	errs := make(chan error, racers)
+	for i := 0; i < racers; i++ {
+		go func() {
+			_, err := service.CreatePlaylist("Chill", "", nil)
+			errs <- err
+		}()
+	}
+
+	var created, taken int
+	for i := 0; i < racers; i++ {
+		switch err := <-errs; {
+		case err == nil:
+			created++
+		case errors.Is(err, ErrPlaylistNameTaken):
+			taken++
+		default:
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if created != 1 || taken != 1 {
+		t.Errorf("Expected one create and one ErrPlaylistNameTaken, got %d and %d", created, taken)
+	}
+}
+
+func TestPlaylistSizeLimit(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	service := NewPlaylistService(playlistRepo, nil, nil)
+	service.SetMaxPlaylistSize(2)
+
+	if _, err := service.CreatePlaylist("Too Big", "", []string{"a", "b", "c"}); !errors.Is(err, ErrPlaylistTooLarge) {
+		t.Errorf("Expected ErrPlaylistTooLarge for 3 songs, got %v", err)
+	}
+	if len(playlistRepo.playlists) != 0 {
+		t.Errorf("Expected no playlist to be created past the limit, have %d", len(playlistRepo.playlists))
+	}
+	if _, err := service.CreatePlaylist("Just Right", "", []string{"a", "b"}); err != nil {
+		t.Errorf("Expected 2 songs to be allowed, got %v", err)
+	}
+
+	lines := []ImportLine{{Line: 1, YouTubeID: "a"}, {Line: 2, YouTubeID: "b"}, {Line: 3, YouTubeID: "c"}}
+	if _, err := service.ImportList("Imported", "", lines); !errors.Is(err, ErrPlaylistTooLarge) {
+		t.Errorf("Expected ErrPlaylistTooLarge importing 3 songs, got %v", err)
+	}
+	// Repeats aren't imported so they don't count towards the limit
+	lines = []ImportLine{{Line: 1, YouTubeID: "a"}, {Line: 2, YouTubeID: "b"}, {Line: 3, YouTubeID: "a"}}
+	if _, err := service.ImportList("Imported", "", lines); err != nil {
+		t.Errorf("Expected 2 distinct songs to import, got %v", err)
+	}
+
+	playlistRepo.playlists["full"] = createTestPlaylist("full", "Full")
+	playlistRepo.songs["full"] = []*models.Song{createTestSong("a", "A", "Artist", 180)}
+	if err := service.AddSongToPlaylist("full", "b", 1); err != nil {
+		t.Errorf("Expected a second song to fit, got %v", err)
+	}
+	playlistRepo.songs["full"] = append(playlistRepo.songs["full"], createTestSong("b", "B", "Artist", 180))
+	if err := service.AddSongToPlaylist("full", "c", 2); !errors.Is(err, ErrPlaylistTooLarge) {
+		t.Errorf("Expected ErrPlaylistTooLarge adding a third song, got %v", err)
+	}
+
+	service.SetMaxPlaylistSize(0)
+	if err := service.AddSongToPlaylist("full", "c", 2); err != nil {
+		t.Errorf("Expected no limit with a maximum of 0, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// Problems playlist validation can report for a song
+const (
+	SongIssueMissingSong  = "missing_song"
+	SongIssueMissingAudio = "missing_audio"
+	SongIssueZeroDuration = "zero_duration"
+)
+
+// ErrPlaylistNotFound is returned when validating a playlist that doesn't exist
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// SongValidation lists the problems found with one playlist entry
+type SongValidation struct {
+	YouTubeID string   `json:"youtube_id"`
+	Title     string   `json:"title,omitempty"`
+	Issues    []string `json:"issues"`
+}
+
+// PlaylistValidationReport is the per-song health of a playlist
+type PlaylistValidationReport struct {
+	PlaylistID string           `json:"playlist_id"`
+	Healthy    int              `json:"healthy"`
+	Broken     int              `json:"broken"`
+	Songs      []SongValidation `json:"songs"`
+}
+
+// PlaylistSongLister reads a playlist and the song IDs it lists
+type PlaylistSongLister interface {
+	GetByID(playlistID string) (*models.Playlist, error)
+	GetSongIDs(playlistID string) ([]string, error)
+}
+
+// SongBatchLookup fetches many songs in one query
+type SongBatchLookup interface {
+	GetByYouTubeIDs(youtubeIDs []string) (map[string]*models.Song, error)
+}
+
+// PlaylistValidator checks that every song in a playlist can be played
+type PlaylistValidator struct {
+	playlists PlaylistSongLister
+	songs     SongBatchLookup
+	storage   S3ServiceInterface
+}
+
+func NewPlaylistValidator(playlists PlaylistSongLister, songs SongBatchLookup, storage S3ServiceInterface) *PlaylistValidator {
+	return &PlaylistValidator{
+		playlists: playlists,
+		songs:     songs,
+		storage:   storage,
+	}
+}
+
+// Validate reports songs whose row is missing, whose audio isn't in storage
+// or whose duration is unknown
+func (v *PlaylistValidator) Validate(ctx context.Context, playlistID string) (*PlaylistValidationReport, error) {
+	playlist, err := v.playlists.GetByID(playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist: %w", err)
+	}
+	if playlist == nil {
+		return nil, ErrPlaylistNotFound
+	}
+
+	ids, err := v.playlists.GetSongIDs(playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist songs: %w", err)
+	}
+	songs, err := v.songs.GetByYouTubeIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up songs: %w", err)
+	}
+
+	report := &PlaylistValidationReport{
+		PlaylistID: playlistID,
+		Songs:      make([]SongValidation, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result := SongValidation{YouTubeID: id, Issues: []string{}}
+
+		song, ok := songs[id]
+		if !ok {
+			result.Issues = append(result.Issues, SongIssueMissingSong)
+		} else {
+			result.Title = song.Title
+			if song.Duration <= 0 {
+				result.Issues = append(result.Issues, SongIssueZeroDuration)
+			}
+		}
+
+		audio, err := FindSongAudio(ctx, v.storage, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check audio for %s: %w", id, err)
+		}
+		if audio == nil {
+			result.Issues = append(result.Issues, SongIssueMissingAudio)
+		}
+
+		if len(result.Issues) == 0 {
+			report.Healthy++
+		} else {
+			report.Broken++
+		}
+		report.Songs = append(report.Songs, result)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+// PrefetchStatus shows whether downloads are keeping up with playback. A
+// backlog of pending downloads that keeps growing comes before dead air.
+type PrefetchStatus struct {
+	QueueLength int `json:"queue_length"`
+	// PredownloadCount is how many songs after the current one are fetched ahead
+	PredownloadCount int `json:"predownload_count"`
+	// PendingDownloads counts the current song and the songs fetched ahead
+	// of it whose audio isn't known to be in storage yet
+	PendingDownloads int `json:"pending_downloads"`
+	// InFlightDownloads counts the downloads running right now
+	InFlightDownloads int `json:"in_flight_downloads"`
+}
+
+// PrefetchStatus reads the queue and download state in one lock acquisition
+func (s *RadioService) PrefetchStatus() PrefetchStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := PrefetchStatus{
+		PredownloadCount:  s.predownloadCount,
+		InFlightDownloads: len(s.fetchSlots),
+	}
+	if s.state == nil {
+		return status
+	}
+
+	status.QueueLength = len(s.state.Queue)
+	if s.audioFetcher == nil {
+		// Nothing is downloaded, so nothing can be pending
+		return status
+	}
+	for _, song := range s.upcomingSongs(s.state.Queue, s.state.CurrentSongIndex) {
+		if !s.downloaded[song.YouTubeID] {
+			status.PendingDownloads++
+		}
+	}
+	return status
+}
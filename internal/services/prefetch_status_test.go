@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// blockingFetcher holds every download until release is closed
+type blockingFetcher struct {
+	started chan string
+	release chan struct{}
+}
+
+func (f *blockingFetcher) Fetch(ctx context.Context, youtubeID string) (bool, error) {
+	f.started <- youtubeID
+	<-f.release
+	return true, nil
+}
+
+func TestPrefetchStatusTracksDownloads(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, &recordingEventBus{})
+	if status := service.PrefetchStatus(); status != (PrefetchStatus{PredownloadCount: 1}) {
+		t.Errorf("Expected an empty status without a fetcher or queue, got %+v", status)
+	}
+
+	fetcher := &blockingFetcher{started: make(chan string, 10), release: make(chan struct{})}
+	service.SetAudioFetcher(fetcher)
+	service.SetPredownloadCount(2)
+	for i := 0; i < 5; i++ {
+		service.state.Queue = append(service.state.Queue, createTestSong(fmt.Sprintf("song%d", i), "Song", "Artist", 180))
+	}
+
+	want := PrefetchStatus{QueueLength: 5, PredownloadCount: 2, PendingDownloads: 3}
+	if status := service.PrefetchStatus(); status != want {
+		t.Errorf("Expected %+v before any download, got %+v", want, status)
+	}
+
+	// Only maxConcurrentFetches of the three downloads run at once
+	service.ensureSongsDownloaded(service.upcomingSongs(service.state.Queue, 0)...)
+	for i := 0; i < maxConcurrentFetches; i++ {
+		select {
+		case <-fetcher.started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for downloads to start")
+		}
+	}
+	want.InFlightDownloads = maxConcurrentFetches
+	if status := service.PrefetchStatus(); status != want {
+		t.Errorf("Expected %+v while downloading, got %+v", want, status)
+	}
+
+	close(fetcher.release)
+	deadline := time.Now().Add(2 * time.Second)
+	want = PrefetchStatus{QueueLength: 5, PredownloadCount: 2}
+	for service.PrefetchStatus() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %+v once downloads finish, got %+v", want, service.PrefetchStatus())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Moving on brings songs that haven't been fetched into the window
+	service.state.Queue = append(service.state.Queue, &models.Song{YouTubeID: "extra"})
+	service.state.CurrentSongIndex = 2
+	want = PrefetchStatus{QueueLength: 6, PredownloadCount: 2, PendingDownloads: 2}
+	if status := service.PrefetchStatus(); status != want {
+		t.Errorf("Expected %+v after advancing, got %+v", want, status)
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// presignSafetyMargin is how long before a presigned URL expires it stops
+// being handed out, so clients get time to fetch it
+const presignSafetyMargin = time.Minute
+
+type presignCacheEntry struct {
+	url       string
+	expires   time.Duration
+	expiresAt time.Time
+}
+
+// PresignCache wraps storage so presigned URLs are reused until shortly before
+// they expire instead of being signed again for every request or broadcast
+type PresignCache struct {
+	S3ServiceInterface
+	margin time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]presignCacheEntry
+}
+
+// NewPresignCache caches the presigned URLs storage hands out, keyed by
+// storage key
+func NewPresignCache(storage S3ServiceInterface) *PresignCache {
+	return &PresignCache{
+		S3ServiceInterface: storage,
+		margin:             presignSafetyMargin,
+		now:                time.Now,
+		entries:            make(map[string]presignCacheEntry),
+	}
+}
+
+// GetPresignedURL returns a cached URL for key when one signed for expires is
+// still good, otherwise it signs a new one
+func (c *PresignCache) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	url, _, err := c.PresignedURL(ctx, key, expires)
+	return url, err
+}
+
+// PresignedURL is GetPresignedURL that also returns when the URL expires,
+// which is earlier than expires from now for a cached URL
+func (c *PresignCache) PresignedURL(ctx context.Context, key string, expires time.Duration) (string, time.Time, error) {
+	now := c.now()
+	// Short expiries keep at least half their lifetime cached
+	margin := min(c.margin, expires/2)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.expires == expires && now.Before(entry.expiresAt.Add(-margin)) {
+		return entry.url, entry.expiresAt, nil
+	}
+
+	url, err := c.S3ServiceInterface.GetPresignedURL(ctx, key, expires)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	entry = presignCacheEntry{url: url, expires: expires, expiresAt: now.Add(expires)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cachedKey, cached := range c.entries {
+		if !now.Before(cached.expiresAt) {
+			delete(c.entries, cachedKey)
+		}
+	}
+	c.entries[key] = entry
+	return entry.url, entry.expiresAt, nil
+}
+
+// DeleteFile deletes key from storage and forgets any URL cached for it
+func (c *PresignCache) DeleteFile(ctx context.Context, key string) error {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+	return c.S3ServiceInterface.DeleteFile(ctx, key)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// signingStorage hands out a new URL each time one is presigned
+type signingStorage struct {
+	*memoryStorage
+	signed int
+}
+
+func (s *signingStorage) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	s.signed++
+	return fmt.Sprintf("https://example.com/%s?signature=%d", key, s.signed), nil
+}
+
+func TestPresignCacheReusesURLsUntilNearExpiry(t *testing.T) {
+	storage := &signingStorage{memoryStorage: newMemoryStorage()}
+	cache := NewPresignCache(storage)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+	key := SongAudioKey("dQw4w9WgXcQ")
+
+	first, expiresAt, err := cache.PresignedURL(ctx, key, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignedURL failed: %v", err)
+	}
+	if !expiresAt.Equal(now.Add(15 * time.Minute)) {
+		t.Errorf("Expected the URL to expire at %v, got %v", now.Add(15*time.Minute), expiresAt)
+	}
+
+	// Repeated requests inside the window get the same URL and expiry
+	now = now.Add(13 * time.Minute)
+	for i := 0; i < 3; i++ {
+		url, cachedExpiry, err := cache.PresignedURL(ctx, key, 15*time.Minute)
+		if err != nil {
+			t.Fatalf("PresignedURL failed: %v", err)
+		}
+		if url != first || !cachedExpiry.Equal(expiresAt) {
+			t.Errorf("Expected the cached URL %s expiring at %v, got %s expiring at %v", first, expiresAt, url, cachedExpiry)
+		}
+	}
+	if storage.signed != 1 {
+		t.Errorf("Expected one URL signed, got %d", storage.signed)
+	}
+
+	// Within the safety margin of expiry a new URL is signed
+	now = now.Add(time.Minute + time.Second)
+	url, err := cache.GetPresignedURL(ctx, key, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GetPresignedURL failed: %v", err)
+	}
+	if url == first || storage.signed != 2 {
+		t.Errorf("Expected a newly signed URL, got %s after %d signings", url, storage.signed)
+	}
+
+	// A different expiry or key isn't served from the cache
+	if _, err := cache.GetPresignedURL(ctx, key, time.Hour); err != nil {
+		t.Fatalf("GetPresignedURL failed: %v", err)
+	}
+	if _, err := cache.GetPresignedURL(ctx, SongAudioKey("other"), time.Hour); err != nil {
+		t.Fatalf("GetPresignedURL failed: %v", err)
+	}
+	if storage.signed != 4 {
+		t.Errorf("Expected 4 URLs signed, got %d", storage.signed)
+	}
+}
+
+func TestPresignCacheShortExpiry(t *testing.T) {
+	storage := &signingStorage{memoryStorage: newMemoryStorage()}
+	cache := NewPresignCache(storage)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A margin longer than the expiry still caches for half the lifetime
+	for _, elapsed := range []time.Duration{0, 10 * time.Second, 20 * time.Second} {
+		now = now.Add(elapsed)
+		if _, err := cache.GetPresignedURL(ctx, "songs/a.mp3", 40*time.Second); err != nil {
+			t.Fatalf("GetPresignedURL failed: %v", err)
+		}
+	}
+	if storage.signed != 2 {
+		t.Errorf("Expected 2 URLs signed, got %d", storage.signed)
+	}
+}
+
+func TestPresignCacheForgetsDeletedFiles(t *testing.T) {
+	storage := &signingStorage{memoryStorage: newMemoryStorage()}
+	storage.files["songs/a.mp3"] = []byte("audio")
+	cache := NewPresignCache(storage)
+	ctx := context.Background()
+
+	first, _ := cache.GetPresignedURL(ctx, "songs/a.mp3", time.Hour)
+	if err := cache.DeleteFile(ctx, "songs/a.mp3"); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+	if _, ok := storage.files["songs/a.mp3"]; ok {
+		t.Error("Expected the file deleted from storage")
+	}
+	if url, _ := cache.GetPresignedURL(ctx, "songs/a.mp3", time.Hour); url == first {
+		t.Error("Expected a new URL after the file was deleted")
+	}
+}
+
+func TestAudioURLsReportCachedExpiry(t *testing.T) {
+	storage := &signingStorage{memoryStorage: newMemoryStorage()}
+	storage.files[SongAudioKey("stored")] = []byte("audio")
+	cache := NewPresignCache(storage)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	urls := NewAudioURLs(cache, time.Hour)
+
+	first, err := urls.SongURL(context.Background(), "stored")
+	if err != nil {
+		t.Fatalf("SongURL failed: %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+	second, err := urls.SongURL(context.Background(), "stored")
+	if err != nil {
+		t.Fatalf("SongURL failed: %v", err)
+	}
+	if second.URL != first.URL || !second.ExpiresAt.Equal(*first.ExpiresAt) {
+		t.Errorf("Expected the cached URL with its original expiry, got %+v then %+v", first, second)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// Queue sources selectable through QUEUE_SOURCE
+const (
+	QueueSourcePlaylist = "playlist"
+	QueueSourceRandom   = "random"
+)
+
+// DefaultRandomBatchSize is how many songs RandomQueueSource queues at a time
+const DefaultRandomBatchSize = 20
+
+// QueueSource supplies the songs the radio plays. Each time the queue runs
+// out, RadioService asks for the next batch and plays it in order.
+type QueueSource interface {
+	NextBatch(ctx context.Context) ([]*models.Song, error)
+}
+
+// playlistBacked is implemented by sources that play a stored playlist, so
+// the radio can report which one is on air
+type playlistBacked interface {
+	Playlist() *models.Playlist
+}
+
+// PlaylistQueueSource plays a stored playlist, reshuffled on every pass.
+// It is the source equivalent of the radio's built-in playlist playback.
+type PlaylistQueueSource struct {
+	playlistRepo PlaylistRepositoryInterface
+	playlist     *models.Playlist
+}
+
+func NewPlaylistQueueSource(playlistRepo PlaylistRepositoryInterface, playlist *models.Playlist) *PlaylistQueueSource {
+	return &PlaylistQueueSource{
+		playlistRepo: playlistRepo,
+		playlist:     playlist,
+	}
+}
+
+// Playlist returns the playlist the source plays
+func (p *PlaylistQueueSource) Playlist() *models.Playlist {
+	return p.playlist
+}
+
+// NextBatch returns the playlist's songs in a new random order
+func (p *PlaylistQueueSource) NextBatch(ctx context.Context) ([]*models.Song, error) {
+	songs, err := p.playlistRepo.GetSongs(p.playlist.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playlist songs: %w", err)
+	}
+
+	shuffled := make([]*models.Song, len(songs))
+	copy(shuffled, songs)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled, nil
+}
+
+// RandomQueueSource plays random songs from the whole library
+type RandomQueueSource struct {
+	songRepo  SongRepositoryInterface
+	batchSize int
+}
+
+// NewRandomQueueSource creates a source that queues batchSize random songs
+// at a time. Non-positive sizes use DefaultRandomBatchSize.
+func NewRandomQueueSource(songRepo SongRepositoryInterface, batchSize int) *RandomQueueSource {
+	if batchSize <= 0 {
+		batchSize = DefaultRandomBatchSize
+	}
+	return &RandomQueueSource{
+		songRepo:  songRepo,
+		batchSize: batchSize,
+	}
+}
+
+// NextBatch picks up to batchSize distinct random songs. Small libraries
+// may yield fewer songs than requested.
+func (r *RandomQueueSource) NextBatch(ctx context.Context) ([]*models.Song, error) {
+	seen := make(map[string]bool, r.batchSize)
+	songs := make([]*models.Song, 0, r.batchSize)
+
+	// Random picks repeat, so allow a few extra draws before giving up
+	for attempts := 0; attempts < r.batchSize*2 && len(songs) < r.batchSize; attempts++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		song, err := r.songRepo.GetRandomSong()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get random song: %w", err)
+		}
+		if song == nil {
+			break
+		}
+		if seen[song.YouTubeID] {
+			continue
+		}
+		seen[song.YouTubeID] = true
+		songs = append(songs, song)
+	}
+	return songs, nil
+}
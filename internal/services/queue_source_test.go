@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// fakeQueueSource hands out its batches in order, repeating the last one
+type fakeQueueSource struct {
+	mu      sync.Mutex
+	batches [][]*models.Song
+	calls   int
+}
+
+func (f *fakeQueueSource) NextBatch(ctx context.Context) ([]*models.Song, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	batch := f.batches[min(f.calls, len(f.batches)-1)]
+	f.calls++
+	return batch, nil
+}
+
+func TestQueueSourceDrivesPlayback(t *testing.T) {
+	source := &fakeQueueSource{batches: [][]*models.Song{
+		{createTestSong("a", "A", "Artist", 1), createTestSong("b", "B", "Artist", 1)},
+		{createTestSong("c", "C", "Artist", 1), createTestSong("d", "D", "Artist", 1)},
+	}}
+
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetQueueSource(source)
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	// Batches are played in the order the source gives them
+	if song := service.GetCurrentSong(); song.YouTubeID != "a" {
+		t.Fatalf("Expected first song a, got %s", song.YouTubeID)
+	}
+
+	clock.Advance(t, 1200*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID != "b" {
+		t.Fatalf("Expected second song b, got %s", song.YouTubeID)
+	}
+
+	// Running out of songs pulls the next batch
+	clock.Advance(t, time.Second)
+	if song := service.GetCurrentSong(); song.YouTubeID != "c" {
+		t.Fatalf("Expected next batch to start with c, got %s", song.YouTubeID)
+	}
+	if queue := service.GetQueueInfo().Queue; len(queue) != 2 || queue[1].YouTubeID != "d" {
+		t.Errorf("Expected queue to hold the second batch, got %v", queue)
+	}
+}
+
+func TestSetActivePlaylistClearsQueueSource(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.playlists["1"] = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{createTestSong("song1", "Song 1", "Artist 1", 180)}
+
+	service := NewRadioService(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus())
+	service.SetQueueSource(&fakeQueueSource{batches: [][]*models.Song{{createTestSong("a", "A", "Artist", 1)}}})
+
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("Failed to set active playlist: %v", err)
+	}
+	if batch := service.nextSourceBatch(); batch != nil {
+		t.Errorf("Expected queue source to be cleared, got batch %v", batch)
+	}
+}
+
+func TestPlaylistQueueSource(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlist := createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+	}
+
+	source := NewPlaylistQueueSource(playlistRepo, playlist)
+	songs, err := source.NextBatch(context.Background())
+	if err != nil {
+		t.Fatalf("NextBatch failed: %v", err)
+	}
+	if len(songs) != 2 {
+		t.Errorf("Expected 2 songs, got %d", len(songs))
+	}
+	if source.Playlist() != playlist {
+		t.Error("Expected source to report its playlist")
+	}
+}
+
+func TestRandomQueueSourceSkipsRepeats(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	songRepo.randomSong = createTestSong("only", "Only Song", "Artist", 180)
+
+	songs, err := NewRandomQueueSource(songRepo, 5).NextBatch(context.Background())
+	if err != nil {
+		t.Fatalf("NextBatch failed: %v", err)
+	}
+	if len(songs) != 1 || songs[0].YouTubeID != "only" {
+		t.Errorf("Expected the single library song once, got %v", songs)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+const (
+	// DefaultQueueURLCount is how many songs after the current one get URLs by default
+	DefaultQueueURLCount = 3
+	// MaxQueueURLCount caps how many songs after the current one get URLs
+	MaxQueueURLCount = 10
+)
+
+// SongURL is where a client can fetch a song's audio ahead of playing it
+type SongURL struct {
+	YouTubeID string `json:"youtube_id"`
+	// URL is empty while the song's audio isn't in storage yet
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// expiringPresigner presigns URLs and reports when they expire, e.g. a
+// PresignCache handing out a URL signed earlier
+type expiringPresigner interface {
+	PresignedURL(ctx context.Context, key string, expires time.Duration) (string, time.Time, error)
+}
+
+// AudioURLs hands out URLs for song audio: presigned storage URLs clients
+// fetch directly, or the server's own file endpoint when presigning is off
+type AudioURLs struct {
+	storage S3ServiceInterface
+	expiry  time.Duration
+}
+
+// NewAudioURLs presigns URLs valid for expiry. A zero expiry serves audio
+// through /api/v1/playlists/{youtube_id}/file instead, and storage may be nil.
+func NewAudioURLs(storage S3ServiceInterface, expiry time.Duration) *AudioURLs {
+	return &AudioURLs{storage: storage, expiry: expiry}
+}
+
+// SongURL returns the URL to fetch a song's audio from
+func (u *AudioURLs) SongURL(ctx context.Context, youtubeID string) (*SongURL, error) {
+	songURL := &SongURL{YouTubeID: youtubeID}
+	if u.expiry <= 0 {
+		songURL.URL = "/api/v1/playlists/" + youtubeID + "/file"
+		return songURL, nil
+	}
+
+	audio, err := FindSongAudio(ctx, u.storage, youtubeID)
+	if err != nil || audio == nil {
+		return songURL, err
+	}
+	url, expiresAt, err := u.presign(ctx, audio.Key)
+	if err != nil {
+		return nil, err
+	}
+	songURL.URL = url
+	songURL.ExpiresAt = &expiresAt
+	return songURL, nil
+}
+
+// presign returns a presigned URL for key and when it expires
+func (u *AudioURLs) presign(ctx context.Context, key string) (string, time.Time, error) {
+	if presigner, ok := u.storage.(expiringPresigner); ok {
+		return presigner.PresignedURL(ctx, key, u.expiry)
+	}
+	expiresAt := time.Now().Add(u.expiry)
+	url, err := u.storage.GetPresignedURL(ctx, key, u.expiry)
+	return url, expiresAt, err
+}
+
+// QueueSongs returns the current song and up to count songs after it, read in
+// one lock acquisition. Non-positive counts fall back to the default and
+// large ones are clamped.
+func (s *RadioService) QueueSongs(count int) []*models.Song {
+	if count <= 0 {
+		count = DefaultQueueURLCount
+	}
+	count = min(count, MaxQueueURLCount)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	songs := []*models.Song{}
+	if s.state == nil {
+		return songs
+	}
+	index := s.state.CurrentSongIndex
+	if index < 0 || index >= len(s.state.Queue) {
+		return songs
+	}
+	end := min(index+1+count, len(s.state.Queue))
+	return append(songs, s.state.Queue[index:end]...)
+}
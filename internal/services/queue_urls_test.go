@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQueueSongsClampsCount(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, &recordingEventBus{})
+	if songs := service.QueueSongs(3); len(songs) != 0 {
+		t.Errorf("Expected no songs with an empty queue, got %d", len(songs))
+	}
+
+	for i := 0; i < 20; i++ {
+		service.state.Queue = append(service.state.Queue, createTestSong(fmt.Sprintf("song%d", i), "Song", "Artist", 180))
+	}
+	service.state.CurrentSongIndex = 2
+
+	tests := []struct {
+		count int
+		want  int
+	}{
+		{count: 0, want: 1 + DefaultQueueURLCount},
+		{count: -1, want: 1 + DefaultQueueURLCount},
+		{count: 1, want: 2},
+		{count: 100, want: 1 + MaxQueueURLCount},
+	}
+	for _, tt := range tests {
+		songs := service.QueueSongs(tt.count)
+		if len(songs) != tt.want {
+			t.Errorf("count=%d: expected %d songs, got %d", tt.count, tt.want, len(songs))
+			continue
+		}
+		if songs[0].YouTubeID != "song2" {
+			t.Errorf("count=%d: expected the current song first, got %s", tt.count, songs[0].YouTubeID)
+		}
+	}
+
+	// Near the end of the queue only the songs left are returned
+	service.state.CurrentSongIndex = 18
+	if songs := service.QueueSongs(5); len(songs) != 2 {
+		t.Errorf("Expected the last 2 songs, got %d", len(songs))
+	}
+}
+
+func TestAudioURLs(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.files[SongAudioKey("stored")] = []byte("audio")
+	storage.files[SongAudioKeyFor("opus", AudioFormatOpus)] = []byte("audio")
+
+	tests := []struct {
+		name      string
+		expiry    time.Duration
+		youtubeID string
+		want      string
+	}{
+		{name: "file", youtubeID: "stored", want: "/api/v1/playlists/stored/file"},
+		{name: "file without stored audio", youtubeID: "missing", want: "/api/v1/playlists/missing/file"},
+		{name: "presigned", expiry: time.Hour, youtubeID: "stored", want: "https://example.com/" + SongAudioKey("stored")},
+		{name: "presigned in another format", expiry: time.Hour, youtubeID: "opus", want: "https://example.com/" + SongAudioKeyFor("opus", AudioFormatOpus)},
+		{name: "presigned without stored audio", expiry: time.Hour, youtubeID: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := NewAudioURLs(storage, tt.expiry).SongURL(context.Background(), tt.youtubeID)
+			if err != nil {
+				t.Fatalf("SongURL failed: %v", err)
+			}
+			if url.YouTubeID != tt.youtubeID || url.URL != tt.want {
+				t.Errorf("Expected %q for %s, got %q for %s", tt.want, tt.youtubeID, url.URL, url.YouTubeID)
+			}
+
+			wantExpiry := tt.expiry > 0 && tt.want != ""
+			if (url.ExpiresAt != nil) != wantExpiry {
+				t.Fatalf("Expected an expiry: %v, got %v", wantExpiry, url.ExpiresAt)
+			}
+			if wantExpiry && time.Until(*url.ExpiresAt) > tt.expiry {
+				t.Errorf("Expected the URL to expire within %v, got %v", tt.expiry, url.ExpiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// SetAnnouncer has the next song announced during the interstitial gap
+// before it. Announcements need a gap long enough to hold the clip, so with
+// no gap configured nothing is announced. It must be called before
+// StartPlaybackLoop.
+func (s *RadioService) SetAnnouncer(announcer *Announcer) {
+	s.announcer = announcer
+}
+
+// announceNext prepares the next song's announcement while the current song
+// plays, then publishes it as the gap after the song begins so clients can
+// play the clip before the next song starts. A clip that isn't ready by then
+// is skipped rather than holding up playback.
+func (s *RadioService) announceNext(remaining time.Duration) {
+	s.mu.Lock()
+	if s.state == nil || s.interstitialGap <= 0 || remaining <= 0 {
+		s.mu.Unlock()
+		return
+	}
+	// The song after the last one isn't known until the queue is refilled
+	index := s.state.CurrentSongIndex
+	if index < 0 || index+1 >= len(s.state.Queue) || s.state.Queue[index+1] == nil {
+		s.mu.Unlock()
+		return
+	}
+	next := s.state.Queue[index+1]
+	inGap := remaining <= s.interstitialGap
+	due := inGap && !s.announcedSlot.Equal(s.state.StartTime)
+	if due {
+		s.announcedSlot = s.state.StartTime
+	}
+	s.mu.Unlock()
+
+	if !inGap {
+		s.announcer.PrepareAsync(next)
+		return
+	}
+	if !due {
+		return
+	}
+
+	announcement, ok := s.announcer.Ready(next)
+	if !ok {
+		log.Printf("[WARN] announceNext: Announcement for %s isn't ready, playing it unannounced", next.YouTubeID)
+		return
+	}
+	if s.eventBus != nil {
+		s.eventBus.PublishAnnouncement(next, announcement.ID, announcement.Text)
+	}
+}
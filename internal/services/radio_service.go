@@ -2,24 +2,65 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/feline-dis/go-radio-v2/internal/metrics"
 	"github.com/feline-dis/go-radio-v2/internal/models"
 )
 
 // Test configuration - set to 5 seconds for faster song transitions during testing
 const TestSongDuration = 5 * time.Second
 
+// MinPlayableSongDuration is the default lower bound on how long a song is
+// scheduled for, so missing (zero) or implausibly short durations don't make
+// the playback loop skip through songs instantly
+const MinPlayableSongDuration = 30 * time.Second
+
+// DefaultVolume is the level playback starts at
+const DefaultVolume = 1.0
+
+// playbackLoopLogWindow is how long identical playback loop log lines are collapsed for
+const playbackLoopLogWindow = 30 * time.Second
+
+// playbackTickInterval is how often the playback loop checks whether the current song has ended
+const playbackTickInterval = 100 * time.Millisecond
+
+// maxConcurrentFetches bounds how many songs are downloaded at once
+const maxConcurrentFetches = 2
+
+var (
+	// ErrSongNotFound is returned when queueing a song that isn't in the library
+	ErrSongNotFound = errors.New("song not found")
+	// ErrPlaybackRunning is returned when starting playback that is already running
+	ErrPlaybackRunning = errors.New("playback is already running")
+	// ErrPlaybackNotRunning is returned when stopping playback that isn't running
+	ErrPlaybackNotRunning = errors.New("playback is not running")
+	// ErrNothingPlaying is returned when changing the queue before playback starts
+	ErrNothingPlaying = errors.New("nothing is playing")
+)
+
 // Interfaces for dependency injection and testing
 type SongRepositoryInterface interface {
 	GetRandomSong() (*models.Song, error)
 	GetLeastPlayedSong() (*models.Song, error)
 	UpdatePlayStats(youtubeID string) error
+	GetRecentlyAdded(limit int) ([]*models.Song, error)
+	GetByYouTubeID(youtubeID string) (*models.Song, error)
+	GetWithoutDuration() ([]*models.Song, error)
+	UpdateDuration(youtubeID string, duration int) error
+	GetByTag(tag string) ([]*models.Song, error)
+	UpdateTags(youtubeID string, tags models.Tags) error
+	GetArtists() ([]*models.ArtistSummary, error)
+	GetAlbums() ([]*models.AlbumSummary, error)
+	GetByArtist(artist string) ([]*models.Song, error)
 }
 
 type PlaylistRepositoryInterface interface {
@@ -31,16 +72,50 @@ type PlaylistRepositoryInterface interface {
 type S3ServiceInterface interface {
 	GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
 	UploadFile(ctx context.Context, key string, body io.Reader) error
+	GetFile(ctx context.Context, key string) (io.ReadCloser, error)
+	FileExists(ctx context.Context, key string) (bool, error)
+	FileSize(ctx context.Context, key string) (int64, bool, error)
 	DeleteFile(ctx context.Context, key string) error
 }
 
+// SongAudioFetcher makes sure a song's audio is in storage, reporting whether
+// it had to be downloaded
+type SongAudioFetcher interface {
+	Fetch(ctx context.Context, youtubeID string) (bool, error)
+}
+
 type EventBusInterface interface {
 	PublishSongChange(currentSong, nextSong *models.Song, queueInfo *models.QueueInfo)
 	PublishQueueUpdate(queueInfo *models.QueueInfo)
-	PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool)
+	PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool, volume float64)
 	PublishSkip(song *models.Song, nextSong *models.Song, state *models.PlaybackState)
 	PublishPrevious(song *models.Song, nextSong *models.Song, state *models.PlaybackState)
 	PublishPlaylistChange(song *models.Song, nextSong *models.Song, playlist *models.Playlist, state *models.PlaybackState)
+	PublishPlaybackStopped(playlist *models.Playlist)
+	PublishPlaybackStarted(playlist *models.Playlist)
+	PublishAnnouncement(song *models.Song, announcementID, text string)
+	PublishVoteUpdate(song *models.Song, votes, required int)
+}
+
+// RepeatMode decides what happens when playback reaches the end of the queue
+type RepeatMode string
+
+const (
+	// RepeatAll reshuffles and starts the queue again
+	RepeatAll RepeatMode = "all"
+	// RepeatOff stops playback once the queue has played through
+	RepeatOff RepeatMode = "off"
+	// RepeatOne plays the current song again each time it ends
+	RepeatOne RepeatMode = "one"
+)
+
+// ParseRepeatMode parses a repeat mode name
+func ParseRepeatMode(name string) (RepeatMode, error) {
+	switch mode := RepeatMode(name); mode {
+	case RepeatAll, RepeatOff, RepeatOne:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown repeat mode %q, expected %q, %q or %q", name, RepeatAll, RepeatOff, RepeatOne)
 }
 
 type RadioService struct {
@@ -51,6 +126,85 @@ type RadioService struct {
 	state        *models.PlaybackState
 	mu           sync.RWMutex
 	randMu       sync.Mutex // For thread-safe random number generation
+	clock        Clock
+
+	// interstitialGap is the silence held after each song before the next one starts
+	interstitialGap time.Duration
+
+	// announcer speaks the next song's title during the gap, when set.
+	// announcedSlot is the start of the song whose gap was last announced.
+	announcer     *Announcer
+	announcedSlot time.Time
+
+	// minSongDuration and maxSongDuration clamp how long a song is scheduled
+	// for; a zero maxSongDuration means no upper limit
+	minSongDuration time.Duration
+	maxSongDuration time.Duration
+	clampWarned     sync.Map // song IDs already warned about
+
+	// loopLog dedupes the playback loop's repetitive log lines
+	loopLog *logLimiter
+
+	// playlistCache saves repeated GetSongs queries on playlist switches
+	playlistCache *playlistSongsCache
+
+	// audioFetcher downloads songs missing from storage as they come up;
+	// fetching holds the songFetch of each download in flight by ID and
+	// fetchSlots bounds how many run at once
+	audioFetcher SongAudioFetcher
+	fetching     sync.Map
+	fetchSlots   chan struct{}
+
+	// predownloadCount is how many songs after the current one are fetched
+	// ahead of time
+	predownloadCount int
+
+	// fetchFailures counts each song's consecutive failed downloads; songs
+	// reaching downloadFailureLimit go in disabledSongs and leave the queue
+	fetchFailures        map[string]int
+	disabledSongs        map[string]bool
+	downloadFailureLimit int
+
+	// downloaded holds the songs whose audio a fetch found or put in storage
+	downloaded map[string]bool
+
+	// queueSource replaces the built-in playlist playback when set
+	queueSource QueueSource
+
+	// repeatMode decides whether the queue restarts after the last song
+	repeatMode RepeatMode
+
+	// excludedTags keeps songs carrying any of these tags out of the queue
+	excludedTags []string
+
+	// shuffle plays playlists in random order; when false they play in
+	// stored order
+	shuffle bool
+
+	// history holds the songs played before the current one, for Previous
+	history *playHistory
+
+	// loopMu serializes starting and stopping the playback loop. loopStop
+	// is closed to end the running loop and loopDone closes once it has;
+	// both are nil while no loop runs.
+	loopMu   sync.Mutex
+	loopStop chan struct{}
+	loopDone chan struct{}
+
+	// stopped is set while an operator has taken playback off the air
+	stopped bool
+
+	// videoInfo looks up songs EnqueueSong can't find in the library, which
+	// are saved to library
+	videoInfo VideoInfoFetcher
+	library   SongCreator
+
+	// listeners counts connected clients for vote skipping; skipVotes holds
+	// the votes to skip the current song and voteSkipPercent is the share of
+	// listeners the votes must exceed
+	listeners       ListenerCounter
+	skipVotes       skipVotes
+	voteSkipPercent int
 }
 
 func NewRadioService(
@@ -58,35 +212,355 @@ func NewRadioService(
 	playlistRepo PlaylistRepositoryInterface,
 	s3Service S3ServiceInterface,
 	eventBus EventBusInterface,
+) *RadioService {
+	return NewRadioServiceWithClock(songRepo, playlistRepo, s3Service, eventBus, RealClock{})
+}
+
+// NewRadioServiceWithClock creates a radio service that reads time from clock
+func NewRadioServiceWithClock(
+	songRepo SongRepositoryInterface,
+	playlistRepo PlaylistRepositoryInterface,
+	s3Service S3ServiceInterface,
+	eventBus EventBusInterface,
+	clock Clock,
 ) *RadioService {
 	// Initialize with a non-nil state
 	state := &models.PlaybackState{
-		Queue: make([]*models.Song, 0),
+		Queue:  make([]*models.Song, 0),
+		Volume: DefaultVolume,
 	}
+
+	loopLog := newLogLimiter(playbackLoopLogWindow)
+	loopLog.now = clock.Now
+	playlistCache := newPlaylistSongsCache(playlistCacheTTL)
+	playlistCache.now = clock.Now
+
 	return &RadioService{
 		songRepo:     songRepo,
 		playlistRepo: playlistRepo,
 		s3Service:    s3Service,
 		eventBus:     eventBus,
 		state:        state,
+		clock:        clock,
+
+		minSongDuration: MinPlayableSongDuration,
+		repeatMode:      RepeatAll,
+		shuffle:         true,
+		loopLog:         loopLog,
+		playlistCache:   playlistCache,
+		history:         newPlayHistory(playHistorySize),
+
+		fetchSlots:       make(chan struct{}, maxConcurrentFetches),
+		predownloadCount: 1,
+
+		fetchFailures:        make(map[string]int),
+		disabledSongs:        make(map[string]bool),
+		downloadFailureLimit: DefaultDownloadFailureLimit,
+		downloaded:           make(map[string]bool),
+
+		voteSkipPercent: DefaultVoteSkipPercent,
+	}
+}
+
+// InvalidatePlaylistCache drops cached songs for a playlist so the next
+// switch to it reads them fresh
+func (s *RadioService) InvalidatePlaylistCache(playlistID string) {
+	s.playlistCache.invalidate(playlistID)
+}
+
+// getPlaylistSongs returns a playlist's songs, from the cache when possible
+func (s *RadioService) getPlaylistSongs(playlistID string) ([]*models.Song, error) {
+	if songs, ok := s.playlistCache.get(playlistID); ok {
+		return s.playableSongs(songs), nil
+	}
+
+	songs, err := s.playlistRepo.GetSongs(playlistID)
+	if err != nil {
+		return nil, err
+	}
+	s.playlistCache.set(playlistID, songs)
+	return s.playableSongs(songs), nil
+}
+
+// SetExcludedTags keeps songs carrying any of tags out of the queue, e.g.
+// "explicit". It applies from the next playlist or batch queued.
+func (s *RadioService) SetExcludedTags(tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.excludedTags = models.NormalizeTags(tags)
+}
+
+// playableSongs filters out songs carrying an excluded tag and songs
+// dropped after failing to download
+func (s *RadioService) playableSongs(songs []*models.Song) []*models.Song {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.excludedTags) == 0 && len(s.disabledSongs) == 0 {
+		return songs
+	}
+
+	allowed := make([]*models.Song, 0, len(songs))
+	for _, song := range songs {
+		if !song.Tags.HasAny(s.excludedTags) && !s.disabledSongs[song.YouTubeID] {
+			allowed = append(allowed, song)
+		}
+	}
+	return allowed
+}
+
+// SetQueueSource makes the radio play batches from source instead of a
+// stored playlist. Switching playlists with SetActivePlaylist clears it.
+func (s *RadioService) SetQueueSource(source QueueSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueSource = source
+}
+
+// nextSourceBatch fetches the next batch from the queue source, if one is set.
+// Errors are logged and yield no songs so playback falls back to the current queue.
+func (s *RadioService) nextSourceBatch() []*models.Song {
+	s.mu.RLock()
+	source := s.queueSource
+	s.mu.RUnlock()
+	if source == nil {
+		return nil
+	}
+
+	songs, err := source.NextBatch(context.Background())
+	if err != nil {
+		log.Printf("[ERROR] nextSourceBatch: Failed to get next batch: %v", err)
+		return nil
+	}
+	return s.playableSongs(songs)
+}
+
+// atEndOfQueue reports whether the current song is the last one queued and
+// will be followed by whatever comes after the queue
+func (s *RadioService) atEndOfQueue() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.repeatMode != RepeatOne && s.state != nil && len(s.state.Queue) > 0 && s.state.CurrentSongIndex >= len(s.state.Queue)-1
+}
+
+// SetRepeatMode sets what happens when playback reaches the end of the queue
+func (s *RadioService) SetRepeatMode(mode RepeatMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repeatMode = mode
+}
+
+// upNext returns the song that plays when the one at index ends. The caller
+// must hold s.mu.
+func (s *RadioService) upNext(queue []*models.Song, index int) *models.Song {
+	if s.repeatMode == RepeatOne && index >= 0 && index < len(queue) {
+		return queue[index]
+	}
+	return nextInQueue(queue, index)
+}
+
+// SetShuffle picks whether playlists are shuffled when they are queued. With
+// shuffle off, playlists play, and repeat, in stored order.
+func (s *RadioService) SetShuffle(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shuffle = enabled
+}
+
+// queueOrder returns a copy of songs in the order they should be queued
+func (s *RadioService) queueOrder(songs []*models.Song) []*models.Song {
+	s.mu.RLock()
+	shuffle := s.shuffle
+	s.mu.RUnlock()
+
+	if shuffle {
+		return s.shuffleSongs(songs)
+	}
+	ordered := make([]*models.Song, len(songs))
+	copy(ordered, songs)
+	return ordered
+}
+
+// SetAudioFetcher makes the radio download songs missing from storage as
+// they come up, and StartPlaybackLoop wait for the first song to download.
+// It must be called before StartPlaybackLoop.
+func (s *RadioService) SetAudioFetcher(fetcher SongAudioFetcher) {
+	s.audioFetcher = fetcher
+}
+
+// ensureSongsDownloaded fetches the audio for each song in the background,
+// skipping songs that are already being fetched
+func (s *RadioService) ensureSongsDownloaded(songs ...*models.Song) {
+	if s.audioFetcher == nil {
+		return
+	}
+
+	for _, song := range songs {
+		if song != nil {
+			s.fetchSong(song.YouTubeID)
+		}
+	}
+}
+
+// songFetch is a download running in the background; err is set before done
+// is closed
+type songFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// fetchSong downloads the audio for youtubeID in the background, or joins the
+// download already running for it, so the same song is never fetched twice
+// at once. The caller must have checked that there is an audio fetcher.
+func (s *RadioService) fetchSong(youtubeID string) *songFetch {
+	fetch := &songFetch{done: make(chan struct{})}
+	if running, inFlight := s.fetching.LoadOrStore(youtubeID, fetch); inFlight {
+		return running.(*songFetch)
+	}
+
+	go func() {
+		defer close(fetch.done)
+		defer s.fetching.Delete(youtubeID)
+
+		s.fetchSlots <- struct{}{}
+		defer func() { <-s.fetchSlots }()
+
+		downloaded, err := s.audioFetcher.Fetch(context.Background(), youtubeID)
+		fetch.err = err
+		s.recordFetchResult(youtubeID, err)
+		if err != nil {
+			log.Printf("[ERROR] fetchSong: Failed to fetch %s: %v", youtubeID, err)
+			return
+		}
+		if downloaded {
+			log.Printf("[DEBUG] fetchSong: Downloaded %s to storage", youtubeID)
+		}
+	}()
+	return fetch
+}
+
+// SetPredownloadCount sets how many songs after the current one are
+// downloaded ahead of time, so quick skips land on audio that's ready.
+// Downloads still stop when the fetcher runs short of disk space. It must be
+// called before StartPlaybackLoop.
+func (s *RadioService) SetPredownloadCount(count int) {
+	s.predownloadCount = max(count, 0)
+}
+
+// upcomingSongs returns the song at index and the predownloadCount songs
+// after it, wrapping around the queue
+func (s *RadioService) upcomingSongs(queue []*models.Song, index int) []*models.Song {
+	if index < 0 || index >= len(queue) {
+		return nil
+	}
+	count := min(s.predownloadCount+1, len(queue))
+	songs := make([]*models.Song, 0, count)
+	for i := 0; i < count; i++ {
+		songs = append(songs, queue[(index+i)%len(queue)])
+	}
+	return songs
+}
+
+// nextInQueue returns the song after index, wrapping around the queue. A
+// queue of one song has no next song, it just plays again.
+func nextInQueue(queue []*models.Song, index int) *models.Song {
+	if len(queue) < 2 {
+		return nil
+	}
+	return queue[(index+1)%len(queue)]
+}
+
+// SetInterstitialGap configures the gap inserted between songs. Negative
+// values are treated as no gap.
+func (s *RadioService) SetInterstitialGap(gap time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if gap < 0 {
+		gap = 0
 	}
+	s.interstitialGap = gap
 }
 
+// SetSongDurationLimits configures the range song durations are clamped to.
+// A non-positive min keeps the current minimum; a non-positive max removes
+// the upper limit.
+func (s *RadioService) SetSongDurationLimits(min, max time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if min > 0 {
+		s.minSongDuration = min
+	}
+	if max > 0 && max < s.minSongDuration {
+		max = s.minSongDuration
+	}
+	if max < 0 {
+		max = 0
+	}
+	s.maxSongDuration = max
+}
+
+// songDuration returns how long the song plays once its offsets are applied,
+// clamped to the configured limits, warning once per song when a clamp
+// applies. Callers must hold s.mu.
+func (s *RadioService) songDuration(song *models.Song) time.Duration {
+	duration := time.Duration(song.PlayedDuration()) * time.Second
+
+	clamped := duration
+	if clamped < s.minSongDuration {
+		clamped = s.minSongDuration
+	}
+	if s.maxSongDuration > 0 && clamped > s.maxSongDuration {
+		clamped = s.maxSongDuration
+	}
+
+	if clamped != duration {
+		if _, warned := s.clampWarned.LoadOrStore(song.YouTubeID, true); !warned {
+			log.Printf("[WARN] songDuration: Song %s has duration %v, scheduling it for %v", song.YouTubeID, duration, clamped)
+		}
+	}
+	return clamped
+}
+
+// slotDuration returns how long a song occupies the schedule, including the
+// interstitial gap that follows it. Callers must hold s.mu.
+func (s *RadioService) slotDuration(song *models.Song) time.Duration {
+	return s.songDuration(song) + s.interstitialGap
+}
+
+// GetPlaybackState returns a copy of the playback state taken under the
+// lock, since pausing, resuming and volume changes update it in place
 func (s *RadioService) GetPlaybackState() *models.PlaybackState {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.state
+	if s.state == nil {
+		return nil
+	}
+	state := *s.state
+	state.Queue = slices.Clone(s.state.Queue)
+	return &state
 }
 
 func (s *RadioService) Next() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.skipToNext()
+}
+
+// skipToNext moves on to the next song in the queue and drops the skip votes
+// for the one that was playing. The caller must hold s.mu.
+func (s *RadioService) skipToNext() {
+	s.skipVotes = skipVotes{}
+
 	if s.state == nil || len(s.state.Queue) == 0 {
 		return
 	}
 
+	metrics.Skips.Inc()
+	s.songEnded()
+
 	// Move to next song
 	s.state.CurrentSongIndex = s.state.CurrentSongIndex + 1
 
@@ -95,17 +569,16 @@ func (s *RadioService) Next() {
 		s.state.CurrentSongIndex = 0
 	}
 
-	s.state.StartTime = time.Now()
+	s.state.StartTime = s.clock.Now()
+	// Changing songs by hand resumes playback
+	s.state.Paused = false
 
 	// Get current and next songs safely
 	var currentSong, nextSong *models.Song
 	if s.state.CurrentSongIndex < len(s.state.Queue) {
 		currentSong = s.state.Queue[s.state.CurrentSongIndex]
 	}
-	nextIndex := (s.state.CurrentSongIndex + 1) % len(s.state.Queue)
-	if nextIndex < len(s.state.Queue) {
-		nextSong = s.state.Queue[nextIndex]
-	}
+	nextSong = nextInQueue(s.state.Queue, s.state.CurrentSongIndex)
 
 	// Create queue info without additional locking
 	queueInfo := &models.QueueInfo{
@@ -116,9 +589,15 @@ func (s *RadioService) Next() {
 		CurrentSongIndex: s.state.CurrentSongIndex,
 	}
 
-	s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
+	s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
+	if s.eventBus != nil {
+		s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
+	}
 }
 
+// Previous goes back to the song played before the current one. After a
+// reshuffle or playlist switch that isn't the song before it in the queue, so
+// the play history decides; the queue order is only used when it's empty.
 func (s *RadioService) Previous() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -127,25 +606,28 @@ func (s *RadioService) Previous() {
 		return
 	}
 
-	// Move to previous song
-	s.state.CurrentSongIndex = s.state.CurrentSongIndex - 1
+	if previous := s.history.pop(); previous != nil {
+		s.rewindTo(previous)
+	} else {
+		// Move to previous song
+		s.state.CurrentSongIndex = s.state.CurrentSongIndex - 1
 
-	// Handle wrap-around at beginning of playlist
-	if s.state.CurrentSongIndex < 0 {
-		s.state.CurrentSongIndex = len(s.state.Queue) - 1
+		// Handle wrap-around at beginning of playlist
+		if s.state.CurrentSongIndex < 0 {
+			s.state.CurrentSongIndex = len(s.state.Queue) - 1
+		}
 	}
 
-	s.state.StartTime = time.Now()
+	s.state.StartTime = s.clock.Now()
+	// Changing songs by hand resumes playback
+	s.state.Paused = false
 
 	// Get current and next songs safely
 	var currentSong, nextSong *models.Song
 	if s.state.CurrentSongIndex < len(s.state.Queue) {
 		currentSong = s.state.Queue[s.state.CurrentSongIndex]
 	}
-	nextIndex := (s.state.CurrentSongIndex + 1) % len(s.state.Queue)
-	if nextIndex < len(s.state.Queue) {
-		nextSong = s.state.Queue[nextIndex]
-	}
+	nextSong = nextInQueue(s.state.Queue, s.state.CurrentSongIndex)
 
 	// Create queue info without additional locking
 	queueInfo := &models.QueueInfo{
@@ -156,7 +638,49 @@ func (s *RadioService) Previous() {
 		CurrentSongIndex: s.state.CurrentSongIndex,
 	}
 
-	s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
+	s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
+	if s.eventBus != nil {
+		s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
+	}
+}
+
+// recordPlayed adds the current song to the play history before it's
+// replaced, reporting whether there was one. The caller must hold s.mu.
+func (s *RadioService) recordPlayed() bool {
+	if s.state == nil || s.state.CurrentSongIndex < 0 || s.state.CurrentSongIndex >= len(s.state.Queue) {
+		return false
+	}
+	s.history.push(s.state.Queue[s.state.CurrentSongIndex])
+	return true
+}
+
+// songEnded records the current song as played and counts it, for songs
+// that finished or were skipped rather than cut off by stopping playback or
+// switching playlists. The caller must hold s.mu.
+func (s *RadioService) songEnded() {
+	if s.recordPlayed() {
+		metrics.SongsPlayed.Inc()
+	}
+}
+
+// rewindTo makes song current again, pushing the current song back to play
+// after it. When song is already the one before it in the queue only the
+// index moves. The caller must hold s.mu.
+func (s *RadioService) rewindTo(song *models.Song) {
+	index := s.state.CurrentSongIndex
+	if index > 0 && index <= len(s.state.Queue) && s.state.Queue[index-1].YouTubeID == song.YouTubeID {
+		s.state.CurrentSongIndex = index - 1
+		return
+	}
+
+	// Build a new slice since published queue info may still reference the old one
+	index = min(max(index, 0), len(s.state.Queue))
+	queue := make([]*models.Song, 0, len(s.state.Queue)+1)
+	queue = append(queue, s.state.Queue[:index]...)
+	queue = append(queue, song)
+	queue = append(queue, s.state.Queue[index:]...)
+	s.state.Queue = queue
+	s.state.CurrentSongIndex = index
 }
 
 func (s *RadioService) GetElapsedTime() time.Duration {
@@ -171,7 +695,16 @@ func (s *RadioService) GetElapsedTime() time.Duration {
 		return 0
 	}
 
-	return time.Since(s.state.StartTime)
+	return s.positionTime().Sub(s.state.StartTime)
+}
+
+// positionTime is the time playback position is measured at: now, or the
+// moment playback was paused. Callers must hold s.mu.
+func (s *RadioService) positionTime() time.Time {
+	if s.state.Paused {
+		return s.state.PauseTime
+	}
+	return s.clock.Now()
 }
 
 func (s *RadioService) GetRemainingTime() time.Duration {
@@ -191,8 +724,8 @@ func (s *RadioService) GetRemainingTime() time.Duration {
 		return 0
 	}
 
-	elapsed := time.Since(s.state.StartTime)
-	remaining := time.Duration(currentSong.Duration)*time.Second - elapsed
+	elapsed := s.positionTime().Sub(s.state.StartTime)
+	remaining := s.slotDuration(currentSong) - elapsed
 
 	if remaining < 0 {
 		return 0
@@ -223,8 +756,8 @@ func (s *RadioService) GetQueueInfo() *models.QueueInfo {
 	// Calculate remaining time directly to avoid deadlock
 	var remaining float64
 	if currentSong != nil && !s.state.Paused {
-		elapsed := time.Since(s.state.StartTime)
-		remainingDuration := time.Duration(currentSong.Duration)*time.Second - elapsed
+		elapsed := s.clock.Now().Sub(s.state.StartTime)
+		remainingDuration := s.slotDuration(currentSong) - elapsed
 		if remainingDuration > 0 {
 			remaining = remainingDuration.Seconds()
 		}
@@ -255,26 +788,70 @@ func (s *RadioService) GetCurrentSong() *models.Song {
 }
 
 func (s *RadioService) StartPlaybackLoop() error {
-	// Get the first playlist without holding the lock
-	playlist, err := s.playlistRepo.GetFirstPlaylist()
-	if err != nil {
-		log.Printf("[ERROR] StartPlaybackLoop: Failed to get first playlist: %v", err)
-		return fmt.Errorf("failed to get first playlist: %w", err)
+	s.loopMu.Lock()
+	defer s.loopMu.Unlock()
+	if s.loopStop != nil {
+		return ErrPlaybackRunning
 	}
-	if playlist == nil {
-		log.Printf("[ERROR] StartPlaybackLoop: No playlists found")
-		return fmt.Errorf("no playlists found")
+
+	s.mu.RLock()
+	source := s.queueSource
+	resume := s.state.CurrentPlaylist
+	s.mu.RUnlock()
+
+	var playlist *models.Playlist
+	var songs, shuffledSongs []*models.Song
+	if source != nil {
+		// Sources order their own batches
+		batch, err := source.NextBatch(context.Background())
+		if err != nil {
+			log.Printf("[ERROR] StartPlaybackLoop: Failed to get songs from queue source: %v", err)
+			return fmt.Errorf("failed to get songs from queue source: %w", err)
+		}
+		batch = s.playableSongs(batch)
+		if len(batch) == 0 {
+			log.Printf("[ERROR] StartPlaybackLoop: Queue source returned no songs")
+			return fmt.Errorf("queue source returned no songs")
+		}
+		if backed, ok := source.(playlistBacked); ok {
+			playlist = backed.Playlist()
+		}
+		songs = batch
+		shuffledSongs = batch
+	} else {
+		// Restarting after StopPlayback reshuffles the playlist that was
+		// playing, otherwise get the first playlist without holding the lock
+		var err error
+		playlist = resume
+		if playlist == nil {
+			playlist, err = s.playlistRepo.GetFirstPlaylist()
+			if err != nil {
+				log.Printf("[ERROR] StartPlaybackLoop: Failed to get first playlist: %v", err)
+				return fmt.Errorf("failed to get first playlist: %w", err)
+			}
+			if playlist == nil {
+				log.Printf("[ERROR] StartPlaybackLoop: No playlists found")
+				return fmt.Errorf("no playlists found")
+			}
+		}
+
+		// Get songs from the playlist without holding the lock
+		songs, err = s.getPlaylistSongs(playlist.ID)
+		if err != nil {
+			log.Printf("[ERROR] StartPlaybackLoop: Failed to get playlist songs: %v", err)
+			return fmt.Errorf("failed to get playlist songs: %w", err)
+		}
+		if len(songs) == 0 {
+			log.Printf("[ERROR] StartPlaybackLoop: Playlist %s is empty", playlist.ID)
+			return fmt.Errorf("playlist %s is empty", playlist.ID)
+		}
+		shuffledSongs = s.queueOrder(songs)
 	}
 
-	// Get songs from the playlist without holding the lock
-	songs, err := s.playlistRepo.GetSongs(playlist.ID)
+	// Start on a song whose audio is actually there
+	shuffledSongs, err := s.startOnDownloadedSong(shuffledSongs)
 	if err != nil {
-		log.Printf("[ERROR] StartPlaybackLoop: Failed to get playlist songs: %v", err)
-		return fmt.Errorf("failed to get playlist songs: %w", err)
-	}
-	if len(songs) == 0 {
-		log.Printf("[ERROR] StartPlaybackLoop: Playlist %s is empty", playlist.ID)
-		return fmt.Errorf("playlist %s is empty", playlist.ID)
+		return err
 	}
 
 	// Verify songs data
@@ -283,14 +860,13 @@ func (s *RadioService) StartPlaybackLoop() error {
 			i, song.YouTubeID, song.Title, song.Duration)
 	}
 
-	shuffledSongs := s.shuffleSongs(songs)
 	numShuffledSongs := len(shuffledSongs)
 
 	// Create new state before acquiring lock
 	newState := &models.PlaybackState{
 		CurrentPlaylist:  playlist,
 		CurrentSongIndex: 0,
-		StartTime:        time.Now(),
+		StartTime:        s.clock.Now(),
 		Paused:           false,
 		Queue:            make([]*models.Song, 0, numShuffledSongs),
 	}
@@ -299,13 +875,19 @@ func (s *RadioService) StartPlaybackLoop() error {
 		newState.Queue = append(newState.Queue, shuffledSongs[i])
 	}
 
-	// Set state with proper synchronization
+	// Set state with proper synchronization. The volume is the station's,
+	// not the playlist's, so it carries over.
 	s.mu.Lock()
+	newState.Volume = s.state.Volume
 	s.state = newState
+	s.stopped = false
 	s.mu.Unlock()
 
 	// Send initial song change notification
-	s.notifySongChange(songs[0], songs[1%len(songs)])
+	if s.eventBus != nil {
+		s.eventBus.PublishPlaybackStarted(playlist)
+	}
+	s.notifySongChange(shuffledSongs[0], nextInQueue(shuffledSongs, 0))
 
 	// Verify state after initialization
 	s.mu.RLock()
@@ -338,7 +920,11 @@ func (s *RadioService) StartPlaybackLoop() error {
 	songsCopy := make([]*models.Song, len(songs))
 	copy(songsCopy, songs)
 
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.loopStop, s.loopDone = stop, done
+
 	go func() {
+		defer close(done)
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("[ERROR] playbackLoop: Panic recovered: %v", r)
@@ -346,7 +932,7 @@ func (s *RadioService) StartPlaybackLoop() error {
 		}()
 		log.Printf("[DEBUG] playbackLoop: Goroutine started")
 		close(loopStarted)
-		s.playbackLoop(songsCopy)
+		s.playbackLoop(songsCopy, stop)
 	}()
 
 	// Wait for goroutine to start
@@ -369,49 +955,106 @@ func (s *RadioService) StartPlaybackLoop() error {
 	return nil
 }
 
-func (s *RadioService) playbackLoop(songs []*models.Song) {
+// playbackLoop advances through the queue until stop is closed
+func (s *RadioService) playbackLoop(songs []*models.Song, stop <-chan struct{}) {
 	log.Printf("[DEBUG] playbackLoop: Starting with %d songs", len(songs))
 
 	// Create a ticker for periodic state updates
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := s.clock.NewTicker(playbackTickInterval)
 	defer ticker.Stop()
 
-	// Log initial state
-	for range ticker.C {
+	for {
+		select {
+		case <-stop:
+			log.Printf("[DEBUG] playbackLoop: Stopped")
+			return
+		case <-ticker.C():
+		}
+
 		// Get remaining time without holding the lock
 		remaining := s.GetRemainingTime()
 
+		if s.announcer != nil {
+			s.announceNext(remaining)
+		}
+
 		// Song has finished playing
 		if remaining <= 0 {
+			// Fetch the next batch before locking, sources may be slow
+			var batch []*models.Song
+			if s.atEndOfQueue() {
+				batch = s.nextSourceBatch()
+			}
+
 			// Only lock during the state update
 			s.mu.Lock()
 
 			if s.state == nil || len(s.state.Queue) == 0 {
 				s.mu.Unlock()
+				s.loopLog.Printf("[DEBUG] playbackLoop: Queue is empty, waiting for songs")
 				continue
 			}
 
+			// Under repeat one the song that ended starts over
+			if s.repeatMode == RepeatOne && s.state.CurrentSongIndex < len(s.state.Queue) {
+				s.songEnded()
+				s.state.StartTime = s.clock.Now()
+				currentSong := s.state.Queue[s.state.CurrentSongIndex]
+				queueInfo := &models.QueueInfo{
+					Queue:            s.state.Queue,
+					Playlist:         s.state.CurrentPlaylist,
+					Remaining:        0,
+					StartTime:        s.state.StartTime,
+					CurrentSongIndex: s.state.CurrentSongIndex,
+				}
+				s.mu.Unlock()
+
+				s.loopLog.Printf("[DEBUG] playbackLoop: Repeating %s (%s)", currentSong.YouTubeID, currentSong.Title)
+				if s.eventBus != nil {
+					s.eventBus.PublishSongChange(currentSong, currentSong, queueInfo)
+				}
+				continue
+			}
+
+			// Without repeat, a queue that has played through stops playback.
+			// The emptied queue leaves the loop idle until a playlist is set.
+			if s.state.CurrentSongIndex >= len(s.state.Queue)-1 && len(batch) == 0 && s.repeatMode == RepeatOff {
+				s.songEnded()
+				playlist := s.state.CurrentPlaylist
+				s.state.Queue = []*models.Song{}
+				s.state.CurrentSongIndex = 0
+				s.mu.Unlock()
+
+				log.Printf("[DEBUG] playbackLoop: Reached the end of the queue, stopping playback")
+				if s.eventBus != nil {
+					s.eventBus.PublishPlaybackStopped(playlist)
+				}
+				continue
+			}
+
+			s.songEnded()
+
 			// Check if we've reached the end of the playlist
 			if s.state.CurrentSongIndex >= len(s.state.Queue)-1 {
-				// Playlist completed, shuffle and restart
-				shuffledSongs := s.shuffleSongs(s.state.Queue)
+				// Playlist completed, queue the next batch or shuffle and
+				// restart. A single song has nothing to shuffle and replays.
+				shuffledSongs := batch
+				if len(shuffledSongs) == 0 && s.shuffle && len(s.state.Queue) > 1 {
+					finished := s.state.Queue[s.state.CurrentSongIndex]
+					shuffledSongs = s.shuffleAfter(finished, s.state.Queue)
+				} else if len(shuffledSongs) == 0 {
+					shuffledSongs = s.state.Queue
+				}
 				s.state.CurrentSongIndex = 0
-				s.state.StartTime = time.Now()
+				s.state.StartTime = s.clock.Now()
 
 				// Update queue with shuffled songs
-				s.state.Queue = make([]*models.Song, 0, len(shuffledSongs))
-				for i := 0; i < len(shuffledSongs); i++ {
-					s.state.Queue = append(s.state.Queue, shuffledSongs[i%len(shuffledSongs)])
-				}
+				s.state.Queue = make([]*models.Song, len(shuffledSongs))
+				copy(s.state.Queue, shuffledSongs)
 
 				// Get songs for notification without additional locking
-				var currentSong, nextSong *models.Song
-				if len(s.state.Queue) > 0 {
-					currentSong = s.state.Queue[0]
-					if len(s.state.Queue) > 1 {
-						nextSong = s.state.Queue[1]
-					}
-				}
+				currentSong := s.state.Queue[0]
+				nextSong := nextInQueue(s.state.Queue, 0)
 
 				// Create queue info without additional locking
 				queueInfo := &models.QueueInfo{
@@ -424,24 +1067,26 @@ func (s *RadioService) playbackLoop(songs []*models.Song) {
 
 				s.mu.Unlock()
 
+				if currentSong != nil {
+					s.loopLog.Printf("[DEBUG] playbackLoop: Advancing to %s (%s)", currentSong.YouTubeID, currentSong.Title)
+				}
+
 				// Notify outside of lock
+				s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
 				if s.eventBus != nil && currentSong != nil {
 					s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
 				}
 			} else {
 				// Move to next song - increment index
 				s.state.CurrentSongIndex = s.state.CurrentSongIndex + 1
-				s.state.StartTime = time.Now()
+				s.state.StartTime = s.clock.Now()
 
 				// Get songs for notification without additional locking
 				var currentSong, nextSong *models.Song
 				if s.state.CurrentSongIndex < len(s.state.Queue) {
 					currentSong = s.state.Queue[s.state.CurrentSongIndex]
 				}
-				nextIndex := (s.state.CurrentSongIndex + 1) % len(s.state.Queue)
-				if nextIndex < len(s.state.Queue) {
-					nextSong = s.state.Queue[nextIndex]
-				}
+				nextSong = nextInQueue(s.state.Queue, s.state.CurrentSongIndex)
 
 				// Create queue info without additional locking
 				queueInfo := &models.QueueInfo{
@@ -454,7 +1099,12 @@ func (s *RadioService) playbackLoop(songs []*models.Song) {
 
 				s.mu.Unlock()
 
+				if currentSong != nil {
+					s.loopLog.Printf("[DEBUG] playbackLoop: Advancing to %s (%s)", currentSong.YouTubeID, currentSong.Title)
+				}
+
 				// Notify outside of lock
+				s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
 				if s.eventBus != nil && currentSong != nil {
 					s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
 				}
@@ -463,6 +1113,176 @@ func (s *RadioService) playbackLoop(songs []*models.Song) {
 	}
 }
 
+// StopPlayback takes the station off the air until StartPlaybackLoop is
+// called again: the playback loop ends and the queue empties, so nothing
+// advances or downloads, and listeners get playback_stopped. Restarting
+// reshuffles the playlist that was playing.
+func (s *RadioService) StopPlayback() error {
+	s.loopMu.Lock()
+	defer s.loopMu.Unlock()
+	if s.loopStop == nil {
+		return ErrPlaybackNotRunning
+	}
+	close(s.loopStop)
+	<-s.loopDone
+	s.loopStop, s.loopDone = nil, nil
+
+	s.mu.Lock()
+	s.recordPlayed()
+	playlist := s.state.CurrentPlaylist
+	s.state.Queue = []*models.Song{}
+	s.state.CurrentSongIndex = 0
+	s.state.Paused = false
+	s.stopped = true
+	s.mu.Unlock()
+
+	log.Printf("[DEBUG] StopPlayback: Playback stopped")
+	if s.eventBus != nil {
+		s.eventBus.PublishPlaybackStopped(playlist)
+	}
+	return nil
+}
+
+// Pause freezes playback of the current song until Resume is called
+func (s *RadioService) Pause() {
+	s.mu.Lock()
+	if s.state == nil || len(s.state.Queue) == 0 || s.state.Paused {
+		s.mu.Unlock()
+		return
+	}
+	s.state.Paused = true
+	s.state.PauseTime = s.clock.Now()
+	s.mu.Unlock()
+
+	s.publishPlaybackUpdate(true)
+}
+
+// Resume continues the current song from where it was paused
+func (s *RadioService) Resume() {
+	s.mu.Lock()
+	if s.state == nil || !s.state.Paused {
+		s.mu.Unlock()
+		return
+	}
+	// Shift the start so the time spent paused doesn't count as played
+	s.state.StartTime = s.state.StartTime.Add(s.clock.Now().Sub(s.state.PauseTime))
+	s.state.Paused = false
+	s.state.PauseTime = time.Time{}
+	s.mu.Unlock()
+
+	s.publishPlaybackUpdate(false)
+}
+
+// SetVolume sets the level every client plays at, clamped to 0.0–1.0, and
+// returns the level that was applied
+func (s *RadioService) SetVolume(volume float64) float64 {
+	volume = ClampVolume(volume)
+
+	s.mu.Lock()
+	s.state.Volume = volume
+	paused := s.state.Paused
+	s.mu.Unlock()
+
+	s.publishPlaybackUpdate(paused)
+	return volume
+}
+
+// GetVolume returns the level every client plays at
+func (s *RadioService) GetVolume() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state.Volume
+}
+
+// ClampVolume limits volume to 0.0–1.0, treating NaN as muted
+func ClampVolume(volume float64) float64 {
+	if math.IsNaN(volume) {
+		return 0
+	}
+	return max(0, min(volume, 1))
+}
+
+func (s *RadioService) publishPlaybackUpdate(paused bool) {
+	song := s.GetCurrentSong()
+	if s.eventBus == nil || song == nil {
+		return
+	}
+	s.eventBus.PublishPlaybackUpdate(
+		song,
+		s.GetElapsedTime().Seconds(),
+		s.GetRemainingTime().Seconds(),
+		paused,
+		s.GetVolume(),
+	)
+}
+
+// ReshuffleQueue shuffles the songs after the current one. The current song
+// keeps playing from where it is and listeners get a queue update.
+func (s *RadioService) ReshuffleQueue() {
+	s.mu.Lock()
+	if s.state == nil || len(s.state.Queue)-s.state.CurrentSongIndex <= 2 {
+		// Fewer than two upcoming songs leaves nothing to reorder
+		s.mu.Unlock()
+		return
+	}
+
+	// Build a new slice since published queue info may still reference the old one.
+	// Upcoming copies of the current song are dropped so it isn't heard twice.
+	head := s.state.Queue[:s.state.CurrentSongIndex+1]
+	current := head[len(head)-1]
+	tail := make([]*models.Song, 0, len(s.state.Queue)-len(head))
+	for _, song := range s.state.Queue[s.state.CurrentSongIndex+1:] {
+		if song.YouTubeID != current.YouTubeID {
+			tail = append(tail, song)
+		}
+	}
+	tail = s.shuffleSongs(tail)
+	queue := make([]*models.Song, 0, len(s.state.Queue))
+	queue = append(queue, head...)
+	queue = append(queue, tail...)
+	s.state.Queue = queue
+	s.mu.Unlock()
+
+	log.Printf("[DEBUG] ReshuffleQueue: Reshuffled %d upcoming songs", len(tail))
+	if s.eventBus != nil {
+		s.eventBus.PublishQueueUpdate(s.GetQueueInfo())
+	}
+}
+
+// PlayNext inserts a library song right after the current one so it plays
+// next, and starts downloading it straight away.
+func (s *RadioService) PlayNext(youtubeID string) error {
+	song, err := s.songRepo.GetByYouTubeID(youtubeID)
+	if err != nil {
+		return fmt.Errorf("failed to get song: %w", err)
+	}
+	if song == nil {
+		return ErrSongNotFound
+	}
+
+	s.mu.Lock()
+	if s.state == nil || len(s.state.Queue) == 0 {
+		s.mu.Unlock()
+		return ErrNothingPlaying
+	}
+
+	// Build a new slice since published queue info may still reference the old one
+	insertAt := s.state.CurrentSongIndex + 1
+	queue := make([]*models.Song, 0, len(s.state.Queue)+1)
+	queue = append(queue, s.state.Queue[:insertAt]...)
+	queue = append(queue, song)
+	queue = append(queue, s.state.Queue[insertAt:]...)
+	s.state.Queue = queue
+	s.mu.Unlock()
+
+	log.Printf("[DEBUG] PlayNext: Queued %s (%s) to play next", song.YouTubeID, song.Title)
+	s.ensureSongsDownloaded(song)
+	if s.eventBus != nil {
+		s.eventBus.PublishQueueUpdate(s.GetQueueInfo())
+	}
+	return nil
+}
+
 func (s *RadioService) shuffleSongs(songs []*models.Song) []*models.Song {
 	s.randMu.Lock()
 	defer s.randMu.Unlock()
@@ -479,13 +1299,25 @@ func (s *RadioService) shuffleSongs(songs []*models.Song) []*models.Song {
 	return shuffled
 }
 
+// shuffleAfter shuffles songs to play after finished, keeping finished off
+// the front so a reshuffle never replays the song that just ended
+func (s *RadioService) shuffleAfter(finished *models.Song, songs []*models.Song) []*models.Song {
+	shuffled := s.shuffleSongs(songs)
+	if len(shuffled) > 1 && shuffled[0].YouTubeID == finished.YouTubeID {
+		last := len(shuffled) - 1
+		shuffled[0], shuffled[last] = shuffled[last], shuffled[0]
+	}
+	return shuffled
+}
+
 func (s *RadioService) notifySongChange(currentSong, nextSong *models.Song) {
 	if currentSong != nil {
 		fmt.Println("Notifying song change:", currentSong.Title)
 	}
+	// Get queue info once and reuse it
+	queueInfo := s.GetQueueInfo()
+	s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
 	if s.eventBus != nil {
-		// Get queue info once and reuse it
-		queueInfo := s.GetQueueInfo()
 		s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
 
 		// Also publish queue update with the same info
@@ -493,47 +1325,83 @@ func (s *RadioService) notifySongChange(currentSong, nextSong *models.Song) {
 	}
 }
 
-// SetActivePlaylist changes the current playlist and restarts playback
-func (s *RadioService) SetActivePlaylist(playlistID string) error {
-	// Get the new playlist without holding the lock
+// loadPlaylistSongs fetches a playlist and its songs for switching to it
+func (s *RadioService) loadPlaylistSongs(playlistID string) (*models.Playlist, []*models.Song, error) {
 	playlist, err := s.playlistRepo.GetByID(playlistID)
 	if err != nil {
-		return fmt.Errorf("failed to get playlist: %w", err)
+		return nil, nil, fmt.Errorf("failed to get playlist: %w", err)
 	}
 	if playlist == nil {
-		return fmt.Errorf("playlist not found")
+		return nil, nil, ErrPlaylistNotFound
 	}
 
-	// Get songs from the new playlist
-	songs, err := s.playlistRepo.GetSongs(playlist.ID)
+	songs, err := s.getPlaylistSongs(playlist.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get playlist songs: %w", err)
+		return nil, nil, fmt.Errorf("failed to get playlist songs: %w", err)
 	}
 	if len(songs) == 0 {
-		return fmt.Errorf("playlist %s is empty", playlist.ID)
+		return nil, nil, fmt.Errorf("playlist %s is empty", playlist.ID)
+	}
+	return playlist, songs, nil
+}
+
+// PreviewPlaylist builds the queue SetActivePlaylist would switch to, in the
+// current shuffle mode, without touching playback. With shuffle on each
+// preview is a fresh shuffle, so the switch itself may play a different order.
+func (s *RadioService) PreviewPlaylist(playlistID string) (*models.QueueInfo, error) {
+	playlist, songs, err := s.loadPlaylistSongs(playlistID)
+	if err != nil {
+		return nil, err
 	}
+	return &models.QueueInfo{
+		Queue:    s.queueOrder(songs),
+		Playlist: playlist,
+	}, nil
+}
+
+// SetActivePlaylist changes the current playlist and restarts playback. While
+// playback is stopped it picks the playlist to start with instead.
+func (s *RadioService) SetActivePlaylist(playlistID string) error {
+	// Get the new playlist without holding the lock
+	playlist, songs, err := s.loadPlaylistSongs(playlistID)
+	if err != nil {
+		return err
+	}
+
+	// While stopped, only remember the playlist to start with
+	s.mu.Lock()
+	if s.stopped {
+		s.state.CurrentPlaylist = playlist
+		s.queueSource = nil
+		s.mu.Unlock()
+		log.Printf("[DEBUG] SetActivePlaylist: Playback is stopped, %s will play when it starts", playlist.Name)
+		return nil
+	}
+	s.mu.Unlock()
 
 	log.Printf("[DEBUG] SetActivePlaylist: Switching to playlist %s with %d songs", playlist.Name, len(songs))
 
-	shuffledSongs := s.shuffleSongs(songs)
+	shuffledSongs := s.queueOrder(songs)
 
 	// Create new state with the new playlist
 	newState := &models.PlaybackState{
 		CurrentPlaylist:  playlist,
 		CurrentSongIndex: 0,
-		StartTime:        time.Now(),
+		StartTime:        s.clock.Now(),
 		Paused:           false,
 		Queue:            make([]*models.Song, 0, len(shuffledSongs)),
 	}
 
 	// Build new queue
-	for i := 0; i < len(shuffledSongs); i++ {
-		newState.Queue = append(newState.Queue, shuffledSongs[i%len(shuffledSongs)])
-	}
+	newState.Queue = append(newState.Queue, shuffledSongs...)
 
-	// Set state with proper synchronization
+	// Set state with proper synchronization. Picking a playlist by hand takes
+	// over from any queue source.
 	s.mu.Lock()
+	s.recordPlayed()
+	newState.Volume = s.state.Volume
 	s.state = newState
+	s.queueSource = nil
 
 	// Get songs for notification without additional locking
 	var currentSong, nextSong *models.Song
@@ -556,6 +1424,7 @@ func (s *RadioService) SetActivePlaylist(playlistID string) error {
 	s.mu.Unlock()
 
 	// Broadcast playlist change event outside of lock
+	s.ensureSongsDownloaded(s.upcomingSongs(queueInfo.Queue, queueInfo.CurrentSongIndex)...)
 	if s.eventBus != nil && currentSong != nil {
 		s.eventBus.PublishSongChange(currentSong, nextSong, queueInfo)
 	}
@@ -3,11 +3,31 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/metrics"
 	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// The in-memory and JSON repositories stand in for the Postgres ones everywhere
+var (
+	_ SongRepositoryInterface     = (*repositories.InMemorySongRepository)(nil)
+	_ PlaylistRepositoryInterface = (*repositories.InMemoryPlaylistRepository)(nil)
+	_ PlaylistStore               = (*repositories.InMemoryPlaylistRepository)(nil)
+	_ SongRepositoryInterface     = (*repositories.JSONSongRepository)(nil)
+	_ PlaylistRepositoryInterface = (*repositories.JSONPlaylistRepository)(nil)
+	_ PlaylistStore               = (*repositories.JSONPlaylistRepository)(nil)
 )
 
 // Mock repositories for testing
@@ -16,6 +36,8 @@ type MockSongRepository struct {
 	randomSong      *models.Song
 	leastPlayedSong *models.Song
 	updateStatsErr  error
+	recentLimit     int
+	updateDurErr    error
 }
 
 func NewMockSongRepository() *MockSongRepository {
@@ -36,6 +58,104 @@ func (m *MockSongRepository) UpdatePlayStats(youtubeID string) error {
 	return m.updateStatsErr
 }
 
+func (m *MockSongRepository) GetRecentlyAdded(limit int) ([]*models.Song, error) {
+	m.recentLimit = limit
+	songs := make([]*models.Song, 0, len(m.songs))
+	for _, song := range m.songs {
+		songs = append(songs, song)
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].CreatedAt.After(songs[j].CreatedAt)
+	})
+	if len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, nil
+}
+
+func (m *MockSongRepository) GetWithoutDuration() ([]*models.Song, error) {
+	songs := make([]*models.Song, 0)
+	for _, song := range m.songs {
+		if song.Duration == 0 {
+			songs = append(songs, song)
+		}
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].YouTubeID < songs[j].YouTubeID
+	})
+	return songs, nil
+}
+
+func (m *MockSongRepository) UpdateDuration(youtubeID string, duration int) error {
+	if m.updateDurErr != nil {
+		return m.updateDurErr
+	}
+	if song, ok := m.songs[youtubeID]; ok {
+		song.Duration = duration
+	}
+	return nil
+}
+
+func (m *MockSongRepository) GetByTag(tag string) ([]*models.Song, error) {
+	songs := make([]*models.Song, 0)
+	for _, song := range m.songs {
+		if song.Tags.Has(tag) {
+			songs = append(songs, song)
+		}
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].YouTubeID < songs[j].YouTubeID
+	})
+	return songs, nil
+}
+
+func (m *MockSongRepository) UpdateTags(youtubeID string, tags models.Tags) error {
+	if song, ok := m.songs[youtubeID]; ok {
+		song.Tags = tags
+	}
+	return nil
+}
+
+// GetArtists groups songs by artist the way the database does
+func (m *MockSongRepository) GetArtists() ([]*models.ArtistSummary, error) {
+	counts := make(map[string]int)
+	for _, song := range m.songs {
+		counts[models.LibraryName(song.Artist)]++
+	}
+	artists := make([]*models.ArtistSummary, 0, len(counts))
+	for name, count := range counts {
+		artists = append(artists, &models.ArtistSummary{Name: name, SongCount: count})
+	}
+	return artists, nil
+}
+
+// GetAlbums groups songs by album and artist the way the database does
+func (m *MockSongRepository) GetAlbums() ([]*models.AlbumSummary, error) {
+	counts := make(map[models.AlbumSummary]int)
+	for _, song := range m.songs {
+		counts[models.AlbumSummary{Name: models.LibraryName(song.Album), Artist: models.LibraryName(song.Artist)}]++
+	}
+	albums := make([]*models.AlbumSummary, 0, len(counts))
+	for album, count := range counts {
+		album.SongCount = count
+		albums = append(albums, &album)
+	}
+	return albums, nil
+}
+
+func (m *MockSongRepository) GetByArtist(artist string) ([]*models.Song, error) {
+	songs := make([]*models.Song, 0)
+	for _, song := range m.songs {
+		if models.LibraryName(song.Artist) == artist {
+			songs = append(songs, song)
+		}
+	}
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].YouTubeID < songs[j].YouTubeID
+	})
+	return songs, nil
+}
+
 func (m *MockSongRepository) Create(song *models.Song) error {
 	m.songs[song.YouTubeID] = song
 	return nil
@@ -53,6 +173,7 @@ type MockPlaylistRepository struct {
 	playlists     map[string]*models.Playlist
 	songs         map[string][]*models.Song
 	firstPlaylist *models.Playlist
+	getSongsCalls int
 }
 
 func NewMockPlaylistRepository() *MockPlaylistRepository {
@@ -67,6 +188,7 @@ func (m *MockPlaylistRepository) GetFirstPlaylist() (*models.Playlist, error) {
 }
 
 func (m *MockPlaylistRepository) GetSongs(playlistID string) ([]*models.Song, error) {
+	m.getSongsCalls++
 	songs, exists := m.songs[playlistID]
 	if !exists {
 		return []*models.Song{}, nil
@@ -108,9 +230,21 @@ func (m *MockPlaylistRepository) UpdateSongPosition(playlistID string, youtubeID
 }
 
 func (m *MockPlaylistRepository) GetByName(name string) (*models.Playlist, error) {
+	for _, playlist := range m.playlists {
+		if playlist.Name == name {
+			return playlist, nil
+		}
+	}
 	return nil, nil
 }
 
+func (m *MockPlaylistRepository) GetSongsPage(playlistID string, limit, offset int) ([]*models.Song, int, error) {
+	songs := m.songs[playlistID]
+	start := min(offset, len(songs))
+	end := min(start+limit, len(songs))
+	return songs[start:end], len(songs), nil
+}
+
 type MockS3Service struct{}
 
 func (m *MockS3Service) GetPresignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
@@ -121,34 +255,126 @@ func (m *MockS3Service) UploadFile(ctx context.Context, key string, body io.Read
 	return nil
 }
 
+func (m *MockS3Service) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (m *MockS3Service) FileExists(ctx context.Context, key string) (bool, error) {
+	return true, nil
+}
+
+func (m *MockS3Service) FileSize(ctx context.Context, key string) (int64, bool, error) {
+	return 0, true, nil
+}
+
 func (m *MockS3Service) DeleteFile(ctx context.Context, key string) error {
 	return nil
 }
 
-type MockEventBus struct{}
+// The radio service must accept the no-op bus in place of the real one
+var _ EventBusInterface = (*events.NoopEventBus)(nil)
+
+// recordingEventBus keeps the queue updates it is asked to publish
+type recordingEventBus struct {
+	events.NoopEventBus
+
+	mu            sync.Mutex
+	queueUpdates  []*models.QueueInfo
+	volumes       []float64
+	stops         int
+	starts        int
+	announcements []*models.Song
+	songChanges   []songChange
+	voteUpdates   []VoteStatus
+}
+
+// songChange is one published song_change
+type songChange struct {
+	current, next *models.Song
+}
+
+func (b *recordingEventBus) PublishSongChange(currentSong, nextSong *models.Song, queueInfo *models.QueueInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.songChanges = append(b.songChanges, songChange{current: currentSong, next: nextSong})
+}
+
+func (b *recordingEventBus) changedSongs() []songChange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]songChange(nil), b.songChanges...)
+}
+
+func (b *recordingEventBus) PublishAnnouncement(song *models.Song, announcementID, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.announcements = append(b.announcements, song)
+}
+
+func (b *recordingEventBus) PublishVoteUpdate(song *models.Song, votes, required int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.voteUpdates = append(b.voteUpdates, VoteStatus{Votes: votes, Required: required})
+}
+
+func (b *recordingEventBus) votes() []VoteStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]VoteStatus(nil), b.voteUpdates...)
+}
+
+func (b *recordingEventBus) announced() []*models.Song {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*models.Song(nil), b.announcements...)
+}
+
+func (b *recordingEventBus) PublishPlaybackStopped(playlist *models.Playlist) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stops++
+}
+
+func (b *recordingEventBus) stopCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stops
+}
 
-func (m *MockEventBus) PublishSongChange(currentSong, nextSong *models.Song, queueInfo *models.QueueInfo) {
-	// Mock implementation - do nothing for tests
+func (b *recordingEventBus) PublishPlaybackStarted(playlist *models.Playlist) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.starts++
 }
 
-func (m *MockEventBus) PublishQueueUpdate(queueInfo *models.QueueInfo) {
-	// Mock implementation - do nothing for tests
+func (b *recordingEventBus) startCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.starts
 }
 
-func (m *MockEventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool) {
-	// Mock implementation - do nothing for tests
+func (b *recordingEventBus) PublishQueueUpdate(queueInfo *models.QueueInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queueUpdates = append(b.queueUpdates, queueInfo)
 }
 
-func (m *MockEventBus) PublishSkip(song *models.Song, nextSong *models.Song, state *models.PlaybackState) {
-	// Mock implementation - do nothing for tests
+func (b *recordingEventBus) queueUpdateCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queueUpdates)
 }
 
-func (m *MockEventBus) PublishPrevious(song *models.Song, nextSong *models.Song, state *models.PlaybackState) {
-	// Mock implementation - do nothing for tests
+func (b *recordingEventBus) PublishPlaybackUpdate(song *models.Song, elapsed, remaining float64, paused bool, volume float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.volumes = append(b.volumes, volume)
 }
 
-func (m *MockEventBus) PublishPlaylistChange(song *models.Song, nextSong *models.Song, playlist *models.Playlist, state *models.PlaybackState) {
-	// Mock implementation - do nothing for tests  
+func (b *recordingEventBus) publishedVolumes() []float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]float64(nil), b.volumes...)
 }
 
 // Helper function to create test songs
@@ -179,7 +405,7 @@ func TestNewRadioService(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -200,7 +426,7 @@ func TestGetCurrentSong(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -212,7 +438,8 @@ func TestGetCurrentSong(t *testing.T) {
 
 	// Test when a song is playing
 	testSong := createTestSong("test123", "Test Song", "Test Artist", 180)
-	service.state.CurrentSong = testSong
+	service.state.Queue = []*models.Song{testSong}
+	service.state.CurrentSongIndex = 0
 
 	song = service.GetCurrentSong()
 	if song == nil {
@@ -228,7 +455,7 @@ func TestGetPlaybackState(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -242,45 +469,45 @@ func TestGetPlaybackState(t *testing.T) {
 	}
 }
 
-func TestSkip(t *testing.T) {
+func TestNext(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
-	// Test skip with no playlist
-	err := service.Skip()
-	if err == nil {
-		t.Error("Expected error when no playlist available")
+	// Next with an empty queue is a no-op
+	service.Next()
+	if service.GetCurrentSong() != nil {
+		t.Error("Expected no current song when queue is empty")
 	}
 
-	// Test skip with playlist
+	// Test next with a populated queue
 	playlist := createTestPlaylist("1", "Test Playlist")
 	songs := []*models.Song{
 		createTestSong("song1", "Song 1", "Artist 1", 180),
 		createTestSong("song2", "Song 2", "Artist 2", 200),
 		createTestSong("song3", "Song 3", "Artist 3", 160),
 	}
-	
+
 	service.state.CurrentPlaylist = playlist
 	service.state.CurrentSongIndex = 0 // Start at first song
-	service.state.CurrentSong = songs[0]
-	
-	playlistRepo.songs["1"] = songs
+	service.state.Queue = songs
 
-	err = service.Skip()
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
+	skips, played := testutil.ToFloat64(metrics.Skips), testutil.ToFloat64(metrics.SongsPlayed)
+	service.Next()
 
 	if service.state.CurrentSongIndex != 1 {
 		t.Errorf("Expected current song index to be 1, got %d", service.state.CurrentSongIndex)
 	}
 
-	if service.state.CurrentSong.YouTubeID != "song2" {
-		t.Errorf("Expected current song to be song2, got %s", service.state.CurrentSong.YouTubeID)
+	if service.GetCurrentSong().YouTubeID != "song2" {
+		t.Errorf("Expected current song to be song2, got %s", service.GetCurrentSong().YouTubeID)
+	}
+	if testutil.ToFloat64(metrics.Skips) != skips+1 || testutil.ToFloat64(metrics.SongsPlayed) != played+1 {
+		t.Errorf("Expected the skip and the played song counted, got %v skips and %v played",
+			testutil.ToFloat64(metrics.Skips)-skips, testutil.ToFloat64(metrics.SongsPlayed)-played)
 	}
 }
 
@@ -288,7 +515,7 @@ func TestSetActivePlaylist(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -325,8 +552,9 @@ func TestSetActivePlaylist(t *testing.T) {
 		t.Errorf("Expected current playlist ID to be '2', got %s", service.state.CurrentPlaylist.ID)
 	}
 
-	if service.state.CurrentSong.YouTubeID != "song3" {
-		t.Errorf("Expected current song to be 'song3', got %s", service.state.CurrentSong.YouTubeID)
+	currentSong := service.GetCurrentSong()
+	if currentSong == nil || (currentSong.YouTubeID != "song3" && currentSong.YouTubeID != "song4") {
+		t.Errorf("Expected current song to come from playlist 2, got %v", currentSong)
 	}
 
 	if service.state.CurrentSongIndex != 0 {
@@ -348,35 +576,136 @@ func TestPrevious(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
-	// Test previous with no playlist
-	err := service.Previous()
-	if err == nil {
-		t.Error("Expected error when no playlist available")
+	// Previous with an empty queue is a no-op
+	service.Previous()
+	if service.GetCurrentSong() != nil {
+		t.Error("Expected no current song when queue is empty")
 	}
 
-	// Test previous with playlist
+	// Test previous with a populated queue
 	playlist := createTestPlaylist("1", "Test Playlist")
 	songs := []*models.Song{
 		createTestSong("song1", "Song 1", "Artist 1", 180),
 		createTestSong("song2", "Song 2", "Artist 2", 200),
 		createTestSong("song3", "Song 3", "Artist 3", 160),
 	}
-	
+
 	service.state.CurrentPlaylist = playlist
 	service.state.CurrentSongIndex = 1 // Start at second song
-	service.state.CurrentSong = songs[1]
-	
-	playlistRepo.songs["1"] = songs
+	service.state.Queue = songs
 
-	err = service.Previous()
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+	service.Previous()
+
+	if service.state.CurrentSongIndex != 0 {
+		t.Errorf("Expected current song index to be 0, got %d", service.state.CurrentSongIndex)
+	}
+}
+
+func TestPreviousUsesHistoryAfterReshuffle(t *testing.T) {
+	song1 := createTestSong("song1", "Song 1", "Artist 1", 180)
+	song2 := createTestSong("song2", "Song 2", "Artist 2", 200)
+	song3 := createTestSong("song3", "Song 3", "Artist 3", 160)
+
+	tests := []struct {
+		name        string
+		playFirst   bool
+		expected    *models.Song
+		expectedLen int
+	}{
+		// Without history, previous is whatever precedes the current song
+		{name: "positional", playFirst: false, expected: song3, expectedLen: 3},
+		// With history, previous is the song that actually played before
+		{name: "history", playFirst: true, expected: song1, expectedLen: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+			service.state.CurrentPlaylist = createTestPlaylist("1", "Test Playlist")
+
+			if tt.playFirst {
+				service.state.Queue = []*models.Song{song1, song2, song3}
+				service.Next()
+			} else {
+				service.state.Queue = []*models.Song{song2, song1, song3}
+			}
+
+			// A reshuffle moves song3 in front of the current song
+			service.state.Queue = []*models.Song{song3, song2, song1}
+			service.state.CurrentSongIndex = 1
+
+			service.Previous()
+
+			if got := service.GetCurrentSong(); got != tt.expected {
+				t.Errorf("Expected %s after previous, got %s", tt.expected.YouTubeID, got.YouTubeID)
+			}
+			queue := service.GetPlaybackState().Queue
+			if len(queue) != tt.expectedLen {
+				t.Fatalf("Expected %d queued songs, got %d", tt.expectedLen, len(queue))
+			}
+			if tt.playFirst && queue[service.state.CurrentSongIndex+1] != song2 {
+				t.Errorf("Expected the interrupted song to play next, got %s", queue[service.state.CurrentSongIndex+1].YouTubeID)
+			}
+		})
+	}
+}
+
+func TestPreviousAfterPlaybackLoopWraps(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(time.Second, 0)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+		createTestSong("song3", "Song 3", "Artist 3", 10),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	// Play through the queue so the loop reshuffles and starts over
+	clock.Advance(t, 10*time.Second)
+	clock.Advance(t, 10*time.Second)
+	last := service.GetCurrentSong()
+	clock.Advance(t, 10*time.Second)
+	if got := service.GetPlaybackState().CurrentSongIndex; got != 0 {
+		t.Fatalf("Expected the queue to restart, got index %d", got)
+	}
+
+	service.Previous()
+
+	if got := service.GetCurrentSong(); got.YouTubeID != last.YouTubeID {
+		t.Errorf("Expected previous to replay %s from before the reshuffle, got %s", last.YouTubeID, got.YouTubeID)
+	}
+}
+
+func TestNextPreviousWithoutEventBus(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	playlistRepo := NewMockPlaylistRepository()
+	s3Service := &MockS3Service{}
+
+	service := NewRadioService(songRepo, playlistRepo, s3Service, nil)
+
+	service.state.CurrentPlaylist = createTestPlaylist("1", "Test Playlist")
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+	}
+
+	service.Next()
+	if service.state.CurrentSongIndex != 1 {
+		t.Errorf("Expected current song index to be 1, got %d", service.state.CurrentSongIndex)
 	}
 
+	service.Previous()
 	if service.state.CurrentSongIndex != 0 {
 		t.Errorf("Expected current song index to be 0, got %d", service.state.CurrentSongIndex)
 	}
@@ -386,7 +715,7 @@ func TestGetElapsedTime(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -397,7 +726,7 @@ func TestGetElapsedTime(t *testing.T) {
 	}
 
 	// Test with playing song
-	service.state.CurrentSong = createTestSong("test123", "Test Song", "Test Artist", 180)
+	service.state.Queue = []*models.Song{createTestSong("test123", "Test Song", "Test Artist", 180)}
 	service.state.StartTime = time.Now().Add(-time.Second)
 	elapsed = service.GetElapsedTime()
 	if elapsed <= 0 {
@@ -409,7 +738,7 @@ func TestGetRemainingTime(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -420,7 +749,7 @@ func TestGetRemainingTime(t *testing.T) {
 	}
 
 	// Test with playing song
-	service.state.CurrentSong = createTestSong("test123", "Test Song", "Test Artist", 180)
+	service.state.Queue = []*models.Song{createTestSong("test123", "Test Song", "Test Artist", 180)}
 	service.state.StartTime = time.Now().Add(-time.Second)
 	remaining = service.GetRemainingTime()
 	if remaining <= 0 {
@@ -428,18 +757,30 @@ func TestGetRemainingTime(t *testing.T) {
 	}
 
 	// Test with song that has finished
-	service.state.StartTime = time.Now().Add(-time.Duration(service.state.CurrentSong.Duration+1) * time.Second)
+	service.state.StartTime = time.Now().Add(-time.Duration(service.state.Queue[0].Duration+1) * time.Second)
 	remaining = service.GetRemainingTime()
 	if remaining != 0 {
 		t.Errorf("Expected 0 remaining time for finished song, got %v", remaining)
 	}
 }
 
+func TestGetRemainingTimeZeroDurationSong(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+
+	service.state.Queue = []*models.Song{createTestSong("zero", "Zero Song", "Test Artist", 0)}
+	service.state.StartTime = time.Now()
+
+	remaining := service.GetRemainingTime()
+	if remaining <= MinPlayableSongDuration-time.Second || remaining > MinPlayableSongDuration {
+		t.Errorf("Expected about %v remaining for a zero-duration song, got %v", MinPlayableSongDuration, remaining)
+	}
+}
+
 func TestGetQueueInfo(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -449,10 +790,6 @@ func TestGetQueueInfo(t *testing.T) {
 		t.Fatal("Expected queue info to be returned, got nil")
 	}
 
-	if queueInfo.CurrentSong != nil {
-		t.Errorf("Expected nil current song, got %v", queueInfo.CurrentSong)
-	}
-
 	if len(queueInfo.Queue) != 0 {
 		t.Errorf("Expected empty queue, got %d items", len(queueInfo.Queue))
 	}
@@ -462,18 +799,14 @@ func TestGetQueueInfo(t *testing.T) {
 	testPlaylist := createTestPlaylist("1", "Test Playlist")
 	testQueue := []*models.Song{testSong}
 
-	service.state.CurrentSong = testSong
-	service.state.NextSong = testSong
 	service.state.CurrentPlaylist = testPlaylist
 	service.state.Queue = testQueue
+	service.state.CurrentSongIndex = 0
 
 	queueInfo = service.GetQueueInfo()
-	if queueInfo.CurrentSong == nil {
-		t.Fatal("Expected current song to be returned, got nil")
-	}
-
-	if queueInfo.CurrentSong.YouTubeID != testSong.YouTubeID {
-		t.Errorf("Expected current song ID %s, got %s", testSong.YouTubeID, queueInfo.CurrentSong.YouTubeID)
+	currentSong := queueInfo.Queue[queueInfo.CurrentSongIndex]
+	if currentSong.YouTubeID != testSong.YouTubeID {
+		t.Errorf("Expected current song ID %s, got %s", testSong.YouTubeID, currentSong.YouTubeID)
 	}
 
 	if len(queueInfo.Queue) != 1 {
@@ -528,7 +861,7 @@ func TestStartPlaybackLoop(t *testing.T) {
 			songRepo := NewMockSongRepository()
 			playlistRepo := NewMockPlaylistRepository()
 			s3Service := &MockS3Service{}
-			eventBus := &MockEventBus{}
+			eventBus := events.NewNoopEventBus()
 
 			service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
@@ -549,7 +882,7 @@ func TestStartPlaybackLoop(t *testing.T) {
 				time.Sleep(100 * time.Millisecond)
 
 				state := service.GetPlaybackState()
-				if state.CurrentSong == nil {
+				if service.GetCurrentSong() == nil {
 					t.Error("Expected current song to be set after successful start")
 				}
 
@@ -565,9 +898,12 @@ func TestPlaybackLoopStateTransitions(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
-	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, s3Service, eventBus, clock)
+	// Allow the 1 second test songs below the default minimum duration
+	service.SetSongDurationLimits(time.Second, 0)
 
 	// Set up a playlist with short songs for testing
 	playlist := createTestPlaylist("1", "Test Playlist")
@@ -586,16 +922,13 @@ func TestPlaybackLoopStateTransitions(t *testing.T) {
 		t.Fatalf("Failed to start playback loop: %v", err)
 	}
 
-	// Wait for initial state to be set
-	time.Sleep(200 * time.Millisecond)
-
 	initialSong := service.GetCurrentSong()
 	if initialSong == nil {
 		t.Fatal("Expected initial song to be set")
 	}
 
-	// Wait for song to finish and transition to next song
-	time.Sleep(2 * time.Second)
+	// Let the song finish and transition to the next one
+	clock.Advance(t, 2*time.Second)
 
 	newSong := service.GetCurrentSong()
 	if newSong == nil {
@@ -611,13 +944,12 @@ func TestConcurrentAccess(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
 	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
 
 	// Set up some state
-	service.state.CurrentSong = createTestSong("test123", "Test Song", "Test Artist", 180)
-	service.state.Queue = []*models.Song{service.state.CurrentSong}
+	service.state.Queue = []*models.Song{createTestSong("test123", "Test Song", "Test Artist", 180)}
 
 	// Test concurrent reads
 	done := make(chan bool, 10)
@@ -643,9 +975,10 @@ func TestUpdatePlayStatsError(t *testing.T) {
 	songRepo := NewMockSongRepository()
 	playlistRepo := NewMockPlaylistRepository()
 	s3Service := &MockS3Service{}
-	eventBus := &MockEventBus{}
+	eventBus := events.NewNoopEventBus()
 
-	service := NewRadioService(songRepo, playlistRepo, s3Service, eventBus)
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, s3Service, eventBus, clock)
 
 	// Set up error in song repository
 	songRepo.updateStatsErr = errors.New("database error")
@@ -666,8 +999,8 @@ func TestUpdatePlayStatsError(t *testing.T) {
 		t.Fatalf("Expected playback loop to start despite stats update error: %v", err)
 	}
 
-	// Wait for song transition
-	time.Sleep(2 * time.Second)
+	// Let the song transition
+	clock.Advance(t, 2*time.Second)
 
 	// Verify playback continues despite stats update error
 	currentSong := service.GetCurrentSong()
@@ -675,3 +1008,1048 @@ func TestUpdatePlayStatsError(t *testing.T) {
 		t.Fatal("Expected playback to continue despite stats update error")
 	}
 }
+
+func TestGetRemainingTimeWithInterstitialGap(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+	service.SetInterstitialGap(3 * time.Second)
+
+	service.state.Queue = []*models.Song{createTestSong("test123", "Test Song", "Test Artist", 180)}
+
+	// Song audio has finished but the gap is still running
+	service.state.StartTime = time.Now().Add(-180 * time.Second)
+	remaining := service.GetRemainingTime()
+	if remaining <= 2*time.Second || remaining > 3*time.Second {
+		t.Errorf("Expected remaining time to cover the 3s gap, got %v", remaining)
+	}
+
+	queueInfo := service.GetQueueInfo()
+	if queueInfo.Remaining <= 2 || queueInfo.Remaining > 3 {
+		t.Errorf("Expected queue info remaining to cover the 3s gap, got %f", queueInfo.Remaining)
+	}
+
+	// Gap has elapsed too
+	service.state.StartTime = time.Now().Add(-184 * time.Second)
+	if remaining := service.GetRemainingTime(); remaining != 0 {
+		t.Errorf("Expected 0 remaining time after the gap, got %v", remaining)
+	}
+}
+
+func TestSetInterstitialGapIgnoresNegative(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+	service.SetInterstitialGap(-time.Second)
+
+	if service.interstitialGap != 0 {
+		t.Errorf("Expected negative gap to be clamped to 0, got %v", service.interstitialGap)
+	}
+}
+
+func TestPlaybackLoopHoldsForInterstitialGap(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetInterstitialGap(time.Second)
+	service.SetSongDurationLimits(time.Second, 0)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 1),
+		createTestSong("song2", "Song 2", "Artist 2", 1),
+		createTestSong("song3", "Song 3", "Artist 3", 1),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	initialSong := service.GetCurrentSong()
+	if initialSong == nil {
+		t.Fatal("Expected initial song to be set")
+	}
+
+	// The song has ended but we are still inside the gap
+	clock.Advance(t, 1300*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID != initialSong.YouTubeID {
+		t.Errorf("Expected song to hold during the gap, changed to %s", song.YouTubeID)
+	}
+
+	// Song plus gap have elapsed
+	clock.Advance(t, 1200*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID == initialSong.YouTubeID {
+		t.Error("Expected song to change after duration and gap elapsed")
+	}
+}
+
+func TestPlaybackLoopWaitsMinimumForZeroDuration(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(500*time.Millisecond, 0)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 0),
+		createTestSong("song2", "Song 2", "Artist 2", 0),
+		createTestSong("song3", "Song 3", "Artist 3", 0),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	initialSong := service.GetCurrentSong()
+	if initialSong == nil {
+		t.Fatal("Expected initial song to be set")
+	}
+
+	// A zero-duration song must not be skipped straight away
+	clock.Advance(t, 250*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID != initialSong.YouTubeID {
+		t.Errorf("Expected zero-duration song to hold for the minimum, changed to %s", song.YouTubeID)
+	}
+
+	clock.Advance(t, 450*time.Millisecond)
+	if song := service.GetCurrentSong(); song.YouTubeID == initialSong.YouTubeID {
+		t.Error("Expected song to change after the minimum duration")
+	}
+}
+
+func TestSongDurationLimits(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+	service.SetSongDurationLimits(10*time.Second, time.Minute)
+
+	tests := []struct {
+		name     string
+		duration int
+		expected time.Duration
+	}{
+		{name: "zero", duration: 0, expected: 10 * time.Second},
+		{name: "too short", duration: 3, expected: 10 * time.Second},
+		{name: "in range", duration: 42, expected: 42 * time.Second},
+		{name: "too long", duration: 3600, expected: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			song := createTestSong("song", "Song", "Artist", tt.duration)
+			if got := service.songDuration(song); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// A non-positive minimum keeps the current one and a non-positive maximum removes the cap
+	service.SetSongDurationLimits(0, 0)
+	if got := service.songDuration(createTestSong("song", "Song", "Artist", 3600)); got != time.Hour {
+		t.Errorf("Expected uncapped duration of 1h, got %v", got)
+	}
+	if got := service.songDuration(createTestSong("song", "Song", "Artist", 0)); got != 10*time.Second {
+		t.Errorf("Expected minimum to stay at 10s, got %v", got)
+	}
+}
+
+func TestRadioServiceDownloadsUpcomingSongs(t *testing.T) {
+	storage := newMemoryStorage()
+	storage.files[SongAudioKey("song1")] = []byte("audio")
+	downloader := &fileDownloader{content: testMP3}
+
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), storage, events.NewNoopEventBus())
+	service.SetAudioFetcher(NewAudioFetcher(downloader, storage, nil))
+
+	service.state.CurrentPlaylist = createTestPlaylist("1", "Test Playlist")
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+		createTestSong("song3", "Song 3", "Artist 3", 160),
+	}
+
+	service.Next()
+
+	// The new current and next songs are uploaded under the key playback reads
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		song2, _ := storage.FileExists(context.Background(), SongAudioKey("song2"))
+		song3, _ := storage.FileExists(context.Background(), SongAudioKey("song3"))
+		if song2 && song3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for upcoming songs to be uploaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	downloader.mu.Lock()
+	defer downloader.mu.Unlock()
+	if downloader.calls != 2 {
+		t.Errorf("Expected 2 downloads, got %d", downloader.calls)
+	}
+}
+
+func TestRadioServicePredownloadCount(t *testing.T) {
+	storage := newMemoryStorage()
+	downloader := &fileDownloader{content: testMP3}
+
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), storage, events.NewNoopEventBus())
+	service.SetAudioFetcher(NewAudioFetcher(downloader, storage, nil))
+	service.SetPredownloadCount(2)
+
+	service.state.CurrentPlaylist = createTestPlaylist("1", "Test Playlist")
+	for i := 1; i <= 5; i++ {
+		service.state.Queue = append(service.state.Queue, createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 180))
+	}
+	storage.files[SongAudioKey("song1")] = []byte("audio")
+
+	service.Next()
+
+	// The new current song and the two after it are fetched
+	deadline := time.Now().Add(2 * time.Second)
+	for _, id := range []string{"song2", "song3", "song4"} {
+		for {
+			if exists, _ := storage.FileExists(context.Background(), SongAudioKey(id)); exists {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s to be uploaded", id)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if exists, _ := storage.FileExists(context.Background(), SongAudioKey("song5")); exists {
+		t.Error("Expected song5 not to be downloaded yet")
+	}
+	downloader.mu.Lock()
+	defer downloader.mu.Unlock()
+	if downloader.calls != 3 {
+		t.Errorf("Expected 3 downloads, got %d", downloader.calls)
+	}
+}
+
+func TestPlaybackLoopFakeClockTransitions(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(time.Second, 0)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 20),
+		createTestSong("song3", "Song 3", "Artist 3", 30),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	// Walk through a full pass and into the reshuffled next one
+	for step := 0; step < 5; step++ {
+		current := service.GetCurrentSong()
+		index := service.GetPlaybackState().CurrentSongIndex
+		duration := time.Duration(current.Duration) * time.Second
+
+		clock.Advance(t, duration-time.Second)
+		if elapsed := service.GetElapsedTime(); elapsed != duration-time.Second {
+			t.Fatalf("Step %d: expected elapsed %v, got %v", step, duration-time.Second, elapsed)
+		}
+		if got := service.GetPlaybackState().CurrentSongIndex; got != index {
+			t.Fatalf("Step %d: expected song to keep playing, index moved to %d", step, got)
+		}
+
+		clock.Advance(t, time.Second)
+		wantIndex := (index + 1) % 3
+		if got := service.GetPlaybackState().CurrentSongIndex; got != wantIndex {
+			t.Fatalf("Step %d: expected index %d after %v, got %d", step, wantIndex, duration, got)
+		}
+		if elapsed := service.GetElapsedTime(); elapsed != 0 {
+			t.Fatalf("Step %d: expected the next song to start at 0, got %v", step, elapsed)
+		}
+	}
+}
+
+func TestReshuffleQueue(t *testing.T) {
+	eventBus := &recordingEventBus{}
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, eventBus)
+
+	var songs []*models.Song
+	for i := 0; i < 20; i++ {
+		songs = append(songs, createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 180))
+	}
+	startTime := time.Now().Add(-time.Minute)
+	service.state.Queue = append([]*models.Song(nil), songs...)
+	service.state.CurrentSongIndex = 3
+	service.state.StartTime = startTime
+
+	service.ReshuffleQueue()
+
+	state := service.GetPlaybackState()
+	if state.CurrentSongIndex != 3 || service.GetCurrentSong().YouTubeID != "song3" {
+		t.Fatalf("Expected song3 to keep playing at index 3, got %s at %d", service.GetCurrentSong().YouTubeID, state.CurrentSongIndex)
+	}
+	if !state.StartTime.Equal(startTime) {
+		t.Errorf("Expected start time to be unchanged, got %v", state.StartTime)
+	}
+	if len(state.Queue) != len(songs) {
+		t.Fatalf("Expected %d songs in the queue, got %d", len(songs), len(state.Queue))
+	}
+	for i := 0; i <= 3; i++ {
+		if state.Queue[i] != songs[i] {
+			t.Errorf("Expected played song %s to stay at %d, got %s", songs[i].YouTubeID, i, state.Queue[i].YouTubeID)
+		}
+	}
+
+	seen := make(map[string]bool)
+	changed := false
+	for i := 4; i < len(songs); i++ {
+		seen[state.Queue[i].YouTubeID] = true
+		if state.Queue[i] != songs[i] {
+			changed = true
+		}
+	}
+	if len(seen) != len(songs)-4 {
+		t.Errorf("Expected the upcoming songs to be a permutation, got %d distinct", len(seen))
+	}
+	if !changed {
+		t.Error("Expected the upcoming songs to be reordered")
+	}
+
+	if got := eventBus.queueUpdateCount(); got != 1 {
+		t.Errorf("Expected 1 queue update, got %d", got)
+	}
+}
+
+func TestReshuffleQueueNothingUpcoming(t *testing.T) {
+	eventBus := &recordingEventBus{}
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, eventBus)
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+	}
+
+	service.ReshuffleQueue()
+
+	if got := eventBus.queueUpdateCount(); got != 0 {
+		t.Errorf("Expected no queue update when there is nothing to reshuffle, got %d", got)
+	}
+}
+
+func TestReshuffleMidSongPlaysCurrentSongOnce(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	for i := 0; i < 5; i++ {
+		song := createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 10)
+		songRepo.Create(song)
+		playlistRepo.songs["1"] = append(playlistRepo.songs["1"], song)
+	}
+
+	clock := newFakeClock()
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, &MockS3Service{}, bus, clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetShuffle(true)
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	clock.Advance(t, 4*time.Second)
+	current := service.GetCurrentSong()
+	index := service.GetPlaybackState().CurrentSongIndex
+
+	// Queue the playing song again, then reshuffle halfway through it
+	if err := service.PlayNext(current.YouTubeID); err != nil {
+		t.Fatalf("PlayNext failed: %v", err)
+	}
+	service.ReshuffleQueue()
+
+	state := service.GetPlaybackState()
+	if state.CurrentSongIndex != index || service.GetCurrentSong().YouTubeID != current.YouTubeID {
+		t.Fatalf("Expected %s to keep playing at %d, got %s at %d", current.YouTubeID, index, service.GetCurrentSong().YouTubeID, state.CurrentSongIndex)
+	}
+	if remaining := service.GetRemainingTime(); remaining != 6*time.Second {
+		t.Errorf("Expected the current song to play on with 6s left, got %v", remaining)
+	}
+	for i, song := range state.Queue[index+1:] {
+		if song.YouTubeID == current.YouTubeID {
+			t.Errorf("Expected %s only at the current index, also queued at %d", current.YouTubeID, index+1+i)
+		}
+	}
+
+	// Play out the current song and the rest of the queue
+	for i := index; i < len(state.Queue); i++ {
+		clock.Advance(t, 10*time.Second)
+	}
+
+	changes := bus.changedSongs()
+	plays := 0
+	for i, change := range changes {
+		if change.current.YouTubeID == current.YouTubeID && i < len(state.Queue)-index {
+			plays++
+		}
+		if i > 0 && change.current.YouTubeID == changes[i-1].current.YouTubeID {
+			t.Errorf("Song change %d: expected %s not to replay straight after itself", i, change.current.YouTubeID)
+		}
+	}
+	if plays != 1 {
+		t.Errorf("Expected %s to play once before the queue restarts, played %d times", current.YouTubeID, plays)
+	}
+}
+
+func TestEndOfQueueReshuffleDoesNotReplay(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 10),
+		createTestSong("song2", "Song 2", "Artist", 10),
+	}
+
+	clock := newFakeClock()
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetShuffle(true)
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	for i := 0; i < 20; i++ {
+		clock.Advance(t, 10*time.Second)
+	}
+
+	changes := bus.changedSongs()
+	if len(changes) != 21 {
+		t.Fatalf("Expected 21 song changes, got %d", len(changes))
+	}
+	for i := 1; i < len(changes); i++ {
+		if changes[i].current.YouTubeID == changes[i-1].current.YouTubeID {
+			t.Errorf("Song change %d: %s replayed straight after itself", i, changes[i].current.YouTubeID)
+		}
+	}
+}
+
+func TestPlayNext(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	extra := createTestSong("extra", "Extra", "Artist", 180)
+	songRepo.Create(extra)
+
+	storage := newMemoryStorage()
+	downloader := &fileDownloader{content: testMP3}
+	eventBus := &recordingEventBus{}
+	service := NewRadioService(songRepo, NewMockPlaylistRepository(), storage, eventBus)
+	service.SetAudioFetcher(NewAudioFetcher(downloader, storage, nil))
+
+	if err := service.PlayNext("extra"); !errors.Is(err, ErrNothingPlaying) {
+		t.Errorf("Expected ErrNothingPlaying with an empty queue, got %v", err)
+	}
+
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+		createTestSong("song3", "Song 3", "Artist", 180),
+	}
+	service.state.CurrentSongIndex = 1
+
+	if err := service.PlayNext("missing"); !errors.Is(err, ErrSongNotFound) {
+		t.Errorf("Expected ErrSongNotFound, got %v", err)
+	}
+	if err := service.PlayNext("extra"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var order []string
+	for _, song := range service.GetPlaybackState().Queue {
+		order = append(order, song.YouTubeID)
+	}
+	if got := strings.Join(order, ","); got != "song1,song2,extra,song3" {
+		t.Errorf("Expected extra to be inserted after the current song, got %s", got)
+	}
+	if got := service.GetCurrentSong().YouTubeID; got != "song2" {
+		t.Errorf("Expected song2 to keep playing, got %s", got)
+	}
+	if got := eventBus.queueUpdateCount(); got != 1 {
+		t.Errorf("Expected 1 queue update, got %d", got)
+	}
+
+	// The inserted song is downloaded right away rather than at the next transition
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if exists, _ := storage.FileExists(context.Background(), SongAudioKey("extra")); exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the inserted song to be downloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPlaybackLoopPlaysInsertedSongNext(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	songRepo.Create(createTestSong("extra", "Extra", "Artist", 40))
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(time.Second, 0)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 20),
+		createTestSong("song3", "Song 3", "Artist 3", 30),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	current := service.GetCurrentSong()
+	if err := service.PlayNext("extra"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clock.Advance(t, time.Duration(current.Duration)*time.Second)
+
+	if got := service.GetCurrentSong().YouTubeID; got != "extra" {
+		t.Errorf("Expected the inserted song to play next, got %s", got)
+	}
+}
+
+func TestPlaybackLoopRepeatOffStops(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	eventBus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, eventBus, clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetRepeatMode(RepeatOff)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	clock.Advance(t, 10*time.Second)
+	if service.GetCurrentSong() == nil {
+		t.Fatal("Expected the second song to be playing")
+	}
+	if got := eventBus.stopCount(); got != 0 {
+		t.Fatalf("Expected playback to continue before the queue ends, got %d stops", got)
+	}
+
+	clock.Advance(t, 10*time.Second)
+	if song := service.GetCurrentSong(); song != nil {
+		t.Errorf("Expected no current song after the queue ended, got %s", song.YouTubeID)
+	}
+	if got := eventBus.stopCount(); got != 1 {
+		t.Errorf("Expected 1 playback stopped event, got %d", got)
+	}
+
+	// Later ticks leave the radio idle without announcing the stop again
+	clock.Advance(t, 10*time.Second)
+	if service.GetCurrentSong() != nil {
+		t.Error("Expected playback to stay stopped")
+	}
+	if got := eventBus.stopCount(); got != 1 {
+		t.Errorf("Expected a single playback stopped event, got %d", got)
+	}
+}
+
+func TestPlaybackLoopRepeatOne(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	eventBus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, eventBus, clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetShuffle(false)
+	service.SetRepeatMode(RepeatOne)
+
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(t, 10*time.Second)
+	}
+
+	state := service.GetPlaybackState()
+	if state.CurrentSongIndex != 0 || service.GetCurrentSong().YouTubeID != "song1" {
+		t.Fatalf("Expected song1 to keep replaying at index 0, got %s at %d", service.GetCurrentSong().YouTubeID, state.CurrentSongIndex)
+	}
+	if !state.StartTime.Equal(clock.Now()) {
+		t.Errorf("Expected the replay to start now, started %v", state.StartTime)
+	}
+	changes := eventBus.changedSongs()
+	if len(changes) != 4 {
+		t.Fatalf("Expected a song change for the start and each of 3 replays, got %d", len(changes))
+	}
+	for i, change := range changes[1:] {
+		if change.current.YouTubeID != "song1" || change.next == nil || change.next.YouTubeID != "song1" {
+			t.Errorf("Replay %d: expected song1 to play and follow itself, got %+v", i+1, change)
+		}
+	}
+	if next := service.Snapshot(0).NextSong; next == nil || next.YouTubeID != "song1" {
+		t.Errorf("Expected song1 to be next in the snapshot, got %v", next)
+	}
+
+	// Skipping still moves on, and switching back to repeat all advances
+	service.Next()
+	if song := service.GetCurrentSong(); song.YouTubeID != "song2" {
+		t.Errorf("Expected a skip to move to song2, got %s", song.YouTubeID)
+	}
+	service.SetRepeatMode(RepeatAll)
+	clock.Advance(t, 10*time.Second)
+	if song := service.GetCurrentSong(); song.YouTubeID != "song1" {
+		t.Errorf("Expected the queue to start again under repeat all, got %s", song.YouTubeID)
+	}
+}
+
+func TestSongsPlayedCountsSongsThatEnd(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetSongDurationLimits(time.Second, 0)
+	service.SetShuffle(false)
+
+	playlist := createTestPlaylist("1", "Test Playlist")
+	playlistRepo.firstPlaylist = playlist
+	playlistRepo.playlists["1"] = playlist
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 10),
+		createTestSong("song2", "Song 2", "Artist 2", 10),
+		createTestSong("song3", "Song 3", "Artist 3", 10),
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+
+	played := testutil.ToFloat64(metrics.SongsPlayed)
+	expectPlayed := func(step string, want float64) {
+		t.Helper()
+		if got := testutil.ToFloat64(metrics.SongsPlayed) - played; got != want {
+			t.Errorf("%s: expected %v songs counted as played, got %v", step, want, got)
+		}
+	}
+
+	// Replacing the queue cuts the song off rather than playing it
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+	expectPlayed("switching playlists", 0)
+
+	clock.Advance(t, 10*time.Second)
+	expectPlayed("a song finishing", 1)
+
+	service.Next()
+	expectPlayed("a skip", 2)
+
+	service.SetRepeatMode(RepeatOne)
+	clock.Advance(t, 10*time.Second)
+	expectPlayed("a repeat", 3)
+
+	// The last song finishing under repeat off is played and in the history
+	service.SetRepeatMode(RepeatOff)
+	clock.Advance(t, 10*time.Second)
+	expectPlayed("the queue ending", 4)
+	service.mu.RLock()
+	recent := service.history.recent(1)
+	service.mu.RUnlock()
+	if len(recent) != 1 || recent[0].YouTubeID != "song3" {
+		t.Errorf("Expected song3 in the play history, got %v", recent)
+	}
+
+	// Stopping part way through a song doesn't count it
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+	if err := service.StopPlayback(); err != nil {
+		t.Fatalf("StopPlayback failed: %v", err)
+	}
+	expectPlayed("stopping playback", 4)
+}
+
+func TestParseRepeatMode(t *testing.T) {
+	for _, name := range []string{"all", "off", "one"} {
+		mode, err := ParseRepeatMode(name)
+		if err != nil || string(mode) != name {
+			t.Errorf("ParseRepeatMode(%q) = %q, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseRepeatMode("once"); err == nil {
+		t.Error("Expected an error for an unknown repeat mode")
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.state.Queue = []*models.Song{createTestSong("song1", "Song 1", "Artist", 180)}
+	service.state.StartTime = clock.Now().Add(-30 * time.Second)
+
+	service.Pause()
+	clock.now = clock.now.Add(time.Minute)
+
+	if elapsed := service.GetElapsedTime(); elapsed != 30*time.Second {
+		t.Errorf("Expected elapsed time to stay at 30s while paused, got %v", elapsed)
+	}
+	if remaining := service.GetRemainingTime(); remaining != 150*time.Second {
+		t.Errorf("Expected remaining time to stay at 150s while paused, got %v", remaining)
+	}
+
+	service.Resume()
+	clock.now = clock.now.Add(10 * time.Second)
+
+	if service.GetPlaybackState().Paused {
+		t.Error("Expected playback to be resumed")
+	}
+	if elapsed := service.GetElapsedTime(); elapsed != 40*time.Second {
+		t.Errorf("Expected playback to continue from 30s, got %v elapsed", elapsed)
+	}
+}
+
+func TestSetVolume(t *testing.T) {
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, bus, newFakeClock())
+	service.state.Queue = []*models.Song{createTestSong("song1", "Song 1", "Artist", 180)}
+
+	if volume := service.GetVolume(); volume != DefaultVolume {
+		t.Errorf("Expected volume to start at %v, got %v", DefaultVolume, volume)
+	}
+
+	tests := []struct {
+		set, want float64
+	}{
+		{0.5, 0.5},
+		{0, 0},
+		{1, 1},
+		{1.7, 1},
+		{-0.2, 0},
+		{math.NaN(), 0},
+	}
+	var wantPublished []float64
+	for _, tt := range tests {
+		if applied := service.SetVolume(tt.set); applied != tt.want {
+			t.Errorf("SetVolume(%v) applied %v, expected %v", tt.set, applied, tt.want)
+		}
+		if volume := service.GetVolume(); volume != tt.want {
+			t.Errorf("After SetVolume(%v) expected volume %v, got %v", tt.set, tt.want, volume)
+		}
+		wantPublished = append(wantPublished, tt.want)
+	}
+
+	if got := bus.publishedVolumes(); !reflect.DeepEqual(got, wantPublished) {
+		t.Errorf("Expected playback updates with volumes %v, got %v", wantPublished, got)
+	}
+}
+
+func TestStartPlaybackLoopAnnouncesQueueHead(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("song%d", i)
+		playlistRepo.songs["1"] = append(playlistRepo.songs["1"], createTestSong(id, id, "Artist", 180))
+	}
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, newFakeClock())
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+
+	changes := bus.changedSongs()
+	if len(changes) == 0 {
+		t.Fatal("Expected a song change when playback starts")
+	}
+	if playing := service.GetCurrentSong(); changes[0].current.YouTubeID != playing.YouTubeID {
+		t.Errorf("Expected the first song change to announce %s, got %s", playing.YouTubeID, changes[0].current.YouTubeID)
+	}
+}
+
+func TestSingleSongPlaylistReplays(t *testing.T) {
+	for _, shuffle := range []bool{true, false} {
+		t.Run(fmt.Sprintf("shuffle=%v", shuffle), func(t *testing.T) {
+			playlistRepo := NewMockPlaylistRepository()
+			playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist")
+			playlistRepo.songs["1"] = []*models.Song{createTestSong("only", "Only Song", "Artist", 10)}
+
+			clock := newFakeClock()
+			bus := &recordingEventBus{}
+			service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, clock)
+			service.SetSongDurationLimits(time.Second, 0)
+			service.SetShuffle(shuffle)
+
+			if err := service.StartPlaybackLoop(); err != nil {
+				t.Fatalf("Failed to start playback loop: %v", err)
+			}
+			defer service.StopPlayback()
+
+			const cycles = 3
+			for i := 0; i < cycles; i++ {
+				clock.Advance(t, 10*time.Second)
+			}
+
+			changes := bus.changedSongs()
+			if len(changes) != cycles+1 {
+				t.Fatalf("Expected %d song changes for the start and %d replays, got %d", cycles+1, cycles, len(changes))
+			}
+			for i, change := range changes {
+				if change.current.YouTubeID != "only" {
+					t.Errorf("Song change %d: expected the only song, got %s", i, change.current.YouTubeID)
+				}
+				if change.next != nil {
+					t.Errorf("Song change %d: expected no next song, got %s", i, change.next.YouTubeID)
+				}
+			}
+
+			queue := service.GetQueueInfo()
+			if len(queue.Queue) != 1 || queue.CurrentSongIndex != 0 {
+				t.Errorf("Expected the one song queue to stay at index 0, got %d songs at %d", len(queue.Queue), queue.CurrentSongIndex)
+			}
+			if next := service.Snapshot(0).NextSong; next != nil {
+				t.Errorf("Expected no next song in the snapshot, got %s", next.YouTubeID)
+			}
+		})
+	}
+}
+
+func TestPreviewPlaylistLeavesPlaybackAlone(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	playlistRepo.firstPlaylist = createTestPlaylist("1", "Test Playlist 1")
+	playlistRepo.playlists["1"] = playlistRepo.firstPlaylist
+	playlistRepo.playlists["2"] = createTestPlaylist("2", "Test Playlist 2")
+	playlistRepo.songs["1"] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist 1", 180),
+		createTestSong("song2", "Song 2", "Artist 2", 200),
+	}
+	playlistRepo.songs["2"] = []*models.Song{
+		createTestSong("song3", "Song 3", "Artist 3", 160),
+		createTestSong("song4", "Song 4", "Artist 4", 220),
+		createTestSong("song5", "Song 5", "Artist 5", 240),
+	}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), newFakeClock())
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Failed to start playback loop: %v", err)
+	}
+	defer service.StopPlayback()
+	playing := service.GetCurrentSong()
+
+	preview, err := service.PreviewPlaylist("2")
+	if err != nil {
+		t.Fatalf("PreviewPlaylist failed: %v", err)
+	}
+	if preview.Playlist.ID != "2" || len(preview.Queue) != 3 {
+		t.Errorf("Expected a 3 song preview of playlist 2, got %d songs of %v", len(preview.Queue), preview.Playlist)
+	}
+
+	if song := service.GetCurrentSong(); song.YouTubeID != playing.YouTubeID {
+		t.Errorf("Expected %s to keep playing after a preview, got %s", playing.YouTubeID, song.YouTubeID)
+	}
+	if playlist := service.GetQueueInfo().Playlist; playlist.ID != "1" {
+		t.Errorf("Expected playlist 1 to stay active after a preview, got %s", playlist.ID)
+	}
+
+	if _, err := service.PreviewPlaylist("missing"); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Errorf("Expected ErrPlaylistNotFound for an unknown playlist, got %v", err)
+	}
+}
+
+func TestSetActivePlaylistKeepsVolume(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	playlistRepo := NewMockPlaylistRepository()
+	playlist := createTestPlaylist("playlist1", "Test Playlist")
+	playlistRepo.playlists[playlist.ID] = playlist
+	playlistRepo.songs[playlist.ID] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 200),
+	}
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), newFakeClock())
+
+	service.SetVolume(0.25)
+	if err := service.SetActivePlaylist(playlist.ID); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+
+	if volume := service.GetVolume(); volume != 0.25 {
+		t.Errorf("Expected volume 0.25 to carry over to the new playlist, got %v", volume)
+	}
+}
+
+func TestStopAndStartPlayback(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	first := createTestPlaylist("1", "First")
+	second := createTestPlaylist("2", "Second")
+	playlistRepo.playlists[first.ID] = first
+	playlistRepo.playlists[second.ID] = second
+	playlistRepo.firstPlaylist = first
+	playlistRepo.songs[first.ID] = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+	}
+	playlistRepo.songs[second.ID] = []*models.Song{
+		createTestSong("song3", "Song 3", "Artist", 180),
+		createTestSong("song4", "Song 4", "Artist", 180),
+	}
+
+	bus := &recordingEventBus{}
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, bus, newFakeClock())
+
+	if err := service.StopPlayback(); !errors.Is(err, ErrPlaybackNotRunning) {
+		t.Errorf("Expected ErrPlaybackNotRunning before starting, got %v", err)
+	}
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("StartPlaybackLoop failed: %v", err)
+	}
+	if err := service.StartPlaybackLoop(); !errors.Is(err, ErrPlaybackRunning) {
+		t.Errorf("Expected ErrPlaybackRunning for a second start, got %v", err)
+	}
+
+	if err := service.StopPlayback(); err != nil {
+		t.Fatalf("StopPlayback failed: %v", err)
+	}
+	if song := service.GetCurrentSong(); song != nil {
+		t.Errorf("Expected nothing playing once stopped, got %s", song.YouTubeID)
+	}
+	if bus.stopCount() != 1 {
+		t.Errorf("Expected 1 playback_stopped, got %d", bus.stopCount())
+	}
+	if err := service.StopPlayback(); !errors.Is(err, ErrPlaybackNotRunning) {
+		t.Errorf("Expected ErrPlaybackNotRunning when already stopped, got %v", err)
+	}
+
+	// Picking a playlist while stopped only decides what starts
+	if err := service.SetActivePlaylist(second.ID); err != nil {
+		t.Fatalf("SetActivePlaylist failed: %v", err)
+	}
+	if song := service.GetCurrentSong(); song != nil {
+		t.Errorf("Expected playback to stay stopped, got %s", song.YouTubeID)
+	}
+
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("Restarting playback failed: %v", err)
+	}
+	queueInfo := service.GetQueueInfo()
+	if queueInfo.Playlist != second || len(queueInfo.Queue) != 2 {
+		t.Errorf("Expected a fresh queue of the second playlist, got %v with %d songs", queueInfo.Playlist, len(queueInfo.Queue))
+	}
+	if song := service.GetCurrentSong(); song == nil || (song.YouTubeID != "song3" && song.YouTubeID != "song4") {
+		t.Errorf("Expected a song from the second playlist, got %v", song)
+	}
+	if bus.startCount() != 2 {
+		t.Errorf("Expected playback_started for both starts, got %d", bus.startCount())
+	}
+
+	if err := service.StopPlayback(); err != nil {
+		t.Errorf("Stopping the restarted loop failed: %v", err)
+	}
+}
+
+func TestSetActivePlaylistSkipsExcludedTags(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	service := NewRadioService(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus())
+	service.SetExcludedTags([]string{"Explicit"})
+
+	clean := createTestSong("clean", "Clean", "Artist", 180)
+	explicit := createTestSong("explicit", "Explicit", "Artist", 180)
+	explicit.Tags = models.Tags{"explicit"}
+	playlistRepo.playlists["1"] = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.songs["1"] = []*models.Song{clean, explicit}
+
+	if err := service.SetActivePlaylist("1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	queue := service.GetPlaybackState().Queue
+	if len(queue) != 1 || queue[0].YouTubeID != "clean" {
+		t.Errorf("Expected only the clean song to be queued, got %v", queue)
+	}
+}
+
+func TestShuffleDisabledKeepsPlaylistOrder(t *testing.T) {
+	playlistRepo := NewMockPlaylistRepository()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), playlistRepo, &MockS3Service{}, events.NewNoopEventBus(), newFakeClock())
+	service.SetShuffle(false)
+
+	var songs []*models.Song
+	for i := 0; i < 20; i++ {
+		songs = append(songs, createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 180))
+	}
+	playlistRepo.playlists["1"] = createTestPlaylist("1", "Test Playlist")
+	playlistRepo.firstPlaylist = playlistRepo.playlists["1"]
+	playlistRepo.songs["1"] = songs
+
+	assertOrder := func(t *testing.T, queue []*models.Song) {
+		t.Helper()
+		if len(queue) != len(songs) {
+			t.Fatalf("Expected %d queued songs, got %d", len(songs), len(queue))
+		}
+		for i, song := range songs {
+			if queue[i].YouTubeID != song.YouTubeID {
+				t.Fatalf("Expected %s at position %d, got %s", song.YouTubeID, i, queue[i].YouTubeID)
+			}
+		}
+	}
+
+	t.Run("StartPlaybackLoop", func(t *testing.T) {
+		if err := service.StartPlaybackLoop(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertOrder(t, service.GetPlaybackState().Queue)
+	})
+
+	t.Run("SetActivePlaylist", func(t *testing.T) {
+		if err := service.SetActivePlaylist("1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertOrder(t, service.GetPlaybackState().Queue)
+	})
+}
+
+func TestRadioServiceWithInMemoryRepositories(t *testing.T) {
+	songRepo := repositories.NewInMemorySongRepository()
+	playlistRepo := repositories.NewInMemoryPlaylistRepository(songRepo)
+
+	playlist := &models.Playlist{Name: "Demo"}
+	if err := playlistRepo.Create(playlist); err != nil {
+		t.Fatalf("Create playlist failed: %v", err)
+	}
+	for i, id := range []string{"song1", "song2"} {
+		if err := songRepo.Create(createTestSong(id, id, "Artist", 180)); err != nil {
+			t.Fatalf("Create song failed: %v", err)
+		}
+		if err := playlistRepo.AddSong(playlist.ID, id, i); err != nil {
+			t.Fatalf("AddSong failed: %v", err)
+		}
+	}
+
+	service := NewRadioServiceWithClock(songRepo, playlistRepo, &MockS3Service{}, &recordingEventBus{}, newFakeClock())
+	if err := service.StartPlaybackLoop(); err != nil {
+		t.Fatalf("StartPlaybackLoop failed: %v", err)
+	}
+	defer service.StopPlayback()
+
+	first := service.GetCurrentSong()
+	if first == nil {
+		t.Fatal("Expected a song from the in-memory playlist to be playing")
+	}
+	service.Next()
+	if song := service.GetCurrentSong(); song == nil || song.YouTubeID == first.YouTubeID {
+		t.Errorf("Expected the other song after Next, got %v", song)
+	}
+}
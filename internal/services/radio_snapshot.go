@@ -0,0 +1,80 @@
+package services
+
+import "github.com/feline-dis/go-radio-v2/internal/models"
+
+const (
+	// DefaultSnapshotQueueWindow is how many upcoming songs a snapshot lists by default
+	DefaultSnapshotQueueWindow = 10
+	// MaxSnapshotQueueWindow caps how many upcoming songs a snapshot can list
+	MaxSnapshotQueueWindow = 50
+)
+
+// RadioSnapshot is everything the websocket sends across its event types,
+// read at a single instant, for clients that poll instead
+type RadioSnapshot struct {
+	CurrentSong      *models.Song     `json:"current_song"`
+	NextSong         *models.Song     `json:"next_song"`
+	Playlist         *models.Playlist `json:"playlist"`
+	Elapsed          float64          `json:"elapsed"`
+	Remaining        float64          `json:"remaining"`
+	PositionMs       int64            `json:"position_ms"`
+	Paused           bool             `json:"paused"`
+	Volume           float64          `json:"volume"`
+	CurrentSongIndex int              `json:"current_song_index"`
+	QueueLength      int              `json:"queue_length"`
+	Upcoming         []*models.Song   `json:"upcoming"`
+	RepeatMode       RepeatMode       `json:"repeat_mode"`
+	Shuffle          bool             `json:"shuffle"`
+	ListenerCount    int              `json:"listener_count"`
+	ServerTime       int64            `json:"server_time"` // Unix milliseconds the snapshot was taken at
+}
+
+// Snapshot reads the playback state in one lock acquisition, listing up to
+// window songs after the current one. Non-positive windows fall back to the
+// default and large ones are clamped. ListenerCount is left for the caller.
+func (s *RadioService) Snapshot(window int) *RadioSnapshot {
+	if window <= 0 {
+		window = DefaultSnapshotQueueWindow
+	}
+	window = min(window, MaxSnapshotQueueWindow)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := &RadioSnapshot{
+		Volume:     DefaultVolume,
+		Upcoming:   []*models.Song{},
+		RepeatMode: s.repeatMode,
+		Shuffle:    s.shuffle,
+		ServerTime: s.clock.Now().UnixMilli(),
+	}
+	if s.state == nil {
+		return snapshot
+	}
+
+	snapshot.Playlist = s.state.CurrentPlaylist
+	snapshot.Paused = s.state.Paused
+	snapshot.Volume = s.state.Volume
+	snapshot.CurrentSongIndex = s.state.CurrentSongIndex
+	snapshot.QueueLength = len(s.state.Queue)
+
+	index := s.state.CurrentSongIndex
+	if index < 0 || index >= len(s.state.Queue) || s.state.Queue[index] == nil {
+		return snapshot
+	}
+
+	current := s.state.Queue[index]
+	snapshot.CurrentSong = current
+	snapshot.NextSong = s.upNext(s.state.Queue, index)
+
+	elapsed := s.positionTime().Sub(s.state.StartTime)
+	snapshot.Elapsed = elapsed.Seconds()
+	snapshot.PositionMs = elapsed.Milliseconds()
+	if remaining := s.slotDuration(current) - elapsed; remaining > 0 {
+		snapshot.Remaining = remaining.Seconds()
+	}
+
+	end := min(index+1+window, len(s.state.Queue))
+	snapshot.Upcoming = append(snapshot.Upcoming, s.state.Queue[index+1:end]...)
+	return snapshot
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+func TestSnapshotMatchesAccessors(t *testing.T) {
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetRepeatMode(RepeatOff)
+	service.SetShuffle(false)
+
+	service.state.CurrentPlaylist = createTestPlaylist("1", "Test Playlist")
+	for i := 0; i < 20; i++ {
+		service.state.Queue = append(service.state.Queue, createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 180))
+	}
+	service.state.CurrentSongIndex = 3
+	service.state.StartTime = clock.Now().Add(-42*time.Second - 250*time.Millisecond)
+
+	snapshot := service.Snapshot(5)
+
+	queueInfo := service.GetQueueInfo()
+	if snapshot.CurrentSong != service.GetCurrentSong() {
+		t.Errorf("Expected current song %v, got %v", service.GetCurrentSong(), snapshot.CurrentSong)
+	}
+	if snapshot.NextSong != queueInfo.Queue[4] {
+		t.Errorf("Expected next song %s, got %v", queueInfo.Queue[4].YouTubeID, snapshot.NextSong)
+	}
+	if snapshot.Elapsed != service.GetElapsedTime().Seconds() {
+		t.Errorf("Expected elapsed %v, got %v", service.GetElapsedTime().Seconds(), snapshot.Elapsed)
+	}
+	if snapshot.PositionMs != 42250 {
+		t.Errorf("Expected position 42250ms, got %d", snapshot.PositionMs)
+	}
+	if snapshot.Remaining != service.GetRemainingTime().Seconds() {
+		t.Errorf("Expected remaining %v, got %v", service.GetRemainingTime().Seconds(), snapshot.Remaining)
+	}
+	if snapshot.Remaining != queueInfo.Remaining {
+		t.Errorf("Expected remaining to match queue info %v, got %v", queueInfo.Remaining, snapshot.Remaining)
+	}
+	if snapshot.Playlist != queueInfo.Playlist || snapshot.CurrentSongIndex != queueInfo.CurrentSongIndex {
+		t.Errorf("Expected playlist %v at %d, got %v at %d", queueInfo.Playlist, queueInfo.CurrentSongIndex, snapshot.Playlist, snapshot.CurrentSongIndex)
+	}
+	if snapshot.QueueLength != len(queueInfo.Queue) {
+		t.Errorf("Expected queue length %d, got %d", len(queueInfo.Queue), snapshot.QueueLength)
+	}
+	if len(snapshot.Upcoming) != 5 {
+		t.Fatalf("Expected 5 upcoming songs, got %d", len(snapshot.Upcoming))
+	}
+	for i, song := range snapshot.Upcoming {
+		if song != queueInfo.Queue[4+i] {
+			t.Errorf("Expected %s at upcoming %d, got %s", queueInfo.Queue[4+i].YouTubeID, i, song.YouTubeID)
+		}
+	}
+	if snapshot.Paused != service.GetPlaybackState().Paused {
+		t.Errorf("Expected paused %v, got %v", service.GetPlaybackState().Paused, snapshot.Paused)
+	}
+	if snapshot.RepeatMode != RepeatOff || snapshot.Shuffle {
+		t.Errorf("Expected repeat off without shuffle, got %s and %v", snapshot.RepeatMode, snapshot.Shuffle)
+	}
+	if snapshot.ServerTime != clock.Now().UnixMilli() {
+		t.Errorf("Expected server time %d, got %d", clock.Now().UnixMilli(), snapshot.ServerTime)
+	}
+}
+
+func TestSnapshotQueueWindow(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+
+	// Nothing playing gives an empty snapshot
+	snapshot := service.Snapshot(0)
+	if snapshot.CurrentSong != nil || snapshot.Upcoming == nil || len(snapshot.Upcoming) != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", snapshot)
+	}
+
+	for i := 0; i < 80; i++ {
+		service.state.Queue = append(service.state.Queue, &models.Song{YouTubeID: fmt.Sprintf("song%d", i), Duration: 180})
+	}
+
+	tests := []struct {
+		window   int
+		index    int
+		expected int
+	}{
+		{window: 0, index: 0, expected: DefaultSnapshotQueueWindow},
+		{window: 1000, index: 0, expected: MaxSnapshotQueueWindow},
+		{window: 10, index: 75, expected: 4},
+		{window: 10, index: 79, expected: 0},
+	}
+	for _, tt := range tests {
+		service.state.CurrentSongIndex = tt.index
+		if got := len(service.Snapshot(tt.window).Upcoming); got != tt.expected {
+			t.Errorf("Snapshot(%d) at %d: expected %d upcoming songs, got %d", tt.window, tt.index, tt.expected, got)
+		}
+	}
+}
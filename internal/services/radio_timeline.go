@@ -0,0 +1,93 @@
+package services
+
+import "github.com/feline-dis/go-radio-v2/internal/models"
+
+const (
+	// DefaultTimelineBefore is how many played songs a timeline lists by default
+	DefaultTimelineBefore = 3
+	// DefaultTimelineAfter is how many upcoming songs a timeline lists by default
+	DefaultTimelineAfter = 5
+	// MaxTimelineWindow caps how many songs a timeline lists on either side
+	MaxTimelineWindow = playHistorySize
+)
+
+// TimelineEntry is an upcoming song and when it is expected to start
+type TimelineEntry struct {
+	Song     *models.Song `json:"song"`
+	StartsAt int64        `json:"starts_at"` // Unix milliseconds
+	StartsIn float64      `json:"starts_in"` // Seconds after ServerTime
+}
+
+// RadioTimeline is what just played, what is playing and what plays next,
+// for a player to render as one list
+type RadioTimeline struct {
+	History    []*models.Song  `json:"history"` // Oldest first, ending with the song before Current
+	Current    *models.Song    `json:"current"`
+	Elapsed    float64         `json:"elapsed"`
+	Remaining  float64         `json:"remaining"`
+	Paused     bool            `json:"paused"`
+	Upcoming   []TimelineEntry `json:"upcoming"`
+	ServerTime int64           `json:"server_time"` // Unix milliseconds the timeline was taken at
+}
+
+// Timeline lists up to before played songs and after upcoming ones around
+// the current song, read under one lock. Start times add up the remaining
+// slots from now, so while paused they assume playback resumes immediately.
+// Upcoming songs wrap past the end of the queue only when the order is known:
+// repeating without shuffle or a queue source.
+func (s *RadioService) Timeline(before, after int) *RadioTimeline {
+	before = max(0, min(before, MaxTimelineWindow))
+	after = max(0, min(after, MaxTimelineWindow))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	timeline := &RadioTimeline{
+		History:    s.history.recent(before),
+		Upcoming:   []TimelineEntry{},
+		ServerTime: now.UnixMilli(),
+	}
+	if s.state == nil {
+		return timeline
+	}
+	timeline.Paused = s.state.Paused
+
+	queue := s.state.Queue
+	index := s.state.CurrentSongIndex
+	if index < 0 || index >= len(queue) || queue[index] == nil {
+		return timeline
+	}
+
+	current := queue[index]
+	timeline.Current = current
+	elapsed := s.positionTime().Sub(s.state.StartTime)
+	remaining := max(0, s.slotDuration(current)-elapsed)
+	timeline.Elapsed = elapsed.Seconds()
+	timeline.Remaining = remaining.Seconds()
+
+	last := len(queue) - 1
+	switch {
+	case s.repeatMode == RepeatOne:
+		// The current song comes round again and again
+		last = index + after
+	case s.repeatMode == RepeatAll && !s.shuffle && s.queueSource == nil:
+		// Wrap around, stopping short of coming back to the current song
+		last = index + len(queue) - 1
+	}
+
+	startsIn := remaining
+	for i := index + 1; i <= last && len(timeline.Upcoming) < after; i++ {
+		song := queue[i%len(queue)]
+		if s.repeatMode == RepeatOne {
+			song = current
+		}
+		timeline.Upcoming = append(timeline.Upcoming, TimelineEntry{
+			Song:     song,
+			StartsAt: now.Add(startsIn).UnixMilli(),
+			StartsIn: startsIn.Seconds(),
+		})
+		startsIn += s.slotDuration(song)
+	}
+	return timeline
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// newTimelineService is a radio playing the third of five songs, each
+// scheduled for 100s plus a 2s gap, with 40s of it played
+func newTimelineService(t *testing.T) (*RadioService, *fakeClock) {
+	t.Helper()
+
+	clock := newFakeClock()
+	service := NewRadioServiceWithClock(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus(), clock)
+	service.SetShuffle(false)
+	service.SetInterstitialGap(2 * time.Second)
+	for i := 0; i < 5; i++ {
+		service.state.Queue = append(service.state.Queue, createTestSong(fmt.Sprintf("song%d", i), fmt.Sprintf("Song %d", i), "Artist", 100))
+	}
+	service.state.CurrentSongIndex = 2
+	service.state.StartTime = clock.Now().Add(-40 * time.Second)
+	return service, clock
+}
+
+func timelineIDs(songs []*models.Song) []string {
+	ids := []string{}
+	for _, song := range songs {
+		ids = append(ids, song.YouTubeID)
+	}
+	return ids
+}
+
+func upcomingIDs(entries []TimelineEntry) []string {
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, entry.Song.YouTubeID)
+	}
+	return ids
+}
+
+func TestTimelineStartTimes(t *testing.T) {
+	service, clock := newTimelineService(t)
+
+	timeline := service.Timeline(3, 2)
+
+	if timeline.Current == nil || timeline.Current.YouTubeID != "song2" {
+		t.Fatalf("Expected song2 to be current, got %v", timeline.Current)
+	}
+	if timeline.Elapsed != 40 || timeline.Remaining != 62 {
+		t.Errorf("Expected 40s elapsed and 62s remaining, got %v and %v", timeline.Elapsed, timeline.Remaining)
+	}
+	if got := upcomingIDs(timeline.Upcoming); fmt.Sprint(got) != "[song3 song4]" {
+		t.Fatalf("Expected song3 and song4 upcoming, got %v", got)
+	}
+
+	// Each song starts when the one before it and its gap are over
+	for i, startsIn := range []time.Duration{62 * time.Second, 164 * time.Second} {
+		entry := timeline.Upcoming[i]
+		if entry.StartsIn != startsIn.Seconds() {
+			t.Errorf("Expected %s to start in %v, got %vs", entry.Song.YouTubeID, startsIn, entry.StartsIn)
+		}
+		if want := clock.Now().Add(startsIn).UnixMilli(); entry.StartsAt != want {
+			t.Errorf("Expected %s to start at %d, got %d", entry.Song.YouTubeID, want, entry.StartsAt)
+		}
+	}
+	if timeline.ServerTime != clock.Now().UnixMilli() {
+		t.Errorf("Expected server time %d, got %d", clock.Now().UnixMilli(), timeline.ServerTime)
+	}
+}
+
+func TestTimelineHistory(t *testing.T) {
+	service, _ := newTimelineService(t)
+
+	// Nothing has finished playing yet
+	timeline := service.Timeline(3, 0)
+	if timeline.History == nil || len(timeline.History) != 0 {
+		t.Errorf("Expected an empty history, got %v", timeline.History)
+	}
+	if timeline.Upcoming == nil || len(timeline.Upcoming) != 0 {
+		t.Errorf("Expected no upcoming songs for after=0, got %v", upcomingIDs(timeline.Upcoming))
+	}
+
+	service.Next()
+	service.Next()
+	timeline = service.Timeline(3, 0)
+	if got := timelineIDs(timeline.History); fmt.Sprint(got) != "[song2 song3]" {
+		t.Errorf("Expected the two played songs oldest first, got %v", got)
+	}
+
+	service.Next()
+	service.Next()
+	timeline = service.Timeline(3, 0)
+	if got := timelineIDs(timeline.History); fmt.Sprint(got) != "[song3 song4 song0]" {
+		t.Errorf("Expected the last three played songs, got %v", got)
+	}
+}
+
+func TestTimelineEndOfQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		repeat  RepeatMode
+		shuffle bool
+		want    string
+	}{
+		// Wraps to the start but never reaches the current song again
+		{"repeat in order", RepeatAll, false, "[song0 song1 song2 song3]"},
+		{"repeat off", RepeatOff, false, "[]"},
+		// The queue is reshuffled at the end, so what follows isn't known
+		{"repeat with shuffle", RepeatAll, true, "[]"},
+		{"repeat one", RepeatOne, true, "[song4 song4 song4 song4 song4 song4 song4 song4 song4 song4]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTimelineService(t)
+			service.SetRepeatMode(tt.repeat)
+			service.SetShuffle(tt.shuffle)
+			service.state.CurrentSongIndex = 4
+
+			timeline := service.Timeline(0, 10)
+			if got := upcomingIDs(timeline.Upcoming); fmt.Sprint(got) != tt.want {
+				t.Errorf("Expected upcoming %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTimelineIdle(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), &MockS3Service{}, events.NewNoopEventBus())
+
+	timeline := service.Timeline(DefaultTimelineBefore, DefaultTimelineAfter)
+	if timeline.Current != nil || timeline.History == nil || timeline.Upcoming == nil {
+		t.Errorf("Expected an empty timeline with empty lists, got %+v", timeline)
+	}
+}
@@ -15,6 +15,16 @@ import (
 	"github.com/feline-dis/go-radio-v2/internal/config"
 )
 
+// SongAudioPrefix is the storage key prefix all song audio is kept under.
+// Downloads and playback both build keys with SongAudioKey so they can't drift apart.
+const SongAudioPrefix = "songs/"
+
+// SongAudioKey returns the storage key downloaded audio is stored under.
+// Audio in other formats is found with FindSongAudio.
+func SongAudioKey(youtubeID string) string {
+	return SongAudioKeyFor(youtubeID, AudioFormatMP3)
+}
+
 type S3Service struct {
 	client     *s3.Client
 	bucketName string
@@ -101,3 +111,19 @@ func (s *S3Service) FileExists(ctx context.Context, key string) (bool, error) {
 	}
 	return true, nil
 }
+
+// FileSize returns the size of the object in bytes and whether it exists
+func (s *S3Service) FileSize(ctx context.Context, key string) (int64, bool, error) {
+	output, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var responseError *awshttp.ResponseError
+		if errors.As(err, &responseError) && responseError.ResponseError.HTTPStatusCode() == http.StatusNotFound {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return aws.ToInt64(output.ContentLength), true, nil
+}
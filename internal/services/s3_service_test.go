@@ -0,0 +1,16 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSongAudioKey(t *testing.T) {
+	key := SongAudioKey("abc123")
+	if key != "songs/abc123.mp3" {
+		t.Errorf("Expected songs/abc123.mp3, got %s", key)
+	}
+	if !strings.HasPrefix(key, SongAudioPrefix) {
+		t.Errorf("Expected key %s to start with %s", key, SongAudioPrefix)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+const (
+	// DefaultRecentSongsLimit is used when no limit is requested
+	DefaultRecentSongsLimit = 20
+	// MaxRecentSongsLimit caps how many recently added songs can be requested at once
+	MaxRecentSongsLimit = 100
+)
+
+// SongService provides read access to the song library
+type SongService struct {
+	songRepo SongRepositoryInterface
+}
+
+func NewSongService(songRepo SongRepositoryInterface) *SongService {
+	return &SongService{
+		songRepo: songRepo,
+	}
+}
+
+// GetRecentlyAdded returns the newest songs in the library, newest first.
+// Non-positive limits fall back to the default and large limits are clamped.
+func (s *SongService) GetRecentlyAdded(limit int) ([]*models.Song, error) {
+	if limit <= 0 {
+		limit = DefaultRecentSongsLimit
+	}
+	if limit > MaxRecentSongsLimit {
+		limit = MaxRecentSongsLimit
+	}
+
+	songs, err := s.songRepo.GetRecentlyAdded(limit)
+	if err != nil {
+		return nil, err
+	}
+	if songs == nil {
+		songs = []*models.Song{}
+	}
+	return songs, nil
+}
+
+// GetByYouTubeID returns the song with the given ID, or nil if it isn't in the library
+func (s *SongService) GetByYouTubeID(youtubeID string) (*models.Song, error) {
+	return s.songRepo.GetByYouTubeID(youtubeID)
+}
+
+// GetByTag returns the songs carrying tag, newest first
+func (s *SongService) GetByTag(tag string) ([]*models.Song, error) {
+	normalized := models.NormalizeTags([]string{tag})
+	if len(normalized) == 0 {
+		return []*models.Song{}, nil
+	}
+
+	songs, err := s.songRepo.GetByTag(normalized[0])
+	if err != nil {
+		return nil, err
+	}
+	if songs == nil {
+		songs = []*models.Song{}
+	}
+	return songs, nil
+}
+
+// SetTags replaces a song's tags and returns the updated song
+func (s *SongService) SetTags(youtubeID string, tags []string) (*models.Song, error) {
+	song, err := s.songRepo.GetByYouTubeID(youtubeID)
+	if err != nil {
+		return nil, err
+	}
+	if song == nil {
+		return nil, ErrSongNotFound
+	}
+
+	normalized := models.NormalizeTags(tags)
+	if err := s.songRepo.UpdateTags(youtubeID, normalized); err != nil {
+		return nil, err
+	}
+	song.Tags = normalized
+	return song, nil
+}
+
+// GetArtists returns the library's artists with their song counts, sorted by
+// name with songs lacking an artist grouped under "Unknown" at the end
+func (s *SongService) GetArtists() ([]*models.ArtistSummary, error) {
+	artists, err := s.songRepo.GetArtists()
+	if err != nil {
+		return nil, err
+	}
+	if artists == nil {
+		artists = []*models.ArtistSummary{}
+	}
+	sort.SliceStable(artists, func(i, j int) bool {
+		return libraryNameLess(artists[i].Name, artists[j].Name)
+	})
+	return artists, nil
+}
+
+// GetAlbums returns the library's albums with their song counts, sorted by
+// name then artist, with "Unknown" albums at the end
+func (s *SongService) GetAlbums() ([]*models.AlbumSummary, error) {
+	albums, err := s.songRepo.GetAlbums()
+	if err != nil {
+		return nil, err
+	}
+	if albums == nil {
+		albums = []*models.AlbumSummary{}
+	}
+	sort.SliceStable(albums, func(i, j int) bool {
+		if albums[i].Name != albums[j].Name {
+			return libraryNameLess(albums[i].Name, albums[j].Name)
+		}
+		return libraryNameLess(albums[i].Artist, albums[j].Artist)
+	})
+	return albums, nil
+}
+
+// GetByArtist returns an artist's songs. Blank and "unknown" names return the
+// songs without an artist.
+func (s *SongService) GetByArtist(artist string) ([]*models.Song, error) {
+	songs, err := s.songRepo.GetByArtist(models.LibraryName(artist))
+	if err != nil {
+		return nil, err
+	}
+	if songs == nil {
+		songs = []*models.Song{}
+	}
+	return songs, nil
+}
+
+// libraryNameLess orders names case-insensitively with UnknownName last
+func libraryNameLess(a, b string) bool {
+	if (a == models.UnknownName) != (b == models.UnknownName) {
+		return b == models.UnknownName
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
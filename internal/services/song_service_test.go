@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGetRecentlyAdded(t *testing.T) {
+	songRepo := NewMockSongRepository()
+	service := NewSongService(songRepo)
+
+	// Empty library returns an empty, non-nil slice
+	songs, err := service.GetRecentlyAdded(10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if songs == nil || len(songs) != 0 {
+		t.Errorf("Expected empty slice for empty library, got %v", songs)
+	}
+
+	base := time.Now()
+	for i := 0; i < 3; i++ {
+		song := createTestSong(fmt.Sprintf("song%d", i), "Song", "Artist", 180)
+		song.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		songRepo.Create(song)
+	}
+
+	songs, err = service.GetRecentlyAdded(2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(songs) != 2 {
+		t.Fatalf("Expected 2 songs, got %d", len(songs))
+	}
+	if songs[0].YouTubeID != "song2" || songs[1].YouTubeID != "song1" {
+		t.Errorf("Expected newest songs first, got %s, %s", songs[0].YouTubeID, songs[1].YouTubeID)
+	}
+}
+
+func TestGetRecentlyAddedLimitClamp(t *testing.T) {
+	tests := []struct {
+		requested int
+		expected  int
+	}{
+		{requested: 0, expected: DefaultRecentSongsLimit},
+		{requested: -5, expected: DefaultRecentSongsLimit},
+		{requested: 50, expected: 50},
+		{requested: 1000, expected: MaxRecentSongsLimit},
+	}
+
+	for _, tt := range tests {
+		songRepo := NewMockSongRepository()
+		service := NewSongService(songRepo)
+
+		if _, err := service.GetRecentlyAdded(tt.requested); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if songRepo.recentLimit != tt.expected {
+			t.Errorf("Requested %d: expected repository limit %d, got %d", tt.requested, tt.expected, songRepo.recentLimit)
+		}
+	}
+}
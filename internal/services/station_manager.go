@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultStationID names the station served by the unscoped /api/v1 routes and /ws
+const DefaultStationID = "default"
+
+// ErrStationExists is returned when adding a station whose ID is taken
+var ErrStationExists = errors.New("station already exists")
+
+// stationIDPattern keeps station IDs safe to use as a path segment
+var stationIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Station is one independent radio stream with its own queue, event bus and
+// websocket clients
+type Station struct {
+	ID     string
+	Radio  *RadioService
+	Events EventBusInterface
+	// Socket serves the station's websocket endpoint
+	Socket http.Handler
+}
+
+// StationSpec is a station requested through the STATIONS setting
+type StationSpec struct {
+	ID       string
+	Playlist string
+}
+
+// ParseStations parses a station list such as "lofi=Lofi Beats,rock=Rock"
+// into station IDs and the playlist each one plays
+func ParseStations(spec string) ([]StationSpec, error) {
+	var stations []StationSpec
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, playlist, ok := strings.Cut(part, "=")
+		id = strings.TrimSpace(id)
+		playlist = strings.TrimSpace(playlist)
+		if !ok || playlist == "" {
+			return nil, fmt.Errorf("invalid station %q: expected id=playlist", part)
+		}
+		if !stationIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid station %q: id must be lowercase letters, digits, - or _", part)
+		}
+		if id == DefaultStationID || seen[id] {
+			return nil, fmt.Errorf("invalid station %q: %w", part, ErrStationExists)
+		}
+		seen[id] = true
+
+		stations = append(stations, StationSpec{ID: id, Playlist: playlist})
+	}
+	return stations, nil
+}
+
+// StationManager holds the stations a server runs, keyed by ID
+type StationManager struct {
+	mu       sync.RWMutex
+	stations map[string]*Station
+}
+
+func NewStationManager() *StationManager {
+	return &StationManager{
+		stations: make(map[string]*Station),
+	}
+}
+
+// Add registers a station under its ID
+func (m *StationManager) Add(station *Station) error {
+	if !stationIDPattern.MatchString(station.ID) {
+		return fmt.Errorf("invalid station id %q", station.ID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.stations[station.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrStationExists, station.ID)
+	}
+	m.stations[station.ID] = station
+	return nil
+}
+
+// Get returns the station with the given ID, or nil if there isn't one
+func (m *StationManager) Get(id string) *Station {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stations[id]
+}
+
+// List returns every station ordered by ID
+func (m *StationManager) List() []*Station {
+	m.mu.RLock()
+	stations := make([]*Station, 0, len(m.stations))
+	for _, station := range m.stations {
+		stations = append(stations, station)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].ID < stations[j].ID
+	})
+	return stations
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseStations(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []StationSpec
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: nil},
+		{
+			name: "multiple stations",
+			spec: "lofi=Lofi Beats, rock = Rock",
+			want: []StationSpec{{ID: "lofi", Playlist: "Lofi Beats"}, {ID: "rock", Playlist: "Rock"}},
+		},
+		{name: "missing playlist", spec: "lofi=", wantErr: true},
+		{name: "missing separator", spec: "lofi", wantErr: true},
+		{name: "unsafe id", spec: "Lo Fi=Lofi", wantErr: true},
+		{name: "duplicate id", spec: "lofi=A,lofi=B", wantErr: true},
+		{name: "reserved default id", spec: "default=A", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStations(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error for %q, got %+v", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStationManager(t *testing.T) {
+	manager := NewStationManager()
+
+	for _, id := range []string{"rock", DefaultStationID, "lofi"} {
+		if err := manager.Add(&Station{ID: id}); err != nil {
+			t.Fatalf("Failed to add station %s: %v", id, err)
+		}
+	}
+
+	if err := manager.Add(&Station{ID: "rock"}); !errors.Is(err, ErrStationExists) {
+		t.Errorf("Expected ErrStationExists for a duplicate, got %v", err)
+	}
+	if err := manager.Add(&Station{ID: "../etc"}); err == nil {
+		t.Error("Expected an error for an unsafe station id")
+	}
+
+	if station := manager.Get("lofi"); station == nil || station.ID != "lofi" {
+		t.Errorf("Expected to get the lofi station, got %+v", station)
+	}
+	if station := manager.Get("jazz"); station != nil {
+		t.Errorf("Expected no station for an unknown id, got %+v", station)
+	}
+
+	var ids []string
+	for _, station := range manager.List() {
+		ids = append(ids, station.ID)
+	}
+	if want := []string{DefaultStationID, "lofi", "rock"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected stations %v, got %v", want, ids)
+	}
+}
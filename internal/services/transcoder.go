@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Audio quality levels accepted by the song file endpoint
+const (
+	QualityLow    = "low"
+	QualityMedium = "medium"
+	QualityHigh   = "high"
+)
+
+// qualityBitrates maps a quality level to the ffmpeg audio bitrate
+var qualityBitrates = map[string]string{
+	QualityLow:    "64k",
+	QualityMedium: "128k",
+	QualityHigh:   "192k",
+}
+
+// IsValidQuality reports whether quality is a supported quality level
+func IsValidQuality(quality string) bool {
+	_, ok := qualityBitrates[quality]
+	return ok
+}
+
+// TranscodedKey returns the storage key used to cache a transcoded variant of a song
+func TranscodedKey(youtubeID, quality string) string {
+	return fmt.Sprintf("transcoded/%s/%s.mp3", quality, youtubeID)
+}
+
+// Transcoder re-encodes audio at a lower bitrate
+type Transcoder interface {
+	Available() bool
+	Transcode(ctx context.Context, input io.Reader, output io.Writer, quality string) error
+}
+
+// FFmpegTranscoder transcodes audio by piping it through the ffmpeg binary
+type FFmpegTranscoder struct {
+	binary string
+}
+
+func NewFFmpegTranscoder() *FFmpegTranscoder {
+	return &FFmpegTranscoder{binary: "ffmpeg"}
+}
+
+// Available reports whether the ffmpeg binary can be found in PATH
+func (t *FFmpegTranscoder) Available() bool {
+	_, err := exec.LookPath(t.binary)
+	return err == nil
+}
+
+// Transcode reads MP3 audio from input and writes it to output at the bitrate for quality
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, input io.Reader, output io.Writer, quality string) error {
+	bitrate, ok := qualityBitrates[quality]
+	if !ok {
+		return fmt.Errorf("unsupported quality: %s", quality)
+	}
+
+	cmd := exec.CommandContext(ctx, t.binary,
+		"-i", "pipe:0",
+		"-vn",
+		"-b:a", bitrate,
+		"-f", "mp3",
+		"pipe:1",
+	)
+	cmd.Stdin = input
+	cmd.Stdout = output
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg transcode failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// videoInfoTimeout bounds each per-video metadata lookup made by the fallback
+const videoInfoTimeout = 30 * time.Second
+
+// VideoInfoFetcher looks up a single video's metadata
+type VideoInfoFetcher interface {
+	GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error)
+}
+
+// videoDetails is the metadata needed to add a video to the library
+type videoDetails struct {
+	ID       string
+	Title    string
+	Artist   string
+	Duration time.Duration
+	// Live is set for live and upcoming streams
+	Live bool
+}
+
+// song returns a new library song for the video
+func (v videoDetails) song() *models.Song {
+	artist := v.Artist
+	if artist == "" {
+		artist = "Unknown"
+	}
+	return &models.Song{
+		YouTubeID: v.ID,
+		Title:     v.Title,
+		Artist:    artist,
+		Album:     "Unknown",
+		Duration:  int(v.Duration.Seconds()),
+		S3Key:     SongAudioKey(v.ID),
+	}
+}
+
+// lookupVideos fetches metadata for videoIDs from the YouTube API, falling
+// back to the video info fetcher when there is no API key or the quota is
+// exhausted. Videos that can't be found are left out.
+func (s *PlaylistService) lookupVideos(videoIDs []string) ([]videoDetails, error) {
+	if s.youtubeSvc != nil && s.youtubeSvc.HasAPIKey() {
+		videos, err := s.youtubeSvc.getVideoDetails(videoIDs)
+		if err == nil {
+			return videos, nil
+		}
+		if s.videoInfo == nil || !errors.Is(err, ErrYouTubeQuotaExceeded) {
+			return nil, err
+		}
+		log.Printf("[WARN] lookupVideos: %v, falling back to the downloader for metadata", err)
+	}
+
+	if s.videoInfo == nil {
+		return nil, fmt.Errorf("no YouTube API key or downloader to look up videos")
+	}
+	return s.lookupVideosWithInfo(videoIDs), nil
+}
+
+// lookupVideosWithInfo fetches metadata one video at a time
+func (s *PlaylistService) lookupVideosWithInfo(videoIDs []string) []videoDetails {
+	videos := make([]videoDetails, 0, len(videoIDs))
+	for _, id := range videoIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), videoInfoTimeout)
+		info, err := s.videoInfo.GetVideoInfo(ctx, id)
+		cancel()
+		if err != nil {
+			log.Printf("[ERROR] lookupVideos: Failed to get info for %s: %v", id, err)
+			continue
+		}
+
+		videos = append(videos, videoDetails{
+			ID:       id,
+			Title:    info.Title,
+			Artist:   strings.TrimSuffix(info.Uploader, " - Topic"),
+			Duration: time.Duration(info.Duration) * time.Second,
+			Live:     info.IsLive,
+		})
+	}
+	return videos
+}
+
+// getVideoDetails fetches titles and durations for up to 50 videos
+func (s *YouTubeService) getVideoDetails(videoIDs []string) ([]videoDetails, error) {
+	detailsURL := fmt.Sprintf(
+		"https://www.googleapis.com/youtube/v3/videos?part=snippet,contentDetails&id=%s&key=%s",
+		url.QueryEscape(strings.Join(videoIDs, ",")),
+		s.apiKey,
+	)
+
+	resp, err := s.httpClient.Get(detailsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiStatusError(resp)
+	}
+
+	var videoResp struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title                string `json:"title"`
+				LiveBroadcastContent string `json:"liveBroadcastContent"`
+			} `json:"snippet"`
+			ContentDetails struct {
+				Duration string `json:"duration"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&videoResp); err != nil {
+		return nil, fmt.Errorf("failed to decode video details: %w", err)
+	}
+
+	videos := make([]videoDetails, len(videoResp.Items))
+	for i, item := range videoResp.Items {
+		videos[i] = videoDetails{
+			ID:       item.ID,
+			Title:    item.Snippet.Title,
+			Duration: parseDuration(item.ContentDetails.Duration),
+			Live:     item.Snippet.LiveBroadcastContent == "live" || item.Snippet.LiveBroadcastContent == "upcoming",
+		}
+	}
+	return videos, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakeVideoInfo serves video metadata from a map
+type fakeVideoInfo struct {
+	videos map[string]*VideoInfo
+	calls  int
+}
+
+func (f *fakeVideoInfo) GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	f.calls++
+	info, ok := f.videos[videoID]
+	if !ok {
+		return nil, errors.New("video unavailable")
+	}
+	return info, nil
+}
+
+// roundTripFunc answers HTTP requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newFakeVideoInfo() *fakeVideoInfo {
+	return &fakeVideoInfo{videos: map[string]*VideoInfo{
+		"dQw4w9WgXcQ": {ID: "dQw4w9WgXcQ", Title: "Never Gonna Give You Up", Uploader: "Rick Astley - Topic", Duration: 213},
+		"9bZkp7q19f0": {ID: "9bZkp7q19f0", Title: "Gangnam Style", Uploader: "officialpsy", Duration: 252},
+	}}
+}
+
+func TestLookupVideosWithoutAPIKeyUsesFallback(t *testing.T) {
+	info := newFakeVideoInfo()
+	service := &PlaylistService{youtubeSvc: NewYouTubeServiceWithoutKey()}
+	service.SetVideoInfoFallback(info)
+
+	videos, err := service.lookupVideos([]string{"dQw4w9WgXcQ", "missing0000", "9bZkp7q19f0"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(videos) != 2 {
+		t.Fatalf("Expected 2 videos with the unavailable one left out, got %+v", videos)
+	}
+
+	song := videos[0].song()
+	if song.YouTubeID != "dQw4w9WgXcQ" || song.Title != "Never Gonna Give You Up" || song.Artist != "Rick Astley" {
+		t.Errorf("Unexpected song details %+v", song)
+	}
+	if song.Duration != 213 || song.S3Key != SongAudioKey("dQw4w9WgXcQ") {
+		t.Errorf("Expected a playable song, got duration %d and key %q", song.Duration, song.S3Key)
+	}
+	if got := videos[1].song().Artist; got != "officialpsy" {
+		t.Errorf("Expected the uploader as artist, got %q", got)
+	}
+}
+
+func TestLookupVideosFallsBackOnQuotaError(t *testing.T) {
+	quotaExceeded := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"error":{"code":403,"errors":[{"reason":"quotaExceeded"}]}}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+	youtubeSvc := &YouTubeService{apiKey: "key", httpClient: &http.Client{Transport: quotaExceeded}}
+
+	info := newFakeVideoInfo()
+	service := &PlaylistService{youtubeSvc: youtubeSvc}
+	service.SetVideoInfoFallback(info)
+
+	videos, err := service.lookupVideos([]string{"dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(videos) != 1 || videos[0].Duration.Seconds() != 213 {
+		t.Errorf("Expected the fallback's details, got %+v", videos)
+	}
+
+	// Without a fallback the quota error is reported
+	service.videoInfo = nil
+	if _, err := service.lookupVideos([]string{"dQw4w9WgXcQ"}); !errors.Is(err, ErrYouTubeQuotaExceeded) {
+		t.Errorf("Expected ErrYouTubeQuotaExceeded, got %v", err)
+	}
+}
+
+func TestLookupVideosOtherAPIErrorsDontFallBack(t *testing.T) {
+	serverError := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Header:     make(http.Header),
+		}, nil
+	})
+	youtubeSvc := &YouTubeService{apiKey: "key", httpClient: &http.Client{Transport: serverError}}
+
+	info := newFakeVideoInfo()
+	service := &PlaylistService{youtubeSvc: youtubeSvc}
+	service.SetVideoInfoFallback(info)
+
+	if _, err := service.lookupVideos([]string{"dQw4w9WgXcQ"}); err == nil {
+		t.Error("Expected the API error to be returned")
+	}
+	if info.calls != 0 {
+		t.Errorf("Expected no fallback lookups, got %d", info.calls)
+	}
+}
+
+func TestLookupVideosWithoutAPIKeyOrFallback(t *testing.T) {
+	service := &PlaylistService{youtubeSvc: NewYouTubeServiceWithoutKey()}
+	if _, err := service.lookupVideos([]string{"dQw4w9WgXcQ"}); err == nil {
+		t.Error("Expected an error with no way to look up videos")
+	}
+}
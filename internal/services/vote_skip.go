@@ -0,0 +1,99 @@
+package services
+
+import "errors"
+
+// DefaultVoteSkipPercent is the share of listeners whose votes must be
+// exceeded to skip a song
+const DefaultVoteSkipPercent = 50
+
+// ErrVoterRequired is returned when a skip vote doesn't say who cast it
+var ErrVoterRequired = errors.New("a user ID is required to vote")
+
+// ListenerCounter reports how many listeners are connected, e.g. the
+// websocket handler
+type ListenerCounter interface {
+	ListenerCount() int
+}
+
+// VoteStatus is the progress of the vote to skip the current song
+type VoteStatus struct {
+	Votes    int  `json:"votes"`
+	Required int  `json:"required"`
+	Skipped  bool `json:"skipped"`
+}
+
+// skipVotes are the votes to skip the song at index in the queue. A vote set
+// for any other song is stale and starts over.
+type skipVotes struct {
+	index  int
+	songID string
+	voters map[string]bool
+}
+
+// SetListenerCounter sets where the listener count used to size skip votes
+// comes from. Without one a single vote skips.
+func (s *RadioService) SetListenerCounter(listeners ListenerCounter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = listeners
+}
+
+// SetVoteSkipPercent sets the share of listeners, 0–100, whose votes must be
+// exceeded to skip a song
+func (s *RadioService) SetVoteSkipPercent(percent int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voteSkipPercent = min(max(percent, 0), 100)
+}
+
+// VoteSkip records userID's vote to skip the current song, counting each
+// listener once, and skips it once the votes exceed the configured share of
+// listeners
+func (s *RadioService) VoteSkip(userID string) (VoteStatus, error) {
+	if userID == "" {
+		return VoteStatus{}, ErrVoterRequired
+	}
+
+	// Count listeners before locking, the counter has its own lock
+	listeners := 0
+	s.mu.RLock()
+	counter := s.listeners
+	s.mu.RUnlock()
+	if counter != nil {
+		listeners = counter.ListenerCount()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped || s.state == nil || s.state.CurrentSongIndex < 0 || s.state.CurrentSongIndex >= len(s.state.Queue) {
+		return VoteStatus{}, ErrNothingPlaying
+	}
+	index := s.state.CurrentSongIndex
+	song := s.state.Queue[index]
+
+	if s.skipVotes.voters == nil || s.skipVotes.index != index || s.skipVotes.songID != song.YouTubeID {
+		s.skipVotes = skipVotes{index: index, songID: song.YouTubeID, voters: make(map[string]bool)}
+	}
+	s.skipVotes.voters[userID] = true
+
+	status := VoteStatus{
+		Votes:    len(s.skipVotes.voters),
+		Required: requiredSkipVotes(listeners, s.voteSkipPercent),
+	}
+	if s.eventBus != nil {
+		s.eventBus.PublishVoteUpdate(song, status.Votes, status.Required)
+	}
+
+	if status.Votes >= status.Required {
+		status.Skipped = true
+		s.skipToNext()
+	}
+	return status, nil
+}
+
+// requiredSkipVotes is the fewest votes exceeding percent of listeners. It is
+// never more than every listener or less than one vote.
+func requiredSkipVotes(listeners, percent int) int {
+	return max(min(listeners*percent/100+1, listeners), 1)
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+// fixedListeners reports a set listener count
+type fixedListeners int
+
+func (n fixedListeners) ListenerCount() int {
+	return int(n)
+}
+
+func newVotingRadio(listeners int) (*RadioService, *recordingEventBus) {
+	eventBus := &recordingEventBus{}
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), newMemoryStorage(), eventBus)
+	service.SetListenerCounter(fixedListeners(listeners))
+	service.state.Queue = []*models.Song{
+		createTestSong("song1", "Song 1", "Artist", 180),
+		createTestSong("song2", "Song 2", "Artist", 180),
+		createTestSong("song3", "Song 3", "Artist", 180),
+	}
+	return service, eventBus
+}
+
+func TestVoteSkip(t *testing.T) {
+	service, eventBus := newVotingRadio(4)
+
+	// Votes must exceed half of the 4 listeners, and repeat votes count once
+	for _, user := range []string{"alice", "bob", "alice"} {
+		status, err := service.VoteSkip(user)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if status.Skipped {
+			t.Fatalf("Expected no skip after %s voted, got %+v", user, status)
+		}
+	}
+	status, err := service.VoteSkip("carol")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := (VoteStatus{Votes: 3, Required: 3, Skipped: true}); status != want {
+		t.Errorf("Expected %+v, got %+v", want, status)
+	}
+	if got := service.GetCurrentSong().YouTubeID; got != "song2" {
+		t.Errorf("Expected the vote to skip to song2, playing %s", got)
+	}
+
+	want := []VoteStatus{{Votes: 1, Required: 3}, {Votes: 2, Required: 3}, {Votes: 2, Required: 3}, {Votes: 3, Required: 3}}
+	if got := eventBus.votes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected vote updates %+v, got %+v", want, got)
+	}
+
+	// The new song starts with no votes
+	if status, _ := service.VoteSkip("alice"); status.Votes != 1 {
+		t.Errorf("Expected votes to reset for the next song, got %+v", status)
+	}
+}
+
+func TestVoteSkipResetsWhenSongChanges(t *testing.T) {
+	service, _ := newVotingRadio(10)
+
+	service.VoteSkip("alice")
+	service.VoteSkip("bob")
+	service.Next()
+	if status, _ := service.VoteSkip("alice"); status.Votes != 1 {
+		t.Errorf("Expected an admin skip to reset votes, got %+v", status)
+	}
+
+	service.VoteSkip("bob")
+	service.Previous()
+	if status, _ := service.VoteSkip("alice"); status.Votes != 1 {
+		t.Errorf("Expected going back to reset votes, got %+v", status)
+	}
+}
+
+func TestVoteSkipErrors(t *testing.T) {
+	service := NewRadioService(NewMockSongRepository(), NewMockPlaylistRepository(), newMemoryStorage(), nil)
+	if _, err := service.VoteSkip("alice"); !errors.Is(err, ErrNothingPlaying) {
+		t.Errorf("Expected ErrNothingPlaying with an empty queue, got %v", err)
+	}
+	if _, err := service.VoteSkip(""); !errors.Is(err, ErrVoterRequired) {
+		t.Errorf("Expected ErrVoterRequired, got %v", err)
+	}
+}
+
+func TestRequiredSkipVotes(t *testing.T) {
+	tests := []struct {
+		listeners, percent, want int
+	}{
+		{0, 50, 1},
+		{1, 50, 1},
+		{2, 50, 2},
+		{3, 50, 2},
+		{4, 50, 3},
+		{10, 0, 1},
+		{10, 100, 10},
+	}
+	for _, tt := range tests {
+		if got := requiredSkipVotes(tt.listeners, tt.percent); got != tt.want {
+			t.Errorf("requiredSkipVotes(%d, %d) = %d, want %d", tt.listeners, tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidYouTubeID is returned for input that holds no usable video ID
+var ErrInvalidYouTubeID = errors.New("not a YouTube URL or video ID")
+
+// youtubeIDPattern matches the 11 character IDs YouTube gives videos
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// youtubePathPrefixes are URL paths that end in a video ID on youtube.com
+var youtubePathPrefixes = []string{"/embed/", "/shorts/", "/live/", "/v/"}
+
+// ParseYouTubeID extracts the video ID from a bare ID or any common YouTube
+// URL form: watch?v=, youtu.be/, embed/, shorts/ and live/ links, with or
+// without a scheme and with extra parameters such as &list= or ?t=
+func ParseYouTubeID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if youtubeIDPattern.MatchString(input) {
+		return input, nil
+	}
+
+	raw := input
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidYouTubeID, input)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	var id string
+	switch host {
+	case "youtu.be":
+		id = strings.Trim(parsed.Path, "/")
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		if parsed.Path == "/watch" {
+			id = parsed.Query().Get("v")
+			break
+		}
+		for _, prefix := range youtubePathPrefixes {
+			if rest, ok := strings.CutPrefix(parsed.Path, prefix); ok {
+				id = strings.TrimSuffix(rest, "/")
+				break
+			}
+		}
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidYouTubeID, input)
+	}
+
+	if !youtubeIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%w: no valid video ID in %q", ErrInvalidYouTubeID, input)
+	}
+	return id, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseYouTubeID(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare id", input: "dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "bare id with whitespace", input: "  dQw4w9WgXcQ\n", want: "dQw4w9WgXcQ"},
+		{name: "id with dash and underscore", input: "a-b_c-d_e-f", want: "a-b_c-d_e-f"},
+		{name: "watch url", input: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "watch url without www", input: "https://youtube.com/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "watch url without scheme", input: "www.youtube.com/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "http watch url", input: "http://www.youtube.com/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "watch url with playlist", input: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI&index=3", want: "dQw4w9WgXcQ"},
+		{name: "watch url with timestamp", input: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42s", want: "dQw4w9WgXcQ"},
+		{name: "watch url with v after other params", input: "https://www.youtube.com/watch?feature=share&v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "mobile url", input: "https://m.youtube.com/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "music url", input: "https://music.youtube.com/watch?v=dQw4w9WgXcQ&si=abc", want: "dQw4w9WgXcQ"},
+		{name: "short link", input: "https://youtu.be/dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "short link with timestamp", input: "https://youtu.be/dQw4w9WgXcQ?t=42", want: "dQw4w9WgXcQ"},
+		{name: "short link without scheme", input: "youtu.be/dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "embed url", input: "https://www.youtube.com/embed/dQw4w9WgXcQ?autoplay=1", want: "dQw4w9WgXcQ"},
+		{name: "privacy embed url", input: "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "shorts url", input: "https://www.youtube.com/shorts/dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "live url", input: "https://www.youtube.com/live/dQw4w9WgXcQ?feature=share", want: "dQw4w9WgXcQ"},
+		{name: "uppercase host", input: "https://WWW.YOUTUBE.COM/watch?v=dQw4w9WgXcQ", want: "dQw4w9WgXcQ"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "too short id", input: "dQw4w9WgXc", wantErr: true},
+		{name: "too long id", input: "dQw4w9WgXcQQ", wantErr: true},
+		{name: "id with invalid character", input: "dQw4w9WgXc!", wantErr: true},
+		{name: "plain text", input: "never gonna give you up", wantErr: true},
+		{name: "other host", input: "https://vimeo.com/watch?v=dQw4w9WgXcQ", wantErr: true},
+		{name: "lookalike host", input: "https://youtube.com.evil.example/watch?v=dQw4w9WgXcQ", wantErr: true},
+		{name: "watch url without id", input: "https://www.youtube.com/watch?list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI", wantErr: true},
+		{name: "watch url with bad id", input: "https://www.youtube.com/watch?v=short", wantErr: true},
+		{name: "channel url", input: "https://www.youtube.com/@somechannel", wantErr: true},
+		{name: "short link with extra path", input: "https://youtu.be/dQw4w9WgXcQ/extra", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYouTubeID(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidYouTubeID) {
+					t.Errorf("Expected ErrInvalidYouTubeID for %q, got %q, %v", tt.input, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q for %q, got %q", tt.want, tt.input, got)
+			}
+		})
+	}
+}
@@ -2,13 +2,19 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
+// ErrYouTubeQuotaExceeded is returned when the YouTube API refuses requests
+// because the key's daily quota is used up
+var ErrYouTubeQuotaExceeded = errors.New("YouTube API quota exceeded")
+
 type YouTubeService struct {
 	apiKey     string
 	httpClient *http.Client
@@ -33,6 +39,7 @@ type YouTubeSearchResponse struct {
 
 type YouTubeVideoResponse struct {
 	Items []struct {
+		ID             string `json:"id"`
 		ContentDetails struct {
 			Duration string `json:"duration"`
 		} `json:"contentDetails"`
@@ -61,6 +68,67 @@ func NewYouTubeService() (*YouTubeService, error) {
 	}, nil
 }
 
+// NewYouTubeServiceWithoutKey creates a service for running without an API
+// key. API calls fail, but imports can still fall back to the downloader.
+func NewYouTubeServiceWithoutKey() *YouTubeService {
+	return &YouTubeService{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// HasAPIKey reports whether the service can call the YouTube API
+func (s *YouTubeService) HasAPIKey() bool {
+	return s.apiKey != ""
+}
+
+// apiStatusError describes a non-200 API response, wrapping
+// ErrYouTubeQuotaExceeded when the quota is the reason
+func apiStatusError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if resp.StatusCode == http.StatusForbidden && json.NewDecoder(resp.Body).Decode(&body) == nil {
+		for _, e := range body.Error.Errors {
+			if e.Reason == "quotaExceeded" || e.Reason == "dailyLimitExceeded" {
+				return ErrYouTubeQuotaExceeded
+			}
+		}
+	}
+	return fmt.Errorf("YouTube API returned non-200 status code: %d", resp.StatusCode)
+}
+
+// ParseProxyURL validates a proxy URL for use with the YouTube API client and yt-dlp
+func ParseProxyURL(raw string) (*url.URL, error) {
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https, socks5 or socks5h", raw)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+
+	return proxyURL, nil
+}
+
+// SetProxy routes all YouTube API requests through the given proxy
+func (s *YouTubeService) SetProxy(proxyURL *url.URL) {
+	s.httpClient.Transport = &http.Transport{
+		Proxy: http.ProxyURL(proxyURL),
+	}
+}
+
 func (s *YouTubeService) SearchVideos(query string) ([]SearchResult, error) {
 	// First, search for videos
 	searchURL := fmt.Sprintf(
@@ -160,3 +228,41 @@ func (s *YouTubeService) getVideoDurations(videoIDs []string) (map[string]string
 
 	return durations, nil
 }
+
+// GetVideoDurations looks up the durations of up to 50 videos. Videos that
+// don't exist or whose duration can't be parsed are left out of the result.
+func (s *YouTubeService) GetVideoDurations(videoIDs []string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+	if len(videoIDs) == 0 {
+		return durations, nil
+	}
+
+	detailsURL := fmt.Sprintf(
+		"https://www.googleapis.com/youtube/v3/videos?part=contentDetails&id=%s&key=%s",
+		url.QueryEscape(strings.Join(videoIDs, ",")),
+		s.apiKey,
+	)
+
+	resp, err := s.httpClient.Get(detailsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("YouTube API returned non-200 status code: %d", resp.StatusCode)
+	}
+
+	var detailsResp YouTubeVideoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&detailsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode video details response: %w", err)
+	}
+
+	for _, item := range detailsResp.Items {
+		if duration := parseDuration(item.ContentDetails.Duration); duration > 0 {
+			durations[item.ID] = duration
+		}
+	}
+
+	return durations, nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/models"
+)
+
+const (
+	defaultYtDlpDownloadTimeout = 5 * time.Minute
+	defaultYtDlpInfoTimeout     = 30 * time.Second
+	ytDlpWaitDelay              = time.Second
+)
+
+// YtDlpServiceInterface is the yt-dlp specific downloader
+type YtDlpServiceInterface interface {
+	Downloader
+}
+
+// YtDlpOptions holds optional settings passed to every yt-dlp invocation
+type YtDlpOptions struct {
+	// CookiesFile is a Netscape-format cookies file passed via --cookies
+	CookiesFile string
+	// CookiesFromBrowser names a browser to read cookies from via --cookies-from-browser
+	CookiesFromBrowser string
+	// Proxy is an HTTP or SOCKS proxy URL passed via --proxy
+	Proxy string
+	// DownloadTimeout and InfoTimeout bound audio downloads and metadata
+	// lookups; zero uses the defaults
+	DownloadTimeout time.Duration
+	InfoTimeout     time.Duration
+}
+
+// YtDlpService downloads audio by shelling out to yt-dlp or a CLI-compatible
+// fork such as youtube-dl
+type YtDlpService struct {
+	binary          string
+	options         YtDlpOptions
+	downloadTimeout time.Duration
+	infoTimeout     time.Duration
+}
+
+func NewYtDlpService() *YtDlpService {
+	return NewYtDlpServiceWithBinary("yt-dlp")
+}
+
+// NewYtDlpServiceWithBinary creates a service that runs the given executable
+func NewYtDlpServiceWithBinary(binary string) *YtDlpService {
+	return NewYtDlpServiceWithOptions(binary, YtDlpOptions{})
+}
+
+// NewYtDlpServiceWithOptions creates a service that runs the given executable
+// with the given options
+func NewYtDlpServiceWithOptions(binary string, options YtDlpOptions) *YtDlpService {
+	service := &YtDlpService{
+		binary:          binary,
+		options:         options,
+		downloadTimeout: defaultYtDlpDownloadTimeout,
+		infoTimeout:     defaultYtDlpInfoTimeout,
+	}
+	if options.DownloadTimeout > 0 {
+		service.downloadTimeout = options.DownloadTimeout
+	}
+	if options.InfoTimeout > 0 {
+		service.infoTimeout = options.InfoTimeout
+	}
+	return service
+}
+
+// DownloadAudio extracts the video's audio as mp3 into outputDir
+func (s *YtDlpService) DownloadAudio(ctx context.Context, videoID, outputDir string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.downloadTimeout)
+	defer cancel()
+
+	outputPath := filepath.Join(outputDir, videoID+".mp3")
+	args := []string{
+		"-x", // Extract audio
+		"--audio-format", "mp3",
+		"--audio-quality", "0", // Best quality
+		"-o", outputPath,
+		videoURL(videoID),
+	}
+
+	if _, err := s.run(ctx, args...); err != nil {
+		removePartialDownloads(outputDir, videoID)
+		return "", fmt.Errorf("failed to download %s: %w", videoID, err)
+	}
+
+	// yt-dlp may keep the source extension if conversion was skipped
+	if _, err := os.Stat(outputPath); err == nil {
+		return outputPath, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(outputDir, videoID+".*"))
+	if err != nil || len(matches) == 0 {
+		return "", fmt.Errorf("downloaded file for %s not found", videoID)
+	}
+	return matches[0], nil
+}
+
+// removePartialDownloads deletes the fragments a failed yt-dlp run leaves
+// behind (.part, .ytdl, half-converted audio) so they are never mistaken for
+// a finished download
+func removePartialDownloads(outputDir, videoID string) {
+	matches, err := filepath.Glob(filepath.Join(outputDir, videoID+".*"))
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] removePartialDownloads: Failed to remove %s: %v", match, err)
+		}
+	}
+}
+
+// GetVideoInfo reads the video's metadata without downloading it
+func (s *YtDlpService) GetVideoInfo(ctx context.Context, videoID string) (*VideoInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.infoTimeout)
+	defer cancel()
+
+	output, err := s.run(ctx, "--dump-json", "--no-download", videoURL(videoID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for %s: %w", videoID, err)
+	}
+
+	var raw struct {
+		ID         string  `json:"id"`
+		Title      string  `json:"title"`
+		Uploader   string  `json:"uploader"`
+		Duration   float64 `json:"duration"`
+		IsLive     bool    `json:"is_live"`
+		LiveStatus string  `json:"live_status"`
+		Chapters   []struct {
+			Title     string  `json:"title"`
+			StartTime float64 `json:"start_time"`
+			EndTime   float64 `json:"end_time"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse info for %s: %w", videoID, err)
+	}
+
+	info := &VideoInfo{
+		ID:       raw.ID,
+		Title:    raw.Title,
+		Uploader: raw.Uploader,
+		Duration: int(raw.Duration),
+		IsLive:   raw.IsLive || raw.LiveStatus == "is_live" || raw.LiveStatus == "is_upcoming",
+	}
+	for _, chapter := range raw.Chapters {
+		info.Chapters = append(info.Chapters, models.Chapter{
+			Title: chapter.Title,
+			Start: chapter.StartTime,
+			End:   chapter.EndTime,
+		})
+	}
+	return info, nil
+}
+
+// IsVideoAvailable reports whether the video can be fetched. Failures that
+// say nothing about the video itself, such as a missing binary or a network
+// error, are returned as errors, as is ErrAgeRestricted when the video needs
+// cookies that weren't supplied.
+func (s *YtDlpService) IsVideoAvailable(ctx context.Context, videoID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.infoTimeout)
+	defer cancel()
+
+	_, err := s.run(ctx, "--simulate", "--quiet", videoURL(videoID))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrVideoUnavailable) || errors.Is(err, ErrGeoBlocked) {
+		return false, nil
+	}
+	if errors.Is(err, ErrAgeRestricted) || errors.Is(err, ErrDownloadNetwork) {
+		return false, err
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return false, nil
+	}
+	return false, err
+}
+
+// Version returns the version string reported by the binary
+func (s *YtDlpService) Version(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.infoTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.binary, "--version")
+	cmd.WaitDelay = ytDlpWaitDelay
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s version: %w", s.binary, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsYtDlpVersionOlder reports whether a yt-dlp version such as "2024.08.06"
+// is older than minVersion. Versions are compared numerically part by part;
+// an empty minVersion never reports outdated.
+func IsYtDlpVersionOlder(version, minVersion string) bool {
+	if minVersion == "" {
+		return false
+	}
+
+	current := strings.Split(version, ".")
+	minimum := strings.Split(minVersion, ".")
+	for i := 0; i < len(current) || i < len(minimum); i++ {
+		var c, m int
+		if i < len(current) {
+			c, _ = strconv.Atoi(current[i])
+		}
+		if i < len(minimum) {
+			m, _ = strconv.Atoi(minimum[i])
+		}
+		if c != m {
+			return c < m
+		}
+	}
+	return false
+}
+
+// commonArgs returns the flags shared by every invocation
+func (s *YtDlpService) commonArgs() []string {
+	var args []string
+	if s.options.CookiesFile != "" {
+		args = append(args, "--cookies", s.options.CookiesFile)
+	}
+	if s.options.CookiesFromBrowser != "" {
+		args = append(args, "--cookies-from-browser", s.options.CookiesFromBrowser)
+	}
+	if s.options.Proxy != "" {
+		args = append(args, "--proxy", s.options.Proxy)
+	}
+	return args
+}
+
+// run executes the binary and returns its stdout. On failure the error
+// includes stderr so callers can see why the tool failed.
+func (s *YtDlpService) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.binary, append(s.commonArgs(), args...)...)
+	// Don't wait indefinitely on child processes (e.g. ffmpeg) still holding
+	// the output pipes after the binary has been killed
+	cmd.WaitDelay = ytDlpWaitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s timed out: %w", s.binary, ctx.Err())
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if cause := classifyDownloadError(msg); cause != nil {
+			return nil, fmt.Errorf("%w: %s", cause, msg)
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -1,13 +1,17 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/metrics"
 	"github.com/feline-dis/go-radio-v2/internal/models"
 	"github.com/gorilla/websocket"
 )
@@ -19,11 +23,23 @@ type RadioServiceInterface interface {
 	GetRemainingTime() time.Duration
 	GetQueueInfo() *models.QueueInfo
 	GetCurrentSong() *models.Song
+
+	// Playback controls for authenticated clients
+	Next()
+	Previous()
+	Pause()
+	Resume()
 }
 
 // EventBusInterface defines the methods we need from the event bus
 type EventBusInterface interface {
-	Subscribe(eventType string, handler events.EventHandler)
+	Subscribe(eventType string, handler events.EventHandler) events.SubscriptionID
+	Unsubscribe(eventType string, id events.SubscriptionID)
+}
+
+// orderedEventBus is an event bus that can deliver a subscriber's events in order
+type orderedEventBus interface {
+	Ordered() *events.OrderedSubscriber
 }
 
 var upgrader = websocket.Upgrader{
@@ -39,12 +55,58 @@ type Client struct {
 	send     chan []byte
 	radioSvc RadioServiceInterface
 	handler  *Handler
+
+	// Connection metadata used for diagnostics
+	remoteAddr    string
+	connectedAt   time.Time
+	authenticated bool
+	lastPong      atomic.Int64 // Unix nanoseconds, 0 until the first pong
+	unanswered    atomic.Int32 // Pings sent since the last pong
+	version       atomic.Int32 // Schema version agreed in hello, 0 until then
+
+	// sendMu guards send, which Run closes when it drops the client while
+	// the read pump may still be replying to messages
+	sendMu     sync.Mutex
+	sendClosed bool
 }
 
+// ClientInfo is a point-in-time snapshot of a connected client
+type ClientInfo struct {
+	RemoteAddr     string     `json:"remote_addr"`
+	ConnectedAt    time.Time  `json:"connected_at"`
+	Authenticated  bool       `json:"authenticated"`
+	LastPong       *time.Time `json:"last_pong"`
+	QueuedMessages int        `json:"queued_messages"`
+	MissedPongs    int        `json:"missed_pongs"`
+	Version        int        `json:"version,omitempty"`
+}
+
+// Authenticator reports whether a websocket upgrade request carries valid credentials
+type Authenticator func(r *http.Request) bool
+
+// ProtocolVersion is the message schema version the server speaks, sent as
+// "v" on every message. Bump it when a payload changes incompatibly.
+const ProtocolVersion = 1
+
+// MinProtocolVersion is the oldest schema version a client may declare in hello
+const MinProtocolVersion = 1
+
 type Message struct {
 	Type      string      `json:"type"`
 	Payload   interface{} `json:"payload"`
 	Timestamp int64       `json:"timestamp,omitempty"`
+	// Version is the schema version; zero is sent as ProtocolVersion
+	Version int `json:"v"`
+}
+
+// MarshalJSON stamps the schema version on messages that don't set one, so
+// every outbound message carries it
+func (m Message) MarshalJSON() ([]byte, error) {
+	type wireMessage Message
+	if m.Version == 0 {
+		m.Version = ProtocolVersion
+	}
+	return json.Marshal(wireMessage(m))
 }
 
 // FrontendMessage matches the format expected by the frontend WebSocket handler
@@ -54,13 +116,23 @@ type FrontendMessage struct {
 	Timestamp int64       `json:"timestamp,omitempty"`
 }
 
+// PlaybackUpdate is the playback_state payload. PositionMs is the exact
+// offset into the song at ServerTime, so a reconnecting client can resume
+// where playback is rather than restarting the song: it should seek to
+// PositionMs plus the time since ServerTime, measured against its own clock
+// corrected by the offset seen in pong timestamps, and while playing only
+// re-seek when its position drifts from that by more than a few hundred
+// milliseconds, to avoid audible jumps.
 type PlaybackUpdate struct {
-	Song      *models.Song `json:"song"`
-	Elapsed   float64      `json:"elapsed"`
-	Remaining float64      `json:"remaining"`
-	Paused    bool         `json:"paused"`
-	TotalTime float64      `json:"total_time"`
-	Timestamp int64        `json:"timestamp"` // Unix timestamp for sync
+	Song       *models.Song `json:"song"`
+	Elapsed    float64      `json:"elapsed"`
+	Remaining  float64      `json:"remaining"`
+	Paused     bool         `json:"paused"`
+	TotalTime  float64      `json:"total_time"`
+	Volume     float64      `json:"volume"`      // 0.0–1.0, the level every client should play at
+	Timestamp  int64        `json:"timestamp"`   // Unix timestamp for sync
+	ServerTime int64        `json:"server_time"` // Unix milliseconds PositionMs was measured at
+	PositionMs int64        `json:"position_ms"`
 }
 
 type SongChangeEvent struct {
@@ -103,6 +175,30 @@ type PlaylistChangeEvent struct {
 	Timestamp int64                 `json:"timestamp"`
 }
 
+type PlaybackStoppedEvent struct {
+	Playlist  *models.Playlist `json:"playlist"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+type PlaybackStartedEvent struct {
+	Playlist  *models.Playlist `json:"playlist"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+type AnnouncementEvent struct {
+	Song           *models.Song `json:"song"`
+	AnnouncementID string       `json:"announcement_id"`
+	Text           string       `json:"text"`
+	Timestamp      int64        `json:"timestamp"`
+}
+
+type VoteUpdateEvent struct {
+	Song      *models.Song `json:"song"`
+	Votes     int          `json:"votes"`
+	Required  int          `json:"required"`
+	Timestamp int64        `json:"timestamp"`
+}
+
 type QueueUpdate struct {
 	CurrentSong      *models.Song     `json:"current_song"`
 	NextSong         *models.Song     `json:"next_song"`
@@ -118,8 +214,33 @@ type UserReactionEvent struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+type ListenerCountEvent struct {
+	Count     int   `json:"count"`
+	Timestamp int64 `json:"timestamp"`
+}
+
 type ClientRequest struct {
 	Type string `json:"type"`
+	// Action is the playback control requested by "control" messages
+	Action string `json:"action,omitempty"`
+	// Version is the newest schema version the client supports, sent in "hello"
+	Version int `json:"v,omitempty"`
+}
+
+// HelloResult answers a client's hello with the schema version both sides will use
+type HelloResult struct {
+	OK         bool   `json:"ok"`
+	Version    int    `json:"version"`
+	MinVersion int    `json:"min_version"`
+	MaxVersion int    `json:"max_version"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ControlResult reports the outcome of a control request to the client that sent it
+type ControlResult struct {
+	Action string `json:"action"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
 }
 
 type ReactionRequest struct {
@@ -135,8 +256,80 @@ type Handler struct {
 	radioSvc   RadioServiceInterface
 	eventBus   EventBusInterface
 	mu         sync.RWMutex
+
+	// listenerInterval debounces listener_count broadcasts so connection
+	// churn results in at most one update per interval. Run schedules one
+	// when a client registers or is dropped.
+	listenerInterval time.Duration
+
+	// authenticator marks clients as authenticated; optional
+	authenticator Authenticator
+
+	// pingInterval is how often clients are pinged; a client is dropped once
+	// it misses more than missedPongs pongs in a row
+	pingInterval time.Duration
+	missedPongs  int
+
+	// quit is closed by Shutdown to stop Run, which closes done once every
+	// client has been disconnected
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+
+	// subscriptions holds the handler's event subscriptions, removed by
+	// Shutdown; nil without an event bus
+	subscriptions *subscriptionTracker
+	// orderedEvents runs the event handlers; Shutdown closes it once they
+	// are unsubscribed. Nil unless the event bus supports ordered delivery.
+	orderedEvents *events.OrderedSubscriber
+
+	// droppedBroadcasts counts messages dropped because the broadcast queue
+	// was full; lastDropLog is when that was last logged, in Unix nanoseconds
+	droppedBroadcasts atomic.Uint64
+	lastDropLog       atomic.Int64
 }
 
+// subscriptionTracker records the subscriptions made through it so they can
+// all be removed together
+type subscriptionTracker struct {
+	events.Subscriber
+
+	mu   sync.Mutex
+	subs []trackedSubscription
+}
+
+type trackedSubscription struct {
+	eventType string
+	id        events.SubscriptionID
+}
+
+func (t *subscriptionTracker) Subscribe(eventType string, handler events.EventHandler) events.SubscriptionID {
+	id := t.Subscriber.Subscribe(eventType, handler)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subs = append(t.subs, trackedSubscription{eventType: eventType, id: id})
+	return id
+}
+
+// unsubscribeAll removes every subscription made through the tracker
+func (t *subscriptionTracker) unsubscribeAll() {
+	t.mu.Lock()
+	subs := t.subs
+	t.subs = nil
+	t.mu.Unlock()
+
+	for _, sub := range subs {
+		t.Unsubscribe(sub.eventType, sub.id)
+	}
+}
+
+// DefaultListenerCountInterval is how long after the listener count changes
+// it is broadcast, so churn within the interval gives a single update
+const DefaultListenerCountInterval = 3 * time.Second
+
+// DefaultPingInterval is how often clients are pinged to check they're alive
+const DefaultPingInterval = 54 * time.Second
+
 func NewHandler(radioSvc RadioServiceInterface, eventBus EventBusInterface) *Handler {
 	handler := &Handler{
 		clients:    make(map[*Client]bool),
@@ -145,16 +338,41 @@ func NewHandler(radioSvc RadioServiceInterface, eventBus EventBusInterface) *Han
 		unregister: make(chan *Client, 10), // Buffer for client unregistrations
 		radioSvc:   radioSvc,
 		eventBus:   eventBus,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+
+		listenerInterval: DefaultListenerCountInterval,
+		pingInterval:     DefaultPingInterval,
 	}
 
 	// Subscribe to events
 	if eventBus != nil {
-		eventBus.Subscribe(events.EventSongChange, handler.handleSongChangeEvent)
-		eventBus.Subscribe(events.EventQueueUpdate, handler.handleQueueUpdateEvent)
-		eventBus.Subscribe(events.EventUserReaction, handler.handleUserReactionEvent)
-		eventBus.Subscribe(events.EventSkip, handler.handleSkipEvent)
-		eventBus.Subscribe(events.EventPrevious, handler.handlePreviousEvent)
-		eventBus.Subscribe(events.EventPlaylistChange, handler.handlePlaylistChangeEvent)
+		// Clients rely on seeing events in publish order, e.g. a song_change
+		// before the queue_update that follows it
+		var subscriber events.Subscriber = eventBus
+		if bus, ok := eventBus.(orderedEventBus); ok {
+			handler.orderedEvents = bus.Ordered()
+			subscriber = handler.orderedEvents
+		}
+		handler.subscriptions = &subscriptionTracker{Subscriber: subscriber}
+		subscriber = handler.subscriptions
+
+		subscribed := func(_ events.SubscriptionID, err error) {
+			if err != nil {
+				log.Printf("[ERROR] NewHandler: Failed to subscribe: %v", err)
+			}
+		}
+		subscribed(events.SubscribeTyped(subscriber, events.EventSongChange, handler.handleSongChangeEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventQueueUpdate, handler.handleQueueUpdateEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventUserReaction, handler.handleUserReactionEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventSkip, handler.handleSkipEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventPrevious, handler.handlePreviousEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventPlaylistChange, handler.handlePlaylistChangeEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventPlaybackStop, handler.handlePlaybackStoppedEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventPlaybackStart, handler.handlePlaybackStartedEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventAnnouncement, handler.handleAnnouncementEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventVoteUpdate, handler.handleVoteUpdateEvent))
+		subscribed(events.SubscribeTyped(subscriber, events.EventPlaybackUpdate, handler.handlePlaybackUpdateEvent))
 	}
 
 	return handler
@@ -164,14 +382,75 @@ func (h *Handler) SetRadioService(radioSvc RadioServiceInterface) {
 	h.radioSvc = radioSvc
 }
 
-// handleSongChangeEvent handles song change events from the event bus
-func (h *Handler) handleSongChangeEvent(event events.Event) {
-	songChangeEvent, ok := event.Payload.(events.SongChangeEvent)
-	if !ok {
-		log.Printf("[ERROR] handleSongChangeEvent: Failed to cast payload to SongChangeEvent")
-		return
+// SetAuthenticator sets the check used to flag clients as authenticated
+func (h *Handler) SetAuthenticator(authenticator Authenticator) {
+	h.authenticator = authenticator
+}
+
+// SetMissedPongs lets clients miss up to count pongs in a row before being
+// disconnected, for listeners on networks that drop the odd packet
+func (h *Handler) SetMissedPongs(count int) {
+	h.missedPongs = max(count, 0)
+}
+
+// pongWait is how long a client may go without a pong: until the ping after
+// its allowance of missed ones, plus time to answer it. That is 60s at the
+// default interval with no misses allowed.
+func (h *Handler) pongWait() time.Duration {
+	return time.Duration(h.missedPongs+1)*h.pingInterval + h.pingInterval/9
+}
+
+// Clients returns a snapshot of the connected clients, oldest connection first
+func (h *Handler) Clients() []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := make([]ClientInfo, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client.info())
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ConnectedAt.Before(clients[j].ConnectedAt)
+	})
+	return clients
+}
+
+// ListenerCount returns the number of currently connected clients
+func (h *Handler) ListenerCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// queueListenerCount queues a listener_count message on the broadcast channel.
+// It never blocks, since it is called from Run which also drains the channel;
+// it returns false if the message could not be queued.
+func (h *Handler) queueListenerCount(count int) bool {
+	message := Message{
+		Type: "listener_count",
+		Payload: ListenerCountEvent{
+			Count:     count,
+			Timestamp: time.Now().UnixMilli(),
+		},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] queueListenerCount: Failed to marshal event: %v", err)
+		return false
 	}
 
+	select {
+	case h.broadcast <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleSongChangeEvent handles song change events from the event bus
+func (h *Handler) handleSongChangeEvent(songChangeEvent events.SongChangeEvent) {
 	wsEvent := SongChangeEvent{
 		CurrentSong:      songChangeEvent.CurrentSong,
 		NextSong:         songChangeEvent.NextSong,
@@ -195,17 +474,11 @@ func (h *Handler) handleSongChangeEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
 }
 
 // handleQueueUpdateEvent handles queue update events from the event bus
-func (h *Handler) handleQueueUpdateEvent(event events.Event) {
-	queueUpdateEvent, ok := event.Payload.(events.QueueUpdateEvent)
-	if !ok {
-		log.Printf("[ERROR] handleQueueUpdateEvent: Failed to cast payload to QueueUpdateEvent")
-		return
-	}
-
+func (h *Handler) handleQueueUpdateEvent(queueUpdateEvent events.QueueUpdateEvent) {
 	wsEvent := QueueUpdate{
 		CurrentSong:      queueUpdateEvent.CurrentSong,
 		NextSong:         queueUpdateEvent.NextSong,
@@ -228,18 +501,12 @@ func (h *Handler) handleQueueUpdateEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
 }
 
 // handleUserReactionEvent handles user reaction events from the event bus
-func (h *Handler) handleUserReactionEvent(event events.Event) {
-	reactionEvent, ok := event.Payload.(events.UserReactionEvent)
-	if !ok {
-		log.Printf("[ERROR] handleUserReactionEvent: Failed to cast payload to UserReactionEvent")
-		return
-	}
-
-	log.Printf("[DEBUG] handleUserReactionEvent: Broadcasting reaction from %s: %s", reactionEvent.Emote)
+func (h *Handler) handleUserReactionEvent(reactionEvent events.UserReactionEvent) {
+	log.Printf("[DEBUG] handleUserReactionEvent: Broadcasting reaction: %s", reactionEvent.Emote)
 
 	wsEvent := UserReactionEvent{
 		Emote:     reactionEvent.Emote,
@@ -258,17 +525,11 @@ func (h *Handler) handleUserReactionEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
 }
 
 // handleSkipEvent handles skip events from the event bus
-func (h *Handler) handleSkipEvent(event events.Event) {
-	skipEvent, ok := event.Payload.(events.SkipEvent)
-	if !ok {
-		log.Printf("[ERROR] handleSkipEvent: Failed to cast payload to SkipEvent")
-		return
-	}
-
+func (h *Handler) handleSkipEvent(skipEvent events.SkipEvent) {
 	wsEvent := SkipEvent{
 		Song:      skipEvent.Song,
 		NextSong:  skipEvent.NextSong,
@@ -288,17 +549,11 @@ func (h *Handler) handleSkipEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
 }
 
 // handlePreviousEvent handles previous events from the event bus
-func (h *Handler) handlePreviousEvent(event events.Event) {
-	previousEvent, ok := event.Payload.(events.PreviousEvent)
-	if !ok {
-		log.Printf("[ERROR] handlePreviousEvent: Failed to cast payload to PreviousEvent")
-		return
-	}
-
+func (h *Handler) handlePreviousEvent(previousEvent events.PreviousEvent) {
 	wsEvent := PreviousEvent{
 		Song:      previousEvent.Song,
 		NextSong:  previousEvent.NextSong,
@@ -318,17 +573,11 @@ func (h *Handler) handlePreviousEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
 }
 
 // handlePlaylistChangeEvent handles playlist change events from the event bus
-func (h *Handler) handlePlaylistChangeEvent(event events.Event) {
-	playlistChangeEvent, ok := event.Payload.(events.PlaylistChangeEvent)
-	if !ok {
-		log.Printf("[ERROR] handlePlaylistChangeEvent: Failed to cast payload to PlaylistChangeEvent")
-		return
-	}
-
+func (h *Handler) handlePlaylistChangeEvent(playlistChangeEvent events.PlaylistChangeEvent) {
 	wsEvent := PlaylistChangeEvent{
 		Song:      playlistChangeEvent.Song,
 		NextSong:  playlistChangeEvent.NextSong,
@@ -349,7 +598,120 @@ func (h *Handler) handlePlaylistChangeEvent(event events.Event) {
 		return
 	}
 
-	h.broadcast <- data
+	h.queueBroadcast(data)
+}
+
+// handlePlaybackUpdateEvent sends clients the new playback position, e.g. after a pause
+func (h *Handler) handlePlaybackUpdateEvent(updateEvent events.PlaybackUpdateEvent) {
+	message := Message{
+		Type: "playback_state",
+		Payload: PlaybackUpdate{
+			Song:       updateEvent.Song,
+			Elapsed:    updateEvent.Elapsed,
+			Remaining:  updateEvent.Remaining,
+			Paused:     updateEvent.Paused,
+			TotalTime:  updateEvent.TotalTime,
+			Volume:     updateEvent.Volume,
+			Timestamp:  updateEvent.Timestamp,
+			ServerTime: updateEvent.Timestamp,
+			PositionMs: int64(updateEvent.Elapsed * 1000),
+		},
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] handlePlaybackUpdateEvent: Failed to marshal event: %v", err)
+		return
+	}
+
+	h.queueBroadcast(data)
+}
+
+// handlePlaybackStoppedEvent tells clients playback has ended so they can show an idle state
+func (h *Handler) handlePlaybackStoppedEvent(stoppedEvent events.PlaybackStoppedEvent) {
+	message := Message{
+		Type: "playback_stopped",
+		Payload: PlaybackStoppedEvent{
+			Playlist:  stoppedEvent.Playlist,
+			Timestamp: stoppedEvent.Timestamp,
+		},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] handlePlaybackStoppedEvent: Failed to marshal event: %v", err)
+		return
+	}
+
+	h.queueBroadcast(data)
+}
+
+// handlePlaybackStartedEvent tells clients an operator has started playback
+// again; the song_change for the first song follows
+func (h *Handler) handlePlaybackStartedEvent(startedEvent events.PlaybackStartedEvent) {
+	message := Message{
+		Type: "playback_started",
+		Payload: PlaybackStartedEvent{
+			Playlist:  startedEvent.Playlist,
+			Timestamp: startedEvent.Timestamp,
+		},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] handlePlaybackStartedEvent: Failed to marshal event: %v", err)
+		return
+	}
+
+	h.queueBroadcast(data)
+}
+
+// handleAnnouncementEvent tells clients to play the clip introducing the
+// next song, fetched from /api/v1/announcements/{announcement_id}
+func (h *Handler) handleAnnouncementEvent(announcementEvent events.AnnouncementEvent) {
+	message := Message{
+		Type: "announcement",
+		Payload: AnnouncementEvent{
+			Song:           announcementEvent.Song,
+			AnnouncementID: announcementEvent.AnnouncementID,
+			Text:           announcementEvent.Text,
+			Timestamp:      announcementEvent.Timestamp,
+		},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] handleAnnouncementEvent: Failed to marshal event: %v", err)
+		return
+	}
+
+	h.queueBroadcast(data)
+}
+
+// handleVoteUpdateEvent tells clients how many listeners have voted to skip
+// the current song and how many votes it takes
+func (h *Handler) handleVoteUpdateEvent(voteEvent events.VoteUpdateEvent) {
+	message := Message{
+		Type: "vote_update",
+		Payload: VoteUpdateEvent{
+			Song:      voteEvent.Song,
+			Votes:     voteEvent.Votes,
+			Required:  voteEvent.Required,
+			Timestamp: voteEvent.Timestamp,
+		},
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[ERROR] handleVoteUpdateEvent: Failed to marshal event: %v", err)
+		return
+	}
+
+	h.queueBroadcast(data)
 }
 
 func (h *Handler) Run() {
@@ -357,40 +719,179 @@ func (h *Handler) Run() {
 	ticker := time.NewTicker(100 * time.Millisecond) // 10 FPS for smooth updates
 	defer ticker.Stop()
 
+	// listenerUpdate fires listenerInterval after the client set first
+	// changes, and is nil while no listener_count broadcast is due
+	var listenerUpdate <-chan time.Time
+	scheduleListenerCount := func() {
+		if listenerUpdate == nil {
+			listenerUpdate = time.After(h.listenerInterval)
+		}
+	}
+	lastListenerCount := 0
+
 	for {
 		select {
+		case <-listenerUpdate:
+			listenerUpdate = nil
+			if count := h.ListenerCount(); count != lastListenerCount {
+				if h.queueListenerCount(count) {
+					lastListenerCount = count
+				} else {
+					// The broadcast queue is full, try again later
+					scheduleListenerCount()
+				}
+			}
+
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
 			h.mu.Unlock()
-
-			// Send immediate state to new client
-			go client.sendPlaybackState()
+			metrics.WebSocketConnections.Inc()
+			scheduleListenerCount()
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
+			h.removeClient(client)
 			h.mu.Unlock()
+			scheduleListenerCount()
+
+		case <-h.quit:
+			h.disconnectAll()
+			close(h.done)
+			return
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			// Clients too far behind to take the message are dropped, so
+			// this needs the write lock
+			h.mu.Lock()
+			dropped := false
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				if !client.trySend(message) {
+					h.removeClient(client)
+					dropped = true
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
+			if dropped {
+				scheduleListenerCount()
+			}
 		}
 
 	}
 }
 
+// removeClient drops c and closes its send channel, which ends its write
+// pump. Clients can be dropped by a broadcast and then unregister, so it only
+// acts the first time. The caller must hold h.mu.
+func (h *Handler) removeClient(c *Client) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	c.closeSend()
+	metrics.WebSocketDisconnections.Inc()
+}
+
+// Shutdown closes every client's connection with a going-away close frame
+// and stops Run, waiting until it has or ctx is done. The handler's event
+// subscriptions are removed, so events published afterwards don't reach it,
+// and the goroutine running its handlers stops.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.quitOnce.Do(func() {
+		if h.subscriptions != nil {
+			h.subscriptions.unsubscribeAll()
+		}
+		if h.orderedEvents != nil {
+			h.orderedEvents.Close()
+		}
+		close(h.quit)
+	})
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// disconnectAll sends each client a going-away close frame and closes its
+// connection, which ends its read and write pumps. Clients still waiting in
+// the register buffer are included. The send channels stay open since the
+// read pumps may still be replying to messages.
+func (h *Handler) disconnectAll() {
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(time.Second)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for pending := true; pending; {
+		select {
+		case client := <-h.register:
+			h.clients[client] = true
+		default:
+			pending = false
+		}
+	}
+	for client := range h.clients {
+		if client.conn != nil {
+			client.conn.WriteControl(websocket.CloseMessage, closeMessage, deadline)
+			client.conn.Close()
+		}
+		delete(h.clients, client)
+		metrics.WebSocketDisconnections.Inc()
+	}
+}
+
+// broadcastDropLogInterval is the least time between warnings about
+// dropped broadcasts
+const broadcastDropLogInterval = 10 * time.Second
+
+// queueBroadcast hands data to Run for every client. It never blocks, since
+// it runs on the event bus's ordered queue: the message is dropped if the
+// broadcast queue is full or the handler has shut down.
+func (h *Handler) queueBroadcast(data []byte) {
+	select {
+	case <-h.quit:
+		return
+	default:
+	}
+
+	select {
+	case h.broadcast <- data:
+	default:
+		dropped := h.droppedBroadcasts.Add(1)
+		now := time.Now().UnixNano()
+		last := h.lastDropLog.Load()
+		if now-last >= int64(broadcastDropLogInterval) && h.lastDropLog.CompareAndSwap(last, now) {
+			log.Printf("[WARN] queueBroadcast: Broadcast queue full, dropping messages (%d dropped so far)", dropped)
+		}
+	}
+}
+
+// join queues the current playback state for c and asks Run to add it. The
+// state is queued before registering, while nothing else can close c.send,
+// and reading it here keeps Run off the radio's lock. It returns false once
+// the handler has shut down.
+func (h *Handler) join(c *Client) bool {
+	c.sendPlaybackState()
+
+	select {
+	case h.register <- c:
+		return true
+	case <-h.quit:
+		return false
+	}
+}
+
+// leave asks Run to drop c, unless the handler has shut down and already has
+func (h *Handler) leave(c *Client) {
+	select {
+	case h.unregister <- c:
+	case <-h.quit:
+	}
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -399,23 +900,52 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &Client{
-		conn:     conn,
-		send:     make(chan []byte, 256),
-		radioSvc: h.radioSvc,
-		handler:  h,
+		conn:        conn,
+		send:        make(chan []byte, 256),
+		radioSvc:    h.radioSvc,
+		handler:     h,
+		remoteAddr:  r.RemoteAddr,
+		connectedAt: time.Now(),
+	}
+	if h.authenticator != nil {
+		client.authenticated = h.authenticator(r)
 	}
 
-	h.register <- client
+	if !h.join(client) {
+		conn.Close()
+		return
+	}
 
 	go client.writePump()
 	go client.readPump()
 }
 
-func (c *Client) handleMessage(messageType int, data []byte) {
+func (c *Client) info() ClientInfo {
+	info := ClientInfo{
+		RemoteAddr:     c.remoteAddr,
+		ConnectedAt:    c.connectedAt,
+		Authenticated:  c.authenticated,
+		QueuedMessages: len(c.send),
+		Version:        int(c.version.Load()),
+	}
+	// The latest ping may simply not have been answered yet
+	if unanswered := int(c.unanswered.Load()); unanswered > 1 {
+		info.MissedPongs = unanswered - 1
+	}
+	if pong := c.lastPong.Load(); pong != 0 {
+		lastPong := time.Unix(0, pong)
+		info.LastPong = &lastPong
+	}
+	return info
+}
+
+// handleMessage answers a client request. It returns false once the client
+// has been rejected and should be disconnected.
+func (c *Client) handleMessage(messageType int, data []byte) bool {
 	var request ClientRequest
 	if err := json.Unmarshal(data, &request); err != nil {
 		log.Printf("[ERROR] handleMessage: Failed to unmarshal request: %v", err)
-		return
+		return true
 	}
 
 	switch request.Type {
@@ -429,11 +959,12 @@ func (c *Client) handleMessage(messageType int, data []byte) {
 			Timestamp: time.Now().UnixMilli(),
 		}
 		if responseData, err := json.Marshal(response); err == nil {
-			select {
-			case c.send <- responseData:
-			default:
-			}
+			c.trySend(responseData)
 		}
+	case "hello":
+		return c.handleHello(request.Version)
+	case "control":
+		c.handleControl(request.Action)
 	case "user_reaction":
 		// Handle user reaction request - the message structure matches WebSocketMessage format
 		var message struct {
@@ -447,7 +978,7 @@ func (c *Client) handleMessage(messageType int, data []byte) {
 
 		if err := json.Unmarshal(data, &message); err != nil {
 			log.Printf("[ERROR] handleMessage: Failed to unmarshal user_reaction request: %v", err)
-			return
+			return true
 		}
 
 		log.Printf("[DEBUG] Received user reaction: emote=%s", message.Payload.Emote)
@@ -459,18 +990,101 @@ func (c *Client) handleMessage(messageType int, data []byte) {
 			}).PublishUserReaction(message.Payload.Emote)
 		}
 	}
+	return true
+}
+
+// handleHello agrees on a schema version with the client. Clients newer than
+// the server are answered with the server's version and should adapt; clients
+// older than MinProtocolVersion are rejected, and it returns false for them so
+// the read pump disconnects them.
+func (c *Client) handleHello(clientVersion int) bool {
+	result := HelloResult{
+		MinVersion: MinProtocolVersion,
+		MaxVersion: ProtocolVersion,
+	}
+	if clientVersion < MinProtocolVersion {
+		log.Printf("[WARN] handleHello: Rejected client %s with unsupported version %d", c.remoteAddr, clientVersion)
+		result.Error = "unsupported version"
+	} else {
+		result.OK = true
+		result.Version = min(clientVersion, ProtocolVersion)
+		c.version.Store(int32(result.Version))
+	}
+
+	data, err := json.Marshal(Message{
+		Type:      "hello",
+		Payload:   result,
+		Timestamp: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		log.Printf("[ERROR] handleHello: Failed to marshal result: %v", err)
+		return result.OK
+	}
+
+	c.trySend(data)
+	return result.OK
+}
+
+// handleControl runs a playback control for an authenticated client and
+// replies with the result
+func (c *Client) handleControl(action string) {
+	result := ControlResult{Action: action}
+
+	switch {
+	case !c.authenticated:
+		log.Printf("[WARN] handleControl: Rejected %q from unauthenticated client %s", action, c.remoteAddr)
+		result.Error = "unauthorized"
+	case c.radioSvc == nil:
+		result.Error = "radio unavailable"
+	default:
+		switch action {
+		case "skip":
+			c.radioSvc.Next()
+		case "previous":
+			c.radioSvc.Previous()
+		case "pause":
+			c.radioSvc.Pause()
+		case "resume":
+			c.radioSvc.Resume()
+		default:
+			result.Error = "unknown action"
+		}
+		result.OK = result.Error == ""
+	}
+
+	data, err := json.Marshal(Message{
+		Type:      "control_result",
+		Payload:   result,
+		Timestamp: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		log.Printf("[ERROR] handleControl: Failed to marshal result: %v", err)
+		return
+	}
+
+	c.trySend(data)
 }
 
 func (c *Client) readPump() {
+	rejected := false
 	defer func() {
-		c.handler.unregister <- c
-		c.conn.Close()
+		c.handler.leave(c)
+		// A rejected client's connection is closed by writePump once Run has
+		// dropped it, so the reply queued ahead of the close frame is sent
+		if !rejected {
+			c.conn.Close()
+		}
 	}()
 
 	c.conn.SetReadLimit(512)
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	pongWait := c.handler.pongWait()
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		if missed := c.unanswered.Swap(0) - 1; missed > 0 {
+			log.Printf("[DEBUG] readPump: %s answered after missing %d pongs", c.remoteAddr, missed)
+		}
+		c.lastPong.Store(time.Now().UnixNano())
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
 
@@ -484,14 +1098,41 @@ func (c *Client) readPump() {
 		}
 
 		// Handle client messages
-		if messageType == websocket.TextMessage {
-			c.handleMessage(messageType, data)
+		if messageType == websocket.TextMessage && !c.handleMessage(messageType, data) {
+			rejected = true
+			break
 		}
 	}
 }
 
+// trySend queues data for the client without blocking. It returns false if
+// the queue is full or Run has already closed it.
+func (c *Client) trySend(data []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.sendClosed {
+		return false
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend closes the send queue, which ends the write pump
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.sendClosed {
+		c.sendClosed = true
+		close(c.send)
+	}
+}
+
 func (c *Client) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(c.handler.pingInterval)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
@@ -518,6 +1159,7 @@ func (c *Client) writePump() {
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.unanswered.Add(1)
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -529,13 +1171,20 @@ func (c *Client) sendPlaybackState() {
 	state := c.radioSvc.GetPlaybackState()
 	if state == nil || c.radioSvc.GetCurrentSong() == nil {
 		// Send empty state to indicate no song is playing
+		now := time.Now().UnixMilli()
+		volume := 1.0
+		if state != nil {
+			volume = state.Volume
+		}
 		update := PlaybackUpdate{
-			Song:      nil,
-			Elapsed:   0,
-			Remaining: 0,
-			Paused:    true,
-			TotalTime: 0,
-			Timestamp: time.Now().UnixMilli(),
+			Song:       nil,
+			Elapsed:    0,
+			Remaining:  0,
+			Paused:     true,
+			TotalTime:  0,
+			Volume:     volume,
+			Timestamp:  now,
+			ServerTime: now,
 		}
 
 		message := Message{
@@ -549,25 +1198,27 @@ func (c *Client) sendPlaybackState() {
 			return
 		}
 
-		select {
-		case c.send <- data:
-		default:
-		}
+		c.trySend(data)
 		return
 	}
 
-	elapsed := c.radioSvc.GetElapsedTime().Seconds()
+	// Read the server time right after the position so the pair agrees
+	elapsed := c.radioSvc.GetElapsedTime()
+	serverTime := time.Now().UnixMilli()
 	remaining := c.radioSvc.GetRemainingTime().Seconds()
 
 	currentSong := c.radioSvc.GetCurrentSong()
 
 	update := PlaybackUpdate{
-		Song:      currentSong,
-		Elapsed:   elapsed,
-		Remaining: remaining,
-		Paused:    state.Paused,
-		TotalTime: float64(currentSong.Duration),
-		Timestamp: time.Now().UnixMilli(),
+		Song:       currentSong,
+		Elapsed:    elapsed.Seconds(),
+		Remaining:  remaining,
+		Paused:     state.Paused,
+		TotalTime:  float64(currentSong.PlayedDuration()),
+		Volume:     state.Volume,
+		Timestamp:  serverTime,
+		ServerTime: serverTime,
+		PositionMs: elapsed.Milliseconds(),
 	}
 
 	message := Message{
@@ -581,8 +1232,5 @@ func (c *Client) sendPlaybackState() {
 		return
 	}
 
-	select {
-	case c.send <- data:
-	default:
-	}
+	c.trySend(data)
 }
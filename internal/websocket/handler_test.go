@@ -0,0 +1,1032 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/feline-dis/go-radio-v2/internal/events"
+	"github.com/feline-dis/go-radio-v2/internal/models"
+	"github.com/feline-dis/go-radio-v2/internal/repositories"
+	"github.com/feline-dis/go-radio-v2/internal/services"
+	"github.com/gorilla/websocket"
+)
+
+// fakeRadioService has no playback and records the controls it receives
+type fakeRadioService struct {
+	mu       sync.Mutex
+	controls []string
+}
+
+func (f *fakeRadioService) GetPlaybackState() *models.PlaybackState { return nil }
+func (f *fakeRadioService) GetElapsedTime() time.Duration           { return 0 }
+func (f *fakeRadioService) GetRemainingTime() time.Duration         { return 0 }
+func (f *fakeRadioService) GetQueueInfo() *models.QueueInfo         { return nil }
+func (f *fakeRadioService) GetCurrentSong() *models.Song            { return nil }
+func (f *fakeRadioService) Next()                                   { f.record("next") }
+func (f *fakeRadioService) Previous()                               { f.record("previous") }
+func (f *fakeRadioService) Pause()                                  { f.record("pause") }
+func (f *fakeRadioService) Resume()                                 { f.record("resume") }
+
+func (f *fakeRadioService) record(control string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.controls = append(f.controls, control)
+}
+
+func (f *fakeRadioService) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.controls...)
+}
+
+func newTestClient(h *Handler) *Client {
+	return &Client{
+		send:     make(chan []byte, 256),
+		radioSvc: h.radioSvc,
+		handler:  h,
+	}
+}
+
+// waitForListenerCount reads from the client's send channel until a
+// listener_count message with the expected count arrives.
+func waitForListenerCount(t *testing.T, c *Client, expected int) {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data, ok := <-c.send:
+			if !ok {
+				t.Fatalf("Client send channel closed while waiting for count %d", expected)
+			}
+			var message struct {
+				Type    string             `json:"type"`
+				Payload ListenerCountEvent `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type == "listener_count" && message.Payload.Count == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for listener count %d", expected)
+		}
+	}
+}
+
+func TestListenerCountBroadcast(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	handler.listenerInterval = 10 * time.Millisecond
+	go handler.Run()
+
+	first := newTestClient(handler)
+	second := newTestClient(handler)
+
+	handler.register <- first
+	handler.register <- second
+	waitForListenerCount(t, first, 2)
+
+	if count := handler.ListenerCount(); count != 2 {
+		t.Errorf("Expected 2 listeners, got %d", count)
+	}
+
+	handler.unregister <- second
+	waitForListenerCount(t, first, 1)
+
+	if count := handler.ListenerCount(); count != 1 {
+		t.Errorf("Expected 1 listener, got %d", count)
+	}
+}
+
+func TestSlowClientDroppedOnce(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	handler.listenerInterval = time.Hour
+	go handler.Run()
+	defer handler.Shutdown(context.Background())
+
+	listener := newTestClient(handler)
+	// A client with no room to queue anything can't keep up with broadcasts
+	slow := newTestClient(handler)
+	slow.send = make(chan []byte)
+
+	handler.register <- listener
+	handler.register <- slow
+	waitForRegistered(t, handler, 2)
+
+	handler.queueBroadcast([]byte(`{"type":"ping"}`))
+	waitForRegistered(t, handler, 1)
+	if _, ok := <-slow.send; ok {
+		t.Error("Expected the dropped client's send channel to be closed")
+	}
+	// Its read pump may still be answering messages
+	slow.handleMessage(1, []byte(`{"type":"ping"}`))
+
+	// Its read pump unregisters it afterwards, which must not count it again
+	// or close its channel twice
+	handler.unregister <- slow
+	handler.unregister <- listener
+	waitForRegistered(t, handler, 0)
+	if clients := handler.Clients(); len(clients) != 0 {
+		t.Errorf("Expected no clients, got %d", len(clients))
+	}
+}
+
+func TestDroppedClientUpdatesListenerCount(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	handler.listenerInterval = 10 * time.Millisecond
+	go handler.Run()
+	defer handler.Shutdown(context.Background())
+
+	listener := newTestClient(handler)
+	slow := newTestClient(handler)
+	slow.send = make(chan []byte)
+	handler.register <- listener
+	handler.register <- slow
+	waitForListenerCount(t, listener, 2)
+
+	// Dropping a client that can't keep up is a change like any other
+	handler.queueBroadcast([]byte(`{"type":"ping"}`))
+	waitForListenerCount(t, listener, 1)
+}
+
+// waitForRegistered polls until the handler reports the expected client count.
+func waitForRegistered(t *testing.T, h *Handler, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for h.ListenerCount() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d registered clients, got %d", expected, h.ListenerCount())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestListenerCountDebounced(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	handler.listenerInterval = 200 * time.Millisecond
+	go handler.Run()
+
+	listener := newTestClient(handler)
+	handler.register <- listener
+
+	// Churn clients in and out within a single interval
+	churn := make([]*Client, 5)
+	for i := range churn {
+		churn[i] = newTestClient(handler)
+		handler.register <- churn[i]
+	}
+	waitForRegistered(t, handler, 6)
+	for _, c := range churn {
+		handler.unregister <- c
+	}
+	waitForRegistered(t, handler, 1)
+
+	// Only the settled count should be broadcast, once
+	updates := 0
+	deadline := time.After(500 * time.Millisecond)
+	for {
+		select {
+		case data := <-listener.send:
+			var message struct {
+				Type    string             `json:"type"`
+				Payload ListenerCountEvent `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type != "listener_count" {
+				continue
+			}
+			if message.Payload.Count != 1 {
+				t.Fatalf("Expected listener count 1, got %d", message.Payload.Count)
+			}
+			updates++
+		case <-deadline:
+			if updates != 1 {
+				t.Errorf("Expected 1 listener_count broadcast, got %d", updates)
+			}
+			return
+		}
+	}
+}
+
+func TestHandlerForwardsTypedEvents(t *testing.T) {
+	eventBus := events.NewEventBus()
+	handler := NewHandler(&fakeRadioService{}, eventBus)
+	go handler.Run()
+
+	client := newTestClient(handler)
+	handler.register <- client
+	waitForRegistered(t, handler, 1)
+
+	eventBus.PublishSkip(&models.Song{YouTubeID: "song1"}, nil, nil)
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-client.send:
+			var message struct {
+				Type    string    `json:"type"`
+				Payload SkipEvent `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type != "skip" {
+				continue
+			}
+			if message.Payload.Song == nil || message.Payload.Song.YouTubeID != "song1" {
+				t.Errorf("Expected skipped song song1, got %v", message.Payload.Song)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timed out waiting for skip message")
+		}
+	}
+}
+
+func TestClientsSnapshot(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	go handler.Run()
+
+	if clients := handler.Clients(); len(clients) != 0 {
+		t.Fatalf("Expected no clients, got %d", len(clients))
+	}
+
+	first := newTestClient(handler)
+	first.remoteAddr = "10.0.0.1:1234"
+	first.connectedAt = time.Now().Add(-time.Minute)
+	first.authenticated = true
+	first.lastPong.Store(time.Now().UnixNano())
+
+	second := newTestClient(handler)
+	second.remoteAddr = "10.0.0.2:5678"
+	second.connectedAt = time.Now()
+
+	handler.join(first)
+	handler.join(second)
+	waitForRegistered(t, handler, 2)
+
+	clients := handler.Clients()
+	if len(clients) != 2 {
+		t.Fatalf("Expected 2 clients, got %d", len(clients))
+	}
+
+	if clients[0].RemoteAddr != "10.0.0.1:1234" || !clients[0].Authenticated || clients[0].LastPong == nil {
+		t.Errorf("Unexpected snapshot for first client: %+v", clients[0])
+	}
+	if clients[1].RemoteAddr != "10.0.0.2:5678" || clients[1].Authenticated || clients[1].LastPong != nil {
+		t.Errorf("Unexpected snapshot for second client: %+v", clients[1])
+	}
+	// Each client was sent its initial playback state
+	if clients[0].QueuedMessages != 1 {
+		t.Errorf("Expected 1 queued message, got %d", clients[0].QueuedMessages)
+	}
+
+	handler.unregister <- first
+	waitForRegistered(t, handler, 1)
+
+	if clients := handler.Clients(); len(clients) != 1 || clients[0].RemoteAddr != "10.0.0.2:5678" {
+		t.Errorf("Expected only the second client to remain, got %+v", clients)
+	}
+}
+
+func TestHandlerReceivesEventsInPublishOrder(t *testing.T) {
+	eventBus := events.NewEventBus()
+	handler := NewHandler(&fakeRadioService{}, eventBus)
+	go handler.Run()
+
+	client := newTestClient(handler)
+	handler.register <- client
+	waitForRegistered(t, handler, 1)
+
+	song := &models.Song{YouTubeID: "song1", Duration: 180}
+	queueInfo := &models.QueueInfo{Queue: []*models.Song{song}}
+	for i := 0; i < 20; i++ {
+		eventBus.PublishSongChange(song, song, queueInfo)
+		eventBus.PublishQueueUpdate(queueInfo)
+	}
+
+	want := "song_change"
+	received := 0
+	timeout := time.After(2 * time.Second)
+	for received < 40 {
+		select {
+		case data := <-client.send:
+			var message struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type != "song_change" && message.Type != "queue_update" {
+				continue
+			}
+			if message.Type != want {
+				t.Fatalf("Expected %s as message %d, got %s", want, received, message.Type)
+			}
+			received++
+			if want == "song_change" {
+				want = "queue_update"
+			} else {
+				want = "song_change"
+			}
+		case <-timeout:
+			t.Fatalf("Timed out after %d messages", received)
+		}
+	}
+}
+
+// readControlResult returns the next control_result message sent to the client
+func readControlResult(t *testing.T, c *Client) ControlResult {
+	t.Helper()
+
+	for {
+		select {
+		case data := <-c.send:
+			var message struct {
+				Type    string        `json:"type"`
+				Payload ControlResult `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type == "control_result" {
+				return message.Payload
+			}
+		default:
+			t.Fatal("Expected a control_result message")
+		}
+	}
+}
+
+func TestControlMessages(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticated bool
+		action        string
+		wantOK        bool
+		wantError     string
+		wantControls  []string
+	}{
+		{name: "skip", authenticated: true, action: "skip", wantOK: true, wantControls: []string{"next"}},
+		{name: "previous", authenticated: true, action: "previous", wantOK: true, wantControls: []string{"previous"}},
+		{name: "pause", authenticated: true, action: "pause", wantOK: true, wantControls: []string{"pause"}},
+		{name: "resume", authenticated: true, action: "resume", wantOK: true, wantControls: []string{"resume"}},
+		{name: "unknown action", authenticated: true, action: "rewind", wantError: "unknown action"},
+		{name: "unauthenticated skip", authenticated: false, action: "skip", wantError: "unauthorized"},
+		{name: "unauthenticated pause", authenticated: false, action: "pause", wantError: "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			radio := &fakeRadioService{}
+			handler := NewHandler(radio, nil)
+			client := newTestClient(handler)
+			client.authenticated = tt.authenticated
+
+			client.handleMessage(0, []byte(`{"type":"control","action":"`+tt.action+`"}`))
+
+			result := readControlResult(t, client)
+			if result.Action != tt.action || result.OK != tt.wantOK || result.Error != tt.wantError {
+				t.Errorf("Expected result {%s %v %q}, got %+v", tt.action, tt.wantOK, tt.wantError, result)
+			}
+			if got := radio.recorded(); !reflect.DeepEqual(got, tt.wantControls) {
+				t.Errorf("Expected controls %v, got %v", tt.wantControls, got)
+			}
+		})
+	}
+}
+
+func TestOutboundMessagesCarryVersion(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	client := newTestClient(handler)
+
+	// Broadcasts queue on the handler's channel while Run isn't draining it
+	handler.queueListenerCount(1)
+	handler.handleSongChangeEvent(events.SongChangeEvent{})
+	handler.handleQueueUpdateEvent(events.QueueUpdateEvent{})
+	handler.handleUserReactionEvent(events.UserReactionEvent{Emote: "🔥"})
+	handler.handleSkipEvent(events.SkipEvent{})
+	handler.handlePreviousEvent(events.PreviousEvent{})
+	handler.handlePlaylistChangeEvent(events.PlaylistChangeEvent{})
+	handler.handlePlaybackUpdateEvent(events.PlaybackUpdateEvent{})
+	handler.handlePlaybackStoppedEvent(events.PlaybackStoppedEvent{})
+	handler.handlePlaybackStartedEvent(events.PlaybackStartedEvent{})
+	handler.handleAnnouncementEvent(events.AnnouncementEvent{})
+
+	for _, request := range []string{
+		`{"type":"ping"}`,
+		`{"type":"get_playback_state"}`,
+		`{"type":"control","action":"next"}`,
+		`{"type":"hello","v":1}`,
+	} {
+		client.handleMessage(1, []byte(request))
+	}
+
+	var outbound [][]byte
+	for drained := false; !drained; {
+		select {
+		case data := <-handler.broadcast:
+			outbound = append(outbound, data)
+		case data := <-client.send:
+			outbound = append(outbound, data)
+		default:
+			drained = true
+		}
+	}
+	if len(outbound) != 15 {
+		t.Fatalf("Expected 15 outbound messages, got %d", len(outbound))
+	}
+
+	for _, data := range outbound {
+		var message map[string]interface{}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		if v, ok := message["v"].(float64); !ok || int(v) != ProtocolVersion {
+			t.Errorf("Expected %s message to have v=%d, got %v", message["type"], ProtocolVersion, message["v"])
+		}
+	}
+}
+
+// readHelloResult reads from the client's send channel until a hello reply
+// arrives, skipping anything else queued before it.
+func readHelloResult(t *testing.T, c *Client) HelloResult {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case data := <-c.send:
+			var message struct {
+				Type    string      `json:"type"`
+				Payload HelloResult `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if message.Type == "hello" {
+				return message.Payload
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for hello reply")
+		}
+	}
+}
+
+func TestHelloNegotiatesVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		want    int
+	}{
+		{"current", `{"type":"hello","v":1}`, 1},
+		{"newer client", `{"type":"hello","v":7}`, ProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(&fakeRadioService{}, nil)
+			client := newTestClient(handler)
+
+			client.handleMessage(1, []byte(tt.request))
+
+			result := readHelloResult(t, client)
+			if !result.OK || result.Version != tt.want {
+				t.Errorf("Expected ok with version %d, got %+v", tt.want, result)
+			}
+			if result.MinVersion != MinProtocolVersion || result.MaxVersion != ProtocolVersion {
+				t.Errorf("Expected supported range %d-%d, got %d-%d", MinProtocolVersion, ProtocolVersion, result.MinVersion, result.MaxVersion)
+			}
+			if got := client.info().Version; got != tt.want {
+				t.Errorf("Expected client version %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHelloRejectsUnsupportedVersion(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	go handler.Run()
+
+	client := newTestClient(handler)
+	handler.register <- client
+	waitForRegistered(t, handler, 1)
+
+	if client.handleMessage(1, []byte(`{"type":"hello","v":0}`)) {
+		t.Error("Expected the client to be disconnected")
+	}
+
+	result := readHelloResult(t, client)
+	if result.OK || result.Error != "unsupported version" {
+		t.Errorf("Expected unsupported version rejection, got %+v", result)
+	}
+
+	// Its read pump unregisters it, after which messages still in flight
+	// must not be answered on the closed send channel
+	handler.leave(client)
+	waitForRegistered(t, handler, 0)
+	client.handleMessage(1, []byte(`{"type":"ping"}`))
+	client.handleMessage(1, []byte(`{"type":"hello","v":0}`))
+}
+
+func TestHelloRejectionClosesConnection(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	go handler.Run()
+	defer handler.Shutdown(context.Background())
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("Failed to read initial state: %v", err)
+	}
+	waitForRegistered(t, handler, 1)
+
+	// Messages sent straight after the hello reach the server before it
+	// has dropped the client
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"hello","v":0}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"ping"}`))
+
+	var message struct {
+		Type    string      `json:"type"`
+		Payload HelloResult `json:"payload"`
+	}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read hello result: %v", err)
+	}
+	if message.Type != "hello" || message.Payload.OK {
+		t.Errorf("Expected a hello rejection, got %+v", message)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseNormalClosure) {
+		t.Errorf("Expected the connection closed after the rejection, got %v", err)
+	}
+	waitForRegistered(t, handler, 0)
+}
+
+// playingRadioService reports a song that has been playing for elapsed
+type playingRadioService struct {
+	fakeRadioService
+	song    *models.Song
+	elapsed time.Duration
+	volume  float64
+}
+
+func (p *playingRadioService) GetPlaybackState() *models.PlaybackState {
+	return &models.PlaybackState{Queue: []*models.Song{p.song}, Volume: p.volume}
+}
+func (p *playingRadioService) GetCurrentSong() *models.Song    { return p.song }
+func (p *playingRadioService) GetElapsedTime() time.Duration   { return p.elapsed }
+func (p *playingRadioService) GetRemainingTime() time.Duration { return 0 }
+
+func TestPlaybackStateIncludesPosition(t *testing.T) {
+	radio := &playingRadioService{
+		song:    &models.Song{YouTubeID: "song1", Duration: 180},
+		elapsed: 83*time.Second + 456*time.Millisecond,
+	}
+	handler := NewHandler(radio, nil)
+	client := newTestClient(handler)
+
+	before := time.Now().UnixMilli()
+	client.sendPlaybackState()
+	after := time.Now().UnixMilli()
+
+	var message struct {
+		Type    string         `json:"type"`
+		Payload PlaybackUpdate `json:"payload"`
+	}
+	if err := json.Unmarshal(<-client.send, &message); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	update := message.Payload
+
+	if diff := update.PositionMs - int64(update.Elapsed*1000); diff < -1 || diff > 1 {
+		t.Errorf("Expected position_ms %d to match elapsed %.3fs", update.PositionMs, update.Elapsed)
+	}
+	if update.PositionMs != 83456 {
+		t.Errorf("Expected position_ms 83456, got %d", update.PositionMs)
+	}
+	if update.ServerTime < before || update.ServerTime > after {
+		t.Errorf("Expected server_time between %d and %d, got %d", before, after, update.ServerTime)
+	}
+}
+
+func TestPlaybackStateIncludesVolume(t *testing.T) {
+	radio := &playingRadioService{song: &models.Song{YouTubeID: "song1", Duration: 180}, volume: 0.4}
+	handler := NewHandler(radio, nil)
+	client := newTestClient(handler)
+
+	client.sendPlaybackState()
+	handler.handlePlaybackUpdateEvent(events.PlaybackUpdateEvent{Song: radio.song, Volume: 0.6})
+
+	for _, tt := range []struct {
+		source string
+		data   []byte
+		want   float64
+	}{
+		{"initial state", <-client.send, 0.4},
+		{"broadcast", <-handler.broadcast, 0.6},
+	} {
+		var message struct {
+			Type    string         `json:"type"`
+			Payload PlaybackUpdate `json:"payload"`
+		}
+		if err := json.Unmarshal(tt.data, &message); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", tt.source, err)
+		}
+		if message.Type != "playback_state" || message.Payload.Volume != tt.want {
+			t.Errorf("Expected %s playback_state with volume %v, got %s with %v", tt.source, tt.want, message.Type, message.Payload.Volume)
+		}
+	}
+}
+
+// lockedRadioService reads its state under lock, like RadioService does
+// under its mutex, so tests can hold the lock the way a skip does
+type lockedRadioService struct {
+	fakeRadioService
+	lock sync.RWMutex
+}
+
+func (l *lockedRadioService) GetPlaybackState() *models.PlaybackState {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return nil
+}
+
+func (l *lockedRadioService) GetCurrentSong() *models.Song {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return nil
+}
+
+// newPlayingRadio returns a real radio service playing a two-song playlist
+func newPlayingRadio(t *testing.T) *services.RadioService {
+	t.Helper()
+
+	songs := repositories.NewInMemorySongRepository()
+	playlists := repositories.NewInMemoryPlaylistRepository(songs)
+	playlist := &models.Playlist{Name: "Test"}
+	if err := playlists.Create(playlist); err != nil {
+		t.Fatalf("Failed to create playlist: %v", err)
+	}
+	for i, id := range []string{"song1", "song2"} {
+		songs.Create(&models.Song{YouTubeID: id, Title: id, Artist: "Artist", Duration: 180})
+		playlists.AddSong(playlist.ID, id, i)
+	}
+
+	radio := services.NewRadioService(songs, playlists, nil, events.NewNoopEventBus())
+	if err := radio.SetActivePlaylist(playlist.ID); err != nil {
+		t.Fatalf("Failed to start playlist: %v", err)
+	}
+	return radio
+}
+
+// joinWhile joins clients to a handler for radio while change runs, so
+// the race detector sees the playback state read against its writes
+func joinWhile(t *testing.T, radio *services.RadioService, change func(i int)) {
+	t.Helper()
+
+	handler := NewHandler(radio, nil)
+	handler.listenerInterval = time.Hour
+	go handler.Run()
+	defer handler.Shutdown(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			change(i)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		client := newTestClient(handler)
+		handler.join(client)
+		if data := <-client.send; !strings.Contains(string(data), `"playback_state"`) {
+			t.Errorf("Expected the playback state, got %s", data)
+		}
+	}
+	<-done
+}
+
+func TestJoinWhilePausing(t *testing.T) {
+	radio := newPlayingRadio(t)
+	joinWhile(t, radio, func(i int) {
+		if i%2 == 0 {
+			radio.Pause()
+		} else {
+			radio.Resume()
+		}
+	})
+}
+
+func TestJoinWhileVolumeChanges(t *testing.T) {
+	radio := newPlayingRadio(t)
+	joinWhile(t, radio, func(i int) {
+		radio.SetVolume(float64(i%10) / 10)
+	})
+}
+
+func TestRunDoesNotWaitForTheRadioLock(t *testing.T) {
+	radio := &lockedRadioService{}
+	handler := NewHandler(radio, nil)
+	handler.listenerInterval = time.Hour
+	go handler.Run()
+	defer handler.Shutdown(context.Background())
+
+	first := newTestClient(handler)
+	handler.join(first)
+	waitForRegistered(t, handler, 1)
+	<-first.send
+
+	// While a writer holds the radio's lock, registering and broadcasting
+	// still go through
+	radio.lock.Lock()
+	defer radio.lock.Unlock()
+	handler.register <- newTestClient(handler)
+	waitForRegistered(t, handler, 2)
+	handler.queueBroadcast([]byte(`{"type":"test"}`))
+	select {
+	case data := <-first.send:
+		if string(data) != `{"type":"test"}` {
+			t.Errorf("Expected the broadcast, got %s", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Broadcast stalled behind the radio lock")
+	}
+}
+
+func TestEventFloodDuringSkipDoesNotDeadlock(t *testing.T) {
+	eventBus := events.NewEventBus()
+	radio := &lockedRadioService{}
+	handler := NewHandler(radio, eventBus)
+	handler.listenerInterval = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	defer handler.Shutdown(ctx)
+
+	// Run isn't started yet, so nothing drains the broadcast queue. More
+	// events than the ordered queue (256) and the broadcast queue (100) hold
+	// are published while the radio's lock is held, as a skip does.
+	const published = 500
+	skipped := make(chan struct{})
+	go func() {
+		radio.lock.Lock()
+		defer radio.lock.Unlock()
+		song := &models.Song{YouTubeID: "song1", Duration: 180}
+		for i := 0; i < published; i++ {
+			eventBus.PublishSkip(song, song, nil)
+			eventBus.PublishUserReaction("🔥")
+		}
+		close(skipped)
+	}()
+	select {
+	case <-skipped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publishing during a skip blocked")
+	}
+
+	// The handler still serves clients once Run starts
+	go handler.Run()
+	client := newTestClient(handler)
+	handler.join(client)
+	waitForRegistered(t, handler, 1)
+	timeout := time.After(2 * time.Second)
+	for {
+		eventBus.PublishSkip(&models.Song{YouTubeID: "after"}, nil, nil)
+		select {
+		case data := <-client.send:
+			if strings.Contains(string(data), `"after"`) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for a skip message after the flood")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestShutdownDisconnectsClients(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	go handler.Run()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("Failed to read initial state: %v", err)
+	}
+	waitForRegistered(t, handler, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := handler.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("Expected a going-away close, got %v", err)
+	}
+	if count := handler.ListenerCount(); count != 0 {
+		t.Errorf("Expected no listeners after shutdown, got %d", count)
+	}
+
+	// Events published during the rest of shutdown must not block
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 200; i++ {
+			handler.handlePlaybackStoppedEvent(events.PlaybackStoppedEvent{})
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publishing after shutdown blocked")
+	}
+
+	// Shutting down again returns straight away
+	if err := handler.Shutdown(ctx); err != nil {
+		t.Errorf("Second shutdown failed: %v", err)
+	}
+}
+
+func TestShutdownClosesEveryClient(t *testing.T) {
+	handler := NewHandler(&fakeRadioService{}, nil)
+	handler.listenerInterval = time.Hour
+	go handler.Run()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	var conns []*websocket.Conn
+	for i := 0; i < 3; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	waitForRegistered(t, handler, 3)
+	// A client still waiting in the register buffer when Run stops is
+	// closed as well
+	queued := newTestClient(handler)
+	handler.register <- queued
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := handler.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	for i, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var err error
+		for err == nil {
+			// Skip the initial playback state
+			_, _, err = conn.ReadMessage()
+		}
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("Expected client %d to get a going-away close, got %v", i, err)
+		}
+	}
+	if count := handler.ListenerCount(); count != 0 {
+		t.Errorf("Expected no listeners after shutdown, got %d", count)
+	}
+	if len(handler.register) != 0 {
+		t.Errorf("Expected no registrations left waiting, got %d", len(handler.register))
+	}
+}
+
+func TestMissedPongAllowance(t *testing.T) {
+	tests := []struct {
+		name        string
+		missedPongs int
+		wantDropped bool
+	}{
+		{name: "no allowance", missedPongs: 0, wantDropped: true},
+		{name: "within allowance", missedPongs: 1, wantDropped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const interval = 200 * time.Millisecond
+			handler := NewHandler(&fakeRadioService{}, nil)
+			handler.pingInterval = interval
+			handler.SetMissedPongs(tt.missedPongs)
+			go handler.Run()
+			defer handler.Shutdown(context.Background())
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			defer conn.Close()
+
+			// Drop the first ping as a flaky network would, answer the rest
+			var pings atomic.Int32
+			conn.SetPingHandler(func(data string) error {
+				if pings.Add(1) == 1 {
+					return nil
+				}
+				return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+			})
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+
+			select {
+			case <-closed:
+				if !tt.wantDropped {
+					t.Fatalf("Expected the client to stay connected after %d pings", pings.Load())
+				}
+			case <-time.After(5 * interval):
+				if tt.wantDropped {
+					t.Fatal("Expected the client to be dropped for missing a pong")
+				}
+				if pings.Load() < 3 {
+					t.Errorf("Expected the client to keep being pinged, got %d pings", pings.Load())
+				}
+				if count := handler.ListenerCount(); count != 1 {
+					t.Errorf("Expected the client to still be listening, got %d listeners", count)
+				}
+			}
+		})
+	}
+}
+
+// countingEventBus counts the handlers subscribed to it
+type countingEventBus struct {
+	mu     sync.Mutex
+	nextID events.SubscriptionID
+	active map[events.SubscriptionID]string
+}
+
+func (b *countingEventBus) Subscribe(eventType string, handler events.EventHandler) events.SubscriptionID {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	b.active[b.nextID] = eventType
+	return b.nextID
+}
+
+func (b *countingEventBus) Unsubscribe(eventType string, id events.SubscriptionID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active[id] == eventType {
+		delete(b.active, id)
+	}
+}
+
+func (b *countingEventBus) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.active)
+}
+
+func TestShutdownUnsubscribesFromEvents(t *testing.T) {
+	bus := &countingEventBus{active: make(map[events.SubscriptionID]string)}
+	handler := NewHandler(&fakeRadioService{}, bus)
+	if bus.count() == 0 {
+		t.Fatal("Expected the handler to subscribe to events")
+	}
+
+	go handler.Run()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := handler.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := bus.count(); got != 0 {
+		t.Errorf("Expected every subscription removed, %d left", got)
+	}
+}
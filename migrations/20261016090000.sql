@@ -0,0 +1,2 @@
+-- Create index "idx_songs_created_at" to table: "songs"
+CREATE INDEX "idx_songs_created_at" ON "public"."songs" ("created_at");
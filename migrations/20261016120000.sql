@@ -0,0 +1,2 @@
+-- Modify "songs" table
+ALTER TABLE "public"."songs" ADD COLUMN "tags" text NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+-- Modify "songs" table
+ALTER TABLE "public"."songs" ADD COLUMN "normalized" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- Modify "songs" table
+ALTER TABLE "public"."songs" ADD COLUMN "chapters" text NOT NULL DEFAULT '[]', ADD COLUMN "start_offset" integer NOT NULL DEFAULT 0, ADD COLUMN "end_offset" integer NOT NULL DEFAULT 0;
@@ -45,6 +45,31 @@ table "songs" {
     type = timestamp
     null = false
   }
+  column "tags" {
+    type = text
+    default = "[]"
+    null = false
+  }
+  column "normalized" {
+    type = boolean
+    default = false
+    null = false
+  }
+  column "chapters" {
+    type = text
+    default = "[]"
+    null = false
+  }
+  column "start_offset" {
+    type = integer
+    default = 0
+    null = false
+  }
+  column "end_offset" {
+    type = integer
+    default = 0
+    null = false
+  }
   primary_key {
     columns = [column.youtube_id]
   }
@@ -54,6 +79,9 @@ table "songs" {
   index "idx_songs_last_played" {
     columns = [column.last_played]
   }
+  index "idx_songs_created_at" {
+    columns = [column.created_at]
+  }
 }
 
 table "playlists" {